@@ -0,0 +1,154 @@
+package transition
+
+import (
+	"context"
+	"sort"
+	"strconv"
+)
+
+// Meta set metadata of the event, e.g. "label" is the default display label of the event and "order" the position of its AllowedActions
+func (event *Event[T]) Meta(key, value string) *Event[T] {
+	event.checkMutable("Event.Meta")
+	if event.metadata == nil {
+		event.metadata = map[string]string{}
+	}
+	event.metadata[key] = value
+	return event
+}
+
+// MetaValue returns the metadata of the event set with Meta, or an empty string
+func (event *Event[T]) MetaValue(key string) string {
+	return event.metadata[key]
+}
+
+// Meta set metadata of the state, e.g. to tag states shown in views, see StateMachine.View
+func (state *State[T]) Meta(key, value string) *State[T] {
+	state.checkMutable("State.Meta")
+	if state.metadata == nil {
+		state.metadata = map[string]string{}
+	}
+	state.metadata[key] = value
+	return state
+}
+
+// MetaValue returns the metadata of the state set with Meta, or an empty string
+func (state *State[T]) MetaValue(key string) string {
+	return state.metadata[key]
+}
+
+// Action is an event that can be triggered for a value, see AllowedActions
+type Action struct {
+	Event string `json:"event"`
+	// Label is the label of the event, see StateMachine.EventLabel
+	Label string `json:"label"`
+	// To lists the destinations of the transitions accepting the value, sorted
+	To []string `json:"to"`
+	// Authorized reports whether the actor of the context passes the authorizer of the event
+	Authorized bool `json:"authorized"`
+	// Metadata is the metadata of the event, see Event.Meta
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// SetActionOrder define how AllowedActions are sorted, by default by the "order" metadata of their event, then by name
+func (sm *StateMachine[T]) SetActionOrder(less func(a, b Action) bool) *StateMachine[T] {
+	sm.owner.checkMutable("SetActionOrder")
+	sm.actionLess = less
+	return sm
+}
+
+// ActionOption configure AllowedActions
+type ActionOption func(*actionConfig)
+
+type actionConfig struct {
+	locale           string
+	hideUnauthorized bool
+}
+
+// ActionsLocale translate the labels of actions in locale, see StateMachine.EventLabel
+func ActionsLocale(locale string) ActionOption {
+	return func(config *actionConfig) {
+		config.locale = locale
+	}
+}
+
+// HideUnauthorized leave out actions the actor isn't authorized to perform, e.g. lacking the roles of their event,
+// instead of returning them with Authorized false
+func HideUnauthorized() ActionOption {
+	return func(config *actionConfig) {
+		config.hideUnauthorized = true
+	}
+}
+
+// AllowedActions returns the events that can be triggered for value from its current state, evaluating guards,
+// along with their display label, destinations and whether the actor of ctx is authorized, ready to be served as JSON
+func (sm *StateMachine[T]) AllowedActions(ctx context.Context, value T, opts ...ActionOption) []Action {
+	config := actionConfig{locale: sm.defaultLocale}
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	if isNil(value) {
+		return nil
+	}
+
+	state := sm.currentState(value)
+
+	actions := []Action{}
+	for _, name := range sm.eventNames() {
+		if sm.isFrozen(name, value) || sm.blocked(name, state, value) != nil {
+			continue
+		}
+		event := sm.events[name]
+		matched, _ := sm.match(ctx, event, state, value)
+
+		var destinations []string
+		for _, transition := range matched {
+			if to, err := sm.destination(transition, value, nil); err == nil {
+				destinations = append(destinations, to)
+			}
+		}
+		if len(destinations) == 0 {
+			continue
+		}
+		sort.Strings(destinations)
+
+		action := Action{
+			Event:      name,
+			Label:      sm.EventLabel(name, config.locale),
+			To:         destinations,
+			Authorized: sm.checkAuthorization(ctx, event.Name, event, value) == nil,
+		}
+		if config.hideUnauthorized && !action.Authorized {
+			continue
+		}
+		if len(event.metadata) > 0 {
+			action.Metadata = map[string]string{}
+			for key, value := range event.metadata {
+				action.Metadata[key] = value
+			}
+		}
+		actions = append(actions, action)
+	}
+
+	less := sm.actionLess
+	if less == nil {
+		less = defaultActionLess
+	}
+	sort.SliceStable(actions, func(i, j int) bool {
+		return less(actions[i], actions[j])
+	})
+	return actions
+}
+
+// defaultActionLess sorts actions by the "order" metadata of their event, actions without order last, then by name
+func defaultActionLess(a, b Action) bool {
+	orderA, errA := strconv.Atoi(a.Metadata["order"])
+	orderB, errB := strconv.Atoi(b.Metadata["order"])
+	switch {
+	case errA == nil && errB == nil && orderA != orderB:
+		return orderA < orderB
+	case (errA == nil) != (errB == nil):
+		return errA == nil
+	}
+	return a.Event < b.Event
+}
@@ -0,0 +1,45 @@
+package transition
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestAllowedActions(t *testing.T) {
+	orderStateMachine := getStateMachine()
+	orderStateMachine.Event("checkout").Meta("label", "Check out").Meta("order", "1")
+	orderStateMachine.Event("cancel").Meta("label", "Cancel order").Meta("order", "2").Require(func(ctx context.Context, event string, order *Order) error {
+		if ActorFromContext(ctx) != "admin" {
+			return errors.New("only admins can cancel")
+		}
+		return nil
+	})
+	orderStateMachine.Event("cancel").To("cancelled").From("draft")
+	orderStateMachine.Event("split").To("checkout").From("draft")
+	orderStateMachine.Event("split").To("cancelled").From("draft")
+	orderStateMachine.Event("archive").To("cancelled").From("draft").Guard(func(context.Context, *Order) error {
+		return errors.New("not archivable")
+	})
+
+	actions := orderStateMachine.AllowedActions(WithActor(context.Background(), "agent"), &Order{})
+
+	data, err := json.Marshal(actions)
+	if err != nil {
+		t.Fatalf("actions should be serializable, got %v", err)
+	}
+
+	expected := `[{"event":"checkout","label":"Check out","to":["checkout"],"authorized":true,"metadata":{"label":"Check out","order":"1"}},` +
+		`{"event":"cancel","label":"Cancel order","to":["cancelled"],"authorized":false,"metadata":{"label":"Cancel order","order":"2"}},` +
+		`{"event":"split","label":"split","to":["cancelled","checkout"],"authorized":true}]`
+	if string(data) != expected {
+		t.Errorf("unexpected actions, got %s", data)
+	}
+
+	orderStateMachine.SetActionOrder(func(a, b Action) bool { return a.Event < b.Event })
+	actions = orderStateMachine.AllowedActions(context.Background(), &Order{})
+	if len(actions) != 3 || actions[0].Event != "cancel" || actions[2].Event != "split" {
+		t.Errorf("actions should follow the configured order, got %+v", actions)
+	}
+}
@@ -0,0 +1,231 @@
+package transition
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// PathStep is an edge of the transition graph, going from a state to another by an event
+type PathStep struct {
+	From  string
+	Event string
+	To    string
+}
+
+// Path is a sequence of steps through the transition graph
+type Path []PathStep
+
+func (path Path) String() string {
+	if len(path) == 0 {
+		return ""
+	}
+
+	var builder strings.Builder
+	builder.WriteString(path[0].From)
+	for _, step := range path {
+		fmt.Fprintf(&builder, " -%s-> %s", step.Event, step.To)
+	}
+	return builder.String()
+}
+
+// RuleViolation is returned by Assert when a rule doesn't hold, Path is a counterexample
+type RuleViolation struct {
+	Rule string
+	Path Path
+}
+
+func (violation *RuleViolation) Error() string {
+	return fmt.Sprintf("rule %s violated: %s", violation.Rule, violation.Path)
+}
+
+// Rule is a property of the transition graph checked by Assert
+type Rule interface {
+	String() string
+	check(graph *graph) *RuleViolation
+}
+
+// Assert check rules against the static transition graph, without running any hooks.
+// It returns a MultiError holding a RuleViolation, with a counterexample path, for every rule that doesn't hold
+func (sm *StateMachine[T]) Assert(rules ...Rule) error {
+	graph := sm.graph()
+
+	var errs []error
+	for _, rule := range rules {
+		if violation := rule.check(graph); violation != nil {
+			errs = append(errs, violation)
+		}
+	}
+	return newMultiError(errs)
+}
+
+// NeverReaches asserts there is no path from state from to state to
+func NeverReaches(from, to string) Rule {
+	return neverReaches{from: from, to: to}
+}
+
+type neverReaches struct {
+	from, to string
+}
+
+func (rule neverReaches) String() string {
+	return fmt.Sprintf("NeverReaches(%s, %s)", rule.from, rule.to)
+}
+
+func (rule neverReaches) check(graph *graph) *RuleViolation {
+	if path := graph.shortestPath(rule.from, rule.to); path != nil {
+		return &RuleViolation{Rule: rule.String(), Path: path}
+	}
+	return nil
+}
+
+// MustPrecede asserts that, starting from the initial state, eventB can only occur after eventA occurred
+func MustPrecede(eventA, eventB string) Rule {
+	return mustPrecede{eventA: eventA, eventB: eventB}
+}
+
+type mustPrecede struct {
+	eventA, eventB string
+}
+
+func (rule mustPrecede) String() string {
+	return fmt.Sprintf("MustPrecede(%s, %s)", rule.eventA, rule.eventB)
+}
+
+func (rule mustPrecede) check(graph *graph) *RuleViolation {
+	// search the states reachable without performing eventA, and report any eventB performed from them
+	var (
+		visited = map[string]bool{graph.initial: true}
+		paths   = map[string]Path{graph.initial: {}}
+		queue   = []string{graph.initial}
+	)
+
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+
+		for _, edge := range graph.edges[state] {
+			if edge.Event == rule.eventA {
+				continue
+			}
+
+			path := append(append(Path{}, paths[state]...), edge)
+			if edge.Event == rule.eventB {
+				return &RuleViolation{Rule: rule.String(), Path: path}
+			}
+
+			if !visited[edge.To] {
+				visited[edge.To] = true
+				paths[edge.To] = path
+				queue = append(queue, edge.To)
+			}
+		}
+	}
+	return nil
+}
+
+// NoPathFrom asserts state has no outgoing transition
+func NoPathFrom(state string) Rule {
+	return noPathFrom{state: state}
+}
+
+type noPathFrom struct {
+	state string
+}
+
+func (rule noPathFrom) String() string {
+	return fmt.Sprintf("NoPathFrom(%s)", rule.state)
+}
+
+func (rule noPathFrom) check(graph *graph) *RuleViolation {
+	if edges := graph.edges[rule.state]; len(edges) > 0 {
+		return &RuleViolation{Rule: rule.String(), Path: Path{edges[0]}}
+	}
+	return nil
+}
+
+// graph is the static transition graph of a state machine
+type graph struct {
+	initial string
+	states  []string
+	edges   map[string][]PathStep
+}
+
+func (sm *StateMachine[T]) graph() *graph {
+	known := map[string]bool{}
+	if sm.initialState != "" {
+		known[sm.initialState] = true
+	}
+	for name := range sm.states {
+		known[name] = true
+	}
+	for _, event := range sm.events {
+		for _, transition := range event.transitions {
+			if transition.toFunc == nil {
+				known[transition.to] = true
+			}
+			for _, from := range transition.froms {
+				known[from] = true
+			}
+		}
+	}
+
+	g := &graph{initial: sm.initialState, edges: map[string][]PathStep{}}
+	for name := range known {
+		g.states = append(g.states, name)
+	}
+	sort.Strings(g.states)
+
+	for name, event := range sm.events {
+		for _, from := range g.states {
+			for _, transition := range event.matchTransitions(from) {
+				// the destination of a ToFunc transition is only known when triggered, assume it can be any state
+				tos := []string{transition.to}
+				if transition.toFunc != nil {
+					tos = g.states
+				}
+				for _, to := range tos {
+					g.edges[from] = append(g.edges[from], PathStep{From: from, Event: name, To: to})
+				}
+			}
+		}
+	}
+
+	for _, edges := range g.edges {
+		sort.Slice(edges, func(i, j int) bool {
+			if edges[i].Event != edges[j].Event {
+				return edges[i].Event < edges[j].Event
+			}
+			return edges[i].To < edges[j].To
+		})
+	}
+	return g
+}
+
+// shortestPath returns the shortest non-empty path from a state to another, or nil if there is none
+func (g *graph) shortestPath(from, to string) Path {
+	var (
+		visited = map[string]bool{}
+		paths   = map[string]Path{from: {}}
+		queue   = []string{from}
+	)
+
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+
+		for _, edge := range g.edges[state] {
+			path := append(append(Path{}, paths[state]...), edge)
+			if edge.To == to {
+				return path
+			}
+
+			if !visited[edge.To] {
+				visited[edge.To] = true
+				paths[edge.To] = path
+				queue = append(queue, edge.To)
+			}
+		}
+	}
+	return nil
+}
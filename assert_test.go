@@ -0,0 +1,52 @@
+package transition
+
+import (
+	"errors"
+	"testing"
+)
+
+func getAssertStateMachine() *StateMachine[*Order] {
+	orderStateMachine := getStateMachine()
+	orderStateMachine.Event("process").To("processed").From("paid")
+	orderStateMachine.Event("deliver").To("delivered").From("processed")
+	orderStateMachine.Event("refund").To("paid_cancelled").From("paid", "processed")
+	return orderStateMachine
+}
+
+func TestAssertRulesHold(t *testing.T) {
+	err := getAssertStateMachine().Assert(
+		NeverReaches("delivered", "draft"),
+		NeverReaches("paid", "checkout"),
+		MustPrecede("pay", "refund"),
+		NoPathFrom("delivered"),
+	)
+
+	if err != nil {
+		t.Errorf("should not raise any error when rules hold, got %v", err)
+	}
+}
+
+func TestAssertRulesViolated(t *testing.T) {
+	orderStateMachine := getAssertStateMachine()
+	orderStateMachine.Event("reopen").To("draft").From("delivered")
+	orderStateMachine.Event("refund").To("paid_cancelled").From("checkout")
+
+	err := orderStateMachine.Assert(
+		NeverReaches("paid", "draft"),
+		MustPrecede("pay", "refund"),
+		NoPathFrom("delivered"),
+	)
+
+	var violation *RuleViolation
+	if !errors.As(err, &violation) {
+		t.Fatalf("should raise a rule violation, got %v", err)
+	}
+
+	expected := "3 errors occurred:\n" +
+		"  1. rule NeverReaches(paid, draft) violated: paid -process-> processed -deliver-> delivered -reopen-> draft\n" +
+		"  2. rule MustPrecede(pay, refund) violated: draft -checkout-> checkout -refund-> paid_cancelled\n" +
+		"  3. rule NoPathFrom(delivered) violated: delivered -reopen-> draft"
+	if err.Error() != expected {
+		t.Errorf("unexpected counterexamples, got\n%v", err)
+	}
+}
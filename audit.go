@@ -0,0 +1,133 @@
+package transition
+
+import (
+	"context"
+	"sort"
+)
+
+// AuditOption configure AuditValues
+type AuditOption func(*auditConfig)
+
+type auditConfig struct {
+	samples int
+}
+
+// AuditSamples define how many keys of values are sampled per reported state, 5 by default
+func AuditSamples(n int) AuditOption {
+	return func(config *auditConfig) {
+		config.samples = n
+	}
+}
+
+// AuditReport describe stored values against the state machine definition
+type AuditReport struct {
+	// Total is the number of audited values
+	Total int `json:"total"`
+	// States counts values per state, values without state are counted in the initial state
+	States map[string]int `json:"states"`
+	// Unknown are states of values the state machine doesn't know
+	Unknown map[string]AuditedState `json:"unknown,omitempty"`
+	// Unreachable are known states of values that can't be reached from the initial states, see InitialFor
+	Unreachable map[string]AuditedState `json:"unreachable,omitempty"`
+	// Terminal are known states of values that have no outgoing transition
+	Terminal map[string]AuditedState `json:"terminal,omitempty"`
+}
+
+// AuditedState is a state reported by AuditValues, Samples are keys of values in the state, see SetKeyFunc
+type AuditedState struct {
+	Count   int      `json:"count"`
+	Samples []string `json:"samples,omitempty"`
+}
+
+// AuditValues check stored values against the state machine, e.g. before deploying a definition change. values
+// is an iterator yielding every value until yield returns false, so they can be streamed from any storage:
+//
+//	report, err := sm.AuditValues(ctx, func(yield func(*Order) bool) {
+//		for rows.Next() {
+//			if !yield(scanOrder(rows)) {
+//				return
+//			}
+//		}
+//	})
+//
+// When ctx is done, the report of the values audited so far is returned along with ctx's error
+func (sm *StateMachine[T]) AuditValues(ctx context.Context, values func(yield func(T) bool), opts ...AuditOption) (AuditReport, error) {
+	config := auditConfig{samples: 5}
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	var (
+		graph     = sm.graph()
+		reachable = map[string]bool{}
+		report    = AuditReport{States: map[string]int{}}
+		err       error
+	)
+	for _, initial := range sm.initialStates() {
+		for state := range graph.reachable(initial) {
+			reachable[state] = true
+		}
+	}
+
+	record := func(states *map[string]AuditedState, state string, value T) {
+		if *states == nil {
+			*states = map[string]AuditedState{}
+		}
+		audited := (*states)[state]
+		audited.Count++
+		if sm.keyFunc != nil && len(audited.Samples) < config.samples {
+			audited.Samples = append(audited.Samples, sm.keyFunc(value))
+		}
+		(*states)[state] = audited
+	}
+
+	values(func(value T) bool {
+		if err = ctx.Err(); err != nil {
+			return false
+		}
+		if isNil(value) {
+			return true
+		}
+
+		state := sm.currentState(value)
+		report.Total++
+		report.States[state]++
+
+		switch {
+		case !graph.known(state):
+			record(&report.Unknown, state, value)
+		case !reachable[state]:
+			record(&report.Unreachable, state, value)
+		case len(graph.edges[state]) == 0:
+			record(&report.Terminal, state, value)
+		}
+		return true
+	})
+	return report, err
+}
+
+// known reports whether state is declared or used by a transition
+func (g *graph) known(state string) bool {
+	i := sort.SearchStrings(g.states, state)
+	return i < len(g.states) && g.states[i] == state
+}
+
+// reachable returns the states reachable from state, state included
+func (g *graph) reachable(state string) map[string]bool {
+	var (
+		visited = map[string]bool{state: true}
+		queue   = []string{state}
+	)
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+
+		for _, edge := range g.edges[state] {
+			if !visited[edge.To] {
+				visited[edge.To] = true
+				queue = append(queue, edge.To)
+			}
+		}
+	}
+	return visited
+}
@@ -0,0 +1,63 @@
+package transition
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"testing"
+)
+
+func TestAuditValues(t *testing.T) {
+	orderStateMachine := getStateMachine()
+	orderStateMachine.SetKeyFunc(func(order *Order) string { return strconv.Itoa(order.Id) })
+
+	var orders []*Order
+	for i, state := range []string{"", "draft", "checkout", "paid", "shipped", "shipped", "cancelled", "paid_cancelled"} {
+		order := &Order{Id: i}
+		order.State = state
+		orders = append(orders, order)
+	}
+	iterate := func(yield func(*Order) bool) {
+		for _, order := range orders {
+			if !yield(order) {
+				return
+			}
+		}
+	}
+
+	report, err := orderStateMachine.AuditValues(context.Background(), iterate, AuditSamples(1))
+	if err != nil {
+		t.Fatalf("should not raise any error when auditing, got %v", err)
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		t.Fatalf("report should be serializable, got %v", err)
+	}
+	expected := `{"total":8,"states":{"cancelled":1,"checkout":1,"draft":2,"paid":1,"paid_cancelled":1,"shipped":2},` +
+		`"unknown":{"shipped":{"count":2,"samples":["4"]}},` +
+		`"unreachable":{"cancelled":{"count":1,"samples":["6"]},"paid_cancelled":{"count":1,"samples":["7"]}},` +
+		`"terminal":{"paid":{"count":1,"samples":["3"]}}}`
+	if string(data) != expected {
+		t.Errorf("unexpected report, got %s", data)
+	}
+}
+
+func TestAuditValuesCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	report, err := getStateMachine().AuditValues(ctx, func(yield func(*Order) bool) {
+		for i := 0; i < 10; i++ {
+			if i == 3 {
+				cancel()
+			}
+			if !yield(&Order{}) {
+				return
+			}
+		}
+	})
+
+	if !errors.Is(err, context.Canceled) || report.Total != 3 {
+		t.Errorf("audit should stop when the context is cancelled, got %d, %v", report.Total, err)
+	}
+}
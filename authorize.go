@@ -0,0 +1,91 @@
+package transition
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrUnauthorized is raised when an authorizer rejects a trigger, it wraps the authorizer's error
+var ErrUnauthorized = errors.New("unauthorized")
+
+// Authorizer decides whether event can be triggered for value, a non-nil error rejects the trigger.
+// The context is the one given to TriggerContext, e.g. carrying the actor
+type Authorizer[T Stater] func(ctx context.Context, event string, value T) error
+
+// Authorize register the authorizer of the state machine, it's checked before any other phase of a trigger,
+// unless the event has its own authorizer, see Event.Require
+func (sm *StateMachine[T]) Authorize(authorizer Authorizer[T]) *StateMachine[T] {
+	sm.owner.checkMutable("Authorize")
+	sm.authorize = authorizer
+	return sm
+}
+
+// Require register the authorizer of the event, it overrides the authorizer of the state machine
+func (event *Event[T]) Require(authorizer Authorizer[T]) *Event[T] {
+	event.checkMutable("Event.Require")
+	event.authorize = authorizer
+	return event
+}
+
+// SetActorFunc define how the actor of a trigger is extracted from its context, it's recorded by TriggerTraced.
+// By default the actor set with WithActor is used
+func (sm *StateMachine[T]) SetActorFunc(fc func(ctx context.Context) string) *StateMachine[T] {
+	sm.owner.checkMutable("SetActorFunc")
+	sm.actorFunc = fc
+	return sm
+}
+
+// SetDefaultActor define the actor recorded when the context of a trigger has none, "system" by default
+func (sm *StateMachine[T]) SetDefaultActor(actor string) *StateMachine[T] {
+	sm.owner.checkMutable("SetDefaultActor")
+	sm.defaultActor = actor
+	return sm
+}
+
+type actorContextKey struct{}
+
+// WithActor returns a copy of ctx carrying the actor triggering events, see TriggerContext
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorContextKey{}, actor)
+}
+
+// ActorFromContext returns the actor set with WithActor, or an empty string
+func ActorFromContext(ctx context.Context) string {
+	actor, _ := ctx.Value(actorContextKey{}).(string)
+	return actor
+}
+
+// actor returns the actor of a trigger performed with ctx
+func (sm *StateMachine[T]) actor(ctx context.Context) string {
+	actorFunc := sm.actorFunc
+	if actorFunc == nil {
+		actorFunc = ActorFromContext
+	}
+	if actor := actorFunc(ctx); actor != "" {
+		return actor
+	}
+	return sm.defaultActor
+}
+
+// checkAuthorization checks the roles of the event, then runs the authorizer of the event, or of the state machine.
+// Events of sub-machines have no event in the state machine, only its authorizer runs then, see SubMachine
+func (sm *StateMachine[T]) checkAuthorization(ctx context.Context, name string, event *Event[T], value T) error {
+	authorizer := sm.authorize
+	if event != nil {
+		if err := sm.checkRoles(ctx, event); err != nil {
+			return err
+		}
+		if event.authorize != nil {
+			authorizer = event.authorize
+		}
+	}
+	if authorizer == nil {
+		return nil
+	}
+
+	if err := authorizer(ctx, name, value); err != nil {
+		return fmt.Errorf("%w: %w", ErrUnauthorized, err)
+	}
+	return nil
+}
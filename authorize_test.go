@@ -0,0 +1,118 @@
+package transition
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type actorKey struct{}
+
+func actorOf(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
+
+func TestAuthorize(t *testing.T) {
+	var (
+		orderStateMachine = getStateMachine()
+		agent             = context.WithValue(context.Background(), actorKey{}, "agent")
+		admin             = context.WithValue(context.Background(), actorKey{}, "admin")
+		exited            bool
+	)
+	orderStateMachine.Event("cancel").To("cancelled").From("draft", "checkout")
+	orderStateMachine.Event("refund").To("paid_cancelled").From("paid")
+	orderStateMachine.State("paid").Exit(func(*Order) error {
+		exited = true
+		return nil
+	})
+
+	orderStateMachine.Authorize(func(ctx context.Context, event string, order *Order) error {
+		if actorOf(ctx) == "" {
+			return errors.New("anonymous")
+		}
+		return nil
+	})
+	orderStateMachine.Event("refund").Require(func(ctx context.Context, event string, order *Order) error {
+		if actorOf(ctx) != "admin" {
+			return errors.New("only admins can refund")
+		}
+		return nil
+	})
+
+	order := &Order{}
+	if err := orderStateMachine.Trigger("cancel", order); !errors.Is(err, ErrUnauthorized) || order.State != "" {
+		t.Errorf("anonymous trigger should be unauthorized, got %v", err)
+	}
+	if err := orderStateMachine.TriggerContext(agent, "cancel", order); err != nil || order.State != "cancelled" {
+		t.Errorf("agent should be allowed to cancel, got %v", err)
+	}
+
+	order = &Order{}
+	order.State = "paid"
+	err := orderStateMachine.TriggerContext(agent, "refund", order)
+	var transitionErr *TransitionError
+	if !errors.As(err, &transitionErr) || transitionErr.Phase != PhaseAuthorize || !IsPermanent(err) {
+		t.Errorf("agent should not be allowed to refund, got %v", err)
+	}
+	if order.State != "paid" || exited {
+		t.Errorf("unauthorized trigger should have no side effects")
+	}
+	if err := orderStateMachine.TriggerContext(admin, "refund", order); err != nil || order.State != "paid_cancelled" {
+		t.Errorf("admin should be allowed to refund, got %v", err)
+	}
+}
+
+func TestAuthorizeBeforeVersion(t *testing.T) {
+	var (
+		orderStateMachine = getStateMachine().Version("v2")
+		migrated          bool
+	)
+	orderStateMachine.OnVersionMismatch(func(order *Order, recorded, current string) error {
+		migrated = true
+		return nil
+	})
+	orderStateMachine.Authorize(func(ctx context.Context, event string, order *Order) error {
+		return errors.New("anonymous")
+	})
+
+	order := &Order{}
+	order.State, order.Version = "checkout", "v1"
+	if err := orderStateMachine.Trigger("pay", order); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("anonymous trigger should be unauthorized, got %v", err)
+	}
+	if migrated || order.Version != "v1" {
+		t.Errorf("denied triggers should not call the version hook")
+	}
+}
+
+func TestTraceActor(t *testing.T) {
+	orderStateMachine := getStateMachine(WithMutableAfterStart())
+
+	trace, err := orderStateMachine.TriggerTraced("checkout", &Order{})
+	if err != nil || trace.Actor != "system" {
+		t.Errorf("trace should record the default actor, got %q, %v", trace.Actor, err)
+	}
+
+	orderStateMachine.SetDefaultActor("cron")
+	if trace, _ := orderStateMachine.TriggerTraced("checkout", &Order{}); trace.Actor != "cron" {
+		t.Errorf("trace should record the configured default actor, got %q", trace.Actor)
+	}
+
+	orderStateMachine.SetActorFunc(actorOf)
+	trace = &Trace{}
+	err = orderStateMachine.trigger(context.WithValue(context.Background(), actorKey{}, "agent"), "checkout", &Order{}, triggerOptions{trace: trace})
+	if err != nil || trace.Actor != "agent" {
+		t.Errorf("trace should record the actor extracted by the actor func, got %q, %v", trace.Actor, err)
+	}
+}
+
+func TestWithActor(t *testing.T) {
+	ctx := WithActor(context.Background(), "alice")
+	if actor := ActorFromContext(ctx); actor != "alice" {
+		t.Errorf("actor should be read back from the context, got %q", actor)
+	}
+	if actor := ActorFromContext(context.Background()); actor != "" {
+		t.Errorf("context without actor should have no actor, got %q", actor)
+	}
+}
@@ -0,0 +1,200 @@
+package transition
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+// ErrNoEventMatched is returned by the hook registrations of EventsMatching when no event matches
+var ErrNoEventMatched = errors.New("no event matched")
+
+// batchCounter identifies the hooks registered together, so their handle finds them again
+var batchCounter atomic.Uint64
+
+// HookHandle holds the hooks registered by a single call of a StateSelection or EventSelection
+type HookHandle struct {
+	hooks  int
+	remove func()
+}
+
+// Len returns how many hooks were registered
+func (handle *HookHandle) Len() int {
+	return handle.hooks
+}
+
+// Remove unregister all the hooks, hooks registered since on the same states and transitions are kept. Like
+// registering, it panics after the first trigger unless the machine was created with WithMutableAfterStart
+func (handle *HookHandle) Remove() {
+	handle.remove()
+}
+
+// StateSelection registers the same hooks on several states, see StatesOf
+type StateSelection[T Stater] struct {
+	sm    *StateMachine[T]
+	names []string
+	err   error
+	// skip records the registrations of selections skipped by a ConditionalRegistrar
+	skip func(what string)
+}
+
+// StatesOf selects the states names, to register the same hooks on all of them:
+//
+//	handle, err := sm.StatesOf("checkout", "paid", "processed").Exit(releaseReservation)
+//
+// Registering fails, registering nothing, when some states are not declared with State or Initial
+func (sm *StateMachine[T]) StatesOf(names ...string) *StateSelection[T] {
+	selection := &StateSelection[T]{sm: sm, names: names}
+	var errs []error
+	for _, name := range names {
+		if _, ok := sm.states[name]; !ok && name != sm.initialState {
+			errs = append(errs, fmt.Errorf("state %s: %w", name, ErrUndeclaredState))
+		}
+	}
+	selection.err = newMultiError(errs)
+	return selection
+}
+
+// Enter register fc as enter hook of every selected state
+func (selection *StateSelection[T]) Enter(fc func(value T) error) (*HookHandle, error) {
+	return selection.register("StateSelection.Enter", fc, anonymousHook(2), (*State[T]).enterHooks)
+}
+
+// Exit register fc as exit hook of every selected state
+func (selection *StateSelection[T]) Exit(fc func(value T) error) (*HookHandle, error) {
+	return selection.register("StateSelection.Exit", fc, anonymousHook(2), (*State[T]).exitHooks)
+}
+
+func (selection *StateSelection[T]) register(what string, fc func(value T) error, ref HookRef, hooks func(*State[T]) (*[]func(value T) error, *[]HookRef)) (*HookHandle, error) {
+	if selection.skip != nil {
+		selection.skip(what)
+		return &HookHandle{remove: func() {}}, nil
+	}
+	if selection.err != nil {
+		return nil, selection.err
+	}
+
+	sm := selection.sm
+	ref.batch = batchCounter.Add(1)
+	for _, name := range selection.names {
+		fcs, refs := hooks(sm.State(name))
+		*fcs, *refs = append(*fcs, fc), append(*refs, ref)
+	}
+	return &HookHandle{hooks: len(selection.names), remove: func() {
+		sm.owner.checkMutable("HookHandle.Remove")
+		for _, name := range selection.names {
+			if state, ok := sm.ownedState(name); ok {
+				fcs, refs := hooks(state)
+				removeBatch(ref.batch, fcs, refs)
+			}
+		}
+	}}, nil
+}
+
+func (state *State[T]) enterHooks() (*[]func(value T) error, *[]HookRef) {
+	return &state.enters, &state.enterRefs
+}
+
+func (state *State[T]) exitHooks() (*[]func(value T) error, *[]HookRef) {
+	return &state.exits, &state.exitRefs
+}
+
+// EventSelection registers the same hooks on the transitions of several events, see EventsMatching and AllEvents
+type EventSelection[T Stater] struct {
+	sm    *StateMachine[T]
+	names []string
+	err   error
+	// skip records the registrations of selections skipped by a ConditionalRegistrar
+	skip func(what string)
+}
+
+// EventsMatching selects the events whose name match accepts, to register the same hooks on all their transitions.
+// Registering fails, registering nothing, when no event matches
+func (sm *StateMachine[T]) EventsMatching(match func(name string) bool) *EventSelection[T] {
+	selection := &EventSelection[T]{sm: sm}
+	for _, name := range sm.eventNames() {
+		if match(name) {
+			selection.names = append(selection.names, name)
+		}
+	}
+	if len(selection.names) == 0 {
+		selection.err = ErrNoEventMatched
+	}
+	return selection
+}
+
+// AllEvents selects every event, to register the same hooks on all their transitions:
+//
+//	handle, err := sm.AllEvents().After(audit)
+//
+// Only the events and transitions defined when the hooks are registered get them
+func (sm *StateMachine[T]) AllEvents() *EventSelection[T] {
+	return &EventSelection[T]{sm: sm, names: sm.eventNames()}
+}
+
+// Before register fc as before hook of every transition of the selected events
+func (selection *EventSelection[T]) Before(fc func(value T) error) (*HookHandle, error) {
+	return selection.register("EventSelection.Before", fc, anonymousHook(2), (*EventTransition[T]).beforeHooks)
+}
+
+// After register fc as after hook of every transition of the selected events
+func (selection *EventSelection[T]) After(fc func(value T) error) (*HookHandle, error) {
+	return selection.register("EventSelection.After", fc, anonymousHook(2), (*EventTransition[T]).afterHooks)
+}
+
+func (selection *EventSelection[T]) register(what string, fc func(value T) error, ref HookRef, hooks func(*EventTransition[T]) (*[]func(value T) error, *[]HookRef)) (*HookHandle, error) {
+	if selection.skip != nil {
+		selection.skip(what)
+		return &HookHandle{remove: func() {}}, nil
+	}
+	if selection.err != nil {
+		return nil, selection.err
+	}
+
+	var (
+		sm    = selection.sm
+		count int
+	)
+	ref.batch = batchCounter.Add(1)
+	for _, name := range selection.names {
+		for _, transition := range sm.Event(name).transitions {
+			fcs, refs := hooks(transition)
+			*fcs, *refs = append(*fcs, fc), append(*refs, ref)
+			count++
+		}
+	}
+	return &HookHandle{hooks: count, remove: func() {
+		sm.owner.checkMutable("HookHandle.Remove")
+		for _, name := range selection.names {
+			if event, ok := sm.ownedEvent(name); ok {
+				for _, transition := range event.transitions {
+					fcs, refs := hooks(transition)
+					removeBatch(ref.batch, fcs, refs)
+				}
+			}
+		}
+	}}, nil
+}
+
+func (transition *EventTransition[T]) beforeHooks() (*[]func(value T) error, *[]HookRef) {
+	return &transition.befores, &transition.beforeRefs
+}
+
+func (transition *EventTransition[T]) afterHooks() (*[]func(value T) error, *[]HookRef) {
+	return &transition.afters, &transition.afterRefs
+}
+
+// removeBatch removes from fcs the hooks registered by batch, refs are parallel to fcs. The slices are copied, as
+// clones may share them
+func removeBatch[T Stater](batch uint64, fcs *[]func(value T) error, refs *[]HookRef) {
+	var (
+		keptFcs  []func(value T) error
+		keptRefs []HookRef
+	)
+	for i, ref := range *refs {
+		if ref.batch != batch {
+			keptFcs, keptRefs = append(keptFcs, (*fcs)[i]), append(keptRefs, ref)
+		}
+	}
+	*fcs, *refs = keptFcs, keptRefs
+}
@@ -0,0 +1,92 @@
+package transition
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestStatesOf(t *testing.T) {
+	var (
+		orderStateMachine = getStateMachine()
+		released          []string
+	)
+
+	handle, err := orderStateMachine.StatesOf("draft", "checkout").Exit(func(order *Order) error {
+		released = append(released, order.GetState())
+		return nil
+	})
+	if err != nil || handle.Len() != 2 {
+		t.Fatalf("should register an exit hook on both states, got %v", err)
+	}
+
+	// triggered on a clone, as the definition can't change once a machine triggered
+	clone, order := orderStateMachine.Clone(), &Order{}
+	clone.Trigger("checkout", order)
+	clone.Trigger("pay", order)
+	if strings.Join(released, ",") != "draft,checkout" {
+		t.Errorf("the hook should run when exiting each state, got %v", released)
+	}
+
+	handle.Remove()
+	released = nil
+	orderStateMachine.Trigger("checkout", &Order{})
+	if len(released) != 0 || len(orderStateMachine.states["checkout"].exitRefs) != 0 {
+		t.Errorf("removed hooks should not run, got %v", released)
+	}
+
+	if _, err := orderStateMachine.StatesOf("checkout", "shipped").Enter(func(order *Order) error { return nil }); !errors.Is(err, ErrUndeclaredState) {
+		t.Errorf("should reject undeclared states, got %v", err)
+	}
+	if _, ok := orderStateMachine.states["shipped"]; ok || len(orderStateMachine.states["checkout"].enters) != 0 {
+		t.Errorf("nothing should be registered when a state is undeclared")
+	}
+}
+
+func TestEventsMatching(t *testing.T) {
+	var (
+		orderStateMachine = getStateMachine()
+		audited           []string
+		audit             = func(order *Order) error {
+			audited = append(audited, order.GetState())
+			return nil
+		}
+	)
+	orderStateMachine.Event("cancel").To("cancelled").From("draft", "checkout")
+	orderStateMachine.Event("cancel").To("paid_cancelled").From("paid")
+
+	handle, err := orderStateMachine.EventsMatching(func(name string) bool { return name == "cancel" }).Before(audit)
+	if err != nil || handle.Len() != 2 {
+		t.Fatalf("should register a before hook on both cancel transitions, got %v", err)
+	}
+
+	all, err := orderStateMachine.AllEvents().After(audit)
+	if err != nil || all.Len() != 4 {
+		t.Fatalf("should register an after hook on every transition, got %v", err)
+	}
+
+	// triggered on a clone, as the definition can't change once a machine triggered
+	clone, order := orderStateMachine.Clone(), &Order{}
+	clone.Trigger("checkout", order)
+	clone.Trigger("cancel", order)
+	if strings.Join(audited, ",") != "checkout,checkout,cancelled" {
+		t.Errorf("unexpected hooks run %v", audited)
+	}
+
+	// removing one batch keeps the other, and the clone keeps both
+	handle.Remove()
+	audited = nil
+	orderStateMachine.Trigger("cancel", &Order{})
+	if strings.Join(audited, ",") != "cancelled" {
+		t.Errorf("only the after hook should be left, got %v", audited)
+	}
+	audited = nil
+	clone.Trigger("cancel", &Order{})
+	if strings.Join(audited, ",") != "draft,cancelled" {
+		t.Errorf("the clone should keep its hooks, got %v", audited)
+	}
+
+	if _, err := orderStateMachine.EventsMatching(func(name string) bool { return false }).After(audit); !errors.Is(err, ErrNoEventMatched) {
+		t.Errorf("should fail when no event matches, got %v", err)
+	}
+}
@@ -0,0 +1,181 @@
+package transition
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// BehaviorChange is how an event behaves differently for a value between two state machines, see CompareBehavior
+type BehaviorChange string
+
+const (
+	// BehaviorRejected events were allowed by the old state machine and aren't by the new one
+	BehaviorRejected BehaviorChange = "rejected"
+	// BehaviorAllowed events weren't allowed by the old state machine and are by the new one
+	BehaviorAllowed BehaviorChange = "allowed"
+	// BehaviorDestination events are allowed by both state machines but go to another state
+	BehaviorDestination BehaviorChange = "destination"
+)
+
+// DiffReport describe the values behaving differently between two state machines, see CompareBehavior
+type DiffReport struct {
+	// Values is the number of compared values, Events the compared events
+	Values int      `json:"values"`
+	Events []string `json:"events"`
+	// Diffs are the disagreements, sorted by state, event and change
+	Diffs []BehaviorDiff `json:"diffs,omitempty"`
+}
+
+// BehaviorDiff is a disagreement shared by values in the same state, Samples are keys of the values, see SetKeyFunc
+type BehaviorDiff struct {
+	State  string         `json:"state"`
+	Event  string         `json:"event"`
+	Change BehaviorChange `json:"change"`
+	// OldTo and NewTo are the destinations of the event, empty when it isn't allowed
+	OldTo   string   `json:"old_to,omitempty"`
+	NewTo   string   `json:"new_to,omitempty"`
+	Count   int      `json:"count"`
+	Samples []string `json:"samples,omitempty"`
+}
+
+// String renders the report for humans, e.g. "paid/cancel: 2 values went to paid_cancelled, now rejected (e.g. 1, 2)"
+func (report DiffReport) String() string {
+	if len(report.Diffs) == 0 {
+		return fmt.Sprintf("no behavior changes over %d values and %d events", report.Values, len(report.Events))
+	}
+
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "%d behavior changes over %d values and %d events:\n", len(report.Diffs), report.Values, len(report.Events))
+	for _, diff := range report.Diffs {
+		var change string
+		switch diff.Change {
+		case BehaviorRejected:
+			change = fmt.Sprintf("went to %s, now rejected", diff.OldTo)
+		case BehaviorAllowed:
+			change = fmt.Sprintf("were rejected, now go to %s", diff.NewTo)
+		default:
+			change = fmt.Sprintf("went to %s, now go to %s", diff.OldTo, diff.NewTo)
+		}
+		fmt.Fprintf(&builder, "%s/%s: %d values %s", diff.State, diff.Event, diff.Count, change)
+		if len(diff.Samples) > 0 {
+			fmt.Fprintf(&builder, " (e.g. %s)", strings.Join(diff.Samples, ", "))
+		}
+		builder.WriteString("\n")
+	}
+	return builder.String()
+}
+
+// CompareBehavior check how every event behaves for every value with old and updated, e.g. on a sample of stored
+// orders before deploying a definition change. Events are compared like Can: guards run but hooks never do, and
+// values aren't changed. Events default to those of both state machines when empty.
+// Values are grouped by their state in old, nil values and failing ToFunc destinations are returned in a MultiError
+// along with the report of the other values
+func CompareBehavior[T Stater](old, updated *StateMachine[T], values []T, events []string) (DiffReport, error) {
+	if old == nil || updated == nil {
+		return DiffReport{}, errors.New("failed to compare behavior: nil state machine")
+	}
+	if len(events) == 0 {
+		set := map[string]bool{}
+		for _, name := range append(old.eventNames(), updated.eventNames()...) {
+			if !set[name] {
+				set[name] = true
+				events = append(events, name)
+			}
+		}
+		sort.Strings(events)
+	}
+
+	type diffKey struct {
+		state, event string
+		change       BehaviorChange
+		oldTo, newTo string
+	}
+	var (
+		report  = DiffReport{Events: events}
+		diffs   = map[diffKey]*BehaviorDiff{}
+		keyFunc = old.keyFunc
+		errs    []error
+	)
+	if keyFunc == nil {
+		keyFunc = updated.keyFunc
+	}
+	for i, value := range values {
+		if isNil(value) {
+			errs = append(errs, fmt.Errorf("value %d: %w", i, ErrNilValue))
+			continue
+		}
+		report.Values++
+
+		state := old.currentState(value)
+		for _, event := range events {
+			oldTo, oldErr := old.behavior(event, value)
+			newTo, newErr := updated.behavior(event, value)
+			for _, err := range []error{oldErr, newErr} {
+				if err != nil {
+					errs = append(errs, fmt.Errorf("value %d, event %s: %w", i, event, err))
+				}
+			}
+
+			key := diffKey{state: state, event: event, oldTo: oldTo, newTo: newTo}
+			switch {
+			case oldErr != nil || newErr != nil || oldTo == newTo:
+				continue
+			case newTo == "":
+				key.change = BehaviorRejected
+			case oldTo == "":
+				key.change = BehaviorAllowed
+			default:
+				key.change = BehaviorDestination
+			}
+
+			diff := diffs[key]
+			if diff == nil {
+				diff = &BehaviorDiff{State: state, Event: event, Change: key.change, OldTo: oldTo, NewTo: newTo}
+				diffs[key] = diff
+			}
+			diff.Count++
+			if keyFunc != nil && len(diff.Samples) < 5 {
+				diff.Samples = append(diff.Samples, keyFunc(value))
+			}
+		}
+	}
+
+	for _, diff := range diffs {
+		report.Diffs = append(report.Diffs, *diff)
+	}
+	sort.Slice(report.Diffs, func(i, j int) bool {
+		a, b := report.Diffs[i], report.Diffs[j]
+		if a.State != b.State {
+			return a.State < b.State
+		}
+		if a.Event != b.Event {
+			return a.Event < b.Event
+		}
+		if a.Change != b.Change {
+			return a.Change < b.Change
+		}
+		if a.OldTo != b.OldTo {
+			return a.OldTo < b.OldTo
+		}
+		return a.NewTo < b.NewTo
+	})
+	return report, newMultiError(errs)
+}
+
+// behavior returns the state event would move value to, empty when it can't be triggered, see CanContext
+func (sm *StateMachine[T]) behavior(name string, value T) (string, error) {
+	event := sm.events[name]
+	state := sm.currentState(value)
+	if event == nil || sm.isFrozen(name, value) || sm.blocked(name, state, value) != nil {
+		return "", nil
+	}
+
+	matched, _ := sm.match(context.Background(), event, state, value)
+	if len(matched) != 1 {
+		return "", nil
+	}
+	return sm.destination(matched[0], value, nil)
+}
@@ -0,0 +1,69 @@
+package transition
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestCompareBehavior(t *testing.T) {
+	old := getStateMachine()
+	old.SetKeyFunc(func(order *Order) string { return strconv.Itoa(order.Id) })
+	old.Event("cancel").To("cancelled").From("draft", "checkout")
+	old.Event("deliver").To("delivered").From("paid")
+
+	updated := getStateMachine()
+	updated.Event("cancel").To("cancelled").From("draft")
+	updated.Event("deliver").To("processed").From("paid")
+	updated.Event("refund").To("paid_cancelled").From("paid")
+
+	orders := []*Order{{Id: 1}, {Id: 2}, {Id: 3}, {Id: 4}, nil}
+	orders[1].SetState("checkout")
+	orders[2].SetState("checkout")
+	orders[3].SetState("paid")
+
+	report, err := CompareBehavior(old, updated, orders, nil)
+	if !errors.Is(err, ErrNilValue) {
+		t.Errorf("nil values should be reported, got %v", err)
+	}
+
+	expected := DiffReport{
+		Values: 4,
+		Events: []string{"cancel", "checkout", "deliver", "pay", "refund"},
+		Diffs: []BehaviorDiff{
+			{State: "checkout", Event: "cancel", Change: BehaviorRejected, OldTo: "cancelled", Count: 2, Samples: []string{"2", "3"}},
+			{State: "paid", Event: "deliver", Change: BehaviorDestination, OldTo: "delivered", NewTo: "processed", Count: 1, Samples: []string{"4"}},
+			{State: "paid", Event: "refund", Change: BehaviorAllowed, NewTo: "paid_cancelled", Count: 1, Samples: []string{"4"}},
+		},
+	}
+	if !reflect.DeepEqual(report, expected) {
+		t.Errorf("unexpected report %+v", report)
+	}
+	if orders[3].GetState() != "paid" {
+		t.Errorf("values should not change, got %s", orders[3].GetState())
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var unmarshaled DiffReport
+	if err := json.Unmarshal(data, &unmarshaled); err != nil || !reflect.DeepEqual(unmarshaled, report) {
+		t.Errorf("reports should round-trip through JSON, got %+v, %v", unmarshaled, err)
+	}
+
+	expectedString := `3 behavior changes over 4 values and 5 events:
+checkout/cancel: 2 values went to cancelled, now rejected (e.g. 2, 3)
+paid/deliver: 1 values went to delivered, now go to processed (e.g. 4)
+paid/refund: 1 values were rejected, now go to paid_cancelled (e.g. 4)
+`
+	if report.String() != expectedString {
+		t.Errorf("unexpected string\n%s", report.String())
+	}
+
+	if report, err := CompareBehavior(old, old, orders[:4], []string{"cancel"}); err != nil || len(report.Diffs) != 0 || report.String() != "no behavior changes over 4 values and 1 events" {
+		t.Errorf("identical state machines should agree, got %s, %v", report, err)
+	}
+}
@@ -0,0 +1,103 @@
+package transition
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+const benchmarkStates = 50
+
+// getRingStateMachine returns a machine of benchmarkStates states, advance moving values from each state to the next
+// and back to the first from the last, with a before and an after hook on every transition
+func getRingStateMachine() *StateMachine[*Order] {
+	sm := New(&Order{})
+	sm.Initial("s0")
+
+	hook := func(order *Order) error { return nil }
+	for i := 0; i < benchmarkStates; i++ {
+		sm.State(fmt.Sprintf("s%d", i))
+		sm.Event("advance").To(fmt.Sprintf("s%d", (i+1)%benchmarkStates)).From(fmt.Sprintf("s%d", i)).Before(hook).After(hook)
+	}
+	return sm
+}
+
+var triggerBenchmarks = []struct {
+	name      string
+	setup     func(sm *StateMachine[*Order])
+	benchmark func(b *testing.B, sm *StateMachine[*Order])
+}{
+	{
+		name:      "serial",
+		benchmark: benchmarkTriggerSerial,
+	},
+	{
+		name:      "parallel",
+		benchmark: benchmarkTriggerParallel,
+	},
+	{
+		name:      "parallel with stats",
+		setup:     func(sm *StateMachine[*Order]) { sm.EnableStats() },
+		benchmark: benchmarkTriggerParallel,
+	},
+}
+
+func benchmarkTriggerSerial(b *testing.B, sm *StateMachine[*Order]) {
+	order := &Order{}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := sm.Trigger("advance", order); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func benchmarkTriggerParallel(b *testing.B, sm *StateMachine[*Order]) {
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		order := &Order{}
+		for pb.Next() {
+			sm.Trigger("advance", order)
+		}
+	})
+}
+
+func BenchmarkTrigger(b *testing.B) {
+	for _, benchmark := range triggerBenchmarks {
+		b.Run(benchmark.name, func(b *testing.B) {
+			sm := getRingStateMachine()
+			if benchmark.setup != nil {
+				benchmark.setup(sm)
+			}
+			benchmark.benchmark(b, sm)
+		})
+	}
+}
+
+// TestBenchmarkTable runs the trigger benchmarks and writes their results to testdata/benchmarks.md, with -update
+func TestBenchmarkTable(t *testing.T) {
+	if !*update {
+		t.Skip("run with -update to regenerate testdata/benchmarks.md")
+	}
+
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "| Benchmark (%d states) | Triggers/s | ns/op | B/op | allocs/op |\n", benchmarkStates)
+	fmt.Fprintln(&builder, "|---|---|---|---|---|")
+	for _, benchmark := range triggerBenchmarks {
+		result := testing.Benchmark(func(b *testing.B) {
+			sm := getRingStateMachine()
+			if benchmark.setup != nil {
+				benchmark.setup(sm)
+			}
+			benchmark.benchmark(b, sm)
+		})
+		fmt.Fprintf(&builder, "| %s | %.0f | %d | %d | %d |\n", benchmark.name, 1e9/float64(result.NsPerOp()), result.NsPerOp(), result.AllocedBytesPerOp(), result.AllocsPerOp())
+	}
+
+	if err := os.WriteFile("testdata/benchmarks.md", []byte(builder.String()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
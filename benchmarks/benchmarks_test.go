@@ -0,0 +1,177 @@
+// Package benchmarks compares triggering events with transition, looplab/fsm and qmuntal/stateless. It's a separate
+// module so their dependencies stay out of the core module
+package benchmarks
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/daegalus/transition"
+	"github.com/looplab/fsm"
+	"github.com/qmuntal/stateless"
+)
+
+var update = flag.Bool("update", false, "update the results table of README.md")
+
+const states = 50
+
+func state(i int) string {
+	return fmt.Sprintf("s%d", i%states)
+}
+
+type Order struct {
+	transition.Transition
+}
+
+// Every library defines the same ring of states: advance moves from each state to the next and back to the first
+// from the last, running two no-op hooks. newAdvance returns a func advancing one value, its machine is shared by
+// the advancing funcs when the library keeps the state on the value, and built for each of them otherwise
+type library struct {
+	name       string
+	newAdvance func() func() error
+}
+
+var libraries = []library{
+	{name: "transition", newAdvance: newTransitionAdvance()},
+	{name: "looplab/fsm", newAdvance: newFSMAdvance},
+	{name: "qmuntal/stateless", newAdvance: newStatelessAdvance},
+}
+
+// newTransitionAdvance shares the state machine, as transition keeps the state on the values
+func newTransitionAdvance() func() func() error {
+	sm := transition.New(&Order{})
+	sm.Initial(state(0))
+
+	hook := func(order *Order) error { return nil }
+	for i := 0; i < states; i++ {
+		sm.State(state(i))
+		sm.Event("advance").To(state(i + 1)).From(state(i)).Before(hook).After(hook)
+	}
+
+	return func() func() error {
+		order := &Order{}
+		return func() error {
+			return sm.Trigger("advance", order)
+		}
+	}
+}
+
+// newFSMAdvance builds a machine per value, as looplab/fsm keeps the state in the machine
+func newFSMAdvance() func() error {
+	events := make(fsm.Events, 0, states)
+	for i := 0; i < states; i++ {
+		events = append(events, fsm.EventDesc{Name: "advance", Src: []string{state(i)}, Dst: state(i + 1)})
+	}
+
+	hook := func(context.Context, *fsm.Event) {}
+	machine := fsm.NewFSM(state(0), events, fsm.Callbacks{
+		"before_advance": hook,
+		"after_advance":  hook,
+	})
+
+	ctx := context.Background()
+	return func() error {
+		return machine.Event(ctx, "advance")
+	}
+}
+
+// newStatelessAdvance builds a machine per value, as qmuntal/stateless keeps the state in the machine
+func newStatelessAdvance() func() error {
+	machine := stateless.NewStateMachine(state(0))
+
+	hook := func(context.Context, ...any) error { return nil }
+	for i := 0; i < states; i++ {
+		machine.Configure(state(i)).Permit("advance", state(i+1)).OnExit(hook).OnEntry(hook)
+	}
+
+	return func() error {
+		return machine.Fire("advance")
+	}
+}
+
+var variants = []struct {
+	name      string
+	benchmark func(b *testing.B, newAdvance func() func() error)
+}{
+	{name: "serial", benchmark: benchmarkSerial},
+	{name: "parallel", benchmark: benchmarkParallel},
+}
+
+func benchmarkSerial(b *testing.B, newAdvance func() func() error) {
+	advance := newAdvance()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := advance(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// benchmarkParallel advances a value per goroutine, the goroutines contend on the machine when it's shared
+func benchmarkParallel(b *testing.B, newAdvance func() func() error) {
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		advance := newAdvance()
+		for pb.Next() {
+			if err := advance(); err != nil {
+				b.Error(err)
+				return
+			}
+		}
+	})
+}
+
+func BenchmarkTrigger(b *testing.B) {
+	for _, library := range libraries {
+		for _, variant := range variants {
+			b.Run(library.name+"/"+variant.name, func(b *testing.B) {
+				variant.benchmark(b, library.newAdvance)
+			})
+		}
+	}
+}
+
+const (
+	resultsStart = "<!-- results -->\n"
+	resultsEnd   = "<!-- /results -->\n"
+)
+
+// TestBenchmarkTable runs the benchmarks and writes their results to the table of README.md, with -update
+func TestBenchmarkTable(t *testing.T) {
+	if !*update {
+		t.Skip("run with -update to regenerate the results table of README.md")
+	}
+
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "| Library | Benchmark (%d states) | Triggers/s | ns/op | B/op | allocs/op |\n", states)
+	fmt.Fprintln(&builder, "|---|---|---|---|---|---|")
+	for _, library := range libraries {
+		for _, variant := range variants {
+			result := testing.Benchmark(func(b *testing.B) {
+				variant.benchmark(b, library.newAdvance)
+			})
+			fmt.Fprintf(&builder, "| %s | %s | %.0f | %d | %d | %d |\n", library.name, variant.name,
+				1e9/float64(result.NsPerOp()), result.NsPerOp(), result.AllocedBytesPerOp(), result.AllocsPerOp())
+		}
+	}
+
+	readme, err := os.ReadFile("README.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	start, end := strings.Index(string(readme), resultsStart), strings.Index(string(readme), resultsEnd)
+	if start < 0 || end < start {
+		t.Fatalf("README.md should hold the %q and %q markers", resultsStart, resultsEnd)
+	}
+
+	updated := string(readme[:start]) + resultsStart + builder.String() + string(readme[end:])
+	if err := os.WriteFile("README.md", []byte(updated), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
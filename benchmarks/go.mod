@@ -0,0 +1,11 @@
+module github.com/daegalus/transition/benchmarks
+
+go 1.20
+
+require (
+	github.com/daegalus/transition v0.0.0
+	github.com/looplab/fsm v1.0.2
+	github.com/qmuntal/stateless v1.7.0
+)
+
+replace github.com/daegalus/transition => ../
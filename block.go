@@ -0,0 +1,50 @@
+package transition
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrStateBlocked is returned when triggering an event from a state blocking value, see State.BlockWhile
+var ErrStateBlocked = errors.New("state is blocked")
+
+// BlockWhile veto every transition out of State while fc reports value, e.g. while an order is on hold for fraud
+// review. Trigger returns ErrStateBlocked naming the state without running any hooks, Can returns false and
+// AvailableEvents leaves the events out, except events that bypass blocks, see Event.BypassBlocks
+func (state *State[T]) BlockWhile(fc func(value T) bool) *State[T] {
+	state.checkMutable("State.BlockWhile")
+	state.blocks = append(state.blocks, fc)
+	return state
+}
+
+// BlockWhile veto every transition out of any state while fc reports value, see State.BlockWhile
+func (sm *StateMachine[T]) BlockWhile(fc func(value T) bool) *StateMachine[T] {
+	sm.owner.checkMutable("BlockWhile")
+	sm.blocks = append(sm.blocks, fc)
+	return sm
+}
+
+// BypassBlocks allow triggering the event from blocked states, e.g. to release a hold, see State.BlockWhile
+func (event *Event[T]) BypassBlocks() *Event[T] {
+	event.checkMutable("Event.BypassBlocks")
+	event.bypassBlocks = true
+	return event
+}
+
+// blocked returns ErrStateBlocked naming state when the event named name can't be triggered from it on value
+func (sm *StateMachine[T]) blocked(name, state string, value T) error {
+	if event := sm.events[name]; event != nil && event.bypassBlocks {
+		return nil
+	}
+
+	blocks := sm.blocks
+	if declared, ok := sm.states[state]; ok {
+		blocks = append(clip(blocks), declared.blocks...)
+	}
+	for _, block := range blocks {
+		if block(value) {
+			return fmt.Errorf("%w: %s", ErrStateBlocked, state)
+		}
+	}
+	return nil
+}
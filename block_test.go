@@ -0,0 +1,127 @@
+package transition
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBlockWhile(t *testing.T) {
+	orderStateMachine := getStateMachine()
+	orderStateMachine.State("checkout").BlockWhile(func(order *Order) bool {
+		return order.Address == "on hold"
+	})
+	orderStateMachine.Event("cancel").To("cancelled")
+	orderStateMachine.Event("release_hold").BypassBlocks().To("checkout").From("checkout").Before(func(order *Order) error {
+		order.Address = ""
+		return nil
+	})
+
+	var hooks int
+	orderStateMachine.State("checkout").Exit(func(order *Order) error {
+		hooks++
+		return nil
+	})
+
+	order := &Order{Address: "on hold"}
+	order.State = "checkout"
+
+	err := orderStateMachine.Trigger("pay", order)
+	var transitionErr *TransitionError
+	if !errors.Is(err, ErrStateBlocked) || !errors.As(err, &transitionErr) || transitionErr.From != "checkout" || !strings.Contains(err.Error(), "state is blocked: checkout") {
+		t.Fatalf("blocked state should veto pay, got %v", err)
+	}
+	if err := orderStateMachine.Trigger("cancel", order); !errors.Is(err, ErrStateBlocked) {
+		t.Errorf("blocked state should veto transitions from any state, got %v", err)
+	}
+	if order.State != "checkout" || hooks != 0 {
+		t.Errorf("blocked value should be unchanged without running hooks, got state %s and %d hooks", order.State, hooks)
+	}
+
+	if orderStateMachine.Can("pay", order) || !orderStateMachine.Can("release_hold", order) {
+		t.Errorf("only release_hold should be possible while on hold")
+	}
+	if events := orderStateMachine.AvailableEvents(order); !reflect.DeepEqual(events, []string{"release_hold"}) {
+		t.Errorf("expected [release_hold], got %v", events)
+	}
+	explanation := orderStateMachine.Explain("pay", order)
+	if explanation.Allowed || !errors.Is(explanation.Blocked, ErrStateBlocked) || explanation.String() != "pay is blocked while the value is in checkout" {
+		t.Errorf("explanation should report the block, got %+v: %s", explanation, explanation)
+	}
+
+	if err := orderStateMachine.Trigger("release_hold", order); err != nil {
+		t.Fatalf("release_hold should bypass blocks, got %v", err)
+	}
+	if !orderStateMachine.Explain("pay", order).Allowed {
+		t.Errorf("released value should be allowed to pay")
+	}
+	if err := orderStateMachine.Trigger("pay", order); err != nil || order.State != "paid" {
+		t.Errorf("released value should be triggered, got %v", err)
+	}
+
+	held := &Order{Address: "on hold"}
+	if err := orderStateMachine.Trigger("checkout", held); err != nil {
+		t.Errorf("only the blocking state should veto transitions, got %v", err)
+	}
+}
+
+func TestBlockWhileMachineWide(t *testing.T) {
+	orderStateMachine := getStateMachine()
+	orderStateMachine.BlockWhile(func(order *Order) bool {
+		return order.Address == "on hold"
+	})
+	orderStateMachine.Event("cancel").To("cancelled").FromGlob("*")
+
+	order := &Order{Address: "on hold"}
+	for _, event := range []string{"checkout", "cancel"} {
+		if err := orderStateMachine.Trigger(event, order); !errors.Is(err, ErrStateBlocked) || !strings.Contains(err.Error(), "draft") {
+			t.Errorf("every state should be blocked, got %v for %s", err, event)
+		}
+	}
+	if events := orderStateMachine.AvailableEvents(order); len(events) != 0 {
+		t.Errorf("no event should be available, got %v", events)
+	}
+
+	clone := orderStateMachine.Clone()
+	order.Address = ""
+	if err := clone.Trigger("checkout", order); err != nil {
+		t.Errorf("clones should keep blocks and unblocked values should be triggered, got %v", err)
+	}
+	order.Address = "on hold"
+	if err := clone.Trigger("pay", order); !errors.Is(err, ErrStateBlocked) {
+		t.Errorf("clones should keep blocks, got %v", err)
+	}
+}
+
+func TestBypassBlocksAfterStart(t *testing.T) {
+	orderStateMachine := New(&Order{}, WithMutableAfterStart())
+	orderStateMachine.Initial("draft")
+	orderStateMachine.State("draft").BlockWhile(func(order *Order) bool { return order.Address == "on hold" })
+	orderStateMachine.Event("checkout").To("checkout").From("draft")
+	checkout := orderStateMachine.Event("checkout")
+
+	if err := orderStateMachine.Trigger("checkout", &Order{}); err != nil {
+		t.Fatal(err)
+	}
+	order := &Order{Address: "on hold"}
+	if orderStateMachine.ReadOnlySnapshot().Can("checkout", order) {
+		t.Fatalf("blocked states should veto checkout")
+	}
+
+	checkout.BypassBlocks()
+	if !orderStateMachine.Can("checkout", order) || !orderStateMachine.ReadOnlySnapshot().Can("checkout", order) {
+		t.Errorf("snapshots should see events bypassing blocks once changed")
+	}
+
+	defer func() {
+		if recovered := recover(); !strings.Contains(fmt.Sprint(recovered), "Event.BypassBlocks called after the state machine started") {
+			t.Errorf("BypassBlocks should panic after start without WithMutableAfterStart, got %v", recovered)
+		}
+	}()
+	blocked := getStateMachine()
+	pay := blocked.Event("pay")
+	blocked.Trigger("checkout", &Order{})
+	pay.BypassBlocks()
+}
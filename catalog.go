@@ -0,0 +1,240 @@
+package transition
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"io"
+	"sort"
+	"strings"
+)
+
+// CatalogFormat is a format ExportCatalog writes catalogs in
+type CatalogFormat string
+
+const (
+	// CatalogTypeScript writes union types of the states and events and constants of their identifiers, allowed from
+	// states and labels. Identifiers are ASCII, states or events generated as the same identifier fail the export
+	CatalogTypeScript CatalogFormat = "typescript"
+	// CatalogJSON writes the Catalog marshaled to JSON
+	CatalogJSON CatalogFormat = "json"
+)
+
+// CatalogOption configure ExportCatalog
+type CatalogOption func(*catalogConfig)
+
+type catalogConfig struct {
+	eventType string
+	stateType string
+	describe  []DescribeOption
+}
+
+// CatalogTypeNames set the names of the TypeScript types of events and states, Event and State by default
+func CatalogTypeNames(event, state string) CatalogOption {
+	return func(config *catalogConfig) {
+		config.eventType, config.stateType = event, state
+	}
+}
+
+// CatalogDescribe set the options the catalog is described with, e.g. DescribeLocale for its labels
+func CatalogDescribe(opts ...DescribeOption) CatalogOption {
+	return func(config *catalogConfig) {
+		config.describe = append(config.describe, opts...)
+	}
+}
+
+// Catalog lists the states and events of a state machine for clients, e.g. frontends, see ExportCatalog
+type Catalog struct {
+	Initial string   `json:"initial"`
+	States  []string `json:"states"`
+	Events  []string `json:"events"`
+	// AllowedFrom are the states each event can be triggered from, sorted. Transitions from any state and from globs
+	// are expanded to the states they accept
+	AllowedFrom map[string][]string `json:"allowed_from"`
+	// StateLabels and EventLabels are the labels that differ from the names, see State.Label and Meta("label")
+	StateLabels map[string]string `json:"state_labels,omitempty"`
+	EventLabels map[string]string `json:"event_labels,omitempty"`
+}
+
+// Catalog returns the catalog of the states and events of the description
+func (description MachineDescription) Catalog() Catalog {
+	catalog := Catalog{
+		Initial:     description.Initial,
+		States:      []string{},
+		Events:      []string{},
+		AllowedFrom: map[string][]string{},
+	}
+	for _, state := range description.States {
+		catalog.States = append(catalog.States, state.Name)
+		if state.Label != "" && state.Label != state.Name {
+			if catalog.StateLabels == nil {
+				catalog.StateLabels = map[string]string{}
+			}
+			catalog.StateLabels[state.Name] = state.Label
+		}
+	}
+
+	for _, event := range description.Events {
+		catalog.Events = append(catalog.Events, event.Name)
+		if event.Label != "" && event.Label != event.Name {
+			if catalog.EventLabels == nil {
+				catalog.EventLabels = map[string]string{}
+			}
+			catalog.EventLabels[event.Name] = event.Label
+		}
+
+		allowed := map[string]bool{}
+		for _, transition := range event.Transitions {
+			for _, from := range transition.From {
+				allowed[from] = true
+			}
+			for _, state := range catalog.States {
+				if len(transition.From) == 0 && len(transition.FromGlobs) == 0 {
+					allowed[state] = true
+				}
+				for _, glob := range transition.FromGlobs {
+					if matchGlob(glob, state) {
+						allowed[state] = true
+					}
+				}
+			}
+		}
+		froms := make([]string, 0, len(allowed))
+		for from := range allowed {
+			froms = append(froms, from)
+		}
+		sort.Strings(froms)
+		catalog.AllowedFrom[event.Name] = froms
+	}
+	return catalog
+}
+
+// ExportCatalog write the catalog of the states and events of the state machine in format, e.g. to share them with
+// a TypeScript frontend:
+//
+//	export type OrderEvent = "cancel" | "checkout" | "pay";
+//	export const allowedFrom: Record<OrderEvent, OrderState[]> = { ... };
+//
+// The catalog is built from Describe, so the same definition always writes the same bytes
+func (sm *StateMachine[T]) ExportCatalog(w io.Writer, format CatalogFormat, opts ...CatalogOption) error {
+	config := catalogConfig{eventType: "Event", stateType: "State"}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	return sm.Describe(config.describe...).exportCatalog(w, format, config)
+}
+
+// ExportCatalog write the catalog of the description in format, see StateMachine.ExportCatalog
+func (description MachineDescription) ExportCatalog(w io.Writer, format CatalogFormat, opts ...CatalogOption) error {
+	config := catalogConfig{eventType: "Event", stateType: "State"}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	return description.exportCatalog(w, format, config)
+}
+
+func (description MachineDescription) exportCatalog(w io.Writer, format CatalogFormat, config catalogConfig) error {
+	catalog := description.Catalog()
+
+	var buf bytes.Buffer
+	switch format {
+	case CatalogJSON:
+		encoder := json.NewEncoder(&buf)
+		encoder.SetEscapeHTML(false)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(catalog); err != nil {
+			return fmt.Errorf("failed to export catalog: %w", err)
+		}
+	case CatalogTypeScript:
+		if !token.IsIdentifier(config.eventType) || !token.IsIdentifier(config.stateType) {
+			return fmt.Errorf("failed to export catalog: invalid type names %q, %q", config.eventType, config.stateType)
+		}
+		if err := catalog.writeTypeScript(&buf, config); err != nil {
+			return fmt.Errorf("failed to export catalog: %w", err)
+		}
+	default:
+		return fmt.Errorf("failed to export catalog: unknown format %q", format)
+	}
+
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("failed to export catalog: %w", err)
+	}
+	return nil
+}
+
+func (catalog Catalog) writeTypeScript(buf *bytes.Buffer, config catalogConfig) error {
+	stateIdents, err := identifiers("states", catalog.States)
+	if err != nil {
+		return err
+	}
+	eventIdents, err := identifiers("events", catalog.Events)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(buf, "// Code generated by transition.ExportCatalog. DO NOT EDIT.\n\n")
+	fmt.Fprintf(buf, "export type %s = %s;\n\n", config.stateType, tsUnion(catalog.States))
+	fmt.Fprintf(buf, "export type %s = %s;\n\n", config.eventType, tsUnion(catalog.Events))
+
+	// the identifiers of states and events, e.g. OrderState.AwaitingApproval is "awaiting approval"
+	writeIdents := func(typeName string, names []string, idents map[string]string) {
+		fmt.Fprintf(buf, "export const %s = {\n", typeName)
+		for _, name := range names {
+			fmt.Fprintf(buf, "  %s: %s,\n", idents[name], tsString(name))
+		}
+		fmt.Fprintf(buf, "} as const;\n\n")
+	}
+	writeIdents(config.stateType, catalog.States, stateIdents)
+	writeIdents(config.eventType, catalog.Events, eventIdents)
+	if catalog.Initial != "" {
+		fmt.Fprintf(buf, "export const initialState: %s = %s;\n\n", config.stateType, tsString(catalog.Initial))
+	}
+
+	fmt.Fprintf(buf, "export const allowedFrom: Record<%s, %s[]> = {\n", config.eventType, config.stateType)
+	for _, event := range catalog.Events {
+		froms := make([]string, len(catalog.AllowedFrom[event]))
+		for i, from := range catalog.AllowedFrom[event] {
+			froms[i] = tsString(from)
+		}
+		fmt.Fprintf(buf, "  %s: [%s],\n", tsString(event), strings.Join(froms, ", "))
+	}
+	fmt.Fprintf(buf, "};\n")
+
+	writeLabels := func(name, typeName string, names []string, labels map[string]string) {
+		if len(labels) == 0 {
+			return
+		}
+		fmt.Fprintf(buf, "\nexport const %s: Partial<Record<%s, string>> = {\n", name, typeName)
+		for _, name := range names {
+			if label, ok := labels[name]; ok {
+				fmt.Fprintf(buf, "  %s: %s,\n", tsString(name), tsString(label))
+			}
+		}
+		fmt.Fprintf(buf, "};\n")
+	}
+	writeLabels("stateLabels", config.stateType, catalog.States, catalog.StateLabels)
+	writeLabels("eventLabels", config.eventType, catalog.Events, catalog.EventLabels)
+	return nil
+}
+
+// tsUnion returns the TypeScript union type of the string literals of names
+func tsUnion(names []string) string {
+	if len(names) == 0 {
+		return "never"
+	}
+	literals := make([]string, len(names))
+	for i, name := range names {
+		literals[i] = tsString(name)
+	}
+	return strings.Join(literals, " | ")
+}
+
+// tsString returns name as a TypeScript string literal, JSON strings are valid ones
+func tsString(name string) string {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(name)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
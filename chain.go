@@ -0,0 +1,125 @@
+package transition
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var (
+	// ErrChainDepthExceeded is returned when a trigger causes more triggers than allowed, see WithMaxChainDepth
+	ErrChainDepthExceeded = errors.New("chain depth exceeded")
+	// ErrHookBudgetExceeded is returned when a trigger runs more hooks than allowed, see WithMaxHooksPerTrigger
+	ErrHookBudgetExceeded = errors.New("hook budget exceeded")
+)
+
+// ChainError is returned when a chain of triggers exceeded a limit, Path lists the events triggered on the value
+// so far, starting with the external trigger and ending with the trigger that exceeded the limit
+type ChainError struct {
+	Path []string
+	Err  error
+}
+
+func (chainErr *ChainError) Error() string {
+	return fmt.Sprintf("%v: %s", chainErr.Err, strings.Join(chainErr.Path, " -> "))
+}
+
+// Unwrap returns ErrChainDepthExceeded or ErrHookBudgetExceeded
+func (chainErr *ChainError) Unwrap() error {
+	return chainErr.Err
+}
+
+// chain tracks the triggers performed on a value by an external trigger and the triggers its hooks cause
+type chain struct {
+	// machine and key identify the value the chain runs on, see chainKey
+	machine any
+	key     any
+	path    []string
+	hooks   int
+	// steps backs path for short chains
+	steps [4]string
+}
+
+// invocation is a trigger being performed on a value. It's carried by the context passed to hooks, so the triggers
+// hooks perform on the same value with that context join its chain, while other triggers start their own
+type invocation struct {
+	chain  *chain
+	parent *invocation
+	// value identifies the value for checkHookState, it's nil when the value isn't comparable
+	value any
+	// state is the state the value is expected in between hooks, see checkHookState
+	state string
+	// root is the chain of the invocations starting one, so most triggers allocate a single invocation
+	root chain
+}
+
+type invocationContextKey struct{}
+
+// nested reports whether the invocation was caused by the hooks of another trigger on the same value
+func (current *invocation) nested() bool {
+	return current != nil && current.parent != nil
+}
+
+// chainKey returns what identifies value across triggers: its key when the state machine has a key func,
+// otherwise the value itself if comparable, e.g. a pointer. Triggers are not chained when it returns nil
+func (sm *StateMachine[T]) chainKey(value T) any {
+	if sm.keyFunc != nil {
+		return sm.keyFunc(value)
+	}
+	if reflect.TypeOf(value).Comparable() {
+		return value
+	}
+	return nil
+}
+
+// enterChain records the trigger of event on value into the chain of the trigger running the hook ctx was passed
+// to when it runs on the same value, or into a new chain otherwise. The returned context carries the invocation to
+// the hooks of the trigger. It fails when the trigger exceeds the chain depth
+func (sm *StateMachine[T]) enterChain(ctx context.Context, event string, value T) (context.Context, *invocation, error) {
+	key := sm.chainKey(value)
+	if key == nil {
+		return ctx, nil, nil
+	}
+
+	current := &invocation{state: value.GetState()}
+	if reflect.TypeOf(value).Comparable() {
+		current.value = value
+	}
+	if parent, _ := ctx.Value(invocationContextKey{}).(*invocation); parent != nil && parent.chain.machine == sm && parent.chain.key == key {
+		current.chain, current.parent = parent.chain, parent
+	} else {
+		current.root = chain{machine: sm, key: key}
+		current.root.path = current.root.steps[:0]
+		current.chain = &current.root
+	}
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	current.chain.path = append(current.chain.path, event)
+	if sm.maxChainDepth > 0 && len(current.chain.path)-1 > sm.maxChainDepth {
+		err := &ChainError{Path: append([]string{}, current.chain.path...), Err: ErrChainDepthExceeded}
+		current.chain.path = current.chain.path[:len(current.chain.path)-1]
+		return ctx, nil, err
+	}
+	return context.WithValue(ctx, invocationContextKey{}, current), current, nil
+}
+
+// spendHook counts a hook about to run in the chain, failing when the chain ran out of budget
+func (sm *StateMachine[T]) spendHook(invocation *invocation) error {
+	if invocation == nil || sm.maxHooks <= 0 {
+		return nil
+	}
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	current := invocation.chain
+	if current.hooks >= sm.maxHooks {
+		return &ChainError{Path: append([]string{}, current.path...), Err: ErrHookBudgetExceeded}
+	}
+	current.hooks++
+	return nil
+}
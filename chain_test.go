@@ -0,0 +1,99 @@
+package transition
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// getCycleStateMachine returns a machine where ping and pong trigger each other forever, recording the first error
+func getCycleStateMachine(opts ...Option) (*StateMachine[*Order], *error) {
+	var (
+		sm       = New(&Order{}, opts...)
+		chainErr error
+	)
+	sm.Initial("a")
+	sm.State("b")
+
+	follow := func(event string) ContextHook[*Order] {
+		return func(ctx context.Context, order *Order) error {
+			if err := sm.TriggerContext(ctx, event, order); err != nil && chainErr == nil {
+				chainErr = err
+			}
+			return nil
+		}
+	}
+	sm.Event("ping").To("b").From("a").AfterContext(follow("pong"))
+	sm.Event("pong").To("a").From("b").AfterContext(follow("ping"))
+	return sm, &chainErr
+}
+
+func TestMaxChainDepth(t *testing.T) {
+	sm, chainErr := getCycleStateMachine(WithMaxChainDepth(3))
+
+	order := &Order{}
+	if err := sm.Trigger("ping", order); err != nil {
+		t.Fatalf("the external trigger should succeed, got %v", err)
+	}
+
+	var pathErr *ChainError
+	if !errors.Is(*chainErr, ErrChainDepthExceeded) || !errors.As(*chainErr, &pathErr) {
+		t.Fatalf("the cycle should be stopped with ErrChainDepthExceeded, got %v", *chainErr)
+	}
+	if want := "failed to perform event ping from state a: chain depth exceeded: ping -> pong -> ping -> pong -> ping"; (*chainErr).Error() != want {
+		t.Errorf("expected error %q, got %q", want, (*chainErr).Error())
+	}
+	if order.State != "a" {
+		t.Errorf("the steps before the limit should be kept, got state %v", order.State)
+	}
+
+	// The chain ends with the external trigger
+	*chainErr = nil
+	if err := sm.Trigger("ping", order); err != nil || !errors.Is(*chainErr, ErrChainDepthExceeded) {
+		t.Errorf("a new external trigger should start a new chain, got %v, %v", err, *chainErr)
+	}
+}
+
+func TestMaxHooksPerTrigger(t *testing.T) {
+	sm, chainErr := getCycleStateMachine(WithMaxHooksPerTrigger(2))
+
+	order := &Order{}
+	if err := sm.Trigger("ping", order); err != nil {
+		t.Fatalf("the external trigger should succeed, got %v", err)
+	}
+	if !errors.Is(*chainErr, ErrHookBudgetExceeded) {
+		t.Fatalf("the cycle should be stopped with ErrHookBudgetExceeded, got %v", *chainErr)
+	}
+	if want := "failed to perform event ping from state a to b: hook budget exceeded: ping -> pong -> ping"; (*chainErr).Error() != want {
+		t.Errorf("expected error %q, got %q", want, (*chainErr).Error())
+	}
+	if order.State != "a" {
+		t.Errorf("the step exceeding the budget should be rolled back, got state %v", order.State)
+	}
+}
+
+func TestChainIndependentTriggers(t *testing.T) {
+	orderStateMachine, started, release := getBlockingStateMachine(WithMaxChainDepth(1))
+	orderStateMachine.SetKeyFunc(func(order *Order) string { return "same" })
+	orderStateMachine.State("paid")
+	orderStateMachine.Event("pay").To("paid").From("checkout")
+
+	order := &Order{}
+	triggers := make(chan error, 1)
+	go func() { triggers <- orderStateMachine.Trigger("checkout", order) }()
+	<-started
+
+	// triggers on values with the same key, not caused by the hooks of the running trigger, start their own chain
+	for i := 0; i < 2; i++ {
+		other := &Order{}
+		other.SetState("checkout")
+		if err := orderStateMachine.Trigger("pay", other); err != nil || other.GetState() != "paid" {
+			t.Errorf("independent triggers should not join the running chain, got %s, %v", other.GetState(), err)
+		}
+	}
+
+	close(release)
+	if err := <-triggers; err != nil {
+		t.Error(err)
+	}
+}
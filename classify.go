@@ -0,0 +1,97 @@
+package transition
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrorClass tells whether a failed transition is worth retrying
+type ErrorClass int
+
+const (
+	// ClassUnknown is the class of errors nothing is known about, they are not retried
+	ClassUnknown ErrorClass = iota
+	// ClassRetryable errors may succeed when retried, e.g. timeouts
+	ClassRetryable
+	// ClassPermanent errors fail again when retried, e.g. an event not allowed from the current state
+	ClassPermanent
+)
+
+func (class ErrorClass) String() string {
+	switch class {
+	case ClassRetryable:
+		return "retryable"
+	case ClassPermanent:
+		return "permanent"
+	default:
+		return "unknown"
+	}
+}
+
+type classifiedError struct {
+	class ErrorClass
+	err   error
+}
+
+func (classified *classifiedError) Error() string {
+	return classified.err.Error()
+}
+
+func (classified *classifiedError) Unwrap() error {
+	return classified.err
+}
+
+// Retryable wrap err to classify it as retryable, hooks use it to tell a failure is transient
+func Retryable(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &classifiedError{class: ClassRetryable, err: err}
+}
+
+// Permanent wrap err to classify it as permanent, hooks use it to tell a failure won't go away when retried
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &classifiedError{class: ClassPermanent, err: err}
+}
+
+// Classify returns the class of err. Errors wrapped with Retryable or Permanent keep their class, the outermost
+// classification wins. Otherwise matching failures (unknown event, no matching or ambiguous transition,
+// debounced, nil value, empty event name), unauthorized triggers and stale commands are permanent, while context cancellation, timeouts,
+// too many triggers in flight and shutting down are retryable
+func Classify(err error) ErrorClass {
+	if err == nil {
+		return ClassUnknown
+	}
+
+	var classified *classifiedError
+	if errors.As(err, &classified) {
+		return classified.class
+	}
+
+	var timeout interface{ Timeout() bool }
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded), errors.Is(err, ErrTooManyInFlight),
+		errors.Is(err, ErrShuttingDown):
+		return ClassRetryable
+	case errors.As(err, &timeout) && timeout.Timeout():
+		return ClassRetryable
+	case errors.Is(err, ErrUnknownEvent), errors.Is(err, ErrNoMatchingTransition), errors.Is(err, ErrAmbiguousTransition), errors.Is(err, ErrDebounced),
+		errors.Is(err, ErrNilValue), errors.Is(err, ErrEmptyEventName), errors.Is(err, ErrUnauthorized), errors.Is(err, ErrValueFrozen),
+		errors.Is(err, ErrDefinitionChanged):
+		return ClassPermanent
+	}
+	return ClassUnknown
+}
+
+// IsRetryable reports whether err is classified as retryable
+func IsRetryable(err error) bool {
+	return Classify(err) == ClassRetryable
+}
+
+// IsPermanent reports whether err is classified as permanent
+func IsPermanent(err error) bool {
+	return Classify(err) == ClassPermanent
+}
@@ -0,0 +1,52 @@
+package transition
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestClassify(t *testing.T) {
+	orderStateMachine := getStateMachine()
+	orderStateMachine.State("checkout").Enter(func(order *Order) error {
+		return Retryable(errors.New("payment gateway unavailable"))
+	})
+	orderStateMachine.State("paid").Enter(func(order *Order) error {
+		return Permanent(errors.New("card declined"))
+	})
+
+	if err := orderStateMachine.Trigger("checkout", &Order{}); !IsRetryable(err) || !IsHookError(err) {
+		t.Errorf("classification of hook errors should be preserved, got %v", err)
+	}
+
+	order := &Order{}
+	order.State = "checkout"
+	if err := orderStateMachine.Trigger("pay", order); !IsPermanent(err) {
+		t.Errorf("classification of hook errors should be preserved, got %v", err)
+	}
+
+	if err := orderStateMachine.Trigger("pay", &Order{}); !IsPermanent(err) {
+		t.Errorf("matching failures should be permanent, got %v", err)
+	}
+
+	if err := orderStateMachine.Trigger("unknown", &Order{}); !IsPermanent(err) {
+		t.Errorf("unknown events should be permanent, got %v", err)
+	}
+
+	if err := fmt.Errorf("hook: %w", context.DeadlineExceeded); !IsRetryable(err) {
+		t.Errorf("timeouts should be retryable, got %v", Classify(err))
+	}
+
+	if err := Permanent(context.Canceled); IsRetryable(err) {
+		t.Errorf("explicit classification should win")
+	}
+
+	if class := Classify(errors.New("unknown")); class != ClassUnknown || class.String() != "unknown" {
+		t.Errorf("unclassified errors should be unknown, got %v", class)
+	}
+
+	if Retryable(nil) != nil || Permanent(nil) != nil {
+		t.Errorf("classifying nil should return nil")
+	}
+}
@@ -0,0 +1,26 @@
+package transition
+
+import "time"
+
+// Clock is the source of time of a state machine, replace it with WithClock to control time in tests
+type Clock interface {
+	Now() time.Time
+	// AfterFunc calls f once d elapsed
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer is a pending call created by Clock.AfterFunc
+type Timer interface {
+	// Stop prevents the call from happening, it returns false if the call already happened or was stopped
+	Stop() bool
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
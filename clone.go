@@ -0,0 +1,182 @@
+package transition
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// owner identifies the state machine allowed to modify a state or an event in place, see StateMachine.Clone
+type owner struct {
+	ownerGuard
+	// normalize is the state normalizer of the state machine, see StateMachine.StateNormalizer
+	normalize func(state string) string
+	// trackDefinitions records where transitions are defined, see WithDefinitionTracking
+	trackDefinitions bool
+	// clones counts the clones of the state machine, the states and events obtained before the last one are shared
+	// with a clone and copied when requested again, see ownedState
+	clones atomic.Uint64
+}
+
+// Clone returns a copy of the state machine, sharing its states and events until either machine modifies them:
+// State and Event copy the state or event they return the first time it is requested after cloning, so modifying
+// one machine never affects the other. States, events, transitions and hooks obtained before cloning are shared with
+// the clone, so they panic when modified and must be requested again. Clone is safe to call while triggering events.
+// The clone doesn't share runtime data such as pending timeouts, debounces, dead letters and stats, and it can be
+// modified until it triggers an event itself, see WithMutableAfterStart
+func (sm *StateMachine[T]) Clone() *StateMachine[T] {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	// the states and events are now shared with the clone, the source copies them when they're requested again
+	sm.owner.clones.Add(1)
+	sm.statesShared, sm.eventsShared = true, true
+
+	return &StateMachine[T]{
+		initialState: sm.initialState,
+		initials:     sm.initials,
+		states:       sm.states,
+		events:       sm.events,
+		owner:        &owner{ownerGuard: ownerGuard{mutableAfterStart: sm.owner.mutableAfterStart}, normalize: sm.owner.normalize, trackDefinitions: sm.owner.trackDefinitions},
+		statesShared: true,
+		eventsShared: true,
+
+		clock:             sm.clock,
+		scheduler:         sm.scheduler,
+		resolver:          sm.resolver,
+		keyFunc:           sm.keyFunc,
+		onErrors:          clip(sm.onErrors),
+		onWarnings:        clip(sm.onWarnings),
+		befores:           clip(sm.befores),
+		afters:            clip(sm.afters),
+		onDeadLetters:     clip(sm.onDeadLetters),
+		stateChangeLog:    sm.stateChangeLog,
+		persister:         sm.persister,
+		authorize:         sm.authorize,
+		actorFunc:         sm.actorFunc,
+		correlationIDFunc: sm.correlationIDFunc,
+		generateIDs:       sm.generateIDs,
+		rolesFunc:         sm.rolesFunc,
+		defaultActor:      sm.defaultActor,
+		defaultLocale:     sm.defaultLocale,
+		actionLess:        sm.actionLess,
+		version:           sm.version,
+		onVersionMismatch: sm.onVersionMismatch,
+		migrations:        clip(sm.migrations),
+		onStaleCommand:    sm.onStaleCommand,
+		frozen:            sm.frozen,
+		blocks:            clip(sm.blocks),
+
+		idempotencyStore: sm.idempotencyStore,
+		idempotency:      sm.idempotency,
+		singleFlight:     sm.singleFlight,
+		maxChainDepth:    sm.maxChainDepth,
+		maxHooks:         sm.maxHooks,
+		detectMutations:  sm.detectMutations,
+		strictHookState:  sm.strictHookState,
+		limiter:          sm.limiter.renew(),
+		skipped:          clip(sm.skipped),
+
+		timeouts:  map[timeoutKey][]string{},
+		debounced: map[debounceKey]time.Time{},
+	}
+}
+
+// ownedState returns the state named name, copying it first when it's shared with a clone
+func (sm *StateMachine[T]) ownedState(name string) (*State[T], bool) {
+	state, ok := sm.states[name]
+	if !ok || (state.owner == sm.owner && state.epoch == sm.owner.clones.Load()) {
+		return state, ok
+	}
+
+	if sm.statesShared {
+		sm.states, sm.statesShared = cloneMap(sm.states), false
+	}
+	state = state.clone(sm.owner)
+	sm.states[name] = state
+	return state, true
+}
+
+// ownedEvent returns the event named name, copying it first when it's shared with a clone
+func (sm *StateMachine[T]) ownedEvent(name string) (*Event[T], bool) {
+	event, ok := sm.events[name]
+	if !ok || (event.owner == sm.owner && event.epoch == sm.owner.clones.Load()) {
+		return event, ok
+	}
+
+	if sm.eventsShared {
+		sm.events, sm.eventsShared = cloneMap(sm.events), false
+	}
+	event = event.clone(sm.owner)
+	sm.events[name] = event
+	return event, true
+}
+
+func (state *State[T]) clone(owner *owner) *State[T] {
+	copied := *state
+	copied.owner, copied.epoch = owner, owner.clones.Load()
+	copied.enters = clip(state.enters)
+	copied.exits = clip(state.exits)
+	copied.enterRefs = clip(state.enterRefs)
+	copied.exitRefs = clip(state.exitRefs)
+	copied.invariants = clip(state.invariants)
+	copied.materializers = clip(state.materializers)
+	copied.blocks = clip(state.blocks)
+	copied.aliasOf = clip(state.aliasOf)
+	copied.labels = cloneMap(state.labels)
+	copied.metadata = cloneMap(state.metadata)
+	copied.timeouts = clip(state.timeouts)
+	return &copied
+}
+
+func (event *Event[T]) clone(owner *owner) *Event[T] {
+	copied := *event
+	copied.owner, copied.epoch = owner, owner.clones.Load()
+	copied.metadata = cloneMap(event.metadata)
+	copied.labels = cloneMap(event.labels)
+	copied.roles = clip(event.roles)
+	copied.payloadBefores = clip(event.payloadBefores)
+	copied.payloadAfters = clip(event.payloadAfters)
+
+	copied.transitionIndex = cloneMap(event.transitionIndex)
+	if event.transitions != nil {
+		copied.transitions = make([]*EventTransition[T], len(event.transitions))
+		for i, transition := range event.transitions {
+			copiedTransition := *transition
+			copiedTransition.owner, copiedTransition.epoch = owner, copied.epoch
+			copiedTransition.froms = clip(transition.froms)
+			copiedTransition.fromSet = cloneMap(transition.fromSet)
+			copiedTransition.duplicateFroms = clip(transition.duplicateFroms)
+			copiedTransition.fromGlobs = clip(transition.fromGlobs)
+			copiedTransition.fromSites = cloneMap(transition.fromSites)
+			copiedTransition.befores = clip(transition.befores)
+			copiedTransition.pendingBefores = clip(transition.pendingBefores)
+			copiedTransition.afters = clip(transition.afters)
+			copiedTransition.beforeRefs = clip(transition.beforeRefs)
+			copiedTransition.afterRefs = clip(transition.afterRefs)
+			copiedTransition.notifiers = clip(transition.notifiers)
+			copiedTransition.emitters = clip(transition.emitters)
+			copiedTransition.guards = clip(transition.guards)
+			copiedTransition.guardNames = clip(transition.guardNames)
+			copiedTransition.labels = cloneMap(transition.labels)
+			copied.transitions[i] = &copiedTransition
+		}
+	}
+	return &copied
+}
+
+// clip returns s with its capacity limited to its length, so appending to it never modifies a shared array
+func clip[S ~[]E, E any](s S) S {
+	return s[:len(s):len(s)]
+}
+
+func cloneMap[K comparable, V any](m map[K]V) map[K]V {
+	if m == nil {
+		return nil
+	}
+
+	copied := make(map[K]V, len(m))
+	for key, value := range m {
+		copied[key] = value
+	}
+	return copied
+}
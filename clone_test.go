@@ -0,0 +1,152 @@
+package transition
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestClone(t *testing.T) {
+	var calls []string
+	hook := func(name string) func(order *Order) error {
+		return func(order *Order) error {
+			calls = append(calls, name)
+			return nil
+		}
+	}
+
+	original := getStateMachine()
+	original.State("checkout").Enter(hook("original enter"))
+	original.Event("checkout").To("checkout").From("draft").After(hook("original after"))
+
+	clone := original.Clone()
+	clone.State("checkout").Enter(hook("clone enter"))
+	clone.Event("checkout").To("checkout").After(hook("clone after"))
+	clone.Event("checkout").To("checkout").From("paid")
+	clone.State("archived")
+	clone.Event("archive").To("archived").From("paid")
+
+	original.State("checkout").Enter(hook("original enter 2"))
+	original.Event("pay").To("paid").From("checkout").Before(func(order *Order) error { return errors.New("closed") })
+
+	trigger := func(sm *StateMachine[*Order], order *Order, event string) error {
+		calls = nil
+		return sm.Trigger(event, order)
+	}
+
+	if err := trigger(original, &Order{}, "checkout"); err != nil || fmt.Sprint(calls) != "[original enter original enter 2 original after]" {
+		t.Errorf("the clone's modifications should not leak into the original, got %v, %v", calls, err)
+	}
+	if _, ok := original.states["archived"]; ok || original.events["archive"] != nil {
+		t.Errorf("states and events defined on the clone should not leak into the original")
+	}
+	if froms := original.events["checkout"].transitions[0].froms; fmt.Sprint(froms) != "[draft]" {
+		t.Errorf("froms added on the clone should not leak into the original, got %v", froms)
+	}
+
+	order := &Order{}
+	if err := trigger(clone, order, "checkout"); err != nil || fmt.Sprint(calls) != "[original enter clone enter original after clone after]" {
+		t.Errorf("the original's modifications should not leak into the clone, got %v, %v", calls, err)
+	}
+	if err := clone.Trigger("pay", order); err != nil {
+		t.Errorf("before hooks added to the original should not run on the clone, got %v", err)
+	}
+	if err := clone.Trigger("archive", order); err != nil || order.State != "archived" {
+		t.Errorf("the clone should trigger its own events, got %v", err)
+	}
+
+	// Cloning a clone
+	grandClone := clone.Clone()
+	grandClone.State("archived").Enter(func(order *Order) error { return errors.New("read only") })
+	if err := clone.Trigger("archive", &Order{Transition: Transition{State: "paid"}}); err != nil {
+		t.Errorf("the clone of a clone should not leak into its source, got %v", err)
+	}
+	if err := grandClone.Trigger("archive", &Order{Transition: Transition{State: "paid"}}); err == nil {
+		t.Errorf("the clone of a clone should run its own hooks")
+	}
+}
+
+func TestCloneSharesUntouchedEntries(t *testing.T) {
+	original := getStateMachine()
+	clone := original.Clone()
+
+	clone.State("paid").Enter(func(order *Order) error { return nil })
+	if clone.states["checkout"] != original.states["checkout"] || clone.events["pay"] != original.events["pay"] {
+		t.Errorf("untouched states and events should be shared")
+	}
+	if clone.states["paid"] == original.states["paid"] {
+		t.Errorf("touched states should be copied")
+	}
+}
+
+func TestCloneStaleHandles(t *testing.T) {
+	original := getStateMachine()
+	paid, pay := original.State("paid"), original.Event("pay")
+	transition := pay.To("paid")
+	original.Clone()
+
+	for what, modify := range map[string]func(){
+		"state":      func() { paid.Enter(func(order *Order) error { return nil }) },
+		"event":      func() { pay.Label("kind", "payment") },
+		"transition": func() { transition.After(func(order *Order) error { return nil }) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("modifying a %s obtained before Clone should panic", what)
+				}
+			}()
+			modify()
+		}()
+	}
+
+	original.State("paid").Enter(func(order *Order) error { return nil })
+	if original.states["paid"] == paid {
+		t.Errorf("a state requested again after Clone should be copied")
+	}
+}
+
+func TestCloneWhileTriggering(t *testing.T) {
+	original := getStateMachine()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			order := &Order{}
+			if err := original.Trigger("checkout", order); err != nil {
+				t.Error(err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			original.Clone()
+		}()
+	}
+	wg.Wait()
+}
+
+func BenchmarkClone(b *testing.B) {
+	sm := New(&Order{})
+	sm.Initial("s0")
+	for i := 1; i < 500; i++ {
+		sm.State(fmt.Sprintf("s%d", i)).Enter(func(order *Order) error { return nil })
+		sm.Event(fmt.Sprintf("e%d", i)).To(fmt.Sprintf("s%d", i)).From(fmt.Sprintf("s%d", i-1))
+	}
+
+	b.Run("untouched", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			sm.Clone()
+		}
+	})
+
+	b.Run("one state modified", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			sm.Clone().State("s1").Enter(func(order *Order) error { return nil })
+		}
+	})
+}
@@ -0,0 +1,154 @@
+// Package cloudevents converts transition notifications and trigger commands to and from CloudEvents, in the
+// structured JSON format of CloudEvents 1.0. It's a separate module so transition doesn't depend on it
+package cloudevents
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/daegalus/transition"
+)
+
+const (
+	// SpecVersion is the version of the CloudEvents specification events are written in
+	SpecVersion = "1.0"
+	// ContentType is the content type of events in the structured JSON format, e.g. for HTTP requests
+	ContentType = "application/cloudevents+json"
+)
+
+// ErrInvalidEvent is returned when an event misses required attributes or its data can't be decoded
+var ErrInvalidEvent = errors.New("invalid cloud event")
+
+// Event is a CloudEvent in the structured JSON format
+type Event struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Source          string          `json:"source"`
+	Type            string          `json:"type"`
+	Subject         string          `json:"subject,omitempty"`
+	Time            time.Time       `json:"time"`
+	DataContentType string          `json:"datacontenttype,omitempty"`
+	Data            json.RawMessage `json:"data,omitempty"`
+}
+
+// TransitionData is the data of the events of transitions, see Publisher
+type TransitionData struct {
+	Event         string `json:"event"`
+	From          string `json:"from"`
+	To            string `json:"to"`
+	Reason        string `json:"reason,omitempty"`
+	Actor         string `json:"actor,omitempty"`
+	CorrelationID string `json:"correlation_id,omitempty"`
+}
+
+// Config configure the events of transitions
+type Config[T transition.Stater] struct {
+	// Source identifies the service performing transitions, e.g. "/orders"
+	Source string
+	// Type is the type of the events, e.g. "com.example.order.transitioned"
+	Type string
+	// Key returns the key of values, it's the subject of the events
+	Key func(value T) string
+}
+
+// NewTransitionEvent returns the event of the transition a notification hook received
+func NewTransitionEvent[T transition.Stater](config Config[T], data transition.NotifyData[T]) (Event, error) {
+	var subject string
+	if config.Key != nil {
+		subject = config.Key(data.Value)
+	}
+	return newEvent(config.Source, config.Type, subject, data.Time, TransitionData{
+		Event:         data.Transition.Event,
+		From:          data.Transition.From,
+		To:            data.Transition.To,
+		Reason:        data.Reason,
+		Actor:         data.Actor,
+		CorrelationID: data.CorrelationID,
+	})
+}
+
+// Publisher returns a notification hook passing the event of each transition to publish, register it with
+// EventTransition.Notify:
+//
+//	sm.Event("pay").To("paid").From("checkout").Notify(cloudevents.Publisher(config, broker.Publish))
+func Publisher[T transition.Stater](config Config[T], publish func(ctx context.Context, event Event) error) transition.NotifyHook[T] {
+	return func(ctx context.Context, data transition.NotifyData[T]) error {
+		event, err := NewTransitionEvent(config, data)
+		if err != nil {
+			return err
+		}
+		return publish(ctx, event)
+	}
+}
+
+// NewCommandEvent returns an event carrying command, to be handled with HandleCloudEvent. Its subject is the key
+// of the command
+func NewCommandEvent(source, eventType string, command transition.TriggerCommand) (Event, error) {
+	return newEvent(source, eventType, command.Key, time.Now(), command)
+}
+
+// Parse reads an event in the structured JSON format, checking its required attributes
+func Parse(data []byte) (Event, error) {
+	var event Event
+	if err := json.Unmarshal(data, &event); err != nil {
+		return event, fmt.Errorf("%w: %w", ErrInvalidEvent, err)
+	}
+	if event.SpecVersion != SpecVersion {
+		return event, fmt.Errorf("%w: unsupported spec version %q", ErrInvalidEvent, event.SpecVersion)
+	}
+	if event.ID == "" || event.Source == "" || event.Type == "" {
+		return event, fmt.Errorf("%w: id, source and type are required", ErrInvalidEvent)
+	}
+	return event, nil
+}
+
+// HandleCloudEvent execute the trigger command carried by event on the value resolver loads, see
+// StateMachine.Execute. The value is resolved with the key of the command, or the subject of the event when the
+// command has no key
+func HandleCloudEvent[T transition.Stater](ctx context.Context, sm *transition.StateMachine[T], resolver transition.Resolver[T], event Event) (*transition.TransitionResult, error) {
+	var command transition.TriggerCommand
+	if err := json.Unmarshal(event.Data, &command); err != nil {
+		return nil, fmt.Errorf("%w: event %s: %w", ErrInvalidEvent, event.ID, err)
+	}
+	if command.Key == "" {
+		command.Key = event.Subject
+	}
+
+	value, err := resolver.Resolve(command.Key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %q of event %s: %w", command.Key, event.ID, err)
+	}
+	return sm.Execute(ctx, command, value)
+}
+
+func newEvent(source, eventType, subject string, at time.Time, data any) (Event, error) {
+	if source == "" || eventType == "" {
+		return Event{}, fmt.Errorf("%w: source and type are required", ErrInvalidEvent)
+	}
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return Event{}, fmt.Errorf("%w: %w", ErrInvalidEvent, err)
+	}
+	return Event{
+		SpecVersion:     SpecVersion,
+		ID:              newID(),
+		Source:          source,
+		Type:            eventType,
+		Subject:         subject,
+		Time:            at.UTC(),
+		DataContentType: "application/json",
+		Data:            encoded,
+	}, nil
+}
+
+// newID returns a random event ID
+func newID() string {
+	var id [16]byte
+	_, _ = rand.Read(id[:])
+	return hex.EncodeToString(id[:])
+}
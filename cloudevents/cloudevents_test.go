@@ -0,0 +1,92 @@
+package cloudevents_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/daegalus/transition"
+	"github.com/daegalus/transition/cloudevents"
+)
+
+type Order struct {
+	ID string
+	transition.Transition
+}
+
+func TestRoundTrip(t *testing.T) {
+	var published [][]byte
+	config := cloudevents.Config[*Order]{
+		Source: "/orders",
+		Type:   "com.example.order.transitioned",
+		Key:    func(order *Order) string { return order.ID },
+	}
+
+	orderStateMachine := transition.New(&Order{})
+	orderStateMachine.Initial("draft")
+	orderStateMachine.State("paid").Final()
+	orderStateMachine.Event("pay").To("paid").From("draft").Notify(cloudevents.Publisher(config, func(ctx context.Context, event cloudevents.Event) error {
+		data, err := json.Marshal(event)
+		published = append(published, data)
+		return err
+	}))
+
+	orders := map[string]*Order{"o1": {ID: "o1"}}
+	var resolver transition.Resolver[*Order] = transition.ResolverFunc[*Order](func(key string) (*Order, error) {
+		if order, ok := orders[key]; ok {
+			return order, nil
+		}
+		return nil, errors.New("not found")
+	})
+
+	command, err := cloudevents.NewCommandEvent("/checkout", "com.example.order.trigger", transition.TriggerCommand{Event: "pay", Key: "o1", Reason: "card captured"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(command)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	received, err := cloudevents.Parse(data)
+	if err != nil || received.Subject != "o1" {
+		t.Fatalf("command events should round-trip, got %+v, %v", received, err)
+	}
+	result, err := cloudevents.HandleCloudEvent(context.Background(), orderStateMachine, resolver, received)
+	if err != nil || result.To != "paid" || orders["o1"].GetState() != "paid" {
+		t.Fatalf("handling the command should trigger pay, got %+v, %v", result, err)
+	}
+
+	if len(published) != 1 {
+		t.Fatalf("the transition should be published once, got %d", len(published))
+	}
+	event, err := cloudevents.Parse(published[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var transitionData cloudevents.TransitionData
+	if err := json.Unmarshal(event.Data, &transitionData); err != nil {
+		t.Fatal(err)
+	}
+	expected := cloudevents.TransitionData{Event: "pay", From: "draft", To: "paid", Reason: "card captured", Actor: "system", CorrelationID: result.CorrelationID}
+	if event.Type != config.Type || event.Source != "/orders" || event.Subject != "o1" || event.ID == "" || transitionData != expected {
+		t.Errorf("unexpected transition event %+v with data %+v", event, transitionData)
+	}
+
+	if _, err := cloudevents.HandleCloudEvent(context.Background(), orderStateMachine, resolver, cloudevents.Event{ID: "1", Subject: "o2", Data: json.RawMessage(`{"event":"pay"}`)}); err == nil {
+		t.Errorf("unresolved values should fail")
+	}
+}
+
+func TestParse(t *testing.T) {
+	for _, data := range []string{
+		`not json`,
+		`{"specversion":"0.3","id":"1","source":"/orders","type":"order"}`,
+		`{"specversion":"1.0","source":"/orders","type":"order"}`,
+	} {
+		if _, err := cloudevents.Parse([]byte(data)); !errors.Is(err, cloudevents.ErrInvalidEvent) {
+			t.Errorf("%s should be invalid, got %v", data, err)
+		}
+	}
+}
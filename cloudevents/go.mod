@@ -0,0 +1,7 @@
+module github.com/daegalus/transition/cloudevents
+
+go 1.20
+
+require github.com/daegalus/transition v0.0.0
+
+replace github.com/daegalus/transition => ../
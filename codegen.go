@@ -0,0 +1,112 @@
+package transition
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"strconv"
+	"strings"
+)
+
+// GenerateGo emit a Go file of package pkg defining the states and events of definition with the builder API,
+// in a function named Define<varName> taking the state machine as varName:
+//
+//	func DefineOrderStateMachine[T transition.Stater](OrderStateMachine *transition.StateMachine[T]) {
+//		OrderStateMachine.Initial("draft")
+//		OrderStateMachine.State("checkout")
+//		OrderStateMachine.Event("checkout").To("checkout").From("draft")
+//	}
+//
+// The names of states and events are also emitted as constants, e.g. OrderStateMachineStateAwaitingApproval for
+// "awaiting approval", states or events generated as the same identifier fail the generation. Hooks can't be
+// generated, the hooks the definition references are emitted as TODO comments
+func GenerateGo(definition Definition, pkg, varName string) ([]byte, error) {
+	if !token.IsIdentifier(pkg) {
+		return nil, fmt.Errorf("failed to generate go: invalid package name %q", pkg)
+	}
+	if !token.IsIdentifier(varName) {
+		return nil, fmt.Errorf("failed to generate go: invalid variable name %q", varName)
+	}
+	exported := strings.ToUpper(varName[:1]) + varName[1:]
+
+	var states, events []string
+	if definition.Initial != "" {
+		states = append(states, definition.Initial)
+	}
+	for _, state := range definition.States {
+		states = append(states, state.Name)
+	}
+	for _, event := range definition.Events {
+		events = append(events, event.Name)
+	}
+	stateIdents, stateErr := identifiers("states", states)
+	eventIdents, eventErr := identifiers("events", events)
+	if err := newMultiError([]error{stateErr, eventErr}); err != nil {
+		return nil, fmt.Errorf("failed to generate go: %w", err)
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by transition.GenerateGo. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "package %s\n\n", pkg)
+	fmt.Fprintf(&buf, "import \"github.com/daegalus/transition\"\n\n")
+
+	constants := func(kind string, names []string, idents map[string]string) {
+		if len(names) == 0 {
+			return
+		}
+		fmt.Fprintf(&buf, "// %ss of %s\nconst (\n", kind, varName)
+		written := map[string]bool{}
+		for _, name := range names {
+			if !written[name] {
+				written[name] = true
+				fmt.Fprintf(&buf, "%s%s%s = %s\n", exported, kind, idents[name], strconv.Quote(name))
+			}
+		}
+		fmt.Fprintf(&buf, ")\n\n")
+	}
+	constants("State", states, stateIdents)
+	constants("Event", events, eventIdents)
+
+	fmt.Fprintf(&buf, "// Define%s define the states and events of %s\n", exported, varName)
+	fmt.Fprintf(&buf, "func Define%s[T transition.Stater](%s *transition.StateMachine[T]) {\n", exported, varName)
+
+	if definition.Initial != "" {
+		fmt.Fprintf(&buf, "%s.Initial(%s)\n", varName, strconv.Quote(definition.Initial))
+	}
+
+	todo := func(kind string, names []string) {
+		for _, name := range names {
+			fmt.Fprintf(&buf, "// TODO: register %s hook %s\n", kind, name)
+		}
+	}
+
+	for _, state := range definition.States {
+		todo("enter", state.Enter)
+		todo("exit", state.Exit)
+		fmt.Fprintf(&buf, "%s.State(%s)\n", varName, strconv.Quote(state.Name))
+	}
+
+	for _, event := range definition.Events {
+		for _, transition := range event.Transitions {
+			todo("before", transition.Before)
+			todo("after", transition.After)
+			fmt.Fprintf(&buf, "%s.Event(%s).To(%s)", varName, strconv.Quote(event.Name), strconv.Quote(transition.To))
+			if len(transition.From) > 0 {
+				froms := make([]string, len(transition.From))
+				for i, from := range transition.From {
+					froms[i] = strconv.Quote(from)
+				}
+				fmt.Fprintf(&buf, ".From(%s)", strings.Join(froms, ", "))
+			}
+			fmt.Fprintln(&buf)
+		}
+	}
+	fmt.Fprintln(&buf, "}")
+
+	source, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate go: %w", err)
+	}
+	return source, nil
+}
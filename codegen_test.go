@@ -0,0 +1,49 @@
+package transition
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateGo(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "order.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var definition Definition
+	if err := json.Unmarshal(data, &definition); err != nil {
+		t.Fatal(err)
+	}
+
+	source, err := GenerateGo(definition, "generated", "OrderStateMachine")
+	if err != nil {
+		t.Fatalf("should not raise any error when generating go, got %v", err)
+	}
+
+	// the generated file is compiled and checked against the definition by the tests of internal/generated
+	generated := filepath.Join("internal", "generated", "order.go")
+	if *update {
+		if err := os.WriteFile(generated, source, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected, err := os.ReadFile(generated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(source) != string(expected) {
+		t.Errorf("unexpected generated go, got\n%s", source)
+	}
+
+	if !strings.Contains(string(source), "// TODO: register enter hook reserve_stock") {
+		t.Errorf("referenced hooks should be emitted as TODO comments")
+	}
+
+	if _, err := GenerateGo(definition, "generated", "order machine"); err == nil {
+		t.Errorf("invalid variable name should raise an error")
+	}
+}
@@ -0,0 +1,81 @@
+package transition
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrGuardPanicked is returned for guards that panicked, the transition is rejected
+	ErrGuardPanicked = errors.New("guard panicked")
+	// ErrNegatedGuardPassed is returned by Not when the negated guard accepts the value
+	ErrNegatedGuardPassed = errors.New("negated guard passed")
+)
+
+// GuardError is returned by named guards rejecting a value, Name tells which guard rejected it
+type GuardError struct {
+	Name string
+	Err  error
+}
+
+func (guardErr *GuardError) Error() string {
+	return fmt.Sprintf("guard %s: %v", guardErr.Name, guardErr.Err)
+}
+
+func (guardErr *GuardError) Unwrap() error {
+	return guardErr.Err
+}
+
+// Named name a guard, its rejections and panics are returned as a GuardError holding the name,
+// so Explain and trigger errors tell which guard rejected a transition
+func Named[T Stater](name string, guard Guard[T]) Guard[T] {
+	return func(ctx context.Context, value T) error {
+		if err := callGuard(ctx, guard, value); err != nil {
+			return &GuardError{Name: name, Err: err}
+		}
+		return nil
+	}
+}
+
+// And is a guard accepting values accepted by all guards, it stops at the first rejection
+func And[T Stater](guards ...Guard[T]) Guard[T] {
+	return func(ctx context.Context, value T) error {
+		for _, guard := range guards {
+			if err := callGuard(ctx, guard, value); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// Or is a guard accepting values accepted by any guard, it stops at the first acceptance.
+// When all guards reject the value, the returned MultiError holds every rejection
+func Or[T Stater](guards ...Guard[T]) Guard[T] {
+	return func(ctx context.Context, value T) error {
+		var errs []error
+		for _, guard := range guards {
+			err := callGuard(ctx, guard, value)
+			if err == nil {
+				return nil
+			}
+			errs = append(errs, err)
+		}
+		return newMultiError(errs)
+	}
+}
+
+// Not is a guard accepting values rejected by guard, a panicking guard still rejects the value
+func Not[T Stater](guard Guard[T]) Guard[T] {
+	return func(ctx context.Context, value T) error {
+		err := callGuard(ctx, guard, value)
+		switch {
+		case errors.Is(err, ErrGuardPanicked):
+			return err
+		case err == nil:
+			return ErrNegatedGuardPassed
+		}
+		return nil
+	}
+}
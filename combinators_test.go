@@ -0,0 +1,92 @@
+package transition
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestGuardCombinators(t *testing.T) {
+	var (
+		hasAddress = Named("has_address", func(ctx context.Context, order *Order) error {
+			if order.Address == "" {
+				return errors.New("address is required")
+			}
+			return nil
+		})
+		isVip = Named("is_vip", func(ctx context.Context, order *Order) error {
+			if order.Id < 100 {
+				return errors.New("not a vip")
+			}
+			return nil
+		})
+		evaluated int
+		counted   = func(ctx context.Context, order *Order) error {
+			evaluated++
+			return nil
+		}
+		ctx = context.Background()
+	)
+
+	cases := []struct {
+		name   string
+		guard  Guard[*Order]
+		order  *Order
+		accept bool
+	}{
+		{"and accepts", And(hasAddress, isVip), &Order{Id: 100, Address: "a"}, true},
+		{"and rejects", And(hasAddress, isVip), &Order{Address: "a"}, false},
+		{"or accepts", Or(isVip, hasAddress), &Order{Address: "a"}, true},
+		{"or rejects", Or(isVip, hasAddress), &Order{}, false},
+		{"not accepts", Not(isVip), &Order{}, true},
+		{"not rejects", Not(isVip), &Order{Id: 100}, false},
+	}
+	for _, c := range cases {
+		if err := c.guard(ctx, c.order); (err == nil) != c.accept {
+			t.Errorf("%s: unexpected result %v", c.name, err)
+		}
+	}
+
+	if err := And(isVip, counted)(ctx, &Order{}); err == nil || evaluated != 0 {
+		t.Errorf("and should short-circuit on the first rejection")
+	}
+	if err := Or(hasAddress, counted)(ctx, &Order{Address: "a"}); err != nil || evaluated != 0 {
+		t.Errorf("or should short-circuit on the first acceptance")
+	}
+
+	var guardErr *GuardError
+	if err := And(hasAddress, isVip)(ctx, &Order{Address: "a"}); !errors.As(err, &guardErr) || guardErr.Name != "is_vip" {
+		t.Errorf("rejection should name the guard, got %v", err)
+	}
+}
+
+func TestGuardPanic(t *testing.T) {
+	orderStateMachine := getStateMachine()
+	orderStateMachine.Event("checkout").To("checkout").Guard(Named("explodes", func(ctx context.Context, order *Order) error {
+		panic("boom")
+	}))
+
+	order := &Order{}
+	err := orderStateMachine.Trigger("checkout", order)
+
+	var guardErr *GuardError
+	if !errors.Is(err, ErrGuardPanicked) || !errors.As(err, &guardErr) || guardErr.Name != "explodes" {
+		t.Errorf("panic should be recovered into a guard failure, got %v", err)
+	}
+	if order.State != "draft" {
+		t.Errorf("state should not change when a guard panics, got %s", order.State)
+	}
+
+	if explanation := orderStateMachine.Explain("checkout", order); !strings.Contains(explanation.String(), "guard explodes") {
+		t.Errorf("explanation should name the guard, got %s", explanation)
+	}
+
+	orderStateMachine = getStateMachine()
+	orderStateMachine.Event("checkout").To("checkout").Guard(func(ctx context.Context, order *Order) error {
+		panic("boom")
+	})
+	if err := orderStateMachine.Trigger("checkout", &Order{}); !errors.Is(err, ErrGuardPanicked) {
+		t.Errorf("panic of unnamed guards should be recovered, got %v", err)
+	}
+}
@@ -0,0 +1,109 @@
+package transition
+
+import (
+	"context"
+	"errors"
+)
+
+// TriggerCommand is an event to trigger on a value along with how to trigger it, see StateMachine.Execute.
+// Queues, scheduled triggers and dead letters carry commands, they are serialized to JSON unchanged,
+// except for Payload which is decoded as generic JSON values, decoded again into the payload type of the event when
+// triggered, see DefineEvent
+type TriggerCommand struct {
+	Event string `json:"event"`
+	// Key identifies the value the command is for, see SetResolver
+	Key string `json:"key,omitempty"`
+	// Args are free-form arguments of the command, passed to guards and authorizers, see ArgsFromContext
+	Args map[string]any `json:"args,omitempty"`
+	// Payload is passed to the payload hooks of the event, see DefineEvent
+	Payload any `json:"payload,omitempty"`
+	// Reason and Actor are passed in the context, see WithReason and WithActor
+	Reason string `json:"reason,omitempty"`
+	Actor  string `json:"actor,omitempty"`
+	// IdempotencyKey triggers the event once per key, see TriggerIdempotent
+	IdempotencyKey string `json:"idempotency_key,omitempty"`
+	// Attempts is the number of times the command was tried
+	Attempts int `json:"attempts,omitempty"`
+	// CorrelationID correlates the command with the triggers it causes, see WithCorrelationID
+	CorrelationID string `json:"correlation_id,omitempty"`
+	// Fingerprint is the fingerprint of the state machine the command was built for, checked by Execute when set,
+	// see OnStaleCommand
+	Fingerprint string `json:"fingerprint,omitempty"`
+}
+
+// TransitionResult is the outcome of a command, To is empty when no transition was matched
+type TransitionResult struct {
+	Event string `json:"event"`
+	From  string `json:"from"`
+	To    string `json:"to,omitempty"`
+	// CorrelationID is the correlation ID of the trigger, see WithCorrelationID
+	CorrelationID string `json:"correlation_id,omitempty"`
+	// Warnings are the failures that didn't fail the trigger, see Warning
+	Warnings []Warning `json:"warnings,omitempty"`
+	// Commands are the commands emitted by the hooks for the caller to dispatch, see EventTransition.AfterEmit
+	Commands []Command `json:"commands,omitempty"`
+	// Variant is the variant that performed the trigger, see VariantResolver
+	Variant string `json:"variant,omitempty"`
+}
+
+type argsContextKey struct{}
+
+// ArgsFromContext returns the arguments of the command being executed, or nil
+func ArgsFromContext(ctx context.Context) map[string]any {
+	args, _ := ctx.Value(argsContextKey{}).(map[string]any)
+	return args
+}
+
+// Execute trigger the command's event on value, Trigger and its variants are shorthands for it. The result tells
+// which transition was performed, also when a hook failed
+func (sm *StateMachine[T]) Execute(ctx context.Context, command TriggerCommand, value T) (*TransitionResult, error) {
+	result, err := sm.execute(ctx, command, value, triggerOptions{})
+	return &result, err
+}
+
+func (sm *StateMachine[T]) execute(ctx context.Context, command TriggerCommand, value T, opts triggerOptions) (TransitionResult, error) {
+	sm.owner.start()
+	result := TransitionResult{Event: command.Event}
+	if !isNil(value) {
+		result.From = sm.currentState(value)
+	}
+
+	command, err := sm.checkFingerprint(ctx, command)
+	if err != nil {
+		return result, err
+	}
+	result.Event = command.Event
+
+	ctx, result.CorrelationID = sm.correlate(ctx, command, value)
+	if command.Actor != "" {
+		ctx = WithActor(ctx, command.Actor)
+	}
+	if command.Reason != "" {
+		ctx = WithReason(ctx, command.Reason)
+	}
+	if len(command.Args) > 0 {
+		ctx = context.WithValue(ctx, argsContextKey{}, command.Args)
+	}
+	if command.Payload != nil {
+		opts.payload = command.Payload
+	}
+
+	opts.warnings = &result.Warnings
+	opts.commands = &result.Commands
+	switch {
+	case command.IdempotencyKey != "":
+		err = sm.coalesceIdempotent(ctx, command.Event, value, command.IdempotencyKey, opts)
+	default:
+		err = sm.trigger(ctx, command.Event, value, opts)
+	}
+
+	if err == nil {
+		result.To = value.GetState()
+		return result, nil
+	}
+	var transitionErr *TransitionError
+	if errors.As(err, &transitionErr) {
+		result.To = transitionErr.To
+	}
+	return result, err
+}
@@ -0,0 +1,77 @@
+package transition
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestExecute(t *testing.T) {
+	var (
+		orderStateMachine = getStateMachine()
+		actor, reason     string
+	)
+	orderStateMachine.Event("pay").To("paid").From("checkout").Guard(func(ctx context.Context, order *Order) error {
+		if ArgsFromContext(ctx)["amount"] != 42.0 {
+			return errors.New("wrong amount")
+		}
+		return nil
+	})
+	notify, _ := Notify[*Order]("{{.Actor}} {{.Reason}}", func(ctx context.Context, msg string) error {
+		actor, reason = ActorFromContext(ctx), ReasonFromContext(ctx)
+		return nil
+	})
+	orderStateMachine.Event("pay").To("paid").Notify(notify)
+
+	// Commands travel through queues as JSON
+	data, err := json.Marshal(TriggerCommand{Event: "pay", Key: "1", Args: map[string]any{"amount": 42}, Reason: "checkout completed", Actor: "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var command TriggerCommand
+	if err := json.Unmarshal(data, &command); err != nil {
+		t.Fatal(err)
+	}
+	expected := TriggerCommand{Event: "pay", Key: "1", Args: map[string]any{"amount": 42.0}, Reason: "checkout completed", Actor: "alice"}
+	if !reflect.DeepEqual(command, expected) {
+		t.Errorf("expected command %+v, got %+v", expected, command)
+	}
+
+	order := &Order{}
+	order.State = "checkout"
+	result, err := orderStateMachine.Execute(WithCorrelationID(context.Background(), "req-1"), command, order)
+	if err != nil || !reflect.DeepEqual(*result, TransitionResult{Event: "pay", From: "checkout", To: "paid", CorrelationID: "req-1"}) {
+		t.Errorf("unexpected result %+v, %v", result, err)
+	}
+	if actor != "alice" || reason != "checkout completed" {
+		t.Errorf("the actor and reason of the command should be in the context, got %q and %q", actor, reason)
+	}
+
+	result, err = orderStateMachine.Execute(context.Background(), TriggerCommand{Event: "pay", CorrelationID: "req-2"}, order)
+	if !IsNoMatch(err) || !reflect.DeepEqual(*result, TransitionResult{Event: "pay", From: "paid", CorrelationID: "req-2"}) || CorrelationIDOf(err) != "req-2" {
+		t.Errorf("failed commands should report where they stopped, got %+v, %v", result, err)
+	}
+}
+
+func TestExecuteIdempotent(t *testing.T) {
+	orderStateMachine := getStateMachine()
+	orderStateMachine.SetIdempotencyStore(NewMemoryIdempotencyStore(nil))
+
+	var calls int
+	orderStateMachine.Event("checkout").To("checkout").From("draft").After(func(order *Order) error {
+		calls++
+		return nil
+	})
+
+	command := TriggerCommand{Event: "checkout", IdempotencyKey: "message-1"}
+	for i := 0; i < 2; i++ {
+		if _, err := orderStateMachine.Execute(context.Background(), command, &Order{}); err != nil {
+			t.Fatalf("no error should happen, but got %v", err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("commands with an idempotency key should run once, ran %d times", calls)
+	}
+}
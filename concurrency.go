@@ -0,0 +1,115 @@
+package transition
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrTooManyInFlight is returned by triggers that couldn't start because the state machine runs as many triggers
+// as allowed, see WithMaxConcurrentTriggers
+var ErrTooManyInFlight = errors.New("too many triggers in flight")
+
+// ConcurrencyPolicy tells what triggers do when the state machine runs as many triggers as allowed, see
+// WithConcurrencyPolicy
+type ConcurrencyPolicy struct {
+	// wait is how long triggers wait for another to complete, forever when negative
+	wait time.Duration
+}
+
+var (
+	// Block make triggers wait for another trigger to complete, or for their context to be done
+	Block = ConcurrencyPolicy{wait: -1}
+	// FailFast make triggers fail with ErrTooManyInFlight
+	FailFast = ConcurrencyPolicy{}
+)
+
+// WaitUpTo make triggers wait up to d for another trigger to complete, then fail with ErrTooManyInFlight
+func WaitUpTo(d time.Duration) ConcurrencyPolicy {
+	return ConcurrencyPolicy{wait: d}
+}
+
+// WithMaxConcurrentTriggers limit to n the triggers the state machine runs at the same time, triggers caused by
+// hooks on the value being triggered with the context of their trigger don't count, see ContextHook. Beyond n, triggers block unless configured otherwise with
+// WithConcurrencyPolicy. Hooks triggering events on other values of the same machine may deadlock when blocking
+func WithMaxConcurrentTriggers(n int) Option {
+	return func(opts *options) {
+		opts.maxConcurrentTriggers = n
+	}
+}
+
+// WithConcurrencyPolicy define what triggers do beyond the max concurrent triggers, Block by default
+func WithConcurrencyPolicy(policy ConcurrencyPolicy) Option {
+	return func(opts *options) {
+		opts.concurrencyPolicy = &policy
+	}
+}
+
+// limiter is a semaphore bounding the triggers in flight
+type limiter struct {
+	slots  chan struct{}
+	policy ConcurrencyPolicy
+}
+
+func newLimiter(n int, policy *ConcurrencyPolicy) *limiter {
+	if n <= 0 {
+		return nil
+	}
+	if policy == nil {
+		policy = &Block
+	}
+	return &limiter{slots: make(chan struct{}, n), policy: *policy}
+}
+
+// inFlight returns the number of triggers holding a slot
+func (limiter *limiter) inFlight() int {
+	if limiter == nil {
+		return 0
+	}
+	return len(limiter.slots)
+}
+
+// acquire takes a slot for a trigger, release must be called once it returns. Nested triggers, caused by hooks on
+// the value being triggered, hold the slot of the trigger running the hook
+func (sm *StateMachine[T]) acquire(ctx context.Context, nested bool) (release func(), err error) {
+	limiter := sm.limiter
+	if limiter == nil || nested {
+		return func() {}, nil
+	}
+
+	release = func() { <-limiter.slots }
+	select {
+	case limiter.slots <- struct{}{}:
+		return release, nil
+	default:
+	}
+
+	var timeout <-chan struct{}
+	switch {
+	case limiter.policy.wait == 0:
+		return nil, fmt.Errorf("%w: %d", ErrTooManyInFlight, cap(limiter.slots))
+	case limiter.policy.wait > 0:
+		expired := make(chan struct{})
+		timer := sm.clock.AfterFunc(limiter.policy.wait, func() { close(expired) })
+		defer timer.Stop()
+		timeout = expired
+	}
+
+	select {
+	case limiter.slots <- struct{}{}:
+		return release, nil
+	case <-timeout:
+		return nil, fmt.Errorf("%w: %d, waited %s", ErrTooManyInFlight, cap(limiter.slots), limiter.policy.wait)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// renew returns a limiter with the same limit and policy, see StateMachine.Clone
+func (current *limiter) renew() *limiter {
+	if current == nil {
+		return nil
+	}
+	return &limiter{slots: make(chan struct{}, cap(current.slots)), policy: current.policy}
+}
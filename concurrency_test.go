@@ -0,0 +1,159 @@
+package transition
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// getBlockingStateMachine returns a state machine whose checkout blocks until release is closed, started receives
+// a value once a checkout is running
+func getBlockingStateMachine(opts ...Option) (sm *StateMachine[*Order], started chan struct{}, release chan struct{}) {
+	started, release = make(chan struct{}, 10), make(chan struct{})
+	sm = New(&Order{}, opts...)
+	sm.Initial("draft")
+	sm.State("checkout")
+	sm.Event("checkout").To("checkout").From("draft").Before(func(order *Order) error {
+		started <- struct{}{}
+		<-release
+		return nil
+	})
+	sm.Event("fail").To("checkout").From("draft").Before(func(order *Order) error {
+		return errors.New("rolled back")
+	})
+	sm.Event("panic").To("checkout").From("draft").Before(func(order *Order) error {
+		panic("hook panicked")
+	})
+	return sm, started, release
+}
+
+func TestMaxConcurrentTriggersFailFast(t *testing.T) {
+	orderStateMachine, started, release := getBlockingStateMachine(WithMaxConcurrentTriggers(2), WithConcurrencyPolicy(FailFast))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := orderStateMachine.Trigger("checkout", &Order{}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	<-started
+	<-started
+
+	if inFlight := orderStateMachine.Stats().InFlight; inFlight != 2 {
+		t.Errorf("expected 2 triggers in flight, got %d", inFlight)
+	}
+	order := &Order{}
+	err := orderStateMachine.Trigger("checkout", order)
+	if !errors.Is(err, ErrTooManyInFlight) || !IsRetryable(err) || order.State != "" {
+		t.Errorf("expected ErrTooManyInFlight, got %v", err)
+	}
+
+	close(release)
+	wg.Wait()
+	if inFlight := orderStateMachine.Stats().InFlight; inFlight != 0 {
+		t.Errorf("expected no trigger in flight, got %d", inFlight)
+	}
+
+	// slots are released when triggers fail or panic
+	for i := 0; i < 3; i++ {
+		if err := orderStateMachine.Trigger("fail", &Order{}); err == nil {
+			t.Error("expected the hook error")
+		}
+		func() {
+			defer func() { _ = recover() }()
+			_ = orderStateMachine.Trigger("panic", &Order{})
+		}()
+	}
+	if err := orderStateMachine.Trigger("checkout", &Order{}); err != nil {
+		t.Errorf("slots should have been released, got %v", err)
+	}
+}
+
+func TestMaxConcurrentTriggersBlock(t *testing.T) {
+	orderStateMachine, started, release := getBlockingStateMachine(WithMaxConcurrentTriggers(1))
+
+	done := make(chan error, 2)
+	go func() { done <- orderStateMachine.Trigger("checkout", &Order{}) }()
+	<-started
+	go func() { done <- orderStateMachine.Trigger("checkout", &Order{}) }()
+
+	select {
+	case <-started:
+		t.Fatal("the second trigger should wait for the first one")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := orderStateMachine.TriggerContext(ctx, "checkout", &Order{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("waiting triggers should stop with their context, got %v", err)
+	}
+
+	close(release)
+	for i := 0; i < 2; i++ {
+		if err := <-done; err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestMaxConcurrentTriggersWaitUpTo(t *testing.T) {
+	orderStateMachine, started, release := getBlockingStateMachine(WithMaxConcurrentTriggers(1), WithConcurrencyPolicy(WaitUpTo(10*time.Millisecond)))
+
+	done := make(chan error, 1)
+	go func() { done <- orderStateMachine.Trigger("checkout", &Order{}) }()
+	<-started
+
+	if err := orderStateMachine.Trigger("checkout", &Order{}); !errors.Is(err, ErrTooManyInFlight) {
+		t.Errorf("expected ErrTooManyInFlight after waiting, got %v", err)
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Error(err)
+	}
+	if err := orderStateMachine.Trigger("checkout", &Order{}); err != nil {
+		t.Errorf("expected the trigger to run once a slot is free, got %v", err)
+	}
+}
+
+func TestMaxConcurrentTriggersChain(t *testing.T) {
+	orderStateMachine := getStateMachine()
+	orderStateMachine.limiter = newLimiter(1, &FailFast)
+	orderStateMachine.State("checkout").EnterContext(func(ctx context.Context, order *Order) error {
+		return orderStateMachine.TriggerContext(ctx, "pay", order)
+	})
+
+	order := &Order{}
+	if err := orderStateMachine.Trigger("checkout", order); err != nil || order.State != "paid" {
+		t.Errorf("triggers caused by hooks on the same value should share its slot, got %s and %v", order.State, err)
+	}
+}
+
+func TestMaxConcurrentTriggersSameKey(t *testing.T) {
+	orderStateMachine, started, release := getBlockingStateMachine(WithMaxConcurrentTriggers(1), WithConcurrencyPolicy(FailFast))
+	orderStateMachine.SetKeyFunc(func(order *Order) string { return "same" })
+	orderStateMachine.State("paid")
+	orderStateMachine.Event("pay").To("paid").From("checkout")
+
+	triggers := make(chan error, 1)
+	go func() { triggers <- orderStateMachine.Trigger("checkout", &Order{}) }()
+	<-started
+
+	other := &Order{}
+	other.SetState("checkout")
+	if err := orderStateMachine.Trigger("pay", other); !errors.Is(err, ErrTooManyInFlight) {
+		t.Errorf("triggers on values with the same key should take their own slot, got %v", err)
+	}
+
+	close(release)
+	if err := <-triggers; err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,66 @@
+package transition
+
+import "context"
+
+// ContextHook is a hook receiving the context of the trigger running it. Hooks triggering events on the value being
+// triggered pass it on, so the triggers they cause join the chain of the trigger, see WithMaxChainDepth:
+//
+//	OrderStateMachine.Event("checkout").To("checkout").From("draft").AfterContext(func(ctx context.Context, order *Order) error {
+//		return OrderStateMachine.TriggerContext(ctx, "pay", order)
+//	})
+type ContextHook[T Stater] func(ctx context.Context, value T) error
+
+// EnterContext register an enter hook for State receiving the context of the trigger, see ContextHook
+func (state *State[T]) EnterContext(fc ContextHook[T]) *State[T] {
+	state.checkMutable("State.EnterContext")
+	ref := anonymousHook(2)
+	ref.contextual = fc
+	state.enters = append(state.enters, bindContext(context.Background(), fc))
+	state.enterRefs = append(state.enterRefs, ref)
+	return state
+}
+
+// ExitContext register an exit hook for State receiving the context of the trigger, see ContextHook
+func (state *State[T]) ExitContext(fc ContextHook[T]) *State[T] {
+	state.checkMutable("State.ExitContext")
+	ref := anonymousHook(2)
+	ref.contextual = fc
+	state.exits = append(state.exits, bindContext(context.Background(), fc))
+	state.exitRefs = append(state.exitRefs, ref)
+	return state
+}
+
+// BeforeContext register a before hook receiving the context of the trigger, see ContextHook
+func (transition *EventTransition[T]) BeforeContext(fc ContextHook[T]) *EventTransition[T] {
+	transition.checkMutable("EventTransition.BeforeContext")
+	ref := anonymousHook(2)
+	ref.contextual = fc
+	transition.befores = append(transition.befores, bindContext(context.Background(), fc))
+	transition.beforeRefs = append(transition.beforeRefs, ref)
+	return transition
+}
+
+// AfterContext register an after hook receiving the context of the trigger, see ContextHook
+func (transition *EventTransition[T]) AfterContext(fc ContextHook[T]) *EventTransition[T] {
+	transition.checkMutable("EventTransition.AfterContext")
+	ref := anonymousHook(2)
+	ref.contextual = fc
+	transition.afters = append(transition.afters, bindContext(context.Background(), fc))
+	transition.afterRefs = append(transition.afterRefs, ref)
+	return transition
+}
+
+// bindContext returns the hook running fc with the context of a trigger
+func bindContext[T Stater](ctx context.Context, fc ContextHook[T]) func(value T) error {
+	return func(value T) error {
+		return fc(ctx, value)
+	}
+}
+
+// withContext returns hook bound to ctx when it was registered with a context, hook otherwise
+func withContext[T Stater](ctx context.Context, ref HookRef, hook func(value T) error) func(value T) error {
+	if fc, ok := ref.contextual.(ContextHook[T]); ok {
+		return bindContext(ctx, fc)
+	}
+	return hook
+}
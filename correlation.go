@@ -0,0 +1,87 @@
+package transition
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"sync/atomic"
+)
+
+type correlationIDContextKey struct{}
+
+// WithGeneratedCorrelationIDs generate a correlation ID for the triggers without one, see WithCorrelationID.
+// Without it triggers only carry the correlation IDs they are given
+func WithGeneratedCorrelationIDs() Option {
+	return func(opts *options) {
+		opts.generateCorrelationIDs = true
+	}
+}
+
+// WithCorrelationID returns a copy of ctx carrying the correlation ID of the triggers performed with it, e.g. the
+// ID of the incoming request. Triggers without correlation ID get a generated one with WithGeneratedCorrelationIDs
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationIDContextKey{}, id)
+}
+
+// CorrelationIDFromContext returns the correlation ID of the trigger ctx belongs to, or an empty string
+func CorrelationIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(correlationIDContextKey{}).(string)
+	return id
+}
+
+// SetCorrelationIDFunc define how the correlation ID of a trigger is extracted from its context when it has none
+// set with WithCorrelationID, e.g. reading the request ID set by a middleware
+func (sm *StateMachine[T]) SetCorrelationIDFunc(fc func(ctx context.Context) string) *StateMachine[T] {
+	sm.owner.checkMutable("SetCorrelationIDFunc")
+	sm.correlationIDFunc = fc
+	return sm
+}
+
+// CorrelationIDOf returns the correlation ID of the trigger that returned err, or an empty string
+func CorrelationIDOf(err error) string {
+	var transitionErr *TransitionError
+	if errors.As(err, &transitionErr) {
+		return transitionErr.CorrelationID
+	}
+	return ""
+}
+
+var (
+	correlationPrefix  = newCorrelationPrefix()
+	correlationCounter atomic.Uint64
+)
+
+func newCorrelationPrefix() string {
+	var prefix [6]byte
+	rand.Read(prefix[:])
+	return hex.EncodeToString(prefix[:])
+}
+
+// newCorrelationID returns a correlation ID unique to the process, and very likely across processes
+func newCorrelationID() string {
+	return correlationPrefix + "-" + strconv.FormatUint(correlationCounter.Add(1), 36)
+}
+
+// correlate returns ctx carrying the correlation ID of a trigger on value: the one of command, of ctx, extracted by
+// the correlation ID func, or a new one with WithGeneratedCorrelationIDs. Triggers caused by hooks get the one of
+// their trigger through ctx
+func (sm *StateMachine[T]) correlate(ctx context.Context, command TriggerCommand, value T) (context.Context, string) {
+	id := command.CorrelationID
+	if id == "" {
+		if id = CorrelationIDFromContext(ctx); id != "" {
+			return ctx, id
+		}
+	}
+	if id == "" && sm.correlationIDFunc != nil {
+		id = sm.correlationIDFunc(ctx)
+	}
+	if id == "" && sm.generateIDs {
+		id = newCorrelationID()
+	}
+	if id == "" {
+		return ctx, ""
+	}
+	return WithCorrelationID(ctx, id), id
+}
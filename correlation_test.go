@@ -0,0 +1,152 @@
+package transition
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestCorrelationID(t *testing.T) {
+	var (
+		orderStateMachine    = getStateMachine(WithGeneratedCorrelationIDs())
+		shipmentStateMachine = getShipmentStateMachine()
+		shipments            = map[int]*Shipment{1: {OrderId: 1}}
+		recorded             []string
+		reported             []error
+	)
+	record := func(ctx context.Context, data NotifyData[*Order]) error {
+		recorded = append(recorded, data.Transition.Event+" "+data.CorrelationID)
+		return nil
+	}
+
+	orderStateMachine.State("checkout").EnterContext(func(ctx context.Context, order *Order) error {
+		return orderStateMachine.TriggerContext(ctx, "pay", order)
+	})
+	orderStateMachine.Event("checkout").To("checkout").From("draft").Notify(record)
+	orderStateMachine.Event("pay").To("paid").From("checkout").Notify(record)
+	orderStateMachine.OnError(func(err error) { reported = append(reported, err) })
+	shipmentStateMachine.Event("start_packing").To("packing").From("pending").Notify(func(ctx context.Context, data NotifyData[*Shipment]) error {
+		recorded = append(recorded, data.Transition.Event+" "+data.CorrelationID)
+		return nil
+	})
+
+	LinkMachines(orderStateMachine, "paid", shipmentStateMachine, "start_packing", func(order *Order) (*Shipment, error) {
+		if shipment, ok := shipments[order.Id]; ok {
+			return shipment, nil
+		}
+		return &Shipment{Transition: Transition{State: "packing"}}, nil
+	})
+
+	result, err := orderStateMachine.Execute(WithCorrelationID(context.Background(), "req-1"), TriggerCommand{Event: "checkout"}, &Order{Id: 1})
+	if err != nil || result.CorrelationID != "req-1" {
+		t.Fatalf("unexpected result %+v, %v", result, err)
+	}
+	expected := []string{"start_packing req-1", "pay req-1", "checkout req-1"}
+	if len(recorded) != len(expected) {
+		t.Fatalf("expected records %v, got %v", expected, recorded)
+	}
+	for i := range expected {
+		if recorded[i] != expected[i] {
+			t.Errorf("expected records %v, got %v", expected, recorded)
+			break
+		}
+	}
+
+	recorded = nil
+	if err := orderStateMachine.Trigger("checkout", &Order{Id: 2}); err != nil {
+		t.Fatalf("link failures should not fail the source transition, got %v", err)
+	}
+	if len(recorded) != 2 || recorded[0][len("pay "):] != recorded[1][len("checkout "):] || recorded[0] == "pay " {
+		t.Errorf("a generated correlation ID should be shared by the chain, got %v", recorded)
+	}
+	if len(reported) != 1 || "pay "+CorrelationIDOf(reported[0]) != recorded[0] {
+		t.Errorf("the link failure should carry the correlation ID, got %v", reported)
+	}
+}
+
+func TestCorrelationIDFunc(t *testing.T) {
+	type requestIDKey struct{}
+	orderStateMachine := getStateMachine(WithGeneratedCorrelationIDs()).SetCorrelationIDFunc(func(ctx context.Context) string {
+		id, _ := ctx.Value(requestIDKey{}).(string)
+		return id
+	})
+
+	ctx := context.WithValue(context.Background(), requestIDKey{}, "req-2")
+	result, err := orderStateMachine.Execute(ctx, TriggerCommand{Event: "pay"}, &Order{})
+	if CorrelationIDOf(err) != "req-2" || result.CorrelationID != "req-2" {
+		t.Errorf("the correlation ID should be extracted from the context, got %q and %q", CorrelationIDOf(err), result.CorrelationID)
+	}
+
+	trace, _ := orderStateMachine.TriggerTraced("checkout", &Order{})
+	if trace.CorrelationID == "" {
+		t.Errorf("traces should record the generated correlation ID")
+	}
+
+	first, _ := orderStateMachine.Execute(context.Background(), TriggerCommand{Event: "checkout"}, &Order{})
+	second, _ := orderStateMachine.Execute(context.Background(), TriggerCommand{Event: "checkout"}, &Order{})
+	if first.CorrelationID == "" || first.CorrelationID == second.CorrelationID {
+		t.Errorf("unrelated triggers should get distinct generated IDs, got %q and %q", first.CorrelationID, second.CorrelationID)
+	}
+	if CorrelationIDOf(errors.New("not a trigger error")) != "" {
+		t.Errorf("errors not returned by triggers have no correlation ID")
+	}
+}
+
+func TestCorrelationIDNotGenerated(t *testing.T) {
+	orderStateMachine := getStateMachine()
+
+	result, err := orderStateMachine.Execute(context.Background(), TriggerCommand{Event: "checkout"}, &Order{})
+	if err != nil || result.CorrelationID != "" {
+		t.Errorf("correlation IDs should only be generated with WithGeneratedCorrelationIDs, got %q, %v", result.CorrelationID, err)
+	}
+
+	result, _ = orderStateMachine.Execute(WithCorrelationID(context.Background(), "req-4"), TriggerCommand{Event: "checkout"}, &Order{})
+	if result.CorrelationID != "req-4" {
+		t.Errorf("given correlation IDs should still be carried, got %q", result.CorrelationID)
+	}
+}
+
+func TestDeadLetterCorrelationID(t *testing.T) {
+	orderStateMachine := getStateMachine()
+	_, err := orderStateMachine.Execute(WithCorrelationID(context.Background(), "req-3"), TriggerCommand{Event: "pay"}, &Order{})
+	orderStateMachine.deadLetter(context.Background(), TriggerCommand{Event: "pay"}, err)
+
+	if deadLetters := orderStateMachine.DeadLetters(); len(deadLetters) != 1 || deadLetters[0].Command.CorrelationID != "req-3" {
+		t.Errorf("dead letters should carry the correlation ID of the failure, got %+v", deadLetters)
+	}
+}
+
+func TestCorrelationIDRecorded(t *testing.T) {
+	var (
+		sm     = getHistoryStateMachine()
+		logged []string
+		infos  []string
+	)
+	sm.State("checkout").EnterContext(func(ctx context.Context, order *HistoriedOrder) error {
+		return sm.TriggerContext(ctx, "pay", order)
+	})
+	sm.SetStateChangeLog(StateChangeLogFunc(func(ctx context.Context, change StateChange) error {
+		logged = append(logged, change.CorrelationID)
+		return nil
+	}))
+	sm.AfterEach(func(ctx context.Context, order *HistoriedOrder, info TransitionInfo) error {
+		infos = append(infos, info.CorrelationID)
+		return nil
+	})
+
+	order := &HistoriedOrder{}
+	if err := sm.TriggerContext(WithCorrelationID(context.Background(), "req-3"), "checkout", order); err != nil {
+		t.Fatal(err)
+	}
+
+	history := order.GetHistory()
+	if len(history) != 2 || history[0].CorrelationID != "req-3" || history[1].CorrelationID != "req-3" {
+		t.Errorf("both hops should be recorded in the history with the correlation ID, got %v", history)
+	}
+	if len(logged) != 2 || logged[0] != "req-3" || logged[1] != "req-3" {
+		t.Errorf("both hops should be logged with the correlation ID, got %v", logged)
+	}
+	if len(infos) != 2 || infos[0] != "req-3" || infos[1] != "req-3" {
+		t.Errorf("machine hooks of both hops should read the correlation ID, got %v", infos)
+	}
+}
@@ -0,0 +1,52 @@
+package transition
+
+import (
+	"context"
+	"time"
+)
+
+// maxDeadLetters is the number of dead letters kept by the state machine when no OnDeadLetter callback is registered
+const maxDeadLetters = 100
+
+// DeadLetter is a command given up on, see StateMachine.DeadLetters
+type DeadLetter struct {
+	Command TriggerCommand
+	Err     error
+	At      time.Time
+}
+
+// OnDeadLetter register a callback receiving the commands given up on by queues and scheduled triggers, because
+// they failed with a permanent error or too many times. It replaces the default in-memory record, see DeadLetters
+func (sm *StateMachine[T]) OnDeadLetter(fc func(ctx context.Context, command TriggerCommand, err error)) *StateMachine[T] {
+	sm.owner.checkMutable("OnDeadLetter")
+	sm.onDeadLetters = append(sm.onDeadLetters, fc)
+	return sm
+}
+
+// DeadLetters returns the last commands given up on, oldest first, when no OnDeadLetter callback is registered
+func (sm *StateMachine[T]) DeadLetters() []DeadLetter {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	return append([]DeadLetter{}, sm.deadLetters...)
+}
+
+func (sm *StateMachine[T]) deadLetter(ctx context.Context, command TriggerCommand, err error) {
+	if command.CorrelationID == "" {
+		command.CorrelationID = CorrelationIDOf(err)
+	}
+	if len(sm.onDeadLetters) > 0 {
+		for _, onDeadLetter := range sm.onDeadLetters {
+			onDeadLetter(ctx, command, err)
+		}
+		return
+	}
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if len(sm.deadLetters) == maxDeadLetters {
+		sm.deadLetters = append(sm.deadLetters[:0], sm.deadLetters[1:]...)
+	}
+	sm.deadLetters = append(sm.deadLetters, DeadLetter{Command: command, Err: err, At: sm.clock.Now()})
+}
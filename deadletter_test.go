@@ -0,0 +1,20 @@
+package transition
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+)
+
+func TestDeadLettersRingBuffer(t *testing.T) {
+	orderStateMachine := getStateMachine()
+	for i := 0; i < maxDeadLetters+5; i++ {
+		orderStateMachine.deadLetter(context.Background(), TriggerCommand{Event: "pay", Key: strconv.Itoa(i)}, errors.New("failed"))
+	}
+
+	deadLetters := orderStateMachine.DeadLetters()
+	if len(deadLetters) != maxDeadLetters || deadLetters[0].Command.Key != "5" || deadLetters[maxDeadLetters-1].Command.Key != strconv.Itoa(maxDeadLetters+4) {
+		t.Errorf("only the last dead letters should be kept, got %d", len(deadLetters))
+	}
+}
@@ -0,0 +1,73 @@
+package transition
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrDebounced is returned when an event is triggered again on the same value within its debounce window
+var ErrDebounced = errors.New("event debounced")
+
+// Debounce reject triggers of the event on a value within d of the last time it was triggered on the same
+// value, without running any hooks. Values are identified with the key func, see StateMachine.SetKeyFunc
+func (event *Event[T]) Debounce(d time.Duration) *Event[T] {
+	event.checkMutable("Event.Debounce")
+	event.debounce = d
+	return event
+}
+
+type debounceKey struct {
+	key   string
+	event string
+}
+
+// reserveDebounce records the event as fired on value, or returns ErrDebounced when it already fired within its
+// window. The returned func releases the reservation when the trigger fails
+func (sm *StateMachine[T]) reserveDebounce(event *Event[T], value T) (release func(), err error) {
+	if sm.keyFunc == nil {
+		return nil, fmt.Errorf("event %s is debounced but the state machine has no key func", event.Name)
+	}
+
+	var (
+		key = debounceKey{key: sm.keyFunc(value), event: event.Name}
+		now = sm.clock.Now()
+	)
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	expiresWas, fired := sm.debounced[key]
+	if fired && now.Before(expiresWas) {
+		return nil, fmt.Errorf("%w: %s was already triggered for %s within %s", ErrDebounced, event.Name, key.key, event.debounce)
+	}
+
+	sm.debounced[key] = now.Add(event.debounce)
+	if len(sm.debounced) >= sm.nextDebounceSweep {
+		sm.sweepDebounced(now)
+	}
+
+	return func() {
+		sm.mu.Lock()
+		defer sm.mu.Unlock()
+		if fired {
+			sm.debounced[key] = expiresWas
+		} else {
+			delete(sm.debounced, key)
+		}
+	}, nil
+}
+
+// sweepDebounced forgets expired entries, so memory stays bounded by the number of events fired within their window
+func (sm *StateMachine[T]) sweepDebounced(now time.Time) {
+	for key, expires := range sm.debounced {
+		if !now.Before(expires) {
+			delete(sm.debounced, key)
+		}
+	}
+
+	sm.nextDebounceSweep = 2 * len(sm.debounced)
+	if sm.nextDebounceSweep < 64 {
+		sm.nextDebounceSweep = 64
+	}
+}
@@ -0,0 +1,112 @@
+package transition
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func getDebounceStateMachine(clock Clock, notified *int32) *StateMachine[*Order] {
+	orderStateMachine := New(&Order{}, WithClock(clock))
+	orderStateMachine.Initial("draft")
+	orderStateMachine.SetKeyFunc(func(order *Order) string { return strconv.Itoa(order.Id) })
+	orderStateMachine.Event("notify").Debounce(5 * time.Second).To("draft").From("draft").Before(func(order *Order) error {
+		atomic.AddInt32(notified, 1)
+		return nil
+	})
+	return orderStateMachine
+}
+
+func TestDebounce(t *testing.T) {
+	var (
+		notified          int32
+		clock             = &manualClock{now: time.Now()}
+		orderStateMachine = getDebounceStateMachine(clock, &notified)
+	)
+
+	if err := orderStateMachine.Trigger("notify", &Order{Id: 1}); err != nil {
+		t.Errorf("should not raise any error when trigger event notify, got %v", err)
+	}
+
+	if err := orderStateMachine.Trigger("notify", &Order{Id: 1}); !errors.Is(err, ErrDebounced) {
+		t.Errorf("should raise ErrDebounced, got %v", err)
+	}
+
+	if err := orderStateMachine.Trigger("notify", &Order{Id: 2}); err != nil {
+		t.Errorf("other values should not be debounced, got %v", err)
+	}
+
+	clock.now = clock.now.Add(5 * time.Second)
+	if err := orderStateMachine.Trigger("notify", &Order{Id: 1}); err != nil {
+		t.Errorf("should not raise any error after the debounce window, got %v", err)
+	}
+
+	if notified != 3 {
+		t.Errorf("hooks should not run for debounced triggers, ran %d times", notified)
+	}
+}
+
+func TestDebounceReleasedOnFailure(t *testing.T) {
+	var (
+		notified          int32
+		clock             = &manualClock{now: time.Now()}
+		orderStateMachine = getDebounceStateMachine(clock, &notified)
+		fail              = true
+	)
+	orderStateMachine.Event("notify").To("draft").After(func(order *Order) error {
+		if fail {
+			return errors.New("intentional error")
+		}
+		return nil
+	})
+
+	if err := orderStateMachine.Trigger("notify", &Order{Id: 1}); err == nil {
+		t.Errorf("should raise an intentional error")
+	}
+
+	fail = false
+	if err := orderStateMachine.Trigger("notify", &Order{Id: 1}); err != nil {
+		t.Errorf("failed triggers should not be debounced, got %v", err)
+	}
+}
+
+func TestDebounceConcurrent(t *testing.T) {
+	var (
+		notified          int32
+		orderStateMachine = getDebounceStateMachine(&manualClock{now: time.Now()}, &notified)
+		wg                sync.WaitGroup
+	)
+
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			orderStateMachine.Trigger("notify", &Order{Id: 1})
+		}()
+	}
+	wg.Wait()
+
+	if notified != 1 {
+		t.Errorf("concurrent triggers should be debounced, hooks ran %d times", notified)
+	}
+}
+
+func TestDebounceExpiresEntries(t *testing.T) {
+	var (
+		notified          int32
+		clock             = &manualClock{now: time.Now()}
+		orderStateMachine = getDebounceStateMachine(clock, &notified)
+	)
+
+	for i := 0; i < 1000; i++ {
+		orderStateMachine.Trigger("notify", &Order{Id: i})
+		clock.now = clock.now.Add(time.Second)
+	}
+
+	if size := len(orderStateMachine.debounced); size > 128 {
+		t.Errorf("expired entries should be forgotten, %d entries remain", size)
+	}
+}
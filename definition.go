@@ -0,0 +1,103 @@
+package transition
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Definition is a serializable state machine definition, hooks are referenced by name, see LoadDefinition
+type Definition struct {
+	Initial string            `json:"initial"`
+	States  []StateDefinition `json:"states"`
+	Events  []EventDefinition `json:"events"`
+}
+
+// StateDefinition is a state of a Definition
+type StateDefinition struct {
+	Name  string   `json:"name"`
+	Enter []string `json:"enter,omitempty"`
+	Exit  []string `json:"exit,omitempty"`
+}
+
+// EventDefinition is an event of a Definition
+type EventDefinition struct {
+	Name        string                 `json:"name"`
+	Transitions []TransitionDefinition `json:"transitions"`
+}
+
+// TransitionDefinition is a transition of an EventDefinition, an empty From accepts any state
+type TransitionDefinition struct {
+	To     string   `json:"to"`
+	From   []string `json:"from,omitempty"`
+	Before []string `json:"before,omitempty"`
+	After  []string `json:"after,omitempty"`
+}
+
+// HookRegistry holds the hooks a Definition references by name
+type HookRegistry[T Stater] map[string]func(value T) error
+
+// LoadDefinition read a JSON definition, e.g.
+//
+//	{
+//	  "initial": "draft",
+//	  "states": [{"name": "checkout", "enter": ["reserve_stock"]}],
+//	  "events": [{"name": "checkout", "transitions": [{"to": "checkout", "from": ["draft"]}]}]
+//	}
+//
+// and compile it into a state machine, looking up the referenced hooks in hooks
+func LoadDefinition[T Stater](r io.Reader, hooks HookRegistry[T], opts ...Option) (*StateMachine[T], error) {
+	var definition Definition
+	if err := json.NewDecoder(r).Decode(&definition); err != nil {
+		return nil, fmt.Errorf("failed to load definition: %w", err)
+	}
+	return Compile(definition, hooks, opts...)
+}
+
+// Compile build a state machine from definition, looking up the referenced hooks in hooks
+func Compile[T Stater](definition Definition, hooks HookRegistry[T], opts ...Option) (*StateMachine[T], error) {
+	var zero T
+	sm := New(zero, opts...)
+	if definition.Initial != "" {
+		sm.Initial(definition.Initial)
+	}
+
+	lookup := func(owner string, names []string) error {
+		for _, name := range names {
+			if _, ok := hooks[name]; !ok {
+				return fmt.Errorf("failed to compile definition: %s uses unknown hook %s", owner, name)
+			}
+		}
+		return nil
+	}
+
+	for _, stateDefinition := range definition.States {
+		state := sm.State(stateDefinition.Name)
+		if err := lookup("state "+stateDefinition.Name, append(clip(stateDefinition.Enter), stateDefinition.Exit...)); err != nil {
+			return nil, err
+		}
+		for _, name := range stateDefinition.Enter {
+			state.enterNamed(name, hooks[name])
+		}
+		for _, name := range stateDefinition.Exit {
+			state.exitNamed(name, hooks[name])
+		}
+	}
+
+	for _, eventDefinition := range definition.Events {
+		event := sm.Event(eventDefinition.Name)
+		for _, transitionDefinition := range eventDefinition.Transitions {
+			transition := event.To(transitionDefinition.To).From(transitionDefinition.From...)
+			if err := lookup("event "+eventDefinition.Name, append(clip(transitionDefinition.Before), transitionDefinition.After...)); err != nil {
+				return nil, err
+			}
+			for _, name := range transitionDefinition.Before {
+				transition.beforeNamed(name, hooks[name])
+			}
+			for _, name := range transitionDefinition.After {
+				transition.afterNamed(name, hooks[name])
+			}
+		}
+	}
+	return sm, nil
+}
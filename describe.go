@@ -0,0 +1,233 @@
+package transition
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DescriptionSchemaVersion is the version of the MachineDescription format, it's increased on incompatible changes.
+// The JSON Schema of the format is description.schema.json
+const DescriptionSchemaVersion = 1
+
+// MachineDescription is a serializable description of a state machine, see Describe. It's the canonical form
+// exporters and tools share: its JSON fields are always in the same order, and it's read back with ParseDescription
+type MachineDescription struct {
+	SchemaVersion int    `json:"schema_version"`
+	Initial       string `json:"initial"`
+	// Initials are the initial states by discriminator, see StateMachine.InitialFor
+	Initials map[string]string  `json:"initials,omitempty"`
+	States   []StateDescription `json:"states"`
+	Events   []EventDescription `json:"events"`
+}
+
+// StateDescription describe a state, its metadata and how many hooks it has. Final states have no outgoing transition,
+// composite states embed a sub-machine
+type StateDescription struct {
+	Name      string            `json:"name"`
+	Label     string            `json:"label"`
+	Initial   bool              `json:"initial,omitempty"`
+	Final     bool              `json:"final,omitempty"`
+	Metadata  map[string]string `json:"metadata,omitempty"`
+	Enter     int               `json:"enter"`
+	Exit      int               `json:"exit"`
+	Invariant int               `json:"invariant"`
+	// SubStates are the states of the sub-machine of a composite state, SubEntry the one it starts in, see SubMachine
+	SubStates []string `json:"sub_states,omitempty"`
+	SubEntry  string   `json:"sub_entry,omitempty"`
+	// AliasOf are the states the state was split into, see SplitState
+	AliasOf []string `json:"alias_of,omitempty"`
+}
+
+// EventDescription describe an event, its metadata and its transitions
+type EventDescription struct {
+	Name     string            `json:"name"`
+	Label    string            `json:"label"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+	Roles    []string          `json:"roles,omitempty"`
+	// Group is the event group of the event, see StateMachine.EventGroup
+	Group       string                  `json:"group,omitempty"`
+	Transitions []TransitionDescription `json:"transitions"`
+}
+
+// TransitionDescription describe a transition and how many hooks and guards it has. Empty From and FromGlobs accept
+// any state, Dynamic transitions choose their destination when triggered, see Event.ToFunc
+type TransitionDescription struct {
+	To   string   `json:"to,omitempty"`
+	From []string `json:"from,omitempty"`
+	// FromGlobs are the from globs of the transition, see EventTransition.FromGlob
+	FromGlobs []string `json:"from_globs,omitempty"`
+	Dynamic   bool     `json:"dynamic,omitempty"`
+	Before    int      `json:"before"`
+	After     int      `json:"after"`
+	Guards    int      `json:"guards"`
+	// GuardNames are the names of the named guards, in order, see EventTransition.GuardNamed
+	GuardNames []string `json:"guard_names,omitempty"`
+	// Label is the label of the transition in the described locale, empty without label, see EventTransition.Label
+	Label string `json:"label,omitempty"`
+	// ToSite and FromSites are where the transition and each of its from states were defined, see WithDefinitionTracking
+	ToSite    string              `json:"to_site,omitempty"`
+	FromSites map[string][]string `json:"from_sites,omitempty"`
+}
+
+// DescribeOption configure Describe
+type DescribeOption func(*describeConfig)
+
+type describeConfig struct {
+	locale string
+}
+
+// DescribeLocale translate the labels of states and events in locale
+func DescribeLocale(locale string) DescribeOption {
+	return func(config *describeConfig) {
+		config.locale = locale
+	}
+}
+
+// Describe returns the description of the state machine, states, events and from states are sorted,
+// transitions are in definition order
+func (sm *StateMachine[T]) Describe(opts ...DescribeOption) MachineDescription {
+	config := describeConfig{locale: sm.defaultLocale}
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	description := MachineDescription{
+		SchemaVersion: DescriptionSchemaVersion,
+		Initial:       sm.initialState,
+		Initials:      sm.initialsByKind(),
+		States:        []StateDescription{},
+		Events:        []EventDescription{},
+	}
+
+	graph := sm.graph()
+	for _, name := range sm.stateNamesWithInitial() {
+		stateDescription := StateDescription{
+			Name:    name,
+			Label:   sm.StateLabel(name, config.locale),
+			Initial: sm.isInitial(name),
+			Final:   len(graph.edges[name]) == 0,
+		}
+		if state, ok := sm.states[name]; ok {
+			stateDescription.Metadata = cloneMap(state.metadata)
+			stateDescription.Enter, stateDescription.Exit, stateDescription.Invariant = len(state.enters), len(state.exits), len(state.invariants)
+			if state.sub != nil {
+				stateDescription.SubStates, stateDescription.SubEntry = clip(state.sub.states), state.sub.entry
+			}
+			stateDescription.AliasOf = clip(state.aliasOf)
+		}
+		description.States = append(description.States, stateDescription)
+	}
+
+	for _, name := range sm.eventNames() {
+		eventDescription := EventDescription{
+			Name:        name,
+			Label:       sm.EventLabel(name, config.locale),
+			Metadata:    cloneMap(sm.events[name].metadata),
+			Roles:       append([]string(nil), sm.events[name].roles...),
+			Group:       sm.events[name].group,
+			Transitions: []TransitionDescription{},
+		}
+		for _, transition := range sm.events[name].transitions {
+			from := append([]string{}, transition.froms...)
+			sort.Strings(from)
+			eventDescription.Transitions = append(eventDescription.Transitions, TransitionDescription{
+				To:         transition.to,
+				From:       from,
+				FromGlobs:  append([]string(nil), transition.fromGlobs...),
+				Dynamic:    transition.toFunc != nil,
+				Before:     len(transition.befores) + len(transition.pendingBefores),
+				After:      len(transition.afters) + len(transition.notifiers) + len(transition.emitters),
+				Guards:     len(transition.guards),
+				GuardNames: transition.namedGuards(),
+				Label:      sm.label(transition.labels, "", config.locale),
+				ToSite:     transition.toSite,
+				FromSites:  cloneMap(transition.fromSites),
+			})
+		}
+		description.Events = append(description.Events, eventDescription)
+	}
+	return description
+}
+
+// stateNamesWithInitial returns the names of the declared states and the initial state, sorted
+func (sm *StateMachine[T]) stateNamesWithInitial() []string {
+	states := sm.stateNames()
+	if _, ok := sm.states[sm.initialState]; !ok && sm.initialState != "" {
+		states = append(states, sm.initialState)
+		sort.Strings(states)
+	}
+	return states
+}
+
+// Fingerprint returns a hash of the structure of the state machine: its initial state, states, events and
+// transitions. Hooks and guards are not part of it, so machines built from the same definition share fingerprints.
+// It's computed again only after the definition changed, so it's cheap to call when triggering
+func (sm *StateMachine[T]) Fingerprint() string {
+	generation := sm.owner.generation.Load()
+	if cached := sm.fingerprint.Load(); cached != nil && cached.generation == generation {
+		return cached.fingerprint
+	}
+
+	fingerprint := sm.Describe().Fingerprint()
+	sm.fingerprint.Store(&cachedFingerprint{generation: generation, fingerprint: fingerprint})
+	return fingerprint
+}
+
+// cachedFingerprint is the fingerprint of a definition generation, see ownerGuard.generation
+type cachedFingerprint struct {
+	generation  uint64
+	fingerprint string
+}
+
+// ParseDescription reads a description marshaled to JSON, e.g. by another process or tool. Descriptions of a newer
+// schema version than DescriptionSchemaVersion are rejected
+func ParseDescription(data []byte) (MachineDescription, error) {
+	var description MachineDescription
+	if err := json.Unmarshal(data, &description); err != nil {
+		return description, fmt.Errorf("failed to parse description: %w", err)
+	}
+	if description.SchemaVersion < 1 || description.SchemaVersion > DescriptionSchemaVersion {
+		return description, fmt.Errorf("unsupported description schema version %d, supported up to %d", description.SchemaVersion, DescriptionSchemaVersion)
+	}
+	return description, nil
+}
+
+// Fingerprint returns a hash of the structure of the description, see StateMachine.Fingerprint. Descriptions
+// parsed with ParseDescription have the fingerprint of the machine they describe
+func (description MachineDescription) Fingerprint() string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "initial %q\n", description.Initial)
+	// only written when set, so machines without initial states by discriminator keep their fingerprint
+	kinds := make([]string, 0, len(description.Initials))
+	for kind := range description.Initials {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	for _, kind := range kinds {
+		fmt.Fprintf(&builder, "initial %q for %q\n", description.Initials[kind], kind)
+	}
+	for _, state := range description.States {
+		fmt.Fprintf(&builder, "state %q\n", state.Name)
+	}
+	for _, event := range description.Events {
+		// sorted, so the order transitions are defined in doesn't change fingerprints
+		transitions := append([]TransitionDescription{}, event.Transitions...)
+		sort.Slice(transitions, func(i, j int) bool {
+			return transitions[i].To < transitions[j].To
+		})
+		for _, transition := range transitions {
+			fmt.Fprintf(&builder, "event %q from %q to %q dynamic %t\n", event.Name, transition.From, transition.To, transition.Dynamic)
+			// only written when set, so machines without globs keep their fingerprint
+			if len(transition.FromGlobs) > 0 {
+				fmt.Fprintf(&builder, "event %q from globs %q to %q\n", event.Name, transition.FromGlobs, transition.To)
+			}
+		}
+	}
+
+	sum := sha256.Sum256([]byte(builder.String()))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,229 @@
+package transition
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDescribe(t *testing.T) {
+	orderStateMachine := getStateMachine()
+	orderStateMachine.State("checkout").Enter(func(*Order) error { return nil })
+	orderStateMachine.Event("pay").To("paid").Guard(func(context.Context, *Order) error { return nil })
+
+	description := orderStateMachine.Describe()
+	if description.Initial != "draft" || len(description.States) != 7 || description.States[1].Name != "checkout" || description.States[1].Enter != 1 {
+		t.Errorf("unexpected states %+v", description.States)
+	}
+	if len(description.Events) != 2 || description.Events[1].Name != "pay" || description.Events[1].Transitions[0].Guards != 1 {
+		t.Errorf("unexpected events %+v", description.Events)
+	}
+}
+
+func TestDescribeGuardNamesAndLabels(t *testing.T) {
+	orderStateMachine := getStateMachine()
+	fingerprint := orderStateMachine.Fingerprint()
+	orderStateMachine.Event("pay").To("paid").
+		Guard(func(context.Context, *Order) error { return nil }).
+		GuardNamed("has_address", func(context.Context, *Order) error { return errors.New("no address") }).
+		Label("en", "Pay").Label("de", "Bezahlen")
+
+	pay := orderStateMachine.Describe(DescribeLocale("de")).Events[1].Transitions[0]
+	if pay.Guards != 2 || !reflect.DeepEqual(pay.GuardNames, []string{"has_address"}) || pay.Label != "Bezahlen" {
+		t.Errorf("guard names and labels should be described, got %+v", pay)
+	}
+	if checkout := orderStateMachine.Describe().Events[0].Transitions[0]; checkout.Label != "" || checkout.GuardNames != nil {
+		t.Errorf("unlabeled transitions without named guards should describe none, got %+v", checkout)
+	}
+
+	data, _ := json.Marshal(orderStateMachine.Describe())
+	parsed, err := ParseDescription(data)
+	if err != nil || !reflect.DeepEqual(parsed.Events[1].Transitions[0].GuardNames, []string{"has_address"}) || parsed.Events[1].Transitions[0].Label != "Pay" {
+		t.Errorf("guard names and labels should round-trip, got %+v, %v", parsed.Events[1].Transitions, err)
+	}
+	if orderStateMachine.Fingerprint() != fingerprint {
+		t.Errorf("guard names and labels should not change the fingerprint")
+	}
+
+	err = orderStateMachine.Trigger("pay", &Order{Transition: Transition{State: "checkout"}})
+	var guardErr *GuardError
+	if !errors.As(err, &guardErr) || guardErr.Name != "has_address" {
+		t.Errorf("named guards should reject with their name, got %v", err)
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	orderStateMachine := getStateMachine()
+	fingerprint := orderStateMachine.Fingerprint()
+
+	if getStateMachine().Fingerprint() != fingerprint {
+		t.Errorf("fingerprint should be deterministic")
+	}
+
+	orderStateMachine.State("checkout").Enter(func(*Order) error { return nil })
+	if orderStateMachine.Fingerprint() != fingerprint {
+		t.Errorf("hooks should not change the fingerprint")
+	}
+
+	orderStateMachine.Event("pay").To("paid").From("draft")
+	if orderStateMachine.Fingerprint() == fingerprint {
+		t.Errorf("transitions should change the fingerprint")
+	}
+}
+
+func TestTransitionsDefinitionOrder(t *testing.T) {
+	var (
+		orderStateMachine = getStateMachine()
+		errLate           = errors.New("too late")
+		errEarly          = errors.New("too early")
+	)
+	cancel := orderStateMachine.Event("cancel")
+	cancel.To("paid_cancelled").From("paid").Guard(func(context.Context, *Order) error { return errLate })
+	cancel.To("cancelled").From("draft", "checkout", "paid").Guard(func(context.Context, *Order) error { return errEarly })
+
+	var (
+		description = orderStateMachine.Describe()
+		printed     = orderStateMachine.Sprint()
+		fingerprint = orderStateMachine.Fingerprint()
+	)
+	if transitions := description.Events[0].Transitions; transitions[0].To != "paid_cancelled" || transitions[1].To != "cancelled" {
+		t.Errorf("transitions should be described in definition order, got %+v", transitions)
+	}
+
+	paid := &Order{}
+	paid.State = "paid"
+	candidates := func() []string {
+		trace, _ := orderStateMachine.TriggerTraced("cancel", paid)
+		var candidates []string
+		for _, candidate := range trace.Candidates {
+			candidates = append(candidates, candidate.To)
+		}
+		return candidates
+	}
+	explained := func() []string {
+		var transitions []string
+		for _, transition := range orderStateMachine.Explain("cancel", paid).Transitions {
+			transitions = append(transitions, transition.To)
+		}
+		return transitions
+	}
+	definitionOrder := []string{"paid_cancelled", "cancelled"}
+
+	for i := 0; i < 100; i++ {
+		if !reflect.DeepEqual(orderStateMachine.Describe(), description) || orderStateMachine.Sprint() != printed {
+			t.Fatalf("descriptions should be identical every time")
+		}
+		if got := candidates(); !reflect.DeepEqual(got, definitionOrder) {
+			t.Fatalf("trace candidates should be in definition order, got %v", got)
+		}
+		if got := explained(); !reflect.DeepEqual(got, definitionOrder) {
+			t.Fatalf("explained transitions should be in definition order, got %v", got)
+		}
+	}
+
+	reversed := getStateMachine()
+	reversed.Event("cancel").To("cancelled").From("draft", "checkout", "paid")
+	reversed.Event("cancel").To("paid_cancelled").From("paid")
+	if reversed.Fingerprint() != fingerprint {
+		t.Errorf("the order transitions are defined in should not change fingerprints")
+	}
+
+	order := &Order{}
+	order.State = "paid"
+	if err := orderStateMachine.Trigger("cancel", order); !errors.Is(err, errLate) {
+		t.Errorf("the guard error of the first defined transition should be reported, got %v", err)
+	}
+}
+
+func TestParseDescription(t *testing.T) {
+	orderStateMachine := getStateMachine()
+	orderStateMachine.Event("pay").Meta("order", "1")
+
+	description := orderStateMachine.Describe()
+	// cancelled, checkout, delivered, draft, ...
+	if !description.States[3].Initial || description.States[3].Final || !description.States[0].Final || description.States[1].Final {
+		t.Errorf("unexpected initial and final states %+v", description.States)
+	}
+
+	data, err := json.Marshal(description)
+	if err != nil {
+		t.Fatalf("no error should happen when marshaling, but got %v", err)
+	}
+	if !strings.HasPrefix(string(data), `{"schema_version":1,"initial":"draft","states":[{"name":"cancelled","label":"cancelled","final":true,"enter":0`) {
+		t.Errorf("unexpected JSON %s", data)
+	}
+
+	parsed, err := ParseDescription(data)
+	if err != nil || !reflect.DeepEqual(parsed, description) {
+		t.Errorf("descriptions should round-trip, got %+v, %v", parsed, err)
+	}
+	if parsed.Fingerprint() != orderStateMachine.Fingerprint() {
+		t.Errorf("parsed descriptions should keep the fingerprint of the machine")
+	}
+
+	if _, err := ParseDescription([]byte(`{"schema_version":2}`)); err == nil {
+		t.Errorf("descriptions of a newer schema version should be rejected")
+	}
+}
+
+// TestDescriptionSchema checks description.schema.json matches MachineDescription, run with -update to regenerate it
+func TestDescriptionSchema(t *testing.T) {
+	schema := jsonSchema(reflect.TypeOf(MachineDescription{}))
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	schema["title"] = "MachineDescription"
+
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data = append(data, '\n')
+
+	if *update {
+		if err := os.WriteFile("description.schema.json", data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	published, err := os.ReadFile("description.schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(published) != string(data) {
+		t.Errorf("description.schema.json is outdated, run go test -run TestDescriptionSchema -update")
+	}
+}
+
+// jsonSchema returns the JSON Schema of values of typ as marshaled by encoding/json
+func jsonSchema(typ reflect.Type) map[string]any {
+	switch typ.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int:
+		return map[string]any{"type": "integer"}
+	case reflect.Slice:
+		return map[string]any{"type": "array", "items": jsonSchema(typ.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": jsonSchema(typ.Elem())}
+	case reflect.Struct:
+		var (
+			properties = map[string]any{}
+			required   = []string{}
+		)
+		for i := 0; i < typ.NumField(); i++ {
+			name, options, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+			properties[name] = jsonSchema(typ.Field(i).Type)
+			if options != "omitempty" {
+				required = append(required, name)
+			}
+		}
+		return map[string]any{"type": "object", "properties": properties, "required": required, "additionalProperties": false}
+	default:
+		panic("unsupported type " + typ.String())
+	}
+}
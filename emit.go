@@ -0,0 +1,38 @@
+package transition
+
+// Command describes a side effect for the caller to perform once the trigger returned, e.g. sending an email, see
+// EventTransition.AfterEmit. Type tells the caller how to dispatch it
+type Command struct {
+	Type string `json:"type"`
+	Data any    `json:"data,omitempty"`
+}
+
+// EmitHook is an after hook describing side effects as commands instead of performing them, see AfterEmit
+type EmitHook[T Stater] func(value T, info TransitionInfo) ([]Command, error)
+
+// AfterEmit register an after hook emitting commands, they're returned by TransitionResult.Commands for the caller
+// to dispatch once Execute returned, so hooks stay free of side effects:
+//
+//	sm.Event("pay").To("paid").From("checkout").AfterEmit(func(order *Order, info transition.TransitionInfo) ([]transition.Command, error) {
+//		return []transition.Command{{Type: "send_receipt", Data: order.ID}}, nil
+//	})
+//
+// Emit hooks run after the notification hooks, commands keep the order they were emitted in. When the trigger fails
+// or is rolled back, the commands are discarded
+func (transition *EventTransition[T]) AfterEmit(hook EmitHook[T]) *EventTransition[T] {
+	transition.checkMutable("EventTransition.AfterEmit")
+	transition.emitters = append(transition.emitters, hook)
+	return transition
+}
+
+// bindEmit returns hook as an after hook appending its commands to emitted
+func bindEmit[T Stater](hook EmitHook[T], info TransitionInfo, emitted *[]Command) func(value T) error {
+	return func(value T) error {
+		commands, err := hook(value, info)
+		if err != nil {
+			return err
+		}
+		*emitted = append(*emitted, commands...)
+		return nil
+	}
+}
@@ -0,0 +1,47 @@
+package transition
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestAfterEmit(t *testing.T) {
+	var fail bool
+	orderStateMachine := getStateMachine()
+	orderStateMachine.Event("pay").To("paid").From("checkout").
+		AfterEmit(func(order *Order, info TransitionInfo) ([]Command, error) {
+			return []Command{{Type: "send_receipt", Data: order.Id}, {Type: "reserve_stock", Data: info.To}}, nil
+		}).
+		AfterEmit(func(order *Order, info TransitionInfo) ([]Command, error) {
+			return []Command{{Type: "notify_warehouse", Data: info.From}}, nil
+		})
+	orderStateMachine.AfterEach(func(ctx context.Context, order *Order, info TransitionInfo) error {
+		if fail {
+			return errors.New("intentional error")
+		}
+		return nil
+	})
+
+	order := &Order{Id: 1}
+	order.SetState("checkout")
+	result, err := orderStateMachine.Execute(context.Background(), TriggerCommand{Event: "pay"}, order)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Command{{Type: "send_receipt", Data: 1}, {Type: "reserve_stock", Data: "paid"}, {Type: "notify_warehouse", Data: "checkout"}}
+	if !reflect.DeepEqual(result.Commands, expected) {
+		t.Errorf("commands should be returned in the order they were emitted, got %+v", result.Commands)
+	}
+	if description := orderStateMachine.Describe(); description.Events[1].Transitions[0].After != 2 {
+		t.Errorf("emit hooks should be described as after hooks, got %+v", description.Events[1].Transitions[0])
+	}
+
+	fail = true
+	order.SetState("checkout")
+	result, err = orderStateMachine.Execute(context.Background(), TriggerCommand{Event: "pay"}, order)
+	if err == nil || len(result.Commands) != 0 || order.GetState() != "checkout" {
+		t.Errorf("commands should be discarded when the trigger fails, got %+v, %v", result.Commands, err)
+	}
+}
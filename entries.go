@@ -0,0 +1,63 @@
+package transition
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrEntriesNotTracked is returned by MaxEntries when the value doesn't count the entries of its states
+var ErrEntriesNotTracked = errors.New("state entries not tracked")
+
+// EntryCounter is implemented by values counting how many times they entered each state, the embedded
+// CountedTransition implements it. Trigger increments the count of the state entered, and restores it on failure
+type EntryCounter interface {
+	Entries(state string) int
+	SetEntries(state string, n int)
+}
+
+// CountedTransition counts how many times each state was entered, embed it in your struct along with Transition and
+// the other mixins, e.g. FunneledTransition. EntryCounts is serialized with the value
+type CountedTransition struct {
+	EntryCounts map[string]int
+}
+
+// Entries returns how many times state was entered
+func (transition CountedTransition) Entries(state string) int {
+	return transition.EntryCounts[state]
+}
+
+// GetEntryCounts returns a copy of the entry counts of every state
+func (transition CountedTransition) GetEntryCounts() map[string]int {
+	return cloneMap(transition.EntryCounts)
+}
+
+// SetEntries set how many times state was entered
+func (transition *CountedTransition) SetEntries(state string, n int) {
+	if n == 0 {
+		delete(transition.EntryCounts, state)
+		if len(transition.EntryCounts) == 0 {
+			transition.EntryCounts = nil
+		}
+		return
+	}
+	if transition.EntryCounts == nil {
+		transition.EntryCounts = map[string]int{}
+	}
+	transition.EntryCounts[state] = n
+}
+
+// MaxEntries is a guard accepting values that entered state fewer than n times, e.g. on transitions to state to
+// allow it at most n times. Values must count entries, see EntryCounter
+func MaxEntries[T Stater](state string, n int) Guard[T] {
+	return func(ctx context.Context, value T) error {
+		counter, ok := any(value).(EntryCounter)
+		if !ok {
+			return fmt.Errorf("%w: %T doesn't implement EntryCounter", ErrEntriesNotTracked, value)
+		}
+		if entries := counter.Entries(state); entries >= n {
+			return fmt.Errorf("state %s was entered %d times, at most %d allowed", state, entries, n)
+		}
+		return nil
+	}
+}
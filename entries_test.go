@@ -0,0 +1,107 @@
+package transition
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+type CountedOrder struct {
+	Id int
+
+	Transition
+	CountedTransition
+}
+
+func getPaymentStateMachine() *StateMachine[*CountedOrder] {
+	sm := New(&CountedOrder{})
+	sm.Initial("checkout")
+	sm.State("payment_failed")
+	sm.State("paid")
+	sm.Event("fail").To("payment_failed").From("checkout").Guard(MaxEntries[*CountedOrder]("payment_failed", 3))
+	sm.Event("retry").To("checkout").From("payment_failed")
+	sm.Event("pay").To("paid").From("checkout")
+	return sm
+}
+
+func TestEntryCounts(t *testing.T) {
+	var (
+		sm    = getPaymentStateMachine()
+		order = &CountedOrder{}
+	)
+
+	for i := 0; i < 3; i++ {
+		if err := sm.Trigger("fail", order); err != nil {
+			t.Fatalf("failure %d should be allowed, got %v", i+1, err)
+		}
+		sm.Trigger("retry", order)
+	}
+	if order.Entries("payment_failed") != 3 || order.Entries("checkout") != 3 {
+		t.Errorf("unexpected entry counts %v", order.EntryCounts)
+	}
+
+	err := sm.Trigger("fail", order)
+	if !errors.Is(err, ErrNoMatchingTransition) {
+		t.Errorf("a fourth failure should be rejected by MaxEntries, got %v", err)
+	}
+
+	data, _ := json.Marshal(order)
+	var loaded CountedOrder
+	if err := json.Unmarshal(data, &loaded); err != nil || loaded.Entries("payment_failed") != 3 {
+		t.Errorf("entry counts should be serialized with the value, got %s", data)
+	}
+}
+
+func TestEntryCountsRollback(t *testing.T) {
+	var (
+		sm       = getPaymentStateMachine()
+		order    = &CountedOrder{}
+		errEnter = errors.New("enter failed")
+		failing  = true
+	)
+	sm.State("payment_failed").Enter(func(order *CountedOrder) error {
+		if failing {
+			return errEnter
+		}
+		return nil
+	})
+
+	if err := sm.Trigger("fail", order); !errors.Is(err, errEnter) {
+		t.Fatalf("the enter hook should fail, got %v", err)
+	}
+	if order.Entries("payment_failed") != 0 || len(order.EntryCounts) != 0 {
+		t.Errorf("failed entries should be rolled back, got %v", order.EntryCounts)
+	}
+
+	failing = false
+	if err := sm.Trigger("fail", order); err != nil || order.Entries("payment_failed") != 1 {
+		t.Errorf("rolled back entries should not be counted twice, got %v, %v", order.EntryCounts, err)
+	}
+
+	if err := MaxEntries[*Order]("paid", 1)(context.Background(), &Order{}); !errors.Is(err, ErrEntriesNotTracked) {
+		t.Errorf("MaxEntries should require values counting entries, got %v", err)
+	}
+}
+
+type TrackedOrder struct {
+	Transition
+	CountedTransition
+	FunneledTransition
+	StrictTransition
+}
+
+func TestCombinedMixins(t *testing.T) {
+	sm := New(&TrackedOrder{})
+	sm.Initial("draft")
+	sm.State("checkout")
+	sm.Event("checkout").To("checkout").From("draft")
+
+	order := &TrackedOrder{}
+	if err := sm.Trigger("checkout", order); err != nil {
+		t.Fatal(err)
+	}
+	if _, entered := order.FirstEntered("checkout"); !entered || order.Entries("checkout") != 1 || order.GetMachineState() != "checkout" || order.GetState() != "checkout" {
+		t.Errorf("every embedded mixin should be tracked, got %+v", order)
+	}
+}
@@ -0,0 +1,151 @@
+package transition
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// InvariantViolation is returned when a value doesn't satisfy an invariant of its state
+type InvariantViolation struct {
+	State string
+	Err   error
+}
+
+func (violation *InvariantViolation) Error() string {
+	return fmt.Sprintf("invariant of state %s violated: %v", violation.State, violation.Err)
+}
+
+// Unwrap returns the error returned by the invariant
+func (violation *InvariantViolation) Unwrap() error {
+	return violation.Err
+}
+
+var (
+	// ErrUnknownEvent is returned when triggering an event that is not defined
+	ErrUnknownEvent = errors.New("unknown event")
+	// ErrNoMatchingTransition is returned when no transition of the event accepts the value's current state
+	ErrNoMatchingTransition = errors.New("no matching transition")
+	// ErrAmbiguousTransition is returned when several transitions of the event accept the value's current state
+	ErrAmbiguousTransition = errors.New("ambiguous transition")
+	// ErrNilValue is returned when triggering an event on a nil value
+	ErrNilValue = errors.New("nil value")
+	// ErrEmptyEventName is returned when triggering an event without name
+	ErrEmptyEventName = errors.New("empty event name")
+)
+
+// TransitionError is the error returned by Trigger, it wraps the cause of the failure:
+// ErrUnknownEvent, ErrNoMatchingTransition or ErrAmbiguousTransition when matching the event, or the
+// error returned by the hook named Hook. The message reads
+// "failed to perform event <event> from state <from>[ to <to>][: <phase> hook <hook>]: <cause>[ (allowed from: <states>)]"
+type TransitionError struct {
+	Event string
+	From  string
+	// To is the destination of the matched transition, empty when no transition matched
+	To    string
+	Phase Phase
+	// Hook names the failed hook as <state or event>#<registration index>, empty when no hook failed
+	Hook string
+	// AllowedFrom is the sorted union of from states of the event's transitions, set when no transition matched.
+	// The message lists at most maxAllowedFromInMessage of them
+	AllowedFrom []string
+	// CorrelationID is the correlation ID of the trigger, it isn't part of the message, see WithCorrelationID
+	CorrelationID string
+	Err           error
+}
+
+const maxAllowedFromInMessage = 10
+
+func (transitionErr *TransitionError) Error() string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "failed to perform event %s from state %s", transitionErr.Event, transitionErr.From)
+	if transitionErr.To != "" {
+		fmt.Fprintf(&builder, " to %s", transitionErr.To)
+	}
+	if transitionErr.Hook != "" {
+		fmt.Fprintf(&builder, ": %s hook %s", transitionErr.Phase, transitionErr.Hook)
+	}
+	if transitionErr.Err != nil {
+		fmt.Fprintf(&builder, ": %v", transitionErr.Err)
+	}
+	if allowed := transitionErr.AllowedFrom; len(allowed) > 0 {
+		if len(allowed) > maxAllowedFromInMessage {
+			fmt.Fprintf(&builder, " (allowed from: %s, and %d more)", strings.Join(allowed[:maxAllowedFromInMessage], ", "), len(allowed)-maxAllowedFromInMessage)
+		} else {
+			fmt.Fprintf(&builder, " (allowed from: %s)", strings.Join(allowed, ", "))
+		}
+	}
+	return builder.String()
+}
+
+// Unwrap returns the cause of the failure
+func (transitionErr *TransitionError) Unwrap() error {
+	return transitionErr.Err
+}
+
+// IsUnknownEvent reports whether err was raised by triggering an undefined event
+func IsUnknownEvent(err error) bool {
+	return errors.Is(err, ErrUnknownEvent)
+}
+
+// IsNoMatch reports whether err was raised because no transition accepted the value's current state
+func IsNoMatch(err error) bool {
+	return errors.Is(err, ErrNoMatchingTransition)
+}
+
+// IsCanceled reports whether err was raised because the context of the trigger was canceled or its deadline exceeded,
+// triggers check their context before each phase and hook, rolling back the value when it's done
+func IsCanceled(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// IsHookError reports whether err was raised by a hook or an invariant
+func IsHookError(err error) bool {
+	var transitionErr *TransitionError
+	return errors.As(err, &transitionErr) && transitionErr.Hook != ""
+}
+
+// MultiError aggregates the errors of operations reporting many problems at once, such as Validate and TriggerAll.
+// errors.Is and errors.As match any of its errors
+type MultiError struct {
+	errs []error
+}
+
+// newMultiError returns a MultiError of the non-nil errs, or nil if there is none
+func newMultiError(errs []error) error {
+	var nonNil []error
+	for _, err := range errs {
+		if err != nil {
+			nonNil = append(nonNil, err)
+		}
+	}
+
+	if len(nonNil) == 0 {
+		return nil
+	}
+	return &MultiError{errs: nonNil}
+}
+
+// Errors returns the aggregated errors
+func (multiErr *MultiError) Errors() []error {
+	return append([]error(nil), multiErr.errs...)
+}
+
+// Unwrap returns the aggregated errors, for errors.Is and errors.As
+func (multiErr *MultiError) Unwrap() []error {
+	return multiErr.errs
+}
+
+func (multiErr *MultiError) Error() string {
+	if len(multiErr.errs) == 1 {
+		return multiErr.errs[0].Error()
+	}
+
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "%d errors occurred:", len(multiErr.errs))
+	for i, err := range multiErr.errs {
+		fmt.Fprintf(&builder, "\n  %d. %v", i+1, err)
+	}
+	return builder.String()
+}
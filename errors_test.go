@@ -0,0 +1,128 @@
+package transition
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestTransitionError(t *testing.T) {
+	var (
+		orderStateMachine = getStateMachine()
+		hookErr           = errors.New("intentional error")
+		guardErr          = errors.New("address is required")
+		transitionErr     *TransitionError
+	)
+	orderStateMachine.State("paid").Enter(func(order *Order) error { return nil }).Enter(func(order *Order) error { return hookErr })
+	orderStateMachine.Event("cancel").To("cancelled").From("draft").Guard(func(ctx context.Context, order *Order) error { return guardErr })
+	orderStateMachine.Event("reset").To("draft")
+	orderStateMachine.Event("reset").To("cancelled")
+
+	err := orderStateMachine.Trigger("refund", &Order{})
+	if !IsUnknownEvent(err) || IsNoMatch(err) || !errors.As(err, &transitionErr) || transitionErr.Phase != PhaseMatch {
+		t.Errorf("should raise an unknown event error, got %v", err)
+	}
+
+	err = orderStateMachine.Trigger("pay", &Order{})
+	if !IsNoMatch(err) || IsHookError(err) || err.Error() != "failed to perform event pay from state draft: no matching transition (allowed from: checkout)" {
+		t.Errorf("should raise a no match error, got %v", err)
+	}
+
+	err = orderStateMachine.Trigger("cancel", &Order{})
+	if !IsNoMatch(err) || !errors.Is(err, guardErr) {
+		t.Errorf("should raise a no match error wrapping the guard error, got %v", err)
+	}
+
+	err = orderStateMachine.Trigger("reset", &Order{})
+	if !errors.Is(err, ErrAmbiguousTransition) {
+		t.Errorf("should raise an ambiguous transition error, got %v", err)
+	}
+
+	order := &Order{}
+	order.State = "checkout"
+	err = orderStateMachine.Trigger("pay", order)
+	if !IsHookError(err) || !errors.Is(err, hookErr) || !errors.As(err, &transitionErr) {
+		t.Fatalf("should raise a hook error, got %v", err)
+	}
+
+	if transitionErr.Event != "pay" || transitionErr.From != "checkout" || transitionErr.To != "paid" || transitionErr.Phase != PhaseEnter || transitionErr.Hook != "paid#1" {
+		t.Errorf("unexpected error fields %+v", transitionErr)
+	}
+
+	if !strings.HasPrefix(err.Error(), "failed to perform event pay from state checkout to paid: enter hook paid#1") {
+		t.Errorf("unexpected error message %q", err)
+	}
+}
+
+func TestTransitionErrorAllowedFrom(t *testing.T) {
+	var (
+		orderStateMachine = getStateMachine()
+		froms             []string
+		transitionErr     *TransitionError
+	)
+	for i := 0; i < 12; i++ {
+		froms = append(froms, fmt.Sprintf("state_%02d", i))
+	}
+	orderStateMachine.Event("cancel").To("cancelled").From(froms[6:]...)
+	orderStateMachine.Event("cancel").To("paid_cancelled").From(froms[:6]...)
+
+	err := orderStateMachine.Trigger("cancel", &Order{})
+	if !errors.As(err, &transitionErr) || len(transitionErr.AllowedFrom) != 12 || transitionErr.AllowedFrom[0] != "state_00" {
+		t.Fatalf("error should carry all allowed from states, got %v", err)
+	}
+
+	expected := "failed to perform event cancel from state draft: no matching transition (allowed from: " + strings.Join(froms[:10], ", ") + ", and 2 more)"
+	if err.Error() != expected {
+		t.Errorf("expected %q, got %q", expected, err)
+	}
+}
+
+func TestNilValueAndEmptyEventName(t *testing.T) {
+	var (
+		orderStateMachine = getStateMachine()
+		nilOrder          *Order
+	)
+
+	if err := orderStateMachine.Trigger("checkout", nilOrder); !errors.Is(err, ErrNilValue) || !IsPermanent(err) {
+		t.Errorf("Trigger should raise ErrNilValue, got %v", err)
+	}
+
+	if err := orderStateMachine.Trigger("", &Order{}); !errors.Is(err, ErrEmptyEventName) {
+		t.Errorf("Trigger should raise ErrEmptyEventName, got %v", err)
+	}
+
+	if _, err := orderStateMachine.TriggerTraced("checkout", nilOrder); !errors.Is(err, ErrNilValue) {
+		t.Errorf("TriggerTraced should raise ErrNilValue, got %v", err)
+	}
+
+	if orderStateMachine.Can("checkout", nilOrder) || orderStateMachine.Can("", &Order{}) {
+		t.Errorf("Can should be false")
+	}
+
+	if explanation := orderStateMachine.Explain("checkout", nilOrder); !errors.Is(explanation.Err, ErrNilValue) || explanation.Allowed {
+		t.Errorf("Explain should report ErrNilValue, got %v", explanation)
+	}
+
+	if err := orderStateMachine.CheckInvariants(nilOrder); !errors.Is(err, ErrNilValue) {
+		t.Errorf("CheckInvariants should raise ErrNilValue, got %v", err)
+	}
+
+	if _, err := orderStateMachine.Replay(nilOrder, []ReplayStep{{Event: "checkout"}}); !errors.Is(err, ErrNilValue) {
+		t.Errorf("Replay should raise ErrNilValue, got %v", err)
+	}
+
+	if _, err := orderStateMachine.Simulate(SimOptions[*Order]{NewValue: func() *Order { return nil }, MaxSteps: 1}); !errors.Is(err, ErrNilValue) {
+		t.Errorf("Simulate should raise ErrNilValue, got %v", err)
+	}
+
+	report, _ := orderStateMachine.Sweep(context.Background(), []*Order{nil}, InStateLongerThan("checkout", 0, "pay"))
+	if len(report.Results) != 1 || !errors.Is(report.Results[0].Err, ErrNilValue) {
+		t.Errorf("Sweep should report ErrNilValue, got %+v", report)
+	}
+
+	if _, err := orderStateMachine.TriggerAfter(0, "", "1"); !errors.Is(err, ErrEmptyEventName) {
+		t.Errorf("TriggerAfter should raise ErrEmptyEventName, got %v", err)
+	}
+}
@@ -0,0 +1,47 @@
+// Package examples holds fully wired state machines of common workflows: orders, issue tracker tickets and document
+// approvals. They show guards, hooks, authorization, audit trails and exports working together, and serve as known
+// definitions for demos, benchmarks and tests. Each constructor returns a new machine, free to extend
+package examples
+
+import (
+	"context"
+
+	"github.com/daegalus/transition"
+)
+
+// AuditEntry records a transition of a value, see Audited
+type AuditEntry struct {
+	Event string `json:"event"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Actor string `json:"actor"`
+}
+
+// Audited is embedded by the values of the examples, the machines append every transition to their Trail
+type Audited struct {
+	Trail []AuditEntry `json:"trail,omitempty"`
+}
+
+func (audited *Audited) audit(ctx context.Context, info transition.TransitionInfo) {
+	actor := transition.ActorFromContext(ctx)
+	if actor == "" {
+		actor = "system"
+	}
+	audited.Trail = append(audited.Trail, AuditEntry{Event: info.Event, From: info.From, To: info.To, Actor: actor})
+}
+
+// auditor is implemented by values embedding Audited
+type auditor interface {
+	audit(ctx context.Context, info transition.TransitionInfo)
+}
+
+// auditHook appends the transitions of values to their trail, it runs after every other hook
+func auditHook[T interface {
+	transition.Stater
+	auditor
+}]() transition.MachineHook[T] {
+	return func(ctx context.Context, value T, info transition.TransitionInfo) error {
+		value.audit(ctx, info)
+		return nil
+	}
+}
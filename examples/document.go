@@ -0,0 +1,77 @@
+package examples
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/daegalus/transition"
+)
+
+// RequiredApprovals is the number of approvals a document needs to be published
+const RequiredApprovals = 2
+
+// Document is a document going through an approval workflow
+type Document struct {
+	ID        string   `json:"id"`
+	Author    string   `json:"author"`
+	Approvers []string `json:"approvers"`
+
+	transition.Transition
+	Audited
+}
+
+// NewDocumentMachine returns the state machine of documents: authors submit drafts for review, reviewers other than
+// the author approve or reject them, and documents are published once approved by RequiredApprovals reviewers.
+// Rejected documents go back to draft:
+//
+//	draft -submit-> in_review -approve-> in_review or approved -publish-> published
+//	in_review -reject-> draft
+func NewDocumentMachine() *transition.StateMachine[*Document] {
+	sm := transition.New(&Document{})
+	sm.Initial("draft")
+	sm.State("in_review").Label("en", "In review")
+	sm.State("approved")
+	sm.State("published").Final()
+
+	author := func(ctx context.Context, event string, document *Document) error {
+		if actor := transition.ActorFromContext(ctx); actor != document.Author {
+			return fmt.Errorf("only the author %s can %s the document", document.Author, event)
+		}
+		return nil
+	}
+	reviewer := func(ctx context.Context, event string, document *Document) error {
+		if actor := transition.ActorFromContext(ctx); actor == "" || actor == document.Author {
+			return fmt.Errorf("the author can't %s their own document", event)
+		}
+		return nil
+	}
+
+	sm.Event("submit").Require(author).To("in_review").From("draft").After(func(document *Document) error {
+		document.Approvers = nil
+		return nil
+	})
+	sm.Event("approve").Require(reviewer).ToFunc(func(document *Document) (string, error) {
+		if len(document.Approvers)+1 >= RequiredApprovals {
+			return "approved", nil
+		}
+		return "in_review", nil
+	}).From("in_review")
+	sm.Event("reject").Require(reviewer).To("draft").From("in_review")
+	sm.Event("publish").Require(author).To("published").From("approved")
+	return sm.BeforeEach(recordApprover).AfterEach(auditHook[*Document]())
+}
+
+// recordApprover adds the actor approving a document to its approvers, once
+func recordApprover(ctx context.Context, document *Document, info transition.TransitionInfo) error {
+	if info.Event != "approve" {
+		return nil
+	}
+	actor := transition.ActorFromContext(ctx)
+	for _, approver := range document.Approvers {
+		if approver == actor {
+			return fmt.Errorf("%s already approved the document", actor)
+		}
+	}
+	document.Approvers = append(document.Approvers, actor)
+	return nil
+}
@@ -0,0 +1,115 @@
+package examples_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/daegalus/transition"
+	"github.com/daegalus/transition/examples"
+	"github.com/daegalus/transition/transitiontest"
+)
+
+func TestOrderMachine(t *testing.T) {
+	var (
+		sm    = examples.NewOrderMachine()
+		order = &examples.Order{ID: "1"}
+	)
+
+	if err := sm.Trigger("checkout", order); !errors.Is(err, examples.ErrAddressRequired) {
+		t.Errorf("orders without address should not be checked out, got %v", err)
+	}
+	order.Address, order.Paid = "1 Main Street", 42
+	transitiontest.TriggerAll(t, sm, order, "checkout", "pay", "process")
+	if err := sm.Trigger("cancel", order); err != nil || order.State != "paid_cancelled" || order.Paid != 0 {
+		t.Errorf("processed orders should be refunded when cancelled, got %+v, %v", order, err)
+	}
+
+	if len(order.Trail) != 4 || order.Trail[3] != (examples.AuditEntry{Event: "cancel", From: "processed", To: "paid_cancelled", Actor: "system"}) {
+		t.Errorf("unexpected audit trail %+v", order.Trail)
+	}
+}
+
+func TestTicketMachine(t *testing.T) {
+	var (
+		sm       = examples.NewTicketMachine()
+		ticket   = &examples.Ticket{ID: "T-1"}
+		alice    = transition.WithActor(context.Background(), "alice")
+		reporter = transition.WithRoles(context.Background(), "reporter")
+	)
+
+	if err := sm.Trigger("start", ticket); !errors.Is(err, examples.ErrUnassigned) {
+		t.Errorf("unassigned tickets should not be started, got %v", err)
+	}
+	ticket.Assignee = "alice"
+	sm.Trigger("start", ticket)
+	if err := sm.TriggerContext(context.Background(), "resolve", ticket); !errors.Is(err, transition.ErrUnauthorized) {
+		t.Errorf("only the assignee should resolve the ticket, got %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := sm.TriggerContext(alice, "resolve", ticket); err != nil {
+			t.Fatal(err)
+		}
+		if err := sm.Trigger("reopen", ticket); err != nil {
+			t.Fatalf("reopening %d should be allowed, got %v", i+1, err)
+		}
+		sm.Trigger("start", ticket)
+	}
+	sm.TriggerContext(alice, "resolve", ticket)
+	if err := sm.Trigger("reopen", ticket); !transition.IsNoMatch(err) || ticket.Reopened != 3 {
+		t.Errorf("tickets should be reopened at most 3 times, got %v", err)
+	}
+
+	if err := sm.Trigger("close", ticket); !errors.Is(err, transition.ErrUnauthorized) {
+		t.Errorf("only reporters should close tickets, got %v", err)
+	}
+	if err := sm.TriggerContext(reporter, "close", ticket); err != nil || ticket.State != "closed" {
+		t.Errorf("reporters should close resolved tickets, got %v", err)
+	}
+}
+
+func TestDocumentMachine(t *testing.T) {
+	var (
+		sm       = examples.NewDocumentMachine()
+		document = &examples.Document{ID: "D-1", Author: "alice"}
+		as       = func(actor string) context.Context { return transition.WithActor(context.Background(), actor) }
+	)
+
+	if err := sm.TriggerContext(as("bob"), "submit", document); !errors.Is(err, transition.ErrUnauthorized) {
+		t.Errorf("only the author should submit the document, got %v", err)
+	}
+	sm.TriggerContext(as("alice"), "submit", document)
+	if err := sm.TriggerContext(as("alice"), "approve", document); !errors.Is(err, transition.ErrUnauthorized) {
+		t.Errorf("authors should not approve their documents, got %v", err)
+	}
+
+	if err := sm.TriggerContext(as("bob"), "approve", document); err != nil || document.State != "in_review" {
+		t.Errorf("a single approval should not approve the document, got %s, %v", document.State, err)
+	}
+	if err := sm.TriggerContext(as("bob"), "approve", document); err == nil {
+		t.Errorf("reviewers should approve once")
+	}
+	if err := sm.TriggerContext(as("carol"), "approve", document); err != nil || document.State != "approved" {
+		t.Errorf("%d approvals should approve the document, got %s, %v", examples.RequiredApprovals, document.State, err)
+	}
+	if err := sm.TriggerContext(as("alice"), "publish", document); err != nil || document.State != "published" {
+		t.Errorf("the author should publish approved documents, got %v", err)
+	}
+	if len(document.Trail) != 4 || document.Trail[1].Actor != "bob" {
+		t.Errorf("unexpected audit trail %+v", document.Trail)
+	}
+}
+
+func BenchmarkOrderMachine(b *testing.B) {
+	sm := examples.NewOrderMachine()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		order := &examples.Order{Address: "1 Main Street", Paid: 42}
+		for _, event := range []string{"checkout", "pay", "process", "deliver"} {
+			if err := sm.Trigger(event, order); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package examples
+
+import (
+	"context"
+	"errors"
+
+	"github.com/daegalus/transition"
+)
+
+// ErrAddressRequired is returned when checking out an order without address
+var ErrAddressRequired = errors.New("address is required")
+
+// Order is an order of an online shop
+type Order struct {
+	ID      string `json:"id"`
+	Address string `json:"address"`
+	Paid    int    `json:"paid"`
+
+	transition.Transition
+	Audited
+}
+
+// NewOrderMachine returns the state machine of orders: drafts are checked out once they have an address, paid,
+// processed then delivered. Orders are cancelled before payment, and cancelled with a refund once paid:
+//
+//	draft -checkout-> checkout -pay-> paid -process-> processed -deliver-> delivered
+//	draft, checkout -cancel-> cancelled
+//	paid, processed -cancel-> paid_cancelled
+func NewOrderMachine() *transition.StateMachine[*Order] {
+	sm := transition.New(&Order{})
+	sm.Initial("draft")
+	sm.State("checkout").Label("en", "Checking out")
+	sm.State("paid")
+	sm.State("processed")
+	sm.State("delivered").Final()
+	sm.State("cancelled").Final()
+	sm.State("paid_cancelled").Label("en", "Refunded").Final()
+
+	sm.Event("checkout").To("checkout").From("draft").Guard(func(ctx context.Context, order *Order) error {
+		if order.Address == "" {
+			return ErrAddressRequired
+		}
+		return nil
+	})
+	sm.Event("pay").To("paid").From("checkout").Guard(transition.Named("payment received", func(ctx context.Context, order *Order) error {
+		if order.Paid <= 0 {
+			return errors.New("nothing was paid")
+		}
+		return nil
+	}))
+	sm.Event("process").To("processed").From("paid")
+	sm.Event("deliver").To("delivered").From("processed")
+	sm.Event("cancel").To("cancelled").From("draft", "checkout")
+	sm.Event("cancel").To("paid_cancelled").From("paid", "processed").After(func(order *Order) error {
+		order.Paid = 0
+		return nil
+	})
+
+	sm.AfterEach(auditHook[*Order]())
+	return sm
+}
@@ -0,0 +1,61 @@
+package examples
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/daegalus/transition"
+)
+
+// ErrUnassigned is returned when starting work on a ticket without assignee
+var ErrUnassigned = errors.New("ticket is unassigned")
+
+// Ticket is a ticket of an issue tracker
+type Ticket struct {
+	ID       string `json:"id"`
+	Assignee string `json:"assignee"`
+	Reopened int    `json:"reopened"`
+
+	transition.Transition
+	transition.CountedTransition
+	Audited
+}
+
+// NewTicketMachine returns the state machine of tickets: open tickets are started once assigned, resolved, then
+// closed by their reporter, actors with the "reporter" role, see transition.WithRoles. Resolved tickets are reopened at most 3 times, and only the assignee
+// resolves a ticket:
+//
+//	open -start-> in_progress -resolve-> resolved -close-> closed
+//	resolved -reopen-> open
+//	open, in_progress -wontfix-> closed
+func NewTicketMachine() *transition.StateMachine[*Ticket] {
+	sm := transition.New(&Ticket{})
+	sm.Initial("open")
+	sm.State("in_progress").Label("en", "In progress")
+	sm.State("resolved")
+	sm.State("closed").Final()
+
+	sm.Event("start").To("in_progress").From("open").Guard(func(ctx context.Context, ticket *Ticket) error {
+		if ticket.Assignee == "" {
+			return ErrUnassigned
+		}
+		return nil
+	})
+	sm.Event("resolve").Require(func(ctx context.Context, event string, ticket *Ticket) error {
+		if actor := transition.ActorFromContext(ctx); actor != ticket.Assignee {
+			return fmt.Errorf("%s is not the assignee of ticket %s", actor, ticket.ID)
+		}
+		return nil
+	}).To("resolved").From("in_progress")
+	sm.Event("close").Roles("reporter").To("closed").From("resolved")
+	sm.Event("reopen").To("open").From("resolved").Guard(transition.MaxEntries[*Ticket]("open", 3)).After(func(ticket *Ticket) error {
+		ticket.Reopened++
+		return nil
+	})
+	sm.Event("wontfix").Roles("reporter").To("closed").From("open", "in_progress")
+
+	sm.SetRolesFunc(transition.RolesFromContext)
+	sm.AfterEach(auditHook[*Ticket]())
+	return sm
+}
@@ -0,0 +1,115 @@
+package transition_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/daegalus/transition"
+	"github.com/daegalus/transition/examples"
+)
+
+// describer is the part of state machines of any value type exporters and analyzers use
+type describer interface {
+	Describe(opts ...transition.DescribeOption) transition.MachineDescription
+	Fingerprint() string
+	Validate(opts ...transition.ValidateOption) error
+	Sprint() string
+	HookManifest() transition.Manifest
+}
+
+// TestExamples checks exporters and analyzers against the machines of the examples package
+func TestExamples(t *testing.T) {
+	machines := map[string]describer{
+		"order":    examples.NewOrderMachine(),
+		"ticket":   examples.NewTicketMachine(),
+		"document": examples.NewDocumentMachine(),
+	}
+
+	for name, sm := range machines {
+		t.Run(name, func(t *testing.T) {
+			var warnings []error
+			if err := sm.Validate(transition.WithWarnings(func(warning error) { warnings = append(warnings, warning) })); err != nil || len(warnings) > 0 {
+				t.Errorf("examples should be valid without warnings, got %v, %v", err, warnings)
+			}
+
+			data, err := json.Marshal(sm.Describe())
+			if err != nil {
+				t.Fatal(err)
+			}
+			parsed, err := transition.ParseDescription(data)
+			if err != nil || parsed.Fingerprint() != sm.Fingerprint() {
+				t.Errorf("descriptions should round-trip with their fingerprint, got %v", err)
+			}
+
+			printed := sm.Sprint()
+			for _, state := range parsed.States {
+				if !strings.Contains(printed, " "+state.Name+" ") {
+					t.Errorf("state %s should be printed, got\n%s", state.Name, printed)
+				}
+			}
+			if len(sm.HookManifest().Transitions) == 0 {
+				t.Errorf("examples should have hooks")
+			}
+		})
+	}
+
+	result, err := examples.NewOrderMachine().WhatIf("draft", []string{"checkout", "pay", "process", "deliver"})
+	if err != nil || result.State() != "delivered" {
+		t.Errorf("the order example should deliver orders, got %s, %v", result.State(), err)
+	}
+}
+
+// TestExportCatalog checks catalogs of the order example against golden files, run with -update to regenerate them
+func TestExportCatalog(t *testing.T) {
+	formats := map[transition.CatalogFormat]string{
+		transition.CatalogTypeScript: "catalog.ts.golden",
+		transition.CatalogJSON:       "catalog.json.golden",
+	}
+
+	for format, file := range formats {
+		t.Run(string(format), func(t *testing.T) {
+			export := func() []byte {
+				var buf bytes.Buffer
+				if err := examples.NewOrderMachine().ExportCatalog(&buf, format, transition.CatalogTypeNames("OrderEvent", "OrderState")); err != nil {
+					t.Fatal(err)
+				}
+				return buf.Bytes()
+			}
+
+			output := export()
+			golden := filepath.Join("testdata", file)
+			if flag.Lookup("update").Value.String() == "true" {
+				if err := os.WriteFile(golden, output, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			expected, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(output, expected) {
+				t.Errorf("unexpected catalog, got\n%s\nexpected\n%s", output, expected)
+			}
+
+			for i := 0; i < 10; i++ {
+				if !bytes.Equal(export(), output) {
+					t.Fatalf("ExportCatalog output is not deterministic")
+				}
+			}
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := examples.NewOrderMachine().ExportCatalog(&buf, transition.CatalogTypeScript, transition.CatalogTypeNames("Order Event", "OrderState")); err == nil {
+		t.Errorf("invalid type names should be rejected")
+	}
+	if err := examples.NewOrderMachine().ExportCatalog(&buf, "yaml"); err == nil {
+		t.Errorf("unknown formats should be rejected")
+	}
+}
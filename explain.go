@@ -0,0 +1,127 @@
+package transition
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Explanation describe why an event can or cannot be triggered for a value, see Explain
+type Explanation struct {
+	Event string
+	State string
+	// Exists is false when the event is not defined
+	Exists bool
+	// Allowed is true when exactly one transition matches the current state and it doesn't block the event
+	Allowed bool
+	// Blocked is ErrStateBlocked naming the current state when it blocks the event, see State.BlockWhile
+	Blocked error
+	// Transitions are the transitions of the event, in definition order as they are matched
+	Transitions []ExplainedTransition
+	// Err is set when the event can't be explained, e.g. ErrNilValue
+	Err error
+}
+
+// ExplainedTransition is one of the event's transitions, with whether its from-check accepts the current state
+type ExplainedTransition struct {
+	To    string
+	Froms []string
+	// FromMatched is false when the current state is not one of Froms
+	FromMatched bool
+	// GuardErr is the error of the guard that rejected the transition, when its from states matched
+	GuardErr error
+	// FromSites are where the current state was added to Froms, see WithDefinitionTracking
+	FromSites []string
+}
+
+// Explain describe whether event can be triggered for value from its current state, and why
+func (sm *StateMachine[T]) Explain(name string, value T) Explanation {
+	if err := checkTriggerArgs(name, value); err != nil {
+		return Explanation{Event: name, Err: err}
+	}
+
+	explanation := Explanation{Event: name, State: sm.currentState(value)}
+
+	event := sm.events[name]
+	if event == nil {
+		return explanation
+	}
+	explanation.Exists = true
+
+	matched, rejected := sm.match(context.Background(), event, explanation.State, value)
+	for _, transition := range event.transitions {
+		explained := ExplainedTransition{
+			To:        transition.to,
+			Froms:     transition.fromList(),
+			GuardErr:  rejected[transition],
+			FromSites: clip(transition.fromSites[explanation.State]),
+		}
+		for _, match := range matched {
+			if match == transition {
+				explained.FromMatched = true
+			}
+		}
+		if explained.GuardErr != nil {
+			explained.FromMatched = true
+		}
+		explanation.Transitions = append(explanation.Transitions, explained)
+	}
+
+	explanation.Blocked = sm.blocked(name, explanation.State, value)
+	explanation.Allowed = len(matched) == 1 && explanation.Blocked == nil
+	return explanation
+}
+
+// AllowedFrom returns the sorted union of from states of the event's transitions,
+// it's empty when a transition accepts any state
+func (explanation Explanation) AllowedFrom() []string {
+	var froms [][]string
+	for _, transition := range explanation.Transitions {
+		froms = append(froms, transition.Froms)
+	}
+	return unionFroms(froms)
+}
+
+// String renders the explanation for humans, e.g. "cancel is only allowed from draft, checkout; current state is delivered"
+func (explanation Explanation) String() string {
+	if explanation.Err != nil {
+		return fmt.Sprintf("event %s can't be explained: %v", explanation.Event, explanation.Err)
+	}
+
+	if !explanation.Exists {
+		return fmt.Sprintf("event %s does not exist", explanation.Event)
+	}
+
+	if explanation.Blocked != nil {
+		return fmt.Sprintf("%s is blocked while the value is in %s", explanation.Event, explanation.State)
+	}
+
+	if explanation.Allowed {
+		for _, transition := range explanation.Transitions {
+			if transition.FromMatched && transition.GuardErr == nil {
+				return fmt.Sprintf("%s is allowed from %s, going to %s", explanation.Event, explanation.State, transition.To)
+			}
+		}
+	}
+
+	var destinations []string
+	for _, transition := range explanation.Transitions {
+		if transition.FromMatched && transition.GuardErr == nil {
+			destinations = append(destinations, transition.To)
+		}
+	}
+	if len(destinations) > 1 {
+		return fmt.Sprintf("%s is ambiguous from %s, it could go to %s", explanation.Event, explanation.State, strings.Join(destinations, ", "))
+	}
+
+	for _, transition := range explanation.Transitions {
+		if transition.GuardErr != nil {
+			return fmt.Sprintf("%s from %s to %s is rejected by a guard: %v", explanation.Event, explanation.State, transition.To, transition.GuardErr)
+		}
+	}
+
+	if len(explanation.Transitions) == 0 {
+		return fmt.Sprintf("%s has no transitions; current state is %s", explanation.Event, explanation.State)
+	}
+	return fmt.Sprintf("%s is only allowed from %s; current state is %s", explanation.Event, strings.Join(explanation.AllowedFrom(), ", "), explanation.State)
+}
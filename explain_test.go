@@ -0,0 +1,54 @@
+package transition
+
+import "testing"
+
+func TestExplain(t *testing.T) {
+	orderStateMachine := getStateMachine()
+	cancelEvent := orderStateMachine.Event("cancel")
+	cancelEvent.To("cancelled").From("draft", "checkout")
+	cancelEvent.To("paid_cancelled").From("paid", "processed")
+
+	delivered := &Order{}
+	delivered.State = "delivered"
+
+	explanation := orderStateMachine.Explain("cancel", delivered)
+	if !explanation.Exists || explanation.Allowed {
+		t.Errorf("cancel should exist but not be allowed from delivered")
+	}
+
+	if len(explanation.Transitions) != 2 || explanation.Transitions[0].FromMatched || explanation.Transitions[1].FromMatched {
+		t.Errorf("no transition should match delivered, got %+v", explanation.Transitions)
+	}
+
+	if got, expected := explanation.String(), "cancel is only allowed from checkout, draft, paid, processed; current state is delivered"; got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+
+	explanation = orderStateMachine.Explain("cancel", &Order{})
+	if !explanation.Allowed || explanation.State != "draft" {
+		t.Errorf("cancel should be allowed from the initial state")
+	}
+
+	if got, expected := explanation.String(), "cancel is allowed from draft, going to cancelled"; got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+
+	if got, expected := orderStateMachine.Explain("refund", delivered).String(), "event refund does not exist"; got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
+
+func TestExplainAmbiguous(t *testing.T) {
+	orderStateMachine := getStateMachine()
+	orderStateMachine.Event("cancel").To("cancelled").From("draft")
+	orderStateMachine.Event("cancel").To("paid_cancelled").From("draft")
+
+	explanation := orderStateMachine.Explain("cancel", &Order{})
+	if explanation.Allowed {
+		t.Errorf("ambiguous event should not be allowed")
+	}
+
+	if got, expected := explanation.String(), "cancel is ambiguous from draft, it could go to cancelled, paid_cancelled"; got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
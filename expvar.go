@@ -0,0 +1,48 @@
+package transition
+
+import (
+	"expvar"
+	"fmt"
+	"sync"
+)
+
+// expvarMu serializes PublishExpvar, expvar panics when a name is published twice
+var expvarMu sync.Mutex
+
+// PublishExpvar publish the state machine under prefix with expvar, served as JSON by expvar.Handler:
+// <prefix>.fingerprint, <prefix>.states and <prefix>.events describe the definition, and <prefix>.stats counts
+// the <event>.successes and <event>.failures of triggers once stats are enabled, see EnableStats.
+// An error is returned when a name is already published
+func (sm *StateMachine[T]) PublishExpvar(prefix string) error {
+	expvarMu.Lock()
+	defer expvarMu.Unlock()
+
+	names := []string{prefix + ".fingerprint", prefix + ".states", prefix + ".events", prefix + ".stats"}
+	for _, name := range names {
+		if expvar.Get(name) != nil {
+			return fmt.Errorf("expvar %s is already published", name)
+		}
+	}
+
+	stats := new(expvar.Map).Init()
+	sm.expvarStats.Store(stats)
+
+	expvar.Publish(names[0], expvar.Func(func() any { return sm.Fingerprint() }))
+	expvar.Publish(names[1], expvar.Func(func() any { return len(sm.stateNamesWithInitial()) }))
+	expvar.Publish(names[2], expvar.Func(func() any { return len(sm.events) }))
+	expvar.Publish(names[3], stats)
+	return nil
+}
+
+// recordExpvar count a trigger of event in the published stats, if any
+func (sm *StateMachine[T]) recordExpvar(event string, err error) {
+	stats := sm.expvarStats.Load()
+	if stats == nil {
+		return
+	}
+	if err == nil {
+		stats.Add(event+".successes", 1)
+	} else {
+		stats.Add(event+".failures", 1)
+	}
+}
@@ -0,0 +1,42 @@
+package transition
+
+import (
+	"encoding/json"
+	"expvar"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPublishExpvar(t *testing.T) {
+	orderStateMachine := getStateMachine().EnableStats()
+	if err := orderStateMachine.PublishExpvar("orders"); err != nil {
+		t.Fatalf("no error expected, got %v", err)
+	}
+	if err := orderStateMachine.PublishExpvar("orders"); err == nil {
+		t.Errorf("publishing twice should fail")
+	}
+
+	order := &Order{}
+	orderStateMachine.Trigger("checkout", order)
+	orderStateMachine.Trigger("checkout", order)
+
+	recorder := httptest.NewRecorder()
+	expvar.Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/debug/vars", nil))
+
+	var vars struct {
+		Fingerprint string           `json:"orders.fingerprint"`
+		States      int              `json:"orders.states"`
+		Events      int              `json:"orders.events"`
+		Stats       map[string]int64 `json:"orders.stats"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &vars); err != nil {
+		t.Fatalf("expvar should serve JSON, got %v", err)
+	}
+
+	if vars.Fingerprint != orderStateMachine.Fingerprint() || vars.States != 7 || vars.Events != 2 {
+		t.Errorf("unexpected definition vars %+v", vars)
+	}
+	if vars.Stats["checkout.successes"] != 1 || vars.Stats["checkout.failures"] != 1 {
+		t.Errorf("unexpected stats %v", vars.Stats)
+	}
+}
@@ -0,0 +1,75 @@
+package transition
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// WithMutableAfterStart allow defining states, events, transitions and hooks, and configuring the state machine, e.g.
+// with OnError or SetKeyFunc, after the first trigger. Without it they panic once the state machine has triggered an
+// event, as modifying a definition in use is a data race
+func WithMutableAfterStart() Option {
+	return func(opts *options) {
+		opts.mutableAfterStart = true
+	}
+}
+
+// ownerGuard records whether the state machine owning states and events has triggered an event
+type ownerGuard struct {
+	started           atomic.Bool
+	mutableAfterStart bool
+	// generation counts the definition changes, see StateMachine.ReadOnlySnapshot
+	generation atomic.Uint64
+	// skip records the definitions of states and events detached by a ConditionalRegistrar instead of allowing them
+	skip func(what string)
+}
+
+// start records a trigger, only the first one writes
+func (guard *ownerGuard) start() {
+	if !guard.started.Load() {
+		guard.started.Store(true)
+	}
+}
+
+// checkMutable panics when what, a definition method, is called after the first trigger
+func (guard *ownerGuard) checkMutable(what string) {
+	if guard.skip != nil {
+		guard.skip(what)
+		return
+	}
+	if guard.started.Load() && !guard.mutableAfterStart {
+		panic(fmt.Sprintf("transition: %s called after the state machine started triggering events, define it before the first trigger or create it with WithMutableAfterStart", what))
+	}
+	guard.changed()
+}
+
+// changed records a definition change
+func (guard *ownerGuard) changed() {
+	guard.generation.Add(1)
+}
+
+// checkEpoch panics when what is called on a state, event or transition obtained before the last Clone, it's shared
+// with the clone, see StateMachine.Clone
+func (current *owner) checkEpoch(epoch uint64, what string) {
+	if current.clones.Load() != epoch {
+		panic(fmt.Sprintf("transition: %s called on a definition obtained before the state machine was cloned, it's shared with the clone, request it again", what))
+	}
+}
+
+// checkMutable panics when the state can't be modified, see owner.checkEpoch and ownerGuard.checkMutable
+func (state *State[T]) checkMutable(what string) {
+	state.owner.checkEpoch(state.epoch, what)
+	state.owner.checkMutable(what)
+}
+
+// checkMutable panics when the event can't be modified, see owner.checkEpoch and ownerGuard.checkMutable
+func (event *Event[T]) checkMutable(what string) {
+	event.owner.checkEpoch(event.epoch, what)
+	event.owner.checkMutable(what)
+}
+
+// checkMutable panics when the transition can't be modified, see owner.checkEpoch and ownerGuard.checkMutable
+func (transition *EventTransition[T]) checkMutable(what string) {
+	transition.owner.checkEpoch(transition.epoch, what)
+	transition.owner.checkMutable(what)
+}
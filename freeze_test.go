@@ -0,0 +1,148 @@
+package transition
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDefinitionFrozenAfterFirstTrigger(t *testing.T) {
+	hook := func(*Order) error { return nil }
+	mutations := map[string]func(sm *StateMachine[*Order]){
+		"State":                  func(sm *StateMachine[*Order]) { sm.State("archived") },
+		"Event":                  func(sm *StateMachine[*Order]) { sm.Event("archive") },
+		"Event.To":               func(sm *StateMachine[*Order]) { sm.events["pay"].To("cancelled") },
+		"EventTransition.From":   func(sm *StateMachine[*Order]) { sm.events["pay"].transitions[0].From("draft") },
+		"EventTransition.Before": func(sm *StateMachine[*Order]) { sm.events["pay"].transitions[0].Before(hook) },
+		"EventTransition.After":  func(sm *StateMachine[*Order]) { sm.events["pay"].transitions[0].After(hook) },
+		"EventTransition.Guard": func(sm *StateMachine[*Order]) {
+			sm.events["pay"].transitions[0].Guard(func(context.Context, *Order) error { return nil })
+		},
+		"State.Enter": func(sm *StateMachine[*Order]) { sm.states["paid"].Enter(hook) },
+		"State.Exit":  func(sm *StateMachine[*Order]) { sm.states["paid"].Exit(hook) },
+		"BeforeEach": func(sm *StateMachine[*Order]) {
+			sm.BeforeEach(func(context.Context, *Order, TransitionInfo) error { return nil })
+		},
+	}
+
+	for name, mutate := range mutations {
+		t.Run(name, func(t *testing.T) {
+			orderStateMachine := getStateMachine()
+			mutate(orderStateMachine)
+
+			orderStateMachine.Trigger("checkout", &Order{})
+			defer func() {
+				if recovered := recover(); !strings.Contains(fmt.Sprint(recovered), name+" called after the state machine started") {
+					t.Errorf("should panic with a descriptive message, got %v", recovered)
+				}
+			}()
+			mutate(orderStateMachine)
+		})
+	}
+}
+
+func TestWithMutableAfterStart(t *testing.T) {
+	orderStateMachine := New(&Order{}, WithMutableAfterStart())
+	orderStateMachine.Initial("draft")
+	orderStateMachine.Event("checkout").To("checkout").From("draft")
+
+	order := &Order{}
+	if err := orderStateMachine.Trigger("checkout", order); err != nil {
+		t.Fatal(err)
+	}
+	orderStateMachine.State("paid").Enter(func(*Order) error { return nil })
+	orderStateMachine.Event("pay").To("paid").From("checkout")
+	if err := orderStateMachine.Trigger("pay", order); err != nil || order.State != "paid" {
+		t.Errorf("the definition should be modifiable after the first trigger, got %v", err)
+	}
+
+	if clone := orderStateMachine.Clone(); !clone.owner.mutableAfterStart {
+		t.Errorf("clones should keep the option")
+	}
+}
+
+func TestDefinitionFrozenAfterTypedTrigger(t *testing.T) {
+	orderStateMachine := getStateMachine()
+	pay := DefineEvent[*Order, PaymentInfo](orderStateMachine, "pay")
+
+	order := &Order{}
+	order.SetState("checkout")
+	if err := pay.Trigger(order, PaymentInfo{Amount: 42}); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, mutate := range map[string]func(){
+		"State":             func() { orderStateMachine.State("late") },
+		"TypedEvent.Before": func() { pay.Before(func(*Order, PaymentInfo) error { return nil }) },
+		"TypedEvent.After":  func() { pay.After(func(*Order, PaymentInfo) error { return nil }) },
+	} {
+		func() {
+			defer func() {
+				if recovered := recover(); !strings.Contains(fmt.Sprint(recovered), name+" called after the state machine started") {
+					t.Errorf("%s should panic after a typed trigger, got %v", name, recovered)
+				}
+			}()
+			mutate()
+		}()
+	}
+}
+
+func TestDefinitionFrozenHeldReferences(t *testing.T) {
+	orderStateMachine := getStateMachine()
+	pay, paid := orderStateMachine.Event("pay"), orderStateMachine.State("paid")
+	transition := pay.To("paid")
+
+	if err := orderStateMachine.Trigger("checkout", &Order{}); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, mutate := range map[string]func(){
+		"Event.Require":          func() { pay.Require(func(context.Context, string, *Order) error { return nil }) },
+		"Event.Roles":            func() { pay.Roles("admin") },
+		"Event.Debounce":         func() { pay.Debounce(time.Second) },
+		"Event.Label":            func() { pay.Label("fr", "Payer") },
+		"Event.Meta":             func() { pay.Meta("order", "1") },
+		"EventTransition.Weight": func() { transition.Weight(2) },
+		"State.Timeout":          func() { paid.Timeout(time.Hour, "cancel") },
+		"State.SLA":              func() { paid.SLA(time.Hour) },
+		"State.Final":            func() { paid.Final() },
+		"State.Label":            func() { paid.Label("fr", "Payée") },
+		"State.Meta":             func() { paid.Meta("tag", "done") },
+	} {
+		func() {
+			defer func() {
+				if recovered := recover(); !strings.Contains(fmt.Sprint(recovered), name+" called after the state machine started") {
+					t.Errorf("%s should panic on a held reference, got %v", name, recovered)
+				}
+			}()
+			mutate()
+		}()
+	}
+}
+
+func TestMachineSettersAfterStart(t *testing.T) {
+	orderStateMachine := getStateMachine()
+	if err := orderStateMachine.Trigger("checkout", &Order{}); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, set := range map[string]func(){
+		"Frozen":     func() { orderStateMachine.Frozen(func(*Order) bool { return false }) },
+		"BlockWhile": func() { orderStateMachine.BlockWhile(func(*Order) bool { return false }) },
+		"SetKeyFunc": func() { orderStateMachine.SetKeyFunc(func(order *Order) string { return "" }) },
+		"Authorize":  func() { orderStateMachine.Authorize(nil) },
+		"OnError":    func() { orderStateMachine.OnError(func(error) {}) },
+		"Initial":    func() { orderStateMachine.Initial("checkout") },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s should panic after the first trigger", name)
+				}
+			}()
+			set()
+		}()
+	}
+}
@@ -0,0 +1,75 @@
+package transition
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ErrDuplicateFrom is warned by Validate for states passed more than once to a single From call, which usually
+// means the states were assembled by a buggy generator
+var ErrDuplicateFrom = errors.New("duplicate from state")
+
+// Froms returns the from states of the transition, sorted, it's empty when the transition accepts any state.
+// From globs aren't included, see EventTransition.FromGlob
+func (transition *EventTransition[T]) Froms() []string {
+	froms := append([]string{}, transition.froms...)
+	sort.Strings(froms)
+	return froms
+}
+
+// addFroms add states to the from states of transition, skipping those it already has
+func (transition *EventTransition[T]) addFroms(states ...string) {
+	if transition.fromSet == nil {
+		transition.fromSet = make(map[string]struct{}, len(states))
+	}
+	for _, state := range states {
+		if _, ok := transition.fromSet[state]; !ok {
+			transition.fromSet[state] = struct{}{}
+			transition.froms = append(transition.froms, state)
+		}
+	}
+}
+
+// linearFroms is the number of from states up to which comparing them is faster than looking state up in the set
+const linearFroms = 8
+
+// acceptsFrom reports whether state is one of the from states of transition
+func (transition *EventTransition[T]) acceptsFrom(state string) bool {
+	if len(transition.froms) <= linearFroms {
+		for _, from := range transition.froms {
+			if from == state {
+				return true
+			}
+		}
+		return false
+	}
+	_, ok := transition.fromSet[state]
+	return ok
+}
+
+// recordDuplicateFroms remember the states passed more than once to a From call, see fromWarnings
+func (transition *EventTransition[T]) recordDuplicateFroms(states []string) {
+	if len(states) < 2 {
+		return
+	}
+	counts := make(map[string]int, len(states))
+	for _, state := range states {
+		if counts[state]++; counts[state] == 2 {
+			transition.duplicateFroms = append(transition.duplicateFroms, state)
+		}
+	}
+}
+
+// fromWarnings returns a warning for every state passed more than once to a From call
+func (sm *StateMachine[T]) fromWarnings() []error {
+	var warnings []error
+	for _, name := range sm.eventNames() {
+		for _, transition := range sm.events[name].transitions {
+			for _, from := range transition.duplicateFroms {
+				warnings = append(warnings, fmt.Errorf("event %s to %s: From called with %s more than once%s: %w", name, transition.to, from, transition.fromAddedAt(from), ErrDuplicateFrom))
+			}
+		}
+	}
+	return warnings
+}
@@ -0,0 +1,92 @@
+package transition
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFroms(t *testing.T) {
+	orderStateMachine := getStateMachine()
+	cancel := orderStateMachine.Event("cancel").To("cancelled").From("paid", "checkout").From("draft", "checkout")
+
+	if froms := cancel.Froms(); !reflect.DeepEqual(froms, []string{"checkout", "draft", "paid"}) {
+		t.Errorf("expected sorted from states without duplicates, got %v", froms)
+	}
+	if froms := orderStateMachine.Event("reset").To("draft").Froms(); len(froms) != 0 {
+		t.Errorf("transitions from any state should have no from states, got %v", froms)
+	}
+
+	var warnings []error
+	if err := orderStateMachine.Validate(WithWarnings(func(warning error) { warnings = append(warnings, warning) })); err != nil {
+		t.Fatal(err)
+	}
+	for _, warning := range warnings {
+		if errors.Is(warning, ErrDuplicateFrom) {
+			t.Errorf("states added again by another From call should not be warned, got %v", warning)
+		}
+	}
+
+	clone := orderStateMachine.Clone()
+	clone.Event("cancel").To("cancelled").From("processed")
+	order := &Order{}
+	order.State = "processed"
+	if orderStateMachine.Can("cancel", order) || !clone.Can("cancel", order) {
+		t.Errorf("from states added to a clone should not be shared")
+	}
+}
+
+func TestDuplicateFromWarning(t *testing.T) {
+	orderStateMachine := New(&Order{}, WithDefinitionTracking())
+	orderStateMachine.Initial("draft")
+	orderStateMachine.State("checkout")
+	orderStateMachine.State("cancelled").Final()
+	orderStateMachine.Event("checkout").To("checkout").From("draft")
+	orderStateMachine.Event("cancel").To("cancelled").From("draft", "checkout", "draft", "draft", "checkout")
+
+	var warnings []string
+	err := orderStateMachine.Validate(WithWarnings(func(warning error) {
+		if errors.Is(warning, ErrDuplicateFrom) {
+			warnings = append(warnings, warning.Error())
+		}
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 2 || !strings.HasPrefix(warnings[0], "event cancel to cancelled: From called with draft more than once (added at ") || !strings.Contains(warnings[1], "checkout more than once") {
+		t.Errorf("each duplicated state should be warned once, got %q", warnings)
+	}
+	if froms := orderStateMachine.Event("cancel").To("cancelled").Froms(); !reflect.DeepEqual(froms, []string{"checkout", "draft"}) {
+		t.Errorf("duplicates should be stored once, got %v", froms)
+	}
+}
+
+func BenchmarkManyFroms(b *testing.B) {
+	sm := New(&Order{})
+	sm.Initial("s0")
+	states := make([]string, 100)
+	for i := range states {
+		states[i] = fmt.Sprintf("s%d", i)
+		sm.State(states[i])
+	}
+	sm.State("cancelled")
+	sm.Event("cancel").To("cancelled").From(states...)
+
+	b.Run("define", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			New(&Order{}).Event("cancel").To("cancelled").From(states...)
+		}
+	})
+
+	b.Run("match", func(b *testing.B) {
+		order := &Order{}
+		order.State = states[len(states)-1]
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			sm.Can("cancel", order)
+		}
+	})
+}
@@ -0,0 +1,33 @@
+package transition
+
+import "errors"
+
+// ErrValueFrozen is returned when triggering an event on a value the frozen predicate reports, see StateMachine.Frozen
+var ErrValueFrozen = errors.New("value is frozen")
+
+// Frozen define a predicate reporting values that can't be triggered on, e.g. archived or soft-deleted values.
+// Trigger returns ErrValueFrozen without running any hooks for them, Can returns false and AvailableEvents leaves
+// their events out, except events that ignore it, see Event.IgnoreFrozen
+func (sm *StateMachine[T]) Frozen(fc func(value T) bool) *StateMachine[T] {
+	sm.owner.checkMutable("Frozen")
+	sm.frozen = fc
+	return sm
+}
+
+// IgnoreFrozen allow triggering the event on frozen values, e.g. to unarchive them, see StateMachine.Frozen
+func (event *Event[T]) IgnoreFrozen() *Event[T] {
+	event.checkMutable("Event.IgnoreFrozen")
+	event.ignoreFrozen = true
+	return event
+}
+
+// isFrozen reports whether the event named name can't be triggered on value because it's frozen
+func (sm *StateMachine[T]) isFrozen(name string, value T) bool {
+	if sm.frozen == nil {
+		return false
+	}
+	if event := sm.events[name]; event != nil && event.ignoreFrozen {
+		return false
+	}
+	return sm.frozen(value)
+}
@@ -0,0 +1,92 @@
+package transition
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestFrozen(t *testing.T) {
+	orderStateMachine := getStateMachine()
+	orderStateMachine.Event("unarchive").IgnoreFrozen().To("checkout").From("checkout")
+
+	var hooks int
+	orderStateMachine.State("checkout").Exit(func(order *Order) error {
+		hooks++
+		return nil
+	})
+	orderStateMachine.BeforeEach(func(ctx context.Context, order *Order, info TransitionInfo) error {
+		hooks++
+		return nil
+	})
+
+	archived := map[*Order]bool{}
+	orderStateMachine.Frozen(func(order *Order) bool {
+		return archived[order]
+	})
+
+	order := &Order{}
+	order.State = "checkout"
+	archived[order] = true
+
+	err := orderStateMachine.Trigger("pay", order)
+	var transitionErr *TransitionError
+	if !errors.Is(err, ErrValueFrozen) || !errors.As(err, &transitionErr) || transitionErr.From != "checkout" || !IsPermanent(err) {
+		t.Fatalf("frozen value should not be triggered, got %v", err)
+	}
+	if order.State != "checkout" || hooks != 0 {
+		t.Errorf("frozen value should be unchanged without running hooks, got state %s and %d hooks", order.State, hooks)
+	}
+
+	if orderStateMachine.Can("pay", order) || !orderStateMachine.Can("unarchive", order) {
+		t.Errorf("only unarchive should be possible on a frozen value")
+	}
+	if events := orderStateMachine.AvailableEvents(order); !reflect.DeepEqual(events, []string{"unarchive"}) {
+		t.Errorf("expected [unarchive], got %v", events)
+	}
+
+	if err := orderStateMachine.Trigger("unarchive", order); err != nil {
+		t.Fatalf("unarchive should ignore the frozen predicate, got %v", err)
+	}
+	archived[order] = false
+	if err := orderStateMachine.Trigger("pay", order); err != nil || order.State != "paid" {
+		t.Errorf("unfrozen value should be triggered, got %v", err)
+	}
+
+	var nilOrder *Order
+	if err := orderStateMachine.Trigger("pay", nilOrder); !errors.Is(err, ErrNilValue) {
+		t.Errorf("nil value should be checked before the frozen predicate, got %v", err)
+	}
+}
+
+func TestIgnoreFrozenAfterStart(t *testing.T) {
+	orderStateMachine := New(&Order{}, WithMutableAfterStart())
+	orderStateMachine.Initial("draft")
+	orderStateMachine.Event("checkout").To("checkout").From("draft")
+	orderStateMachine.Frozen(func(order *Order) bool { return order.Address == "archived" })
+	checkout := orderStateMachine.Event("checkout")
+
+	if err := orderStateMachine.Trigger("checkout", &Order{}); err != nil {
+		t.Fatal(err)
+	}
+	order := &Order{Address: "archived"}
+	if orderStateMachine.ReadOnlySnapshot().Can("checkout", order) {
+		t.Fatalf("frozen values should not be triggered")
+	}
+
+	checkout.IgnoreFrozen()
+	if !orderStateMachine.Can("checkout", order) || !orderStateMachine.ReadOnlySnapshot().Can("checkout", order) {
+		t.Errorf("snapshots should see events ignoring frozen values once changed")
+	}
+
+	defer func() {
+		if recovered := recover(); recovered == nil {
+			t.Errorf("IgnoreFrozen should panic after start without WithMutableAfterStart")
+		}
+	}()
+	frozen := getStateMachine()
+	pay := frozen.Event("pay")
+	frozen.Trigger("checkout", &Order{})
+	pay.IgnoreFrozen()
+}
@@ -0,0 +1,55 @@
+package transition
+
+import "time"
+
+// FunnelTracker is implemented by values recording when they first entered each state, the embedded
+// FunneledTransition implements it. Trigger records the time a state is first entered, and removes it on failure
+type FunnelTracker interface {
+	FirstEntered(state string) (time.Time, bool)
+	SetFirstEntered(state string, at time.Time)
+}
+
+// FunneledTransition records when each state was first entered, embed it in your struct along with Transition and
+// the other mixins, e.g. CountedTransition. FirstEnteredAt is serialized with the value
+type FunneledTransition struct {
+	FirstEnteredAt map[string]time.Time
+}
+
+// FirstEntered returns when state was first entered, false if it never was
+func (transition FunneledTransition) FirstEntered(state string) (time.Time, bool) {
+	at, ok := transition.FirstEnteredAt[state]
+	return at, ok
+}
+
+// GetFirstEnteredAt returns a copy of when every state was first entered
+func (transition FunneledTransition) GetFirstEnteredAt() map[string]time.Time {
+	return cloneMap(transition.FirstEnteredAt)
+}
+
+// SetFirstEntered set when state was first entered, the zero time removes it
+func (transition *FunneledTransition) SetFirstEntered(state string, at time.Time) {
+	if at.IsZero() {
+		delete(transition.FirstEnteredAt, state)
+		if len(transition.FirstEnteredAt) == 0 {
+			transition.FirstEnteredAt = nil
+		}
+		return
+	}
+	if transition.FirstEnteredAt == nil {
+		transition.FirstEnteredAt = map[string]time.Time{}
+	}
+	transition.FirstEnteredAt[state] = at
+}
+
+// FunnelDurations returns how long after first entering from the value first entered to, false unless both were entered
+func (transition FunneledTransition) FunnelDurations(from, to string) (time.Duration, bool) {
+	fromAt, ok := transition.FirstEnteredAt[from]
+	if !ok {
+		return 0, false
+	}
+	toAt, ok := transition.FirstEnteredAt[to]
+	if !ok {
+		return 0, false
+	}
+	return toAt.Sub(fromAt), true
+}
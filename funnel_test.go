@@ -0,0 +1,84 @@
+package transition
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+type FunneledOrder struct {
+	Id int
+
+	Transition
+	FunneledTransition
+}
+
+func getFunnelStateMachine(clock Clock) *StateMachine[*FunneledOrder] {
+	sm := New(&FunneledOrder{}, WithClock(clock))
+	sm.Initial("draft")
+	sm.State("checkout")
+	sm.State("paid")
+	sm.State("delivered")
+	sm.Event("checkout").To("checkout").From("draft")
+	sm.Event("edit").To("draft").From("checkout")
+	sm.Event("pay").To("paid").From("checkout")
+	sm.Event("deliver").To("delivered").From("paid")
+	return sm
+}
+
+func TestFirstEntered(t *testing.T) {
+	var (
+		start = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		clock = &manualClock{now: start}
+		sm    = getFunnelStateMachine(clock)
+		order = &FunneledOrder{}
+	)
+
+	sm.Trigger("checkout", order)
+	clock.now = start.Add(time.Hour)
+	sm.Trigger("edit", order)
+	sm.Trigger("checkout", order)
+	clock.now = start.Add(2 * time.Hour)
+	sm.Trigger("pay", order)
+	clock.now = start.Add(26 * time.Hour)
+	sm.Trigger("deliver", order)
+
+	if at, ok := order.FirstEntered("checkout"); !ok || !at.Equal(start) {
+		t.Errorf("the first entry into checkout should never be overwritten, got %v", at)
+	}
+	if d, ok := order.FunnelDurations("paid", "delivered"); !ok || d != 24*time.Hour {
+		t.Errorf("expected 24h from paid to delivered, got %v", d)
+	}
+	if _, ok := order.FunnelDurations("paid", "cancelled"); ok {
+		t.Errorf("states never entered have no funnel duration")
+	}
+
+	data, _ := json.Marshal(order)
+	var loaded FunneledOrder
+	if err := json.Unmarshal(data, &loaded); err != nil || len(loaded.FirstEnteredAt) != 4 {
+		t.Errorf("first entry times should be serialized with the value, got %s", data)
+	}
+}
+
+func TestFirstEnteredRollback(t *testing.T) {
+	var (
+		clock = &manualClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+		sm    = getFunnelStateMachine(clock)
+		order = &FunneledOrder{}
+	)
+	sm.Event("pay").To("paid").From("checkout").After(func(order *FunneledOrder) error {
+		return errors.New("payment failed")
+	})
+
+	sm.Trigger("checkout", order)
+	if err := sm.Trigger("pay", order); err == nil {
+		t.Fatalf("pay should fail")
+	}
+	if _, ok := order.FirstEntered("paid"); ok {
+		t.Errorf("the entry into paid should be removed on failure, got %v", order.FirstEnteredAt)
+	}
+	if _, ok := order.FirstEntered("checkout"); !ok {
+		t.Errorf("entries of earlier triggers should be kept")
+	}
+}
@@ -0,0 +1,43 @@
+package transition
+
+import "testing"
+
+func FuzzTrigger(f *testing.F) {
+	f.Add("checkout", "")
+	f.Add("checkout", "draft")
+	f.Add("pay", "checkout")
+	f.Add("pay", "draft")
+	f.Add("cancel", "paid")
+	f.Add("", "")
+	f.Add("unknown", "unknown")
+
+	f.Fuzz(func(t *testing.T, event, state string) {
+		orderStateMachine := getStateMachine()
+		cancelEvent := orderStateMachine.Event("cancel")
+		cancelEvent.To("cancelled").From("draft", "checkout")
+		cancelEvent.To("paid_cancelled").From("paid", "processed")
+
+		destinations := map[string]bool{}
+		for _, event := range orderStateMachine.events {
+			for _, transition := range event.transitions {
+				destinations[transition.to] = true
+			}
+		}
+
+		order := &Order{}
+		order.State = state
+		stateWas := state
+		if stateWas == "" {
+			stateWas = orderStateMachine.initialState
+		}
+
+		err := orderStateMachine.Trigger(event, order)
+		if err != nil && order.State != stateWas && order.State != state {
+			t.Errorf("state changed from %q to %q on failed event %q", stateWas, order.State, event)
+		}
+
+		if err == nil && !destinations[order.State] {
+			t.Errorf("state %q after event %q is not a declared destination", order.State, event)
+		}
+	})
+}
@@ -0,0 +1,91 @@
+package transition
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrUnmatchedGlob is warned by Validate for from globs matching no declared state
+var ErrUnmatchedGlob = errors.New("glob matches no declared state")
+
+// FromGlob accept from states matching globs, where * matches any sequence of characters, e.g. "processing:*"
+// matches "processing:picking" and "processing:packing". Transitions of the event accepting a state exactly, or
+// accepting any state, beat those accepting it through a glob. Several transitions accepting a state through
+// globs are ambiguous, like several transitions accepting it exactly
+func (transition *EventTransition[T]) FromGlob(globs ...string) *EventTransition[T] {
+	transition.checkMutable("EventTransition.FromGlob")
+	transition.fromGlobs = removeDuplicateValues(append(transition.fromGlobs, globs...))
+	return transition
+}
+
+// FromPrefix accept from states starting with prefixes, it's FromGlob(prefix + "*")
+func (transition *EventTransition[T]) FromPrefix(prefixes ...string) *EventTransition[T] {
+	for _, prefix := range prefixes {
+		transition.FromGlob(prefix + "*")
+	}
+	return transition
+}
+
+// matchesGlob reports whether state matches a from glob of transition
+func (transition *EventTransition[T]) matchesGlob(state string) bool {
+	for _, glob := range transition.fromGlobs {
+		if matchGlob(glob, state) {
+			return true
+		}
+	}
+	return false
+}
+
+// fromList returns the from states and globs of transition, sorted, it's empty when transition accepts any state
+func (transition *EventTransition[T]) fromList() []string {
+	froms := append(append([]string{}, transition.froms...), transition.fromGlobs...)
+	sort.Strings(froms)
+	return froms
+}
+
+// matchGlob reports whether name matches glob, where * matches any sequence of characters
+func matchGlob(glob, name string) bool {
+	parts := strings.Split(glob, "*")
+	if len(parts) == 1 {
+		return glob == name
+	}
+
+	if !strings.HasPrefix(name, parts[0]) {
+		return false
+	}
+	name = name[len(parts[0]):]
+	for _, part := range parts[1 : len(parts)-1] {
+		index := strings.Index(name, part)
+		if index < 0 {
+			return false
+		}
+		name = name[index+len(part):]
+	}
+	last := parts[len(parts)-1]
+	return len(name) >= len(last) && strings.HasSuffix(name, last)
+}
+
+// globWarnings returns a warning for every from glob matching no declared state
+func (sm *StateMachine[T]) globWarnings() []error {
+	var warnings []error
+	states := sm.stateNamesWithInitial()
+	for _, name := range sm.eventNames() {
+		for _, transition := range sm.events[name].transitions {
+			for _, glob := range transition.fromGlobs {
+				var matched bool
+				for _, state := range states {
+					if matchGlob(glob, state) {
+						matched = true
+						break
+					}
+				}
+				if !matched {
+					warnings = append(warnings, fmt.Errorf("event %s goes from %s: %w", name, glob, ErrUnmatchedGlob))
+				}
+			}
+		}
+	}
+	return warnings
+}
@@ -0,0 +1,116 @@
+package transition
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func getGlobStateMachine() *StateMachine[*Order] {
+	orderStateMachine := getStateMachine()
+	orderStateMachine.State("processing:picking")
+	orderStateMachine.State("processing:packing")
+	orderStateMachine.State("processing:on_hold")
+	orderStateMachine.Event("ship").To("delivered").FromPrefix("processing:")
+	orderStateMachine.Event("ship").To("cancelled").From("processing:on_hold")
+	return orderStateMachine
+}
+
+func TestMatchGlob(t *testing.T) {
+	cases := []struct {
+		glob, name string
+		match      bool
+	}{
+		{"processing:*", "processing:picking", true},
+		{"processing:*", "processing:", true},
+		{"processing:*", "paid", false},
+		{"*:picking", "processing:picking", true},
+		{"processing:*ing", "processing:packing", true},
+		{"processing:*ing", "processing:on_hold", false},
+		{"a*b*c", "abc", true},
+		{"a*b*c", "ac", false},
+		{"*", "anything", true},
+		{"paid", "paid", true},
+	}
+	for _, c := range cases {
+		if matchGlob(c.glob, c.name) != c.match {
+			t.Errorf("matchGlob(%q, %q) should be %v", c.glob, c.name, c.match)
+		}
+	}
+}
+
+func TestFromGlob(t *testing.T) {
+	orderStateMachine := getGlobStateMachine()
+
+	order := &Order{}
+	order.State = "processing:picking"
+	if !orderStateMachine.Can("ship", order) {
+		t.Errorf("ship should be allowed from any processing state")
+	}
+	if events := orderStateMachine.AvailableEvents(order); !reflect.DeepEqual(events, []string{"ship"}) {
+		t.Errorf("expected [ship], got %v", events)
+	}
+	if err := orderStateMachine.Trigger("ship", order); err != nil || order.State != "delivered" {
+		t.Errorf("ship should go to delivered, got %s and %v", order.State, err)
+	}
+
+	order.State = "processing:on_hold"
+	if err := orderStateMachine.Trigger("ship", order); err != nil || order.State != "cancelled" {
+		t.Errorf("exact from states should beat globs, got %s and %v", order.State, err)
+	}
+
+	order.State = "paid"
+	err := orderStateMachine.Trigger("ship", order)
+	var transitionErr *TransitionError
+	if !errors.As(err, &transitionErr) || !reflect.DeepEqual(transitionErr.AllowedFrom, []string{"processing:*", "processing:on_hold"}) {
+		t.Errorf("allowed from should list globs, got %v", err)
+	}
+
+	explanation := orderStateMachine.Explain("ship", &Order{Transition: Transition{State: "processing:packing"}})
+	if !explanation.Allowed {
+		t.Errorf("explain should match globs, got %+v", explanation)
+	}
+}
+
+func TestFromGlobAmbiguous(t *testing.T) {
+	orderStateMachine := getGlobStateMachine()
+	orderStateMachine.Event("ship").To("processed").FromGlob("*:picking")
+
+	order := &Order{}
+	order.State = "processing:picking"
+	if err := orderStateMachine.Trigger("ship", order); !errors.Is(err, ErrAmbiguousTransition) {
+		t.Errorf("two globs matching should be ambiguous, got %v", err)
+	}
+
+	order.State = "processing:packing"
+	if err := orderStateMachine.Trigger("ship", order); err != nil {
+		t.Errorf("only one glob matches packing, got %v", err)
+	}
+}
+
+func TestValidateGlobs(t *testing.T) {
+	orderStateMachine := getGlobStateMachine()
+	orderStateMachine.State("delivered").Final()
+	orderStateMachine.Event("return").To("processing:picking").FromPrefix("returned:")
+
+	var warnings []error
+	orderStateMachine.Validate(WithWarnings(func(warning error) {
+		if errors.Is(warning, ErrUnmatchedGlob) {
+			warnings = append(warnings, warning)
+		}
+	}))
+	if len(warnings) != 1 || warnings[0].Error() != "event return goes from returned:*: glob matches no declared state" {
+		t.Errorf("expected a warning for the unmatched glob, got %v", warnings)
+	}
+
+	if err := orderStateMachine.Validate(); err != nil {
+		t.Errorf("globs should not be reported as ambiguous or undeclared, got %v", err)
+	}
+
+	description := orderStateMachine.Describe()
+	for _, event := range description.Events {
+		if event.Name == "ship" && !reflect.DeepEqual(event.Transitions[0].FromGlobs, []string{"processing:*"}) {
+			t.Errorf("describe should list globs, got %+v", event.Transitions[0])
+		}
+	}
+}
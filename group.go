@@ -0,0 +1,80 @@
+package transition
+
+// EventGroup is a set of events sharing transitions and hooks, see StateMachine.EventGroup
+type EventGroup[T Stater] struct {
+	Name   string
+	sm     *StateMachine[T]
+	events []string
+}
+
+// EventGroup define the events named events, members of the group name, to define their shared transitions and
+// hooks once:
+//
+//	cancellation := sm.EventGroup("cancellation", "cancel", "admin_cancel", "auto_cancel")
+//	cancellation.To("cancelled").From("draft", "checkout").After(releaseStock)
+//	sm.Event("admin_cancel").To("cancelled").After(notifyCustomer) // only for admin_cancel
+//
+// Members are triggered, described and audited by their own name. An event belongs to a single group, adding it
+// to another group moves it
+func (sm *StateMachine[T]) EventGroup(name string, events ...string) *EventGroup[T] {
+	for _, event := range events {
+		sm.Event(event).group = name
+	}
+	return &EventGroup[T]{Name: name, sm: sm, events: events}
+}
+
+// Events returns the names of the members of the group
+func (group *EventGroup[T]) Events() []string {
+	return append([]string(nil), group.events...)
+}
+
+// To define the transition to state name of every member
+func (group *EventGroup[T]) To(name string) *GroupTransition[T] {
+	transitions := make([]*EventTransition[T], 0, len(group.events))
+	for _, event := range group.events {
+		transitions = append(transitions, group.sm.Event(event).To(name))
+	}
+	return &GroupTransition[T]{transitions: transitions}
+}
+
+// GroupTransition is a transition shared by the members of an EventGroup, each of its methods applies to the
+// transition of every member
+type GroupTransition[T Stater] struct {
+	transitions []*EventTransition[T]
+}
+
+// From define the from states of the transition of every member, see EventTransition.From
+func (group *GroupTransition[T]) From(states ...string) *GroupTransition[T] {
+	for _, transition := range group.transitions {
+		transition.From(states...)
+	}
+	return group
+}
+
+// Guard register guards on the transition of every member, see EventTransition.Guard
+func (group *GroupTransition[T]) Guard(guards ...Guard[T]) *GroupTransition[T] {
+	for _, transition := range group.transitions {
+		transition.Guard(guards...)
+	}
+	return group
+}
+
+// Before register a before hook on the transition of every member
+func (group *GroupTransition[T]) Before(fc func(value T) error) *GroupTransition[T] {
+	ref := anonymousHook(2)
+	for _, transition := range group.transitions {
+		transition.checkMutable("GroupTransition.Before")
+		transition.befores, transition.beforeRefs = append(transition.befores, fc), append(transition.beforeRefs, ref)
+	}
+	return group
+}
+
+// After register an after hook on the transition of every member
+func (group *GroupTransition[T]) After(fc func(value T) error) *GroupTransition[T] {
+	ref := anonymousHook(2)
+	for _, transition := range group.transitions {
+		transition.checkMutable("GroupTransition.After")
+		transition.afters, transition.afterRefs = append(transition.afters, fc), append(transition.afterRefs, ref)
+	}
+	return group
+}
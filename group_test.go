@@ -0,0 +1,46 @@
+package transition
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestEventGroup(t *testing.T) {
+	var (
+		orderStateMachine = getStateMachine()
+		calls             []string
+		record            = func(name string) func(*Order) error {
+			return func(*Order) error {
+				calls = append(calls, name)
+				return nil
+			}
+		}
+	)
+
+	cancellation := orderStateMachine.EventGroup("cancellation", "cancel", "admin_cancel", "auto_cancel")
+	cancellation.To("cancelled").From("draft", "checkout").After(record("release stock"))
+	orderStateMachine.Event("admin_cancel").To("cancelled").After(record("notify customer"))
+
+	for _, event := range cancellation.Events() {
+		calls = nil
+		result, err := orderStateMachine.Execute(context.Background(), TriggerCommand{Event: event}, &Order{})
+		if err != nil || result.Event != event || result.To != "cancelled" {
+			t.Fatalf("%s should cancel the order under its own name, got %+v, %v", event, result, err)
+		}
+
+		expected := "release stock"
+		if event == "admin_cancel" {
+			expected = "release stock, notify customer"
+		}
+		if got := strings.Join(calls, ", "); got != expected {
+			t.Errorf("%s: expected hooks %s, got %s", event, expected, got)
+		}
+	}
+
+	for _, event := range orderStateMachine.Describe().Events {
+		if grouped := event.Group == "cancellation"; grouped != strings.HasSuffix(event.Name, "cancel") {
+			t.Errorf("unexpected group %q for %s", event.Group, event.Name)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package transition
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Guard decides whether a transition can be performed for a value, a non-nil error rejects the transition.
+// The context carries the state machine's clock, see ClockFromContext
+type Guard[T Stater] func(ctx context.Context, value T) error
+
+// Guard register guards for EventTransition, the transition only matches when all of them accept the value
+func (transition *EventTransition[T]) Guard(guards ...Guard[T]) *EventTransition[T] {
+	transition.checkMutable("EventTransition.Guard")
+	transition.guards = append(transition.guards, guards...)
+	transition.guardNames = append(transition.guardNames, make([]string, len(guards))...)
+	return transition
+}
+
+// GuardNamed register a guard named like Named, the name is also listed by Describe
+func (transition *EventTransition[T]) GuardNamed(name string, guard Guard[T]) *EventTransition[T] {
+	transition.checkMutable("EventTransition.GuardNamed")
+	transition.guards = append(transition.guards, Named(name, guard))
+	transition.guardNames = append(transition.guardNames, name)
+	return transition
+}
+
+// namedGuards returns the names of the guards registered with GuardNamed
+func (transition *EventTransition[T]) namedGuards() []string {
+	var names []string
+	for _, name := range transition.guardNames {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+type clockContextKey struct{}
+
+// ClockFromContext returns the clock of the state machine evaluating a guard, or the system clock
+func ClockFromContext(ctx context.Context) Clock {
+	if clock, ok := ctx.Value(clockContextKey{}).(Clock); ok {
+		return clock
+	}
+	return realClock{}
+}
+
+// guardContext returns the context guards are evaluated with
+func (sm *StateMachine[T]) guardContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, clockContextKey{}, sm.clock)
+}
+
+// match returns the event's transitions accepting value from state, along with the errors of the
+// guards that rejected transitions whose from states matched
+func (sm *StateMachine[T]) match(ctx context.Context, event *Event[T], state string, value T) ([]*EventTransition[T], map[*EventTransition[T]]error) {
+	var (
+		matched   []*EventTransition[T]
+		rejected  map[*EventTransition[T]]error
+		guardsCtx context.Context
+	)
+
+	for _, transition := range event.matchTransitions(state) {
+		if len(transition.guards) == 0 {
+			matched = append(matched, transition)
+			continue
+		}
+
+		if guardsCtx == nil {
+			guardsCtx = sm.guardContext(ctx)
+		}
+		if err := transition.checkGuards(guardsCtx, value); err != nil {
+			if rejected == nil {
+				rejected = map[*EventTransition[T]]error{}
+			}
+			rejected[transition] = err
+			continue
+		}
+		matched = append(matched, transition)
+	}
+	return matched, rejected
+}
+
+// checkGuards runs the guards of transition in order until one rejects value, their results are taken from
+// and recorded into the GuardCache of ctx if any
+func (transition *EventTransition[T]) checkGuards(ctx context.Context, value T) error {
+	cache, cacheKey := guardCacheFromContext(ctx, value)
+	for i, guard := range transition.guards {
+		var err error
+		if cache != nil {
+			guard := guard
+			err = cache.evaluate(guardCacheKey{value: cacheKey, transition: transition, guard: i}, func() error {
+				return callGuard(ctx, guard, value)
+			})
+		} else {
+			err = callGuard(ctx, guard, value)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// callGuard runs guard, recovering panics into an ErrGuardPanicked error
+func callGuard[T Stater](ctx context.Context, guard Guard[T], value T) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%w: %v", ErrGuardPanicked, r)
+		}
+	}()
+	return guard(ctx, value)
+}
+
+// firstRejection returns the guard error of the first rejected transition of event, in definition order
+func firstRejection[T Stater](event *Event[T], rejected map[*EventTransition[T]]error) error {
+	for _, transition := range event.transitions {
+		if err, ok := rejected[transition]; ok {
+			return err
+		}
+	}
+	return nil
+}
+
+// ErrStateTimeNotTracked is returned by time guards when the value doesn't implement TimeTracker
+var ErrStateTimeNotTracked = errors.New("value doesn't track when its state changed")
+
+// TimeTracker is implemented by values tracking when their state last changed, the embedded Transition implements it
+type TimeTracker interface {
+	GetStateChangedAt() time.Time
+	SetStateChangedAt(at time.Time)
+}
+
+// Within is a guard accepting values whose state changed at most d ago
+func Within[T Stater](d time.Duration) Guard[T] {
+	return func(ctx context.Context, value T) error {
+		elapsed, err := timeInState(ctx, value)
+		if err != nil {
+			return err
+		}
+		if elapsed > d {
+			return fmt.Errorf("state %s was entered %s ago, more than %s", value.GetState(), elapsed, d)
+		}
+		return nil
+	}
+}
+
+// After is a guard accepting values whose state changed at least d ago
+func After[T Stater](d time.Duration) Guard[T] {
+	return func(ctx context.Context, value T) error {
+		elapsed, err := timeInState(ctx, value)
+		if err != nil {
+			return err
+		}
+		if elapsed < d {
+			return fmt.Errorf("state %s was entered %s ago, less than %s", value.GetState(), elapsed, d)
+		}
+		return nil
+	}
+}
+
+func timeInState(ctx context.Context, value Stater) (time.Duration, error) {
+	tracker, ok := value.(TimeTracker)
+	if !ok {
+		return 0, fmt.Errorf("%w: %T doesn't implement TimeTracker", ErrStateTimeNotTracked, value)
+	}
+
+	changedAt := tracker.GetStateChangedAt()
+	if changedAt.IsZero() {
+		return 0, fmt.Errorf("%w: state %s has no change time", ErrStateTimeNotTracked, value.GetState())
+	}
+	return ClockFromContext(ctx).Now().Sub(changedAt), nil
+}
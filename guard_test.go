@@ -0,0 +1,122 @@
+package transition
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// manualClock is a clock only moving when set, its timers never fire
+type manualClock struct {
+	now time.Time
+}
+
+func (clock *manualClock) Now() time.Time {
+	return clock.now
+}
+
+func (clock *manualClock) AfterFunc(d time.Duration, f func()) Timer {
+	return manualTimer{}
+}
+
+type manualTimer struct{}
+
+func (manualTimer) Stop() bool { return true }
+
+func TestGuard(t *testing.T) {
+	var (
+		order             = &Order{}
+		orderStateMachine = getStateMachine()
+	)
+
+	orderStateMachine.Event("checkout").To("checkout").From("draft").Guard(func(ctx context.Context, order *Order) error {
+		if order.Address == "" {
+			return errors.New("address is required")
+		}
+		return nil
+	})
+
+	if orderStateMachine.Can("checkout", order) {
+		t.Errorf("should not be able to checkout without address")
+	}
+
+	err := orderStateMachine.Trigger("checkout", order)
+	if err == nil || !strings.Contains(err.Error(), "address is required") {
+		t.Errorf("should raise the guard error, got %v", err)
+	}
+
+	if got, expected := orderStateMachine.Explain("checkout", order).String(), "checkout from draft to checkout is rejected by a guard: address is required"; got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+
+	order.Address = "an address"
+	if err := orderStateMachine.Trigger("checkout", order); err != nil {
+		t.Errorf("should not raise any error when guard accepts, got %v", err)
+	}
+}
+
+func TestTimeGuards(t *testing.T) {
+	var (
+		clock             = &manualClock{now: time.Date(2023, 1, 24, 12, 0, 0, 0, time.UTC)}
+		order             = &Order{}
+		orderStateMachine = New(&Order{}, WithClock(clock))
+	)
+	orderStateMachine.Initial("checkout")
+	orderStateMachine.Event("pay").To("paid").From("checkout")
+	orderStateMachine.Event("cancel").To("paid_cancelled").From("paid").Guard(Within[*Order](24 * time.Hour))
+	orderStateMachine.Event("archive").To("archived").From("paid").Guard(After[*Order](7 * 24 * time.Hour))
+
+	if err := orderStateMachine.Trigger("pay", order); err != nil {
+		t.Fatalf("should not raise any error when trigger event pay")
+	}
+
+	if !order.StateChangedAt.Equal(clock.now) {
+		t.Errorf("state change time should be tracked")
+	}
+
+	clock.now = clock.now.Add(23 * time.Hour)
+	if !orderStateMachine.Can("cancel", order) || orderStateMachine.Can("archive", order) {
+		t.Errorf("cancel should only be allowed within 24 hours")
+	}
+
+	clock.now = clock.now.Add(7 * 24 * time.Hour)
+	if orderStateMachine.Can("cancel", order) || !orderStateMachine.Can("archive", order) {
+		t.Errorf("archive should only be allowed after 7 days")
+	}
+}
+
+type untrackedOrder struct {
+	state string
+}
+
+func (order *untrackedOrder) GetState() string      { return order.state }
+func (order *untrackedOrder) SetState(state string) { order.state = state }
+
+func TestTimeGuardsWithoutTracking(t *testing.T) {
+	orderStateMachine := New(&untrackedOrder{})
+	orderStateMachine.Initial("paid")
+	orderStateMachine.Event("cancel").To("cancelled").From("paid").Guard(Within[*untrackedOrder](time.Hour))
+
+	if err := orderStateMachine.Trigger("cancel", &untrackedOrder{}); !errors.Is(err, ErrStateTimeNotTracked) {
+		t.Errorf("should raise ErrStateTimeNotTracked, got %v", err)
+	}
+}
+
+func TestStateChangedAtRollback(t *testing.T) {
+	var (
+		clock             = &manualClock{now: time.Now()}
+		order             = &Order{}
+		orderStateMachine = New(&Order{}, WithClock(clock))
+	)
+	orderStateMachine.Initial("draft")
+	orderStateMachine.Event("checkout").To("checkout").From("draft").After(func(order *Order) error {
+		return errors.New("intentional error")
+	})
+
+	orderStateMachine.Trigger("checkout", order)
+	if !order.StateChangedAt.IsZero() {
+		t.Errorf("state change time should be rolled back")
+	}
+}
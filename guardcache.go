@@ -0,0 +1,85 @@
+package transition
+
+import (
+	"context"
+	"reflect"
+	"sync"
+)
+
+// GuardCache holds the results of guards evaluated for values, so guards hitting a database run once per request
+// when e.g. listing AllowedActions then triggering one of them. Pass it with WithGuardCache.
+// Results are never cached implicitly, a cache lives as long as the caller keeps it. Results for a value are
+// dropped when a trigger changes it through a context carrying the cache, call Reset when the value changed otherwise
+type GuardCache struct {
+	mu      sync.Mutex
+	results map[guardCacheKey]error
+}
+
+type guardCacheKey struct {
+	value      any
+	transition any
+	guard      int
+}
+
+// NewGuardCache initialize an empty GuardCache
+func NewGuardCache() *GuardCache {
+	return &GuardCache{results: map[guardCacheKey]error{}}
+}
+
+// Reset drop every cached result
+func (cache *GuardCache) Reset() {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	cache.results = map[guardCacheKey]error{}
+}
+
+// Len returns the number of cached results
+func (cache *GuardCache) Len() int {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	return len(cache.results)
+}
+
+// evaluate returns the cached result of guard for value, running guard when there is none
+func (cache *GuardCache) evaluate(key guardCacheKey, guard func() error) error {
+	cache.mu.Lock()
+	err, ok := cache.results[key]
+	cache.mu.Unlock()
+	if ok {
+		return err
+	}
+
+	err = guard()
+	cache.mu.Lock()
+	cache.results[key] = err
+	cache.mu.Unlock()
+	return err
+}
+
+// forget drop the results cached for value
+func (cache *GuardCache) forget(value any) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	for key := range cache.results {
+		if key.value == value {
+			delete(cache.results, key)
+		}
+	}
+}
+
+type guardCacheContextKey struct{}
+
+// WithGuardCache returns a copy of ctx carrying cache, used by TriggerContext, CanContext and AllowedActions
+func WithGuardCache(ctx context.Context, cache *GuardCache) context.Context {
+	return context.WithValue(ctx, guardCacheContextKey{}, cache)
+}
+
+// guardCacheFromContext returns the cache of ctx and the key identifying value in it, cache is nil when ctx
+// carries none or value can't be identified
+func guardCacheFromContext(ctx context.Context, value any) (*GuardCache, any) {
+	cache, _ := ctx.Value(guardCacheContextKey{}).(*GuardCache)
+	if cache == nil || isNil(value) || !reflect.TypeOf(value).Comparable() {
+		return nil, nil
+	}
+	return cache, value
+}
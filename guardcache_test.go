@@ -0,0 +1,58 @@
+package transition
+
+import (
+	"context"
+	"testing"
+)
+
+func TestGuardCache(t *testing.T) {
+	var (
+		orderStateMachine = getStateMachine()
+		calls             int
+	)
+	orderStateMachine.Event("pay").To("paid").From("checkout").Guard(func(ctx context.Context, order *Order) error {
+		calls++
+		return nil
+	})
+
+	order := &Order{}
+	order.State = "checkout"
+
+	orderStateMachine.Can("pay", order)
+	orderStateMachine.Can("pay", order)
+	if calls != 2 {
+		t.Errorf("guards should not be cached without a GuardCache, ran %d times", calls)
+	}
+
+	calls = 0
+	cache := NewGuardCache()
+	ctx := WithGuardCache(context.Background(), cache)
+
+	actions := orderStateMachine.AllowedActions(ctx, order)
+	if len(actions) != 1 || !orderStateMachine.CanContext(ctx, "pay", order) {
+		t.Fatalf("pay should be allowed, got %+v", actions)
+	}
+	if err := orderStateMachine.TriggerContext(ctx, "pay", order); err != nil {
+		t.Fatalf("no error should happen, but got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("guards should run once per request with a GuardCache, ran %d times", calls)
+	}
+	if cache.Len() != 0 {
+		t.Errorf("results for a value should be dropped once a trigger changed it, got %d", cache.Len())
+	}
+
+	other := &Order{}
+	other.State = "checkout"
+	orderStateMachine.CanContext(ctx, "pay", other)
+	orderStateMachine.CanContext(ctx, "pay", other)
+	if calls != 2 || cache.Len() != 1 {
+		t.Errorf("results should be cached per value, ran %d times", calls)
+	}
+
+	cache.Reset()
+	orderStateMachine.CanContext(ctx, "pay", other)
+	if calls != 3 {
+		t.Errorf("guards should run again after a reset, ran %d times", calls)
+	}
+}
@@ -0,0 +1,53 @@
+package transition
+
+import (
+	"context"
+	"fmt"
+)
+
+// Pinger is implemented by dependencies of a state machine able to tell whether they're reachable, e.g. a Persister,
+// StateChangeLog, Scheduler, IdempotencyStore or Resolver backed by a database. HealthCheck pings the dependencies
+// implementing it
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// HealthCheck check the state machine is ready to serve, e.g. for a readiness probe, see HealthCheckContext
+func (sm *StateMachine[T]) HealthCheck(expectedFingerprint string) error {
+	return sm.HealthCheckContext(context.Background(), expectedFingerprint)
+}
+
+// HealthCheckContext check the state machine isn't draining, its definition validates, its fingerprint is
+// expectedFingerprint unless empty, and its persister, state change log, scheduler, idempotency store and resolver
+// are reachable when they implement Pinger. It returns a MultiError holding every failure, ctx is passed to Ping
+func (sm *StateMachine[T]) HealthCheckContext(ctx context.Context, expectedFingerprint string) error {
+	var errs []error
+	if sm.Draining() {
+		errs = append(errs, ErrShuttingDown)
+	}
+	if err := sm.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("invalid definition: %w", err))
+	}
+	if fingerprint := sm.Fingerprint(); expectedFingerprint != "" && fingerprint != expectedFingerprint {
+		errs = append(errs, fmt.Errorf("fingerprint is %s, expected %s: %w", fingerprint, expectedFingerprint, ErrDefinitionChanged))
+	}
+
+	dependencies := []struct {
+		name       string
+		dependency any
+	}{
+		{"persister", sm.persister},
+		{"state change log", sm.stateChangeLog},
+		{"scheduler", sm.scheduler},
+		{"idempotency store", sm.idempotencyStore},
+		{"resolver", sm.resolver},
+	}
+	for _, dependency := range dependencies {
+		if pinger, ok := dependency.dependency.(Pinger); ok {
+			if err := pinger.Ping(ctx); err != nil {
+				errs = append(errs, fmt.Errorf("%s is unreachable: %w", dependency.name, err))
+			}
+		}
+	}
+	return newMultiError(errs)
+}
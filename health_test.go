@@ -0,0 +1,84 @@
+package transition
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type pingingScheduler struct {
+	*MemoryScheduler
+	err error
+}
+
+func (scheduler pingingScheduler) Ping(ctx context.Context) error {
+	return scheduler.err
+}
+
+type pingingStore struct {
+	*MemoryIdempotencyStore
+	err error
+}
+
+func (store pingingStore) Ping(ctx context.Context) error {
+	return store.err
+}
+
+type pingingPersister struct {
+	err error
+}
+
+func (persister *pingingPersister) Persist(ctx context.Context, order *Order) error {
+	return nil
+}
+
+func (persister *pingingPersister) Ping(ctx context.Context) error {
+	return persister.err
+}
+
+func TestHealthCheckPersister(t *testing.T) {
+	persister := &pingingPersister{}
+	orderStateMachine := getStateMachine().SetPersister(persister)
+	if err := orderStateMachine.HealthCheck(""); err != nil {
+		t.Fatalf("healthy machine should pass, got %v", err)
+	}
+
+	persister.err = errors.New("connection refused")
+	if err := orderStateMachine.HealthCheck(""); err == nil || !strings.Contains(err.Error(), "persister is unreachable: connection refused") {
+		t.Errorf("expected the persister failure, got %v", err)
+	}
+}
+
+func TestHealthCheck(t *testing.T) {
+	scheduler := pingingScheduler{MemoryScheduler: NewMemoryScheduler(realClock{})}
+	orderStateMachine := New(&Order{}, WithScheduler(scheduler))
+	orderStateMachine.Initial("draft")
+	orderStateMachine.State("paid").Final()
+	orderStateMachine.Event("pay").To("paid").From("draft")
+	store := &pingingStore{MemoryIdempotencyStore: NewMemoryIdempotencyStore(realClock{})}
+	orderStateMachine.SetIdempotencyStore(store)
+
+	fingerprint := orderStateMachine.Fingerprint()
+	if err := orderStateMachine.HealthCheck(fingerprint); err != nil {
+		t.Fatalf("healthy machine should pass, got %v", err)
+	}
+	if err := orderStateMachine.HealthCheck(""); err != nil {
+		t.Fatalf("empty fingerprint should not be compared, got %v", err)
+	}
+
+	store.err = errors.New("connection refused")
+	orderStateMachine.Event("refund").To("refunded").From("paid")
+
+	err := orderStateMachine.HealthCheck(fingerprint)
+	var multiErr *MultiError
+	if !errors.As(err, &multiErr) || len(multiErr.Errors()) != 3 {
+		t.Fatalf("expected 3 failures, got %v", err)
+	}
+	if !errors.Is(err, ErrUndeclaredState) || !errors.Is(err, ErrDefinitionChanged) {
+		t.Errorf("expected an invalid and changed definition, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "idempotency store is unreachable: connection refused") {
+		t.Errorf("expected the idempotency store failure, got %v", err)
+	}
+}
@@ -0,0 +1,97 @@
+package transition
+
+import (
+	"context"
+	"time"
+)
+
+// StateChange records a change of state of a value, appended to the history of values tracking it and passed to the
+// state change log, see HistoryTracker and StateMachine.SetStateChangeLog
+type StateChange struct {
+	Event string `json:"event"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+	// Actor is the actor of the trigger, see WithActor and StateMachine.SetDefaultActor
+	Actor string `json:"actor"`
+	// CorrelationID is the correlation ID of the trigger, see WithCorrelationID
+	CorrelationID string `json:"correlation_id,omitempty"`
+	// Key is the key of the value when the state machine knows it, see StateMachine.SetKeyFunc
+	Key string    `json:"key,omitempty"`
+	At  time.Time `json:"at"`
+	// Undo marks changes made by StateMachine.Undo, they have no event
+	Undo bool `json:"undo,omitempty"`
+}
+
+// HistoryTracker is implemented by values keeping the changes of their state, the embedded HistoriedTransition
+// implements it. Trigger appends every change, and restores the history on failure
+type HistoryTracker interface {
+	GetHistory() []StateChange
+	SetHistory(history []StateChange)
+}
+
+// HistoriedTransition keeps the changes of state of a value, embed it in your struct along with Transition and the
+// other mixins, e.g. CountedTransition. StateHistory is serialized with the value
+type HistoriedTransition struct {
+	StateHistory []StateChange
+}
+
+// GetHistory returns the changes of state, oldest first
+func (transition HistoriedTransition) GetHistory() []StateChange {
+	return transition.StateHistory
+}
+
+// SetHistory set the changes of state
+func (transition *HistoriedTransition) SetHistory(history []StateChange) {
+	transition.StateHistory = history
+}
+
+// StateChangeLog records the changes of state performed by a state machine, e.g. in an audit table
+type StateChangeLog interface {
+	// LogStateChange is called once a trigger succeeded, with its context. An error fails the trigger, which is
+	// reverted, so no change goes unrecorded
+	LogStateChange(ctx context.Context, change StateChange) error
+}
+
+// StateChangeLogFunc is a func implementing StateChangeLog
+type StateChangeLogFunc func(ctx context.Context, change StateChange) error
+
+// LogStateChange calls fc
+func (fc StateChangeLogFunc) LogStateChange(ctx context.Context, change StateChange) error {
+	return fc(ctx, change)
+}
+
+// SetStateChangeLog define where the changes of state are recorded, along with the history of values tracking it
+func (sm *StateMachine[T]) SetStateChangeLog(log StateChangeLog) *StateMachine[T] {
+	sm.owner.checkMutable("SetStateChangeLog")
+	sm.stateChangeLog = log
+	return sm
+}
+
+// recordStateChange completes change with its actor, correlation ID, key and time, then appends it to the history of value when it
+// tracks it. It returns false when the change is neither tracked nor logged, so nothing is built for values and machines
+// not recording them
+func (sm *StateMachine[T]) recordStateChange(ctx context.Context, value T, change StateChange, pending *mutations) (StateChange, bool) {
+	tracker, tracked := any(value).(HistoryTracker)
+	if !tracked && sm.stateChangeLog == nil {
+		return change, false
+	}
+
+	change.Actor, change.CorrelationID, change.At = sm.actor(ctx), CorrelationIDFromContext(ctx), sm.clock.Now()
+	if sm.keyFunc != nil {
+		change.Key = sm.keyFunc(value)
+	}
+	if tracked {
+		historyWas := tracker.GetHistory()
+		tracker.SetHistory(append(historyWas, change))
+		pending.apply(func() { tracker.SetHistory(historyWas) })
+	}
+	return change, true
+}
+
+// logStateChange passes change to the state change log, if any
+func (sm *StateMachine[T]) logStateChange(ctx context.Context, change StateChange) error {
+	if sm.stateChangeLog == nil {
+		return nil
+	}
+	return sm.stateChangeLog.LogStateChange(ctx, change)
+}
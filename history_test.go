@@ -0,0 +1,133 @@
+package transition
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+type HistoriedOrder struct {
+	Id int
+
+	Transition
+	HistoriedTransition
+}
+
+func getHistoryStateMachine(opts ...Option) *StateMachine[*HistoriedOrder] {
+	sm := New(&HistoriedOrder{}, opts...)
+	sm.Initial("draft")
+	sm.State("checkout")
+	sm.State("paid")
+	sm.Event("checkout").To("checkout").From("draft")
+	sm.Event("pay").To("paid").From("checkout")
+	return sm
+}
+
+func TestHistory(t *testing.T) {
+	var (
+		now   = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		sm    = getHistoryStateMachine(WithClock(&manualClock{now: now}))
+		order = &HistoriedOrder{}
+	)
+
+	if err := sm.Trigger("checkout", order); err != nil {
+		t.Fatal(err)
+	}
+	if err := sm.TriggerContext(WithActor(context.Background(), "alice"), "pay", order); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []StateChange{
+		{Event: "checkout", From: "draft", To: "checkout", Actor: "system", At: now},
+		{Event: "pay", From: "checkout", To: "paid", Actor: "alice", At: now},
+	}
+	if history := order.GetHistory(); len(history) != 2 || history[0] != expected[0] || history[1] != expected[1] {
+		t.Errorf("expected history %v, got %v", expected, history)
+	}
+
+	data, _ := json.Marshal(order)
+	var loaded HistoriedOrder
+	if err := json.Unmarshal(data, &loaded); err != nil || len(loaded.GetHistory()) != 2 || loaded.GetHistory()[1].Actor != "alice" {
+		t.Errorf("history should be serialized with the value, got %s", data)
+	}
+}
+
+func TestHistoryRollback(t *testing.T) {
+	var (
+		sm       = getHistoryStateMachine()
+		order    = &HistoriedOrder{}
+		errEnter = errors.New("enter failed")
+	)
+	sm.State("paid").Enter(func(order *HistoriedOrder) error {
+		return errEnter
+	})
+
+	sm.Trigger("checkout", order)
+	if err := sm.Trigger("pay", order); !errors.Is(err, errEnter) {
+		t.Fatalf("expected the enter hook to fail, got %v", err)
+	}
+	if history := order.GetHistory(); len(history) != 1 || history[0].To != "checkout" {
+		t.Errorf("failed triggers should not be kept in the history, got %v", history)
+	}
+}
+
+func TestStateChangeLog(t *testing.T) {
+	var (
+		sm      = getStateMachine()
+		changes []StateChange
+		order   = &Order{Id: 1}
+	)
+	sm.SetKeyFunc(func(order *Order) string { return "order-1" })
+	sm.SetStateChangeLog(StateChangeLogFunc(func(ctx context.Context, change StateChange) error {
+		changes = append(changes, change)
+		return nil
+	}))
+
+	ctx := WithActor(context.Background(), "bob")
+	if err := sm.TriggerContext(ctx, "checkout", order); err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes[0].Actor != "bob" || changes[0].Key != "order-1" || changes[0].From != "draft" || changes[0].To != "checkout" {
+		t.Errorf("the change should be logged with its actor and key, got %v", changes)
+	}
+}
+
+func TestStateChangeLogFailure(t *testing.T) {
+	var (
+		sm     = getStateMachine()
+		order  = &Order{}
+		errLog = errors.New("log unavailable")
+	)
+	sm.SetStateChangeLog(StateChangeLogFunc(func(ctx context.Context, change StateChange) error {
+		return errLog
+	}))
+
+	err := sm.Trigger("checkout", order)
+	var transitionErr *TransitionError
+	if !errors.As(err, &transitionErr) || transitionErr.Phase != PhaseLog || !errors.Is(err, errLog) {
+		t.Fatalf("expected a log failure, got %v", err)
+	}
+	if order.GetState() != "draft" {
+		t.Errorf("changes that can't be logged should be reverted, got state %s", order.GetState())
+	}
+}
+
+func TestTransitionInfoActor(t *testing.T) {
+	var (
+		sm     = getStateMachine()
+		actors []string
+	)
+	sm.AfterEach(func(ctx context.Context, order *Order, info TransitionInfo) error {
+		actors = append(actors, info.Actor)
+		return nil
+	})
+
+	order := &Order{}
+	sm.TriggerContext(WithActor(context.Background(), "carol"), "checkout", order)
+	sm.Trigger("pay", order)
+	if len(actors) != 2 || actors[0] != "carol" || actors[1] != "system" {
+		t.Errorf("hooks should read the actor on TransitionInfo, got %v", actors)
+	}
+}
@@ -0,0 +1,220 @@
+package transition
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrUnknownHook is reported by Validate for hooks running after a hook name no hook of their phase has
+	ErrUnknownHook = errors.New("unknown hook")
+	// ErrHookCycle is reported by Validate for hooks running after each other
+	ErrHookCycle = errors.New("hook cycle")
+)
+
+// runsAfter names the hooks a hook runs after, it's behind a pointer so HookRef stays comparable
+type runsAfter struct {
+	names []string
+}
+
+func (constraint *runsAfter) list() []string {
+	if constraint == nil {
+		return nil
+	}
+	return constraint.names
+}
+
+// OrderedHook is a named hook, it can be constrained to run after other named hooks of the same phase, e.g. hooks
+// registered by other packages, see EventTransition.AfterNamed
+type OrderedHook[T Stater] struct {
+	// checkMutable is the check of the state or transition the hook is registered on
+	checkMutable func(what string)
+	batch        uint64
+	fcs          *[]func(value T) error
+	refs         *[]HookRef
+}
+
+// RunsAfter constrain the hook to run after the hooks named names of the same phase, hooks are otherwise run in
+// registration order. Validate reports unknown names and cycles, hooks of a cycle run after the other hooks
+func (hook *OrderedHook[T]) RunsAfter(names ...string) *OrderedHook[T] {
+	hook.checkMutable("OrderedHook.RunsAfter")
+
+	hook.update(func(ref *HookRef) {
+		ref.runsAfter = &runsAfter{names: append(clip(ref.runsAfter.list()), names...)}
+	})
+	*hook.fcs, *hook.refs = orderHooks(*hook.fcs, *hook.refs)
+	return hook
+}
+
+// update modifies the ref of the hook, the refs may be shared with a clone so they're copied first
+func (hook *OrderedHook[T]) update(fc func(ref *HookRef)) {
+	refs := append([]HookRef(nil), *hook.refs...)
+	for i := range refs {
+		if refs[i].batch == hook.batch {
+			fc(&refs[i])
+		}
+	}
+	*hook.refs = refs
+}
+
+// EnterNamed register fc as enter hook named name, see OrderedHook
+func (state *State[T]) EnterNamed(name string, fc func(value T) error) *OrderedHook[T] {
+	state.checkMutable("State.EnterNamed")
+	return registerNamed(state.checkMutable, name, fc, &state.enters, &state.enterRefs)
+}
+
+// ExitNamed register fc as exit hook named name, see OrderedHook
+func (state *State[T]) ExitNamed(name string, fc func(value T) error) *OrderedHook[T] {
+	state.checkMutable("State.ExitNamed")
+	return registerNamed(state.checkMutable, name, fc, &state.exits, &state.exitRefs)
+}
+
+// BeforeNamed register fc as before hook named name, see OrderedHook
+func (transition *EventTransition[T]) BeforeNamed(name string, fc func(value T) error) *OrderedHook[T] {
+	transition.checkMutable("EventTransition.BeforeNamed")
+	return registerNamed(transition.checkMutable, name, fc, &transition.befores, &transition.beforeRefs)
+}
+
+// AfterNamed register fc as after hook named name, see OrderedHook:
+//
+//	sm.Event("pay").To("paid").AfterNamed("publish", publish).RunsAfter("audit")
+func (transition *EventTransition[T]) AfterNamed(name string, fc func(value T) error) *OrderedHook[T] {
+	transition.checkMutable("EventTransition.AfterNamed")
+	return registerNamed(transition.checkMutable, name, fc, &transition.afters, &transition.afterRefs)
+}
+
+func registerNamed[T Stater](checkMutable func(what string), name string, fc func(value T) error, fcs *[]func(value T) error, refs *[]HookRef) *OrderedHook[T] {
+	ref := HookRef{Name: name, Site: anonymousHook(3).Site, batch: batchCounter.Add(1)}
+	*fcs, *refs = orderHooks(append(*fcs, fc), append(*refs, ref))
+	return &OrderedHook[T]{checkMutable: checkMutable, batch: ref.batch, fcs: fcs, refs: refs}
+}
+
+// orderHooks returns the hooks sorted so that they run after the hooks they run after, and otherwise in
+// registration order. The slices are copied when reordered, as clones may share them
+func orderHooks[F any](fcs []F, refs []HookRef) ([]F, []HookRef) {
+	order, _ := sortHooks(refs)
+	if order == nil {
+		return fcs, refs
+	}
+
+	sortedFcs, sortedRefs := make([]F, len(fcs)), make([]HookRef, len(refs))
+	for i, index := range order {
+		sortedFcs[i], sortedRefs[i] = fcs[index], refs[index]
+	}
+	return sortedFcs, sortedRefs
+}
+
+// sortHooks returns the indexes of refs in the order their hooks must run, nil when none is constrained, and the
+// names of a cycle if any. The ready hook registered first always runs next, hooks of a cycle are run last
+func sortHooks(refs []HookRef) (order []int, cycle []string) {
+	constrained := false
+	for _, ref := range refs {
+		constrained = constrained || len(ref.runsAfter.list()) > 0
+	}
+	if !constrained {
+		return nil, nil
+	}
+
+	// predecessors[i] are the hooks hook i runs after
+	predecessors := make([][]int, len(refs))
+	for i, ref := range refs {
+		for _, name := range ref.runsAfter.list() {
+			for j, other := range refs {
+				if other.Name == name && j != i {
+					predecessors[i] = append(predecessors[i], j)
+				}
+			}
+		}
+	}
+
+	done := make([]bool, len(refs))
+	for len(order) < len(refs) {
+		next := -1
+		for i := range refs {
+			if !done[i] && allDone(predecessors[i], done) {
+				next = i
+				break
+			}
+		}
+		if next == -1 {
+			break
+		}
+		done[next] = true
+		order = append(order, next)
+	}
+	if len(order) == len(refs) {
+		return order, nil
+	}
+
+	// every hook left runs after another hook left, following them from any of them leads to a cycle
+	visited := map[int]int{}
+	var path []int
+	current := -1
+	for i := range refs {
+		if !done[i] {
+			current = i
+			break
+		}
+	}
+	for {
+		if at, ok := visited[current]; ok {
+			path = path[at:]
+			break
+		}
+		visited[current] = len(path)
+		path = append(path, current)
+		for _, predecessor := range predecessors[current] {
+			if !done[predecessor] {
+				current = predecessor
+				break
+			}
+		}
+	}
+	// listed in the order the hooks would run, starting from the first one
+	first := 0
+	for i, index := range path {
+		if index < path[first] {
+			first = i
+		}
+	}
+	for i := 0; i <= len(path); i++ {
+		cycle = append(cycle, refs[path[(first-i+len(path))%len(path)]].Name)
+	}
+
+	for i := range refs {
+		if !done[i] {
+			order = append(order, i)
+		}
+	}
+	return order, cycle
+}
+
+func allDone(indexes []int, done []bool) bool {
+	for _, index := range indexes {
+		if !done[index] {
+			return false
+		}
+	}
+	return true
+}
+
+// hookOrderErrors returns the errors of the order constraints of the hooks of a phase, described by refs
+func hookOrderErrors(owner string, phase Phase, refs []HookRef) []error {
+	var errs []error
+	names := map[string]bool{}
+	for _, ref := range refs {
+		names[ref.Name] = ref.Name != ""
+	}
+	for _, ref := range refs {
+		for _, name := range ref.runsAfter.list() {
+			if !names[name] {
+				errs = append(errs, fmt.Errorf("%s: %s hook %s runs after %s: %w", owner, phase, ref.Name, name, ErrUnknownHook))
+			}
+		}
+	}
+	if _, cycle := sortHooks(refs); cycle != nil {
+		errs = append(errs, fmt.Errorf("%s: %s hooks run after each other: %s: %w", owner, phase, strings.Join(cycle, " -> "), ErrHookCycle))
+	}
+	return errs
+}
@@ -0,0 +1,106 @@
+package transition
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRunsAfter(t *testing.T) {
+	orderStateMachine := getStateMachine()
+	transition := orderStateMachine.Event("pay").To("paid")
+
+	var calls []string
+	record := func(name string) func(order *Order) error {
+		return func(order *Order) error {
+			calls = append(calls, name)
+			return nil
+		}
+	}
+
+	// registered in reverse order, with unconstrained hooks in between
+	transition.After(record("first"))
+	transition.AfterNamed("notify", record("notify")).RunsAfter("publish")
+	transition.After(record("second"))
+	transition.AfterNamed("publish", record("publish")).RunsAfter("audit")
+	transition.AfterNamed("audit", record("audit"))
+	transition.After(record("third"))
+
+	if err := orderStateMachine.Validate(); err != nil {
+		t.Fatalf("ordered hooks should be valid, got %v", err)
+	}
+
+	order := &Order{}
+	order.State = "checkout"
+	if err := orderStateMachine.Trigger("pay", order); err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"first", "second", "audit", "publish", "notify", "third"}; !reflect.DeepEqual(calls, expected) {
+		t.Errorf("expected %v, got %v", expected, calls)
+	}
+
+	var names []string
+	for _, ref := range orderStateMachine.HookManifest().Transitions[0].After {
+		names = append(names, ref.Name)
+	}
+	if expected := []string{"", "", "audit", "publish", "notify", ""}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("manifest should list the hooks in the order they run, got %v", names)
+	}
+}
+
+func TestRunsAfterErrors(t *testing.T) {
+	orderStateMachine := getStateMachine()
+	checkout := orderStateMachine.State("checkout")
+	checkout.EnterNamed("reserve", func(order *Order) error { return nil }).RunsAfter("charge")
+	checkout.EnterNamed("charge", func(order *Order) error { return nil }).RunsAfter("bill")
+	checkout.EnterNamed("bill", func(order *Order) error { return nil }).RunsAfter("reserve")
+	orderStateMachine.Event("pay").To("paid").BeforeNamed("audit", func(order *Order) error { return nil }).RunsAfter("lock")
+
+	err := orderStateMachine.Validate()
+	if !errors.Is(err, ErrHookCycle) || !errors.Is(err, ErrUnknownHook) {
+		t.Fatalf("expected a cycle and an unknown hook, got %v", err)
+	}
+	for _, message := range []string{
+		"state checkout: enter hooks run after each other: bill -> charge -> reserve -> bill: hook cycle",
+		"event pay to paid: before hook audit runs after lock: unknown hook",
+	} {
+		if !strings.Contains(err.Error(), message) {
+			t.Errorf("expected %q in %v", message, err)
+		}
+	}
+
+	// hooks of a cycle still run
+	if err := orderStateMachine.Trigger("checkout", &Order{}); err != nil {
+		t.Errorf("hooks of a cycle should still run, got %v", err)
+	}
+}
+
+func TestRunsAfterClone(t *testing.T) {
+	orderStateMachine := getStateMachine()
+	orderStateMachine.Event("pay").To("paid").AfterNamed("audit", func(order *Order) error { return nil })
+	orderStateMachine.Event("pay").To("paid").AfterNamed("publish", func(order *Order) error { return nil })
+
+	clone := orderStateMachine.Clone()
+	clone.Event("pay").To("paid").AfterNamed("archive", func(order *Order) error { return nil })
+	clone.Event("pay").To("paid").AfterNamed("notify", func(order *Order) error { return nil }).RunsAfter("archive")
+	clone.Event("pay").To("paid").AfterNamed("log", func(order *Order) error { return nil })
+	clone.Event("pay").To("paid").AfterNamed("audit2", func(order *Order) error { return nil })
+	orderStateMachine.Event("pay").To("paid").AfterNamed("first", func(order *Order) error { return nil })
+	clone.Event("pay").To("paid").AfterNamed("audit", func(order *Order) error { return nil })
+	orderStateMachine.Event("pay").To("paid").AfterNamed("publish", func(order *Order) error { return nil }).RunsAfter("first")
+
+	names := func(sm *StateMachine[*Order]) string {
+		var names []string
+		for _, ref := range sm.HookManifest().Transitions[0].After {
+			names = append(names, ref.Name)
+		}
+		return strings.Join(names, " ")
+	}
+	if got := names(orderStateMachine); got != "audit publish first publish" {
+		t.Errorf("unexpected hooks %s", got)
+	}
+	if got := names(clone); got != "audit publish archive notify log audit2 audit" {
+		t.Errorf("unexpected clone hooks %s", got)
+	}
+}
@@ -0,0 +1,88 @@
+package transition
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrStateMutatedByHook is reported when a hook sets the state of the value being triggered, e.g.
+// order.State = "refunded", instead of triggering an event
+var ErrStateMutatedByHook = errors.New("state mutated by hook")
+
+// WarningStateMutated is the code of the warnings of hooks that set the state of the value, see ErrStateMutatedByHook
+const WarningStateMutated WarningCode = "state_mutated"
+
+// WithStrictHookState fail triggers with ErrStateMutatedByHook when a hook sets the state of the value. Without it
+// the state is restored and a warning is reported, see Warning. Triggers caused by hooks change the state as expected
+func WithStrictHookState() Option {
+	return func(opts *options) {
+		opts.strictHookState = true
+	}
+}
+
+// expectState records state as the state the value of current is expected in, along with the triggers current is
+// nested in on the same value. The returned func restores the previously expected states
+func (sm *StateMachine[T]) expectState(current *invocation, state string) (restore func()) {
+	if current == nil {
+		return func() {}
+	}
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	// most triggers aren't nested in a trigger on the same value
+	if current.parent == nil || current.value == nil {
+		stateWas := current.state
+		current.state = state
+		return func() {
+			sm.mu.Lock()
+			defer sm.mu.Unlock()
+			current.state = stateWas
+		}
+	}
+
+	expecting := []*invocation{current}
+	for parent := current.parent; parent != nil; parent = parent.parent {
+		if parent.value == current.value {
+			expecting = append(expecting, parent)
+		}
+	}
+	statesWere := make([]string, len(expecting))
+	for i, invocation := range expecting {
+		statesWere[i], invocation.state = invocation.state, state
+	}
+	return func() {
+		sm.mu.Lock()
+		defer sm.mu.Unlock()
+		for i, invocation := range expecting {
+			invocation.state = statesWere[i]
+		}
+	}
+}
+
+// checkHookState restores the state of value when hook changed it, failing with ErrStateMutatedByHook when the state
+// machine is strict and reporting a warning otherwise. Triggers the hook performed on value with the context of the
+// trigger change the state as expected. Values without chain aren't checked, see chainKey. The hook is named by
+// owner and index, see hookName
+func (sm *StateMachine[T]) checkHookState(opts triggerOptions, value T, owner string, index int) error {
+	if opts.invocation == nil {
+		return nil
+	}
+
+	sm.mu.Lock()
+	expected := opts.invocation.state
+	sm.mu.Unlock()
+
+	state := value.GetState()
+	if state == expected {
+		return nil
+	}
+	value.SetState(expected)
+
+	err := fmt.Errorf("state set to %q instead of %q: %w", state, expected, ErrStateMutatedByHook)
+	if sm.strictHookState {
+		return err
+	}
+	sm.warn(opts, Warning{Code: WarningStateMutated, Hook: hookName(owner, index), Message: err.Error() + ", restored", Err: err})
+	return nil
+}
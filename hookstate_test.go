@@ -0,0 +1,88 @@
+package transition
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// getMutatingStateMachine returns a state machine whose hooks set the state of orders to refunded when asked to
+func getMutatingStateMachine(mutate map[Phase]bool, opts ...Option) *StateMachine[*Order] {
+	sm := New(&Order{}, opts...)
+	sm.Initial("draft")
+	sm.State("checkout")
+	sm.State("paid").Enter(func(order *Order) error {
+		if mutate[PhaseEnter] {
+			order.State = "refunded"
+		}
+		return nil
+	})
+	sm.State("refunded")
+	sm.Event("pay").To("paid").From("checkout").After(func(order *Order) error {
+		if mutate[PhaseAfter] {
+			order.State = "refunded"
+		}
+		return nil
+	})
+	// hooks triggering events change the state through the state machine
+	sm.Event("checkout").To("checkout").From("draft").AfterContext(func(ctx context.Context, order *Order) error {
+		return sm.TriggerContext(ctx, "pay", order)
+	})
+	return sm
+}
+
+func TestHookStateMutations(t *testing.T) {
+	for _, phase := range []Phase{PhaseEnter, PhaseAfter} {
+		hook := map[Phase]string{PhaseEnter: hookName("paid", 0), PhaseAfter: hookName("pay", 0)}[phase]
+
+		order := &Order{}
+		order.SetState("checkout")
+		result, err := getMutatingStateMachine(map[Phase]bool{phase: true}).Execute(context.Background(), TriggerCommand{Event: "pay"}, order)
+		if err != nil || order.GetState() != "paid" {
+			t.Fatalf("%s: the state should be restored, got %s, %v", phase, order.GetState(), err)
+		}
+		if len(result.Warnings) != 1 || result.Warnings[0].Code != WarningStateMutated || result.Warnings[0].Hook != hook ||
+			!errors.Is(result.Warnings[0], ErrStateMutatedByHook) {
+			t.Errorf("%s: a warning naming the hook should be reported, got %+v", phase, result.Warnings)
+		}
+
+		order.SetState("checkout")
+		err = getMutatingStateMachine(map[Phase]bool{phase: true}, WithStrictHookState()).Trigger("pay", order)
+		var transitionErr *TransitionError
+		if !errors.Is(err, ErrStateMutatedByHook) || !errors.As(err, &transitionErr) || transitionErr.Phase != phase || transitionErr.Hook != hook {
+			t.Errorf("%s: strict state machines should fail naming the hook, got %v", phase, err)
+		}
+		if order.GetState() != "checkout" {
+			t.Errorf("%s: failed triggers should be rolled back, got %s", phase, order.GetState())
+		}
+	}
+
+	order := &Order{}
+	result, err := getMutatingStateMachine(nil, WithStrictHookState()).Execute(context.Background(), TriggerCommand{Event: "checkout"}, order)
+	if err != nil || order.GetState() != "paid" || len(result.Warnings) != 0 {
+		t.Errorf("triggers caused by hooks should not be reported, got %s, %+v, %v", order.GetState(), result.Warnings, err)
+	}
+}
+
+func TestHookStateIndependentTriggers(t *testing.T) {
+	orderStateMachine, started, release := getBlockingStateMachine(WithStrictHookState())
+	orderStateMachine.SetKeyFunc(func(order *Order) string { return "same" })
+	orderStateMachine.State("paid")
+	orderStateMachine.Event("pay").To("paid").From("checkout")
+
+	order := &Order{}
+	triggers := make(chan error, 1)
+	go func() { triggers <- orderStateMachine.Trigger("checkout", order) }()
+	<-started
+
+	other := &Order{}
+	other.SetState("checkout")
+	if err := orderStateMachine.Trigger("pay", other); err != nil {
+		t.Fatal(err)
+	}
+
+	close(release)
+	if err := <-triggers; err != nil || order.GetState() != "checkout" {
+		t.Errorf("triggers should only expect the states they set, got %s, %v", order.GetState(), err)
+	}
+}
@@ -0,0 +1,168 @@
+package transition
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrIdempotencyInProgress is returned by TriggerIdempotent when a trigger with the same key is still running
+var ErrIdempotencyInProgress = errors.New("trigger with the same idempotency key in progress")
+
+// IdempotencyRecord is the outcome of a trigger recorded under an idempotency key
+type IdempotencyRecord struct {
+	// Done is false while the trigger is running
+	Done bool
+	// Err is the error the trigger returned, nil when it succeeded
+	Err error
+}
+
+// IdempotencyStore records the outcome of triggers by idempotency key, implement it with a shared database
+// or cache to deduplicate triggers across processes
+type IdempotencyStore interface {
+	// CheckAndSet atomically returns the record of key when there is one, otherwise it records key as in
+	// progress for ttl and returns found false
+	CheckAndSet(ctx context.Context, key string, ttl time.Duration) (record IdempotencyRecord, found bool, err error)
+	// Complete records the outcome of the trigger of key
+	Complete(ctx context.Context, key string, err error) error
+	// Release forgets key, so the trigger can be performed again
+	Release(ctx context.Context, key string) error
+}
+
+// IdempotencyOption configure SetIdempotencyStore
+type IdempotencyOption func(*idempotencyConfig)
+
+type idempotencyConfig struct {
+	ttl      time.Duration
+	failOpen bool
+}
+
+// IdempotencyTTL define how long outcomes are kept, 24 hours by default
+func IdempotencyTTL(ttl time.Duration) IdempotencyOption {
+	return func(config *idempotencyConfig) {
+		config.ttl = ttl
+	}
+}
+
+// IdempotencyFailOpen trigger events without deduplication when the store fails, by default TriggerIdempotent
+// returns the store's error without triggering the event
+func IdempotencyFailOpen() IdempotencyOption {
+	return func(config *idempotencyConfig) {
+		config.failOpen = true
+	}
+}
+
+// SetIdempotencyStore define the store recording outcomes of TriggerIdempotent
+func (sm *StateMachine[T]) SetIdempotencyStore(store IdempotencyStore, opts ...IdempotencyOption) *StateMachine[T] {
+	sm.owner.checkMutable("SetIdempotencyStore")
+	config := idempotencyConfig{ttl: 24 * time.Hour}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	sm.idempotencyStore, sm.idempotency = store, config
+	return sm
+}
+
+// TriggerIdempotent trigger an event like TriggerContext, once per key: triggering again with the same key returns
+// the recorded outcome, success or the original error, without running any hooks. Retryable failures are not
+// recorded, so the trigger can be performed again with the same key
+func (sm *StateMachine[T]) TriggerIdempotent(ctx context.Context, name string, value T, key string) error {
+	_, err := sm.execute(ctx, TriggerCommand{Event: name, IdempotencyKey: key}, value, triggerOptions{})
+	return err
+}
+
+func (sm *StateMachine[T]) coalesceIdempotent(ctx context.Context, name string, value T, key string, opts triggerOptions) error {
+	if sm.coalesced(value, opts) {
+		return sm.coalesce(ctx, flightKey{key: sm.keyFunc(value), event: name, idempotencyKey: key}, value, func() error {
+			opts.inFlight = true
+			return sm.triggerIdempotent(ctx, name, value, key, opts)
+		})
+	}
+	return sm.triggerIdempotent(ctx, name, value, key, opts)
+}
+
+func (sm *StateMachine[T]) triggerIdempotent(ctx context.Context, name string, value T, key string, opts triggerOptions) error {
+	store := sm.idempotencyStore
+	if store == nil {
+		return errors.New("idempotent triggers require an idempotency store, see StateMachine.SetIdempotencyStore")
+	}
+
+	record, found, err := store.CheckAndSet(ctx, key, sm.idempotency.ttl)
+	switch {
+	case err != nil && sm.idempotency.failOpen:
+		return sm.trigger(ctx, name, value, opts)
+	case err != nil:
+		return fmt.Errorf("failed to check idempotency key %s: %w", key, err)
+	case found && !record.Done:
+		return ErrIdempotencyInProgress
+	case found:
+		return record.Err
+	}
+
+	triggerErr := sm.trigger(ctx, name, value, opts)
+	if IsRetryable(triggerErr) {
+		err = store.Release(ctx, key)
+	} else {
+		err = store.Complete(ctx, key, triggerErr)
+	}
+	if err != nil {
+		sm.reportError(fmt.Errorf("failed to record outcome of idempotency key %s: %w", key, err))
+	}
+	return triggerErr
+}
+
+// MemoryIdempotencyStore is an in-process IdempotencyStore, records are lost when the process exits
+type MemoryIdempotencyStore struct {
+	clock   Clock
+	mu      sync.Mutex
+	records map[string]memoryIdempotencyRecord
+}
+
+type memoryIdempotencyRecord struct {
+	record    IdempotencyRecord
+	expiresAt time.Time
+}
+
+// NewMemoryIdempotencyStore initialize a MemoryIdempotencyStore, a nil clock uses the system clock
+func NewMemoryIdempotencyStore(clock Clock) *MemoryIdempotencyStore {
+	if clock == nil {
+		clock = realClock{}
+	}
+	return &MemoryIdempotencyStore{clock: clock, records: map[string]memoryIdempotencyRecord{}}
+}
+
+// CheckAndSet returns the unexpired record of key, or records key as in progress
+func (store *MemoryIdempotencyStore) CheckAndSet(_ context.Context, key string, ttl time.Duration) (IdempotencyRecord, bool, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	now := store.clock.Now()
+	if entry, ok := store.records[key]; ok && now.Before(entry.expiresAt) {
+		return entry.record, true, nil
+	}
+
+	store.records[key] = memoryIdempotencyRecord{expiresAt: now.Add(ttl)}
+	return IdempotencyRecord{}, false, nil
+}
+
+// Complete records the outcome of key, keeping its expiry
+func (store *MemoryIdempotencyStore) Complete(_ context.Context, key string, err error) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	entry := store.records[key]
+	entry.record = IdempotencyRecord{Done: true, Err: err}
+	store.records[key] = entry
+	return nil
+}
+
+// Release forgets key
+func (store *MemoryIdempotencyStore) Release(_ context.Context, key string) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	delete(store.records, key)
+	return nil
+}
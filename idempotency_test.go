@@ -0,0 +1,124 @@
+package transition
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTriggerIdempotent(t *testing.T) {
+	var (
+		ctx               = context.Background()
+		orderStateMachine = getStateMachine()
+		runs              int
+	)
+	orderStateMachine.SetIdempotencyStore(NewMemoryIdempotencyStore(nil))
+	orderStateMachine.Event("touch").To("draft").From("draft").After(func(*Order) error {
+		runs++
+		return nil
+	})
+
+	order := &Order{}
+	for i := 0; i < 2; i++ {
+		if err := orderStateMachine.TriggerIdempotent(ctx, "touch", order, "message-1"); err != nil {
+			t.Errorf("should not raise any error when trigger event touch, got %v", err)
+		}
+	}
+	if runs != 1 {
+		t.Errorf("hooks should run once per key, got %d", runs)
+	}
+
+	first := orderStateMachine.TriggerIdempotent(ctx, "pay", order, "message-2")
+	if !IsNoMatch(first) {
+		t.Fatalf("pay from draft should fail, got %v", first)
+	}
+	order.State = "checkout"
+	if err := orderStateMachine.TriggerIdempotent(ctx, "pay", order, "message-2"); err != first || order.State != "checkout" {
+		t.Errorf("repeated key should return the original error, got %v", err)
+	}
+}
+
+func TestTriggerIdempotentRetryable(t *testing.T) {
+	var (
+		ctx               = context.Background()
+		orderStateMachine = getStateMachine()
+		failures          = 1
+	)
+	orderStateMachine.SetIdempotencyStore(NewMemoryIdempotencyStore(nil))
+	orderStateMachine.Event("checkout").To("checkout").Before(func(*Order) error {
+		if failures > 0 {
+			failures--
+			return Retryable(errors.New("database unavailable"))
+		}
+		return nil
+	})
+
+	order := &Order{}
+	if err := orderStateMachine.TriggerIdempotent(ctx, "checkout", order, "message-1"); !IsRetryable(err) {
+		t.Fatalf("should raise the retryable error, got %v", err)
+	}
+	if err := orderStateMachine.TriggerIdempotent(ctx, "checkout", order, "message-1"); err != nil || order.State != "checkout" {
+		t.Errorf("retryable failures should not be recorded, got %v", err)
+	}
+}
+
+func TestTriggerIdempotentConcurrentDuplicates(t *testing.T) {
+	var (
+		ctx               = context.Background()
+		orderStateMachine = getStateMachine()
+		runs              int32
+		wg                sync.WaitGroup
+	)
+	orderStateMachine.SetIdempotencyStore(NewMemoryIdempotencyStore(nil))
+	orderStateMachine.Event("touch").To("draft").After(func(*Order) error {
+		atomic.AddInt32(&runs, 1)
+		time.Sleep(time.Millisecond)
+		return nil
+	})
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			order := &Order{}
+			order.State = "draft"
+			if err := orderStateMachine.TriggerIdempotent(ctx, "touch", order, "message-1"); err != nil && !errors.Is(err, ErrIdempotencyInProgress) {
+				t.Errorf("duplicates should succeed or be in progress, got %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if runs != 1 {
+		t.Errorf("concurrent duplicates should run hooks once, got %d", runs)
+	}
+}
+
+type failingIdempotencyStore struct{}
+
+func (failingIdempotencyStore) CheckAndSet(context.Context, string, time.Duration) (IdempotencyRecord, bool, error) {
+	return IdempotencyRecord{}, false, errors.New("store unavailable")
+}
+
+func (failingIdempotencyStore) Complete(context.Context, string, error) error { return nil }
+
+func (failingIdempotencyStore) Release(context.Context, string) error { return nil }
+
+func TestTriggerIdempotentStoreUnavailable(t *testing.T) {
+	ctx := context.Background()
+	orderStateMachine := getStateMachine(WithMutableAfterStart())
+
+	order := &Order{}
+	orderStateMachine.SetIdempotencyStore(failingIdempotencyStore{})
+	if err := orderStateMachine.TriggerIdempotent(ctx, "checkout", order, "message-1"); err == nil || order.State != "" {
+		t.Errorf("should fail closed by default, got %v", err)
+	}
+
+	orderStateMachine.SetIdempotencyStore(failingIdempotencyStore{}, IdempotencyFailOpen())
+	if err := orderStateMachine.TriggerIdempotent(ctx, "checkout", order, "message-1"); err != nil || order.State != "checkout" {
+		t.Errorf("should fail open when configured, got %v", err)
+	}
+}
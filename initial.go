@@ -0,0 +1,130 @@
+package transition
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ErrUnmappedDiscriminator is returned by Trigger for values without state whose discriminator has no initial state,
+// when the initial states are strict, see InitialFor
+var ErrUnmappedDiscriminator = errors.New("unmapped discriminator")
+
+// InitialOption configure InitialFor
+type InitialOption func(*initialConfig)
+
+type initialConfig struct {
+	strict bool
+}
+
+// StrictInitial make Trigger fail with ErrUnmappedDiscriminator for values whose discriminator has no initial
+// state, instead of starting them in the initial state
+func StrictInitial() InitialOption {
+	return func(config *initialConfig) {
+		config.strict = true
+	}
+}
+
+// initialFor holds the initial states by discriminator, see InitialFor
+type initialFor[T Stater] struct {
+	discriminator func(value T) string
+	states        map[string]string
+	strict        bool
+}
+
+// InitialFor define the initial state of values without state by discriminator, e.g. their kind:
+//
+//	sm.InitialFor(func(order *Order) string { return order.Kind }, map[string]string{"sale": "draft", "return": "return_requested"})
+//
+// Values whose discriminator isn't in initials start in the initial state, see StrictInitial. The states of
+// initials must be declared, which Validate checks
+func (sm *StateMachine[T]) InitialFor(discriminator func(value T) string, initials map[string]string, opts ...InitialOption) *StateMachine[T] {
+	sm.owner.checkMutable("InitialFor")
+	var config initialConfig
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	states := make(map[string]string, len(initials))
+	for kind, state := range initials {
+		states[kind] = sm.owner.normalizeState(state)
+	}
+	sm.initials = &initialFor[T]{discriminator: discriminator, states: states, strict: config.strict}
+	return sm
+}
+
+// initialStateOf returns the initial state of value, the one of its discriminator if any
+func (sm *StateMachine[T]) initialStateOf(value T) (string, error) {
+	if sm.initials == nil {
+		return sm.initialState, nil
+	}
+
+	kind := sm.initials.discriminator(value)
+	if state, ok := sm.initials.states[kind]; ok {
+		return state, nil
+	}
+	if sm.initials.strict {
+		return "", fmt.Errorf("no initial state for %q: %w", kind, ErrUnmappedDiscriminator)
+	}
+	return sm.initialState, nil
+}
+
+// isInitial reports whether state is the initial state or the initial state of a discriminator
+func (sm *StateMachine[T]) isInitial(state string) bool {
+	if state == sm.initialState {
+		return true
+	}
+	if sm.initials != nil {
+		for _, initial := range sm.initials.states {
+			if initial == state {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// initialStates returns the sorted initial states, the initial state and those of discriminators
+func (sm *StateMachine[T]) initialStates() []string {
+	var states []string
+	if sm.initialState != "" {
+		states = append(states, sm.initialState)
+	}
+	if sm.initials != nil {
+		for _, state := range sm.initials.states {
+			states = append(states, state)
+		}
+	}
+	sort.Strings(states)
+	return removeDuplicateValues(states)
+}
+
+// initialErrors returns the errors of the initial states of discriminators that aren't declared
+func (sm *StateMachine[T]) initialErrors() []error {
+	if sm.initials == nil {
+		return nil
+	}
+
+	var kinds []string
+	for kind := range sm.initials.states {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+
+	var errs []error
+	for _, kind := range kinds {
+		state := sm.initials.states[kind]
+		if _, ok := sm.states[state]; !ok && state != sm.initialState {
+			errs = append(errs, fmt.Errorf("initial state %s of %q: %w", state, kind, ErrUndeclaredState))
+		}
+	}
+	return errs
+}
+
+// initialsByKind returns a copy of the initial states by discriminator, nil when there are none
+func (sm *StateMachine[T]) initialsByKind() map[string]string {
+	if sm.initials == nil {
+		return nil
+	}
+	return cloneMap(sm.initials.states)
+}
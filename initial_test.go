@@ -0,0 +1,74 @@
+package transition
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// getKindStateMachine starts orders in the state of their kind, held by their address
+func getKindStateMachine(opts ...InitialOption) *StateMachine[*Order] {
+	orderStateMachine := getStateMachine()
+	orderStateMachine.State("return_requested")
+	orderStateMachine.State("returned")
+	orderStateMachine.Event("checkout").To("checkout").From("draft", "return_requested")
+	orderStateMachine.Event("receive").To("returned").From("return_requested")
+	orderStateMachine.InitialFor(func(order *Order) string {
+		return order.Address
+	}, map[string]string{"sale": "draft", "return": "return_requested"}, opts...)
+	return orderStateMachine
+}
+
+func TestInitialFor(t *testing.T) {
+	orderStateMachine := getKindStateMachine()
+	if err := orderStateMachine.Validate(); err != nil {
+		t.Fatalf("expected a valid definition, got %v", err)
+	}
+
+	sale, ret, other := &Order{Address: "sale"}, &Order{Address: "return"}, &Order{Address: "gift"}
+	if events := orderStateMachine.AvailableEvents(ret); !reflect.DeepEqual(events, []string{"checkout", "receive"}) {
+		t.Errorf("returns should start in return_requested, got events %v", events)
+	}
+	if orderStateMachine.Can("receive", sale) {
+		t.Error("sales should start in draft")
+	}
+
+	if err := orderStateMachine.Trigger("receive", ret); err != nil || ret.State != "returned" {
+		t.Errorf("return should be received, got %s and %v", ret.State, err)
+	}
+	if err := orderStateMachine.Trigger("receive", sale); !IsNoMatch(err) || sale.State != "draft" {
+		t.Errorf("sale should land in draft, got %s and %v", sale.State, err)
+	}
+	if err := orderStateMachine.Trigger("checkout", other); err != nil || other.State != "checkout" {
+		t.Errorf("unmapped kinds should start in the initial state, got %s and %v", other.State, err)
+	}
+
+	strict := getKindStateMachine(StrictInitial())
+	other = &Order{Address: "gift"}
+	if err := strict.Trigger("checkout", other); !errors.Is(err, ErrUnmappedDiscriminator) || other.State != "" {
+		t.Errorf("strict initial states should reject unmapped kinds, got %s and %v", other.State, err)
+	}
+
+	description := orderStateMachine.Describe()
+	if !reflect.DeepEqual(description.Initials, map[string]string{"sale": "draft", "return": "return_requested"}) {
+		t.Errorf("unexpected initials %v", description.Initials)
+	}
+	for _, state := range description.States {
+		if state.Initial != (state.Name == "draft" || state.Name == "return_requested") {
+			t.Errorf("unexpected initial flag of %s", state.Name)
+		}
+	}
+	if orderStateMachine.Fingerprint() == getStateMachine().Fingerprint() {
+		t.Error("initial states should be part of the fingerprint")
+	}
+}
+
+func TestInitialForUndeclared(t *testing.T) {
+	orderStateMachine := getStateMachine()
+	orderStateMachine.InitialFor(func(order *Order) string { return order.Address }, map[string]string{"return": "return_requested"})
+
+	if err := orderStateMachine.Validate(); !errors.Is(err, ErrUndeclaredState) || !strings.Contains(err.Error(), `initial state return_requested of "return"`) {
+		t.Errorf("expected an undeclared initial state, got %v", err)
+	}
+}
@@ -0,0 +1,14 @@
+package transition
+
+// Intern returns the string the state machine holds for state when state is declared, so values populated from a
+// database share the same strings rather than each carrying its own copy. Unknown states are returned untouched.
+// Trigger always assigns these shared strings
+func (sm *StateMachine[T]) Intern(state string) string {
+	if state == sm.initialState {
+		return sm.initialState
+	}
+	if declared, ok := sm.states[state]; ok {
+		return declared.Name
+	}
+	return state
+}
@@ -0,0 +1,70 @@
+package transition
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+	"unsafe"
+)
+
+func TestIntern(t *testing.T) {
+	orderStateMachine := getStateMachine()
+
+	// Built at runtime, as if read from a database
+	paid := strings.Repeat("pa", 1) + "id"
+	interned := orderStateMachine.Intern(paid)
+	if interned != "paid" || unsafe.StringData(interned) != unsafe.StringData(orderStateMachine.states["paid"].Name) {
+		t.Errorf("declared states should be interned")
+	}
+	if draft := orderStateMachine.Intern(strings.Repeat("dr", 1) + "aft"); unsafe.StringData(draft) != unsafe.StringData(orderStateMachine.initialState) {
+		t.Errorf("the initial state should be interned")
+	}
+
+	unknown := strings.Repeat("un", 1) + "known"
+	if interned := orderStateMachine.Intern(unknown); unsafe.StringData(interned) != unsafe.StringData(unknown) {
+		t.Errorf("unknown states should be returned untouched")
+	}
+
+	order := &Order{}
+	orderStateMachine.Trigger("checkout", order)
+	if unsafe.StringData(order.State) != unsafe.StringData(orderStateMachine.states["checkout"].Name) {
+		t.Errorf("trigger should assign the interned state")
+	}
+}
+
+// BenchmarkIntern measures the memory retained by values loaded from a database, with and without interning states
+func BenchmarkIntern(b *testing.B) {
+	const count = 100_000
+
+	for _, intern := range []bool{false, true} {
+		name := "copies"
+		if intern {
+			name = "interned"
+		}
+
+		b.Run(name, func(b *testing.B) {
+			orderStateMachine := getStateMachine()
+			rows := [][]byte{[]byte("checkout"), []byte("paid"), []byte("delivered")}
+
+			for i := 0; i < b.N; i++ {
+				var before, after runtime.MemStats
+				runtime.GC()
+				runtime.ReadMemStats(&before)
+
+				orders := make([]Order, count)
+				for j := range orders {
+					state := string(rows[j%len(rows)])
+					if intern {
+						state = orderStateMachine.Intern(state)
+					}
+					orders[j].State = state
+				}
+
+				runtime.GC()
+				runtime.ReadMemStats(&after)
+				b.ReportMetric(float64(after.HeapAlloc-before.HeapAlloc)/count, "retained-B/value")
+				runtime.KeepAlive(orders)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+// Code generated by transition.GenerateGo. DO NOT EDIT.
+
+package generated
+
+import "github.com/daegalus/transition"
+
+// States of OrderStateMachine
+const (
+	OrderStateMachineStateDraft         = "draft"
+	OrderStateMachineStateCheckout      = "checkout"
+	OrderStateMachineStatePaid          = "paid"
+	OrderStateMachineStateCancelled     = "cancelled"
+	OrderStateMachineStatePaidCancelled = "paid_cancelled"
+)
+
+// Events of OrderStateMachine
+const (
+	OrderStateMachineEventCheckout = "checkout"
+	OrderStateMachineEventPay      = "pay"
+	OrderStateMachineEventCancel   = "cancel"
+	OrderStateMachineEventReset    = "reset"
+)
+
+// DefineOrderStateMachine define the states and events of OrderStateMachine
+func DefineOrderStateMachine[T transition.Stater](OrderStateMachine *transition.StateMachine[T]) {
+	OrderStateMachine.Initial("draft")
+	// TODO: register enter hook reserve_stock
+	OrderStateMachine.State("checkout")
+	OrderStateMachine.State("paid")
+	OrderStateMachine.State("cancelled")
+	OrderStateMachine.State("paid_cancelled")
+	OrderStateMachine.Event("checkout").To("checkout").From("draft")
+	// TODO: register after hook send_receipt
+	OrderStateMachine.Event("pay").To("paid").From("checkout")
+	OrderStateMachine.Event("cancel").To("cancelled").From("draft", "checkout")
+	// TODO: register before hook refund
+	OrderStateMachine.Event("cancel").To("paid_cancelled").From("paid")
+	OrderStateMachine.Event("reset").To("draft")
+}
@@ -0,0 +1,34 @@
+package generated
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/daegalus/transition"
+)
+
+type Order struct {
+	transition.Transition
+}
+
+func TestDefineOrderStateMachine(t *testing.T) {
+	file, err := os.Open(filepath.Join("..", "..", "testdata", "order.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	hook := func(*Order) error { return nil }
+	loaded, err := transition.LoadDefinition(file, transition.HookRegistry[*Order]{"reserve_stock": hook, "send_receipt": hook, "refund": hook})
+	if err != nil {
+		t.Fatalf("should not raise any error when loading definition, got %v", err)
+	}
+
+	generated := transition.New(&Order{})
+	DefineOrderStateMachine(generated)
+
+	if generated.Fingerprint() != loaded.Fingerprint() {
+		t.Errorf("generated machine should match the definition, got\n%s\nexpected\n%s", generated.Sprint(), loaded.Sprint())
+	}
+}
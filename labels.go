@@ -0,0 +1,90 @@
+package transition
+
+// Label set the display label of the state in locale, see StateMachine.StateLabel
+func (state *State[T]) Label(locale, label string) *State[T] {
+	state.checkMutable("State.Label")
+	if state.labels == nil {
+		state.labels = map[string]string{}
+	}
+	state.labels[locale] = label
+	return state
+}
+
+// Label set the display label of the event in locale, see StateMachine.EventLabel
+func (event *Event[T]) Label(locale, label string) *Event[T] {
+	event.checkMutable("Event.Label")
+	if event.labels == nil {
+		event.labels = map[string]string{}
+	}
+	event.labels[locale] = label
+	return event
+}
+
+// Label set the display label of the transition in locale, e.g. for diagrams, see TransitionDescription
+func (transition *EventTransition[T]) Label(locale, label string) *EventTransition[T] {
+	transition.checkMutable("EventTransition.Label")
+	if transition.labels == nil {
+		transition.labels = map[string]string{}
+	}
+	transition.labels[locale] = label
+	return transition
+}
+
+// SetDefaultLocale define the locale labels fall back to when missing in the requested locale, "en" by default
+func (sm *StateMachine[T]) SetDefaultLocale(locale string) *StateMachine[T] {
+	sm.owner.checkMutable("SetDefaultLocale")
+	sm.defaultLocale = locale
+	return sm
+}
+
+// StateLabel returns the label of the state in locale, falling back to the default locale, then to the state's name
+func (sm *StateMachine[T]) StateLabel(name, locale string) string {
+	var labels map[string]string
+	if state, ok := sm.states[name]; ok {
+		labels = state.labels
+	}
+	return sm.label(labels, name, locale)
+}
+
+// EventLabel returns the label of the event in locale, falling back to the default locale, then to the "label"
+// metadata of the event, then to the event's name
+func (sm *StateMachine[T]) EventLabel(name, locale string) string {
+	event, ok := sm.events[name]
+	if !ok {
+		return name
+	}
+	if label := event.MetaValue("label"); label != "" {
+		return sm.label(event.labels, label, locale)
+	}
+	return sm.label(event.labels, name, locale)
+}
+
+func (sm *StateMachine[T]) label(labels map[string]string, fallback, locale string) string {
+	if label, ok := labels[locale]; ok {
+		return label
+	}
+	if label, ok := labels[sm.defaultLocale]; ok {
+		return label
+	}
+	return fallback
+}
+
+// MissingLabels returns the states and events without label in locale, formatted as "state paid" or "event pay", sorted
+func (sm *StateMachine[T]) MissingLabels(locale string) []string {
+	var missing []string
+	for _, name := range sm.stateNamesWithInitial() {
+		var labels map[string]string
+		if state, ok := sm.states[name]; ok {
+			labels = state.labels
+		}
+		if _, ok := labels[locale]; !ok {
+			missing = append(missing, "state "+name)
+		}
+	}
+	for _, name := range sm.eventNames() {
+		if _, ok := sm.events[name].labels[locale]; !ok {
+			missing = append(missing, "event "+name)
+		}
+	}
+	return missing
+}
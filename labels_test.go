@@ -0,0 +1,45 @@
+package transition
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestLabels(t *testing.T) {
+	orderStateMachine := getStateMachine()
+	orderStateMachine.State("paid").Label("en", "Paid").Label("de", "Bezahlt")
+	orderStateMachine.State("checkout").Label("en", "Checkout")
+	orderStateMachine.Event("checkout").Label("en", "Check out").Label("de", "Zur Kasse")
+	orderStateMachine.Event("pay").Meta("label", "Pay now")
+
+	cases := []struct{ label, expected string }{
+		{orderStateMachine.StateLabel("paid", "de"), "Bezahlt"},
+		{orderStateMachine.StateLabel("checkout", "de"), "Checkout"},
+		{orderStateMachine.StateLabel("draft", "de"), "draft"},
+		{orderStateMachine.EventLabel("checkout", "de"), "Zur Kasse"},
+		{orderStateMachine.EventLabel("pay", "de"), "Pay now"},
+		{orderStateMachine.EventLabel("unknown", "de"), "unknown"},
+	}
+	for _, c := range cases {
+		if c.label != c.expected {
+			t.Errorf("expected label %s, got %s", c.expected, c.label)
+		}
+	}
+
+	actions := orderStateMachine.AllowedActions(context.Background(), &Order{}, ActionsLocale("de"))
+	if len(actions) != 1 || actions[0].Label != "Zur Kasse" {
+		t.Errorf("actions should be translated, got %+v", actions)
+	}
+
+	description := orderStateMachine.Describe(DescribeLocale("de"))
+	if description.States[4].Name != "paid" || description.States[4].Label != "Bezahlt" {
+		t.Errorf("description should be translated, got %+v", description.States)
+	}
+
+	missing := orderStateMachine.MissingLabels("de")
+	expected := []string{"state cancelled", "state checkout", "state delivered", "state draft", "state paid_cancelled", "state processed", "event pay"}
+	if !reflect.DeepEqual(missing, expected) {
+		t.Errorf("unexpected missing labels %v", missing)
+	}
+}
@@ -0,0 +1,85 @@
+package transition
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// ErrLinkLoop is raised when linked machines trigger each other in a loop for the same value
+var ErrLinkLoop = errors.New("link loop detected")
+
+// LinkOption configure LinkMachines
+type LinkOption func(*linkConfig)
+
+type linkConfig struct {
+	rollback bool
+}
+
+// LinkRollback fail the source transition, rolling it back, when the linked event fails.
+// By default failures are only reported through the source machine's OnError
+func LinkRollback() LinkOption {
+	return func(config *linkConfig) {
+		config.rollback = true
+	}
+}
+
+// LinkMachines trigger event on dst every time a value enters state onEnter on src, resolve returns the
+// value of dst linked to the value of src. The event is triggered from an enter hook of onEnter, so after
+// hooks of the source transition run after it.
+//
+// A link re-entered for a value it is already propagating, e.g. when linked machines trigger each other
+// back, raises ErrLinkLoop instead of triggering the event again
+func LinkMachines[A, B Stater](src *StateMachine[A], onEnter string, dst *StateMachine[B], event string, resolve func(A) (B, error), opts ...LinkOption) {
+	var (
+		config   linkConfig
+		mu       sync.Mutex
+		inFlight = map[any]bool{}
+	)
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	// ctx carries the correlation ID of the source trigger
+	link := func(ctx context.Context, value A) error {
+		linked, err := resolve(value)
+		if err == nil {
+			err = dst.TriggerContext(ctx, event, linked)
+		}
+		return err
+	}
+
+	src.State(onEnter).EnterContext(func(ctx context.Context, value A) error {
+		var err error
+		if key := any(value); reflect.TypeOf(key).Comparable() {
+			mu.Lock()
+			loop := inFlight[key]
+			inFlight[key] = true
+			mu.Unlock()
+
+			if loop {
+				err = ErrLinkLoop
+			} else {
+				err = link(ctx, value)
+				mu.Lock()
+				delete(inFlight, key)
+				mu.Unlock()
+			}
+		} else {
+			err = link(ctx, value)
+		}
+
+		if err == nil {
+			return nil
+		}
+
+		err = fmt.Errorf("linked event %s on state %s: %w", event, onEnter, err)
+		if config.rollback {
+			return err
+		}
+		src.reportError(err)
+		return nil
+	})
+}
@@ -0,0 +1,104 @@
+package transition
+
+import (
+	"errors"
+	"testing"
+)
+
+type Shipment struct {
+	OrderId int
+	Transition
+}
+
+func getShipmentStateMachine() *StateMachine[*Shipment] {
+	shipmentStateMachine := New(&Shipment{})
+	shipmentStateMachine.Initial("pending")
+	shipmentStateMachine.State("packing")
+	shipmentStateMachine.Event("start_packing").To("packing").From("pending")
+	return shipmentStateMachine
+}
+
+func TestLinkMachines(t *testing.T) {
+	var (
+		orderStateMachine    = getStateMachine()
+		shipmentStateMachine = getShipmentStateMachine()
+		shipments            = map[int]*Shipment{1: {OrderId: 1}}
+		reported             []error
+	)
+	orderStateMachine.Event("process").To("processed").From("paid")
+	orderStateMachine.OnError(func(err error) { reported = append(reported, err) })
+
+	LinkMachines(orderStateMachine, "processed", shipmentStateMachine, "start_packing", func(order *Order) (*Shipment, error) {
+		if shipment, ok := shipments[order.Id]; ok {
+			return shipment, nil
+		}
+		return nil, errors.New("shipment not found")
+	})
+
+	order := &Order{Id: 1}
+	order.State = "paid"
+	if err := orderStateMachine.Trigger("process", order); err != nil {
+		t.Fatalf("should not raise any error when trigger event process, got %v", err)
+	}
+	if shipments[1].State != "packing" {
+		t.Errorf("linked shipment should be packing, got %s", shipments[1].State)
+	}
+
+	order = &Order{Id: 2}
+	order.State = "paid"
+	if err := orderStateMachine.Trigger("process", order); err != nil {
+		t.Errorf("link failures should not fail the source transition by default, got %v", err)
+	}
+	if order.State != "processed" || len(reported) != 1 {
+		t.Errorf("link failure should be reported through OnError, got %v", reported)
+	}
+}
+
+func TestLinkMachinesRollback(t *testing.T) {
+	orderStateMachine := getStateMachine()
+	shipmentStateMachine := getShipmentStateMachine()
+	orderStateMachine.Event("process").To("processed").From("paid")
+
+	LinkMachines(orderStateMachine, "processed", shipmentStateMachine, "start_packing", func(order *Order) (*Shipment, error) {
+		shipment := &Shipment{OrderId: order.Id}
+		shipment.State = "packing"
+		return shipment, nil
+	}, LinkRollback())
+
+	order := &Order{Id: 1}
+	order.State = "paid"
+	if err := orderStateMachine.Trigger("process", order); !IsNoMatch(err) {
+		t.Errorf("linked event failure should fail the source transition, got %v", err)
+	}
+	if order.State != "paid" {
+		t.Errorf("source transition should be rolled back, got %s", order.State)
+	}
+}
+
+func TestLinkMachinesLoop(t *testing.T) {
+	var (
+		orderStateMachine    = getStateMachine()
+		shipmentStateMachine = getShipmentStateMachine()
+		order                = &Order{Id: 1}
+		shipment             = &Shipment{OrderId: 1}
+		reported             []error
+	)
+	orderStateMachine.Event("process").To("processed").From("paid", "processed")
+	orderStateMachine.OnError(func(err error) { reported = append(reported, err) })
+	shipmentStateMachine.Event("start_packing").To("packing").From("packing")
+
+	LinkMachines(orderStateMachine, "processed", shipmentStateMachine, "start_packing", func(*Order) (*Shipment, error) {
+		return shipment, nil
+	})
+	LinkMachines(shipmentStateMachine, "packing", orderStateMachine, "process", func(*Shipment) (*Order, error) {
+		return order, nil
+	})
+
+	order.State = "paid"
+	if err := orderStateMachine.Trigger("process", order); err != nil {
+		t.Fatalf("should not raise any error when trigger event process, got %v", err)
+	}
+	if len(reported) != 1 || !errors.Is(reported[0], ErrLinkLoop) {
+		t.Errorf("loop should be reported through OnError, got %v", reported)
+	}
+}
@@ -0,0 +1,168 @@
+package transition
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strconv"
+)
+
+// HookRef identifies a hook in a Manifest: by Name when it was attached from a HookRegistry, see Compile and
+// ApplyManifest, otherwise by the Site it was registered at, as file:line
+type HookRef struct {
+	Name string `json:"name,omitempty"`
+	Site string `json:"site,omitempty"`
+
+	// batch identifies the hooks registered together by a StateSelection or EventSelection, see HookHandle
+	batch uint64
+	// runsAfter names the hooks of the same phase the hook runs after, see OrderedHook
+	runsAfter *runsAfter
+	// bestEffort hooks report their failures as warnings, see OrderedHook.BestEffort
+	bestEffort bool
+	// progress is the *progressHook[T] of hooks reporting their progress, see State.EnterProgress
+	progress any
+	// contextual is the ContextHook[T] of hooks receiving the context of the trigger, see ContextHook
+	contextual any
+}
+
+func (ref HookRef) String() string {
+	if ref.Name != "" {
+		return ref.Name
+	}
+	return "anonymous (" + ref.Site + ")"
+}
+
+// Manifest lists, in order, the enter and exit hooks of states and the before and after hooks of transitions,
+// to review the wiring of a state machine or keep it in configuration, see HookManifest and ApplyManifest
+type Manifest struct {
+	States      []StateManifest      `json:"states,omitempty"`
+	Transitions []TransitionManifest `json:"transitions,omitempty"`
+}
+
+// StateManifest lists the hooks of a state
+type StateManifest struct {
+	State string    `json:"state"`
+	Enter []HookRef `json:"enter,omitempty"`
+	Exit  []HookRef `json:"exit,omitempty"`
+}
+
+// TransitionManifest lists the hooks of the transition of Event going to To
+type TransitionManifest struct {
+	Event  string    `json:"event"`
+	To     string    `json:"to"`
+	Before []HookRef `json:"before,omitempty"`
+	After  []HookRef `json:"after,omitempty"`
+}
+
+// anonymousHook returns the reference of a hook registered by the caller skip frames up
+func anonymousHook(skip int) HookRef {
+	_, file, line, ok := runtime.Caller(skip)
+	if !ok {
+		return HookRef{}
+	}
+	return HookRef{Site: filepath.Base(file) + ":" + strconv.Itoa(line)}
+}
+
+// HookManifest returns the hooks of the states and transitions having some, states sorted by name and
+// transitions by event then in definition order. Payload hooks, redirects, notifiers and invariants aren't listed
+func (sm *StateMachine[T]) HookManifest() Manifest {
+	var manifest Manifest
+	for _, name := range sm.stateNames() {
+		state := sm.states[name]
+		if len(state.enterRefs) > 0 || len(state.exitRefs) > 0 {
+			manifest.States = append(manifest.States, StateManifest{State: name, Enter: clip(state.enterRefs), Exit: clip(state.exitRefs)})
+		}
+	}
+	for _, name := range sm.eventNames() {
+		for _, transition := range sm.events[name].transitions {
+			if len(transition.beforeRefs) > 0 || len(transition.afterRefs) > 0 {
+				manifest.Transitions = append(manifest.Transitions, TransitionManifest{
+					Event:  name,
+					To:     transition.to,
+					Before: clip(transition.beforeRefs),
+					After:  clip(transition.afterRefs),
+				})
+			}
+		}
+	}
+	return manifest
+}
+
+// ApplyManifest attach the hooks of registry named in manifest to the states and transitions of sm, after the
+// hooks they already have. Anonymous hooks are skipped. Nothing is attached when manifest references an unknown
+// hook, state or transition
+func ApplyManifest[T Stater](sm *StateMachine[T], manifest Manifest, registry HookRegistry[T]) error {
+	lookup := func(owner string, refs []HookRef) error {
+		for _, ref := range refs {
+			if _, ok := registry[ref.Name]; ref.Name != "" && !ok {
+				return fmt.Errorf("failed to apply manifest: %s uses unknown hook %s", owner, ref.Name)
+			}
+		}
+		return nil
+	}
+
+	for _, stateManifest := range manifest.States {
+		if _, ok := sm.states[stateManifest.State]; !ok {
+			return fmt.Errorf("failed to apply manifest: state %s: %w", stateManifest.State, ErrUndeclaredState)
+		}
+		if err := lookup("state "+stateManifest.State, append(clip(stateManifest.Enter), stateManifest.Exit...)); err != nil {
+			return err
+		}
+	}
+	for _, transitionManifest := range manifest.Transitions {
+		event, ok := sm.events[transitionManifest.Event]
+		if !ok {
+			return fmt.Errorf("failed to apply manifest: event %s: %w", transitionManifest.Event, ErrUnknownEvent)
+		}
+		if _, ok := event.transitionIndex[transitionManifest.To]; !ok {
+			return fmt.Errorf("failed to apply manifest: event %s has no transition to %s", transitionManifest.Event, transitionManifest.To)
+		}
+		if err := lookup("event "+transitionManifest.Event, append(clip(transitionManifest.Before), transitionManifest.After...)); err != nil {
+			return err
+		}
+	}
+
+	for _, stateManifest := range manifest.States {
+		state := sm.State(stateManifest.State)
+		for _, ref := range stateManifest.Enter {
+			if ref.Name != "" {
+				state.enterNamed(ref.Name, registry[ref.Name])
+			}
+		}
+		for _, ref := range stateManifest.Exit {
+			if ref.Name != "" {
+				state.exitNamed(ref.Name, registry[ref.Name])
+			}
+		}
+	}
+	for _, transitionManifest := range manifest.Transitions {
+		transition := sm.Event(transitionManifest.Event).To(transitionManifest.To)
+		for _, ref := range transitionManifest.Before {
+			if ref.Name != "" {
+				transition.beforeNamed(ref.Name, registry[ref.Name])
+			}
+		}
+		for _, ref := range transitionManifest.After {
+			if ref.Name != "" {
+				transition.afterNamed(ref.Name, registry[ref.Name])
+			}
+		}
+	}
+	return nil
+}
+
+func (state *State[T]) enterNamed(name string, fc func(value T) error) {
+	state.enters, state.enterRefs = orderHooks(append(state.enters, fc), append(state.enterRefs, HookRef{Name: name}))
+}
+
+func (state *State[T]) exitNamed(name string, fc func(value T) error) {
+	state.exits, state.exitRefs = orderHooks(append(state.exits, fc), append(state.exitRefs, HookRef{Name: name}))
+}
+
+func (transition *EventTransition[T]) beforeNamed(name string, fc func(value T) error) {
+	transition.befores, transition.beforeRefs = orderHooks(append(transition.befores, fc), append(transition.beforeRefs, HookRef{Name: name}))
+}
+
+func (transition *EventTransition[T]) afterNamed(name string, fc func(value T) error) {
+	transition.afters, transition.afterRefs = orderHooks(append(transition.afters, fc), append(transition.afterRefs, HookRef{Name: name}))
+}
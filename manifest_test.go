@@ -0,0 +1,119 @@
+package transition
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func getManifestRegistry(calls *[]string) HookRegistry[*Order] {
+	hook := func(name string) func(*Order) error {
+		return func(*Order) error {
+			*calls = append(*calls, name)
+			return nil
+		}
+	}
+	return HookRegistry[*Order]{
+		"reserve_stock": hook("reserve_stock"),
+		"release_stock": hook("release_stock"),
+		"charge":        hook("charge"),
+		"send_receipt":  hook("send_receipt"),
+	}
+}
+
+func TestHookManifest(t *testing.T) {
+	orderStateMachine := getStateMachine()
+	registry := getManifestRegistry(&[]string{})
+	orderStateMachine.State("checkout").enterNamed("reserve_stock", registry["reserve_stock"])
+	orderStateMachine.State("checkout").Exit(func(*Order) error { return nil })
+	orderStateMachine.Event("pay").To("paid").beforeNamed("charge", registry["charge"])
+
+	manifest := orderStateMachine.HookManifest()
+	if len(manifest.States) != 1 || len(manifest.Transitions) != 1 {
+		t.Fatalf("only states and transitions having hooks should be listed, got %+v", manifest)
+	}
+
+	exit := manifest.States[0].Exit[0]
+	if exit.Name != "" || !strings.HasPrefix(exit.Site, "manifest_test.go:") || !strings.HasPrefix(exit.String(), "anonymous (manifest_test.go:") {
+		t.Errorf("anonymous hooks should be listed with their site, got %+v", exit)
+	}
+	if enter := manifest.States[0].Enter[0]; enter != (HookRef{Name: "reserve_stock"}) || enter.String() != "reserve_stock" {
+		t.Errorf("named hooks should be listed with their name, got %+v", enter)
+	}
+	if before := manifest.Transitions[0]; before.Event != "pay" || before.To != "paid" || before.Before[0].Name != "charge" {
+		t.Errorf("unexpected transition manifest %+v", before)
+	}
+}
+
+func TestApplyManifestRoundTrip(t *testing.T) {
+	var calls []string
+	registry := getManifestRegistry(&calls)
+
+	definition := `{
+		"initial": "draft",
+		"states": [{"name": "checkout", "enter": ["reserve_stock"], "exit": ["release_stock"]}, {"name": "paid"}],
+		"events": [
+			{"name": "checkout", "transitions": [{"to": "checkout", "from": ["draft"]}]},
+			{"name": "pay", "transitions": [{"to": "paid", "from": ["checkout"], "before": ["charge"], "after": ["send_receipt"]}]}
+		]
+	}`
+	original, err := LoadDefinition(strings.NewReader(definition), registry)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := json.Marshal(original.HookManifest())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		t.Fatal(err)
+	}
+
+	applied := getStateMachine()
+	if err := ApplyManifest(applied, manifest, registry); err != nil {
+		t.Fatalf("no error expected, got %v", err)
+	}
+	if !reflect.DeepEqual(applied.HookManifest(), original.HookManifest()) {
+		t.Errorf("applied manifest should round trip, got %+v", applied.HookManifest())
+	}
+
+	order := &Order{}
+	if err := applied.Trigger("checkout", order); err != nil {
+		t.Fatal(err)
+	}
+	if err := applied.Trigger("pay", order); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(calls, ",") != "reserve_stock,release_stock,charge,send_receipt" {
+		t.Errorf("applied hooks should run in order, got %v", calls)
+	}
+}
+
+func TestApplyManifestErrors(t *testing.T) {
+	registry := getManifestRegistry(&[]string{})
+	orderStateMachine := getStateMachine()
+
+	err := ApplyManifest(orderStateMachine, Manifest{
+		States:      []StateManifest{{State: "checkout", Enter: []HookRef{{Name: "reserve_stock"}}}},
+		Transitions: []TransitionManifest{{Event: "pay", To: "paid", After: []HookRef{{Name: "refund"}}}},
+	}, registry)
+	if err == nil || err.Error() != "failed to apply manifest: event pay uses unknown hook refund" {
+		t.Errorf("expected unknown hook error, got %v", err)
+	}
+	if manifest := orderStateMachine.HookManifest(); len(manifest.States) != 0 {
+		t.Errorf("nothing should be attached when the manifest is invalid, got %+v", manifest)
+	}
+
+	err = ApplyManifest(orderStateMachine, Manifest{Transitions: []TransitionManifest{{Event: "pay", To: "cancelled"}}}, registry)
+	if err == nil || err.Error() != "failed to apply manifest: event pay has no transition to cancelled" {
+		t.Errorf("expected unknown transition error, got %v", err)
+	}
+
+	err = ApplyManifest(orderStateMachine, Manifest{States: []StateManifest{{State: "archived"}}}, registry)
+	if err == nil || !strings.Contains(err.Error(), "undeclared state") {
+		t.Errorf("expected undeclared state error, got %v", err)
+	}
+}
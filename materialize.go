@@ -0,0 +1,97 @@
+package transition
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrUnreachable is returned by Materialize when no path leads to the target state
+var ErrUnreachable = errors.New("unreachable state")
+
+// Materializer register a function setting the fields a value needs in State, e.g. for its invariants to hold,
+// run by Materialize instead of the hooks leading to State
+func (state *State[T]) Materializer(fc func(value T) error) *State[T] {
+	state.checkMutable("State.Materializer")
+	state.materializers = append(state.materializers, fc)
+	return state
+}
+
+// MaterializeOption configure Materialize
+type MaterializeOption func(*materializeConfig)
+
+type materializeConfig struct {
+	from string
+}
+
+// MaterializeFrom walk the path from state rather than from the state of the value
+func MaterializeFrom(state string) MaterializeOption {
+	return func(config *materializeConfig) {
+		config.from = state
+	}
+}
+
+// Materialize bring value to state target along the shortest path from its state, e.g. to build test values:
+// for every state of the path, value is moved to the state, its materializers are run and its invariants checked.
+// No hook, guard or authorizer is run and no timeout is scheduled, but the fields tracking state changes, such as
+// entry counts, are updated. On failure the fields tracking state changes are reverted, fields set by
+// materializers are not
+func (sm *StateMachine[T]) Materialize(value T, target string, opts ...MaterializeOption) (err error) {
+	if isNil(value) {
+		return ErrNilValue
+	}
+	if _, ok := sm.states[target]; !ok && target != sm.initialState {
+		return fmt.Errorf("materialize state %s: %w", target, ErrUndeclaredState)
+	}
+
+	var config materializeConfig
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	var pending mutations
+	defer func() {
+		if err != nil {
+			pending.revert()
+		}
+	}()
+
+	from := config.from
+	if from == "" {
+		from = value.GetState()
+	}
+	if from == "" {
+		if from, err = sm.initialStateOf(value); err != nil {
+			return err
+		}
+	}
+	states := []string{}
+	if value.GetState() != from {
+		states = append(states, from)
+	}
+	if from != target {
+		path := sm.graph().shortestPath(from, target)
+		if path == nil {
+			return fmt.Errorf("materialize state %s from %s: %w", target, from, ErrUnreachable)
+		}
+		for _, step := range path {
+			states = append(states, step.To)
+		}
+	}
+
+	for _, name := range states {
+		sm.changeState(value, name, &pending)
+		state, ok := sm.states[name]
+		if !ok {
+			continue
+		}
+		for i, materializer := range state.materializers {
+			if err := materializer(value); err != nil {
+				return fmt.Errorf("materialize state %s: materializer %s: %w", target, hookName(name, i), err)
+			}
+		}
+		if err := state.checkInvariants(value); err != nil {
+			return fmt.Errorf("materialize state %s: %w", target, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package transition
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMaterialize(t *testing.T) {
+	orderStateMachine := getStateMachine()
+	orderStateMachine.Event("process").To("processed").From("paid")
+	orderStateMachine.State("paid").Invariant(func(order *Order) error {
+		if order.Address == "" {
+			return errors.New("paid orders have an address")
+		}
+		return nil
+	}).Enter(func(order *Order) error {
+		return errors.New("payment gateway unavailable in tests")
+	})
+
+	order := &Order{}
+	var violation *InvariantViolation
+	if err := orderStateMachine.Materialize(order, "processed"); !errors.As(err, &violation) || violation.State != "paid" {
+		t.Errorf("the invariant of paid should be checked, got %v", err)
+	}
+	if order.State != "" {
+		t.Errorf("a failed materialization should revert the state, got %s", order.State)
+	}
+
+	orderStateMachine.State("checkout").Materializer(func(order *Order) error {
+		order.Address = "1 Main Street"
+		return nil
+	})
+	if err := orderStateMachine.Materialize(order, "processed"); err != nil || order.State != "processed" {
+		t.Fatalf("should materialize a processed order without running hooks, got %s, %v", order.State, err)
+	}
+
+	if err := orderStateMachine.Materialize(order, "draft"); !errors.Is(err, ErrUnreachable) {
+		t.Errorf("should fail without path to the target, got %v", err)
+	}
+	if err := orderStateMachine.Materialize(order, "shipped"); !errors.Is(err, ErrUndeclaredState) {
+		t.Errorf("should fail for undeclared states, got %v", err)
+	}
+	if err := orderStateMachine.Materialize(&Order{}, "paid", MaterializeFrom("checkout")); err != nil {
+		t.Errorf("should materialize from the given state, got %v", err)
+	}
+}
@@ -0,0 +1,49 @@
+package memstore
+
+import (
+	"context"
+	"sync"
+
+	"github.com/daegalus/transition"
+)
+
+// Log is an in-memory log of state changes, safe for concurrent use. It implements transition.StateChangeLog,
+// to try audit trails and to assert on them in tests
+type Log struct {
+	mu      sync.RWMutex
+	changes []transition.StateChange
+}
+
+// NewLog returns an empty log
+func NewLog() *Log {
+	return &Log{}
+}
+
+// LogStateChange appends change to the log, it never fails
+func (log *Log) LogStateChange(ctx context.Context, change transition.StateChange) error {
+	log.mu.Lock()
+	defer log.mu.Unlock()
+	log.changes = append(log.changes, change)
+	return nil
+}
+
+// Changes returns the logged changes, oldest first
+func (log *Log) Changes() []transition.StateChange {
+	log.mu.RLock()
+	defer log.mu.RUnlock()
+	return append([]transition.StateChange{}, log.changes...)
+}
+
+// ChangesOf returns the logged changes of the value having key, oldest first, see transition.StateMachine.SetKeyFunc
+func (log *Log) ChangesOf(key string) []transition.StateChange {
+	log.mu.RLock()
+	defer log.mu.RUnlock()
+
+	var changes []transition.StateChange
+	for _, change := range log.changes {
+		if change.Key == key {
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
@@ -0,0 +1,38 @@
+package memstore_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/daegalus/transition"
+	"github.com/daegalus/transition/memstore"
+)
+
+func TestLog(t *testing.T) {
+	sm := transition.New(&Order{})
+	sm.Initial("draft")
+	sm.State("checkout")
+	sm.Event("checkout").To("checkout").From("draft")
+	sm.SetKeyFunc(func(order *Order) string { return order.ID })
+
+	log := memstore.NewLog()
+	sm.SetStateChangeLog(log)
+
+	ctx := transition.WithActor(context.Background(), "alice")
+	if err := sm.TriggerContext(ctx, "checkout", &Order{ID: "1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := sm.Trigger("checkout", &Order{ID: "2"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if changes := log.Changes(); len(changes) != 2 {
+		t.Fatalf("expected 2 logged changes, got %v", changes)
+	}
+	if changes := log.ChangesOf("1"); len(changes) != 1 || changes[0].Actor != "alice" || changes[0].To != "checkout" {
+		t.Errorf("changes should be logged with their actor, got %v", changes)
+	}
+	if changes := log.ChangesOf("2"); len(changes) != 1 || changes[0].Actor != "system" {
+		t.Errorf("changes without actor should be logged with the default one, got %v", changes)
+	}
+}
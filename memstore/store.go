@@ -0,0 +1,155 @@
+// Package memstore is an in-memory store of values managed by state machines. It's a reference implementation,
+// to try features needing to load values by key, such as timeouts, scheduled triggers and queues, and to use in
+// tests. It keeps nothing when the process exits, use your database in production
+package memstore
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/daegalus/transition"
+)
+
+// ErrNotFound is returned by Resolve when no value is stored under the key
+var ErrNotFound = errors.New("value not found")
+
+// Store is an in-memory store of values by key, safe for concurrent use. It implements transition.Resolver
+// when keys are strings
+type Store[K comparable, T transition.Stater] struct {
+	mu     sync.RWMutex
+	values map[K]T
+	copy   func(value T) T
+}
+
+// Option configure a Store
+type Option[T transition.Stater] func(*options[T])
+
+type options[T transition.Stater] struct {
+	copy func(value T) T
+}
+
+// WithCopy use copy to deep copy values when taking snapshots, see Store.Snapshot
+func WithCopy[T transition.Stater](copy func(value T) T) Option[T] {
+	return func(opts *options[T]) {
+		opts.copy = copy
+	}
+}
+
+// New returns an empty store
+func New[K comparable, T transition.Stater](opts ...Option[T]) *Store[K, T] {
+	var config options[T]
+	for _, opt := range opts {
+		opt(&config)
+	}
+	return &Store[K, T]{values: map[K]T{}, copy: config.copy}
+}
+
+// Put store value under key, replacing the value stored under key if any
+func (store *Store[K, T]) Put(key K, value T) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	store.values[key] = value
+}
+
+// Get returns the value stored under key, false if there is none
+func (store *Store[K, T]) Get(key K) (T, bool) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+	value, ok := store.values[key]
+	return value, ok
+}
+
+// Delete removes the value stored under key, deleting a missing key is not an error
+func (store *Store[K, T]) Delete(key K) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	delete(store.values, key)
+}
+
+// Len returns how many values are stored
+func (store *Store[K, T]) Len() int {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+	return len(store.values)
+}
+
+// Range calls fn for each value in no particular order, until fn returns false. The store may be modified by fn,
+// values stored meanwhile may or may not be visited
+func (store *Store[K, T]) Range(fn func(key K, value T) bool) {
+	store.mu.RLock()
+	keys := make([]K, 0, len(store.values))
+	for key := range store.values {
+		keys = append(keys, key)
+	}
+	store.mu.RUnlock()
+
+	for _, key := range keys {
+		if value, ok := store.Get(key); ok && !fn(key, value) {
+			return
+		}
+	}
+}
+
+// Values returns the stored values in no particular order, e.g. to sweep them, see transition.StateMachine.Sweep
+func (store *Store[K, T]) Values() []T {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+	values := make([]T, 0, len(store.values))
+	for _, value := range store.values {
+		values = append(values, value)
+	}
+	return values
+}
+
+// Resolve returns the value stored under key, it implements transition.Resolver for stores whose keys are strings
+func (store *Store[K, T]) Resolve(key string) (T, error) {
+	var zero T
+	storeKey, ok := any(key).(K)
+	if !ok {
+		return zero, fmt.Errorf("resolve %q: keys of the store are %T, not strings", key, zero)
+	}
+	if value, ok := store.Get(storeKey); ok {
+		return value, nil
+	}
+	return zero, fmt.Errorf("resolve %q: %w", key, ErrNotFound)
+}
+
+// Snapshot is the content of a Store at some point, see Store.Snapshot
+type Snapshot[K comparable, T transition.Stater] struct {
+	values map[K]T
+	states map[K]string
+}
+
+// Snapshot captures the stored values, to put the store back as it was with Restore, e.g. between tests.
+// Values are deep copied with the copy func given with WithCopy, otherwise only their state is captured
+func (store *Store[K, T]) Snapshot() Snapshot[K, T] {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	snapshot := Snapshot[K, T]{values: make(map[K]T, len(store.values)), states: make(map[K]string, len(store.values))}
+	for key, value := range store.values {
+		if store.copy != nil {
+			value = store.copy(value)
+		}
+		snapshot.values[key], snapshot.states[key] = value, value.GetState()
+	}
+	return snapshot
+}
+
+// Restore puts the store back as it was when snapshot was taken: values stored since are removed, deleted values
+// are stored again, and without WithCopy values get back their state
+func (store *Store[K, T]) Restore(snapshot Snapshot[K, T]) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.values = make(map[K]T, len(snapshot.values))
+	for key, value := range snapshot.values {
+		if store.copy != nil {
+			value = store.copy(value)
+		} else {
+			value.SetState(snapshot.states[key])
+		}
+		store.values[key] = value
+	}
+}
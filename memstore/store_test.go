@@ -0,0 +1,93 @@
+package memstore_test
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/daegalus/transition"
+	"github.com/daegalus/transition/memstore"
+)
+
+type Order struct {
+	ID string
+
+	transition.Transition
+}
+
+func TestStore(t *testing.T) {
+	store := memstore.New[string, *Order]()
+	store.Put("1", &Order{ID: "1"})
+	store.Put("2", &Order{ID: "2"})
+	store.Put("3", &Order{ID: "3"})
+	store.Delete("3")
+
+	var resolver transition.Resolver[*Order] = store
+	if order, err := resolver.Resolve("1"); err != nil || order.ID != "1" {
+		t.Errorf("should resolve stored values, got %v, %v", order, err)
+	}
+	if _, err := resolver.Resolve("3"); !errors.Is(err, memstore.ErrNotFound) {
+		t.Errorf("should not resolve deleted values, got %v", err)
+	}
+
+	var keys []string
+	store.Range(func(key string, order *Order) bool {
+		keys = append(keys, key)
+		return true
+	})
+	sort.Strings(keys)
+	if len(keys) != 2 || keys[0] != "1" || keys[1] != "2" || len(store.Values()) != 2 {
+		t.Errorf("unexpected keys %v", keys)
+	}
+
+	if _, err := memstore.New[int, *Order]().Resolve("1"); err == nil {
+		t.Errorf("stores not keyed by strings should not resolve")
+	}
+}
+
+func TestStoreSnapshot(t *testing.T) {
+	store := memstore.New[string, *Order]()
+	order := &Order{ID: "1"}
+	order.SetState("checkout")
+	store.Put("1", order)
+
+	snapshot := store.Snapshot()
+	order.SetState("paid")
+	store.Put("2", &Order{ID: "2"})
+	store.Restore(snapshot)
+
+	if restored, ok := store.Get("1"); !ok || restored.GetState() != "checkout" || store.Len() != 1 {
+		t.Errorf("the store should be restored, got %v", restored)
+	}
+
+	copying := memstore.New[string, *Order](memstore.WithCopy(func(order *Order) *Order {
+		copied := *order
+		return &copied
+	}))
+	copying.Put("1", order)
+	snapshot = copying.Snapshot()
+	order.SetState("delivered")
+	copying.Restore(snapshot)
+	if restored, _ := copying.Get("1"); restored == order || restored.GetState() != "checkout" {
+		t.Errorf("values should be restored from their copy, got %v", restored)
+	}
+}
+
+func TestStoreConcurrency(t *testing.T) {
+	store := memstore.New[int, *Order]()
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			store.Put(i, &Order{})
+			store.Get(i)
+			store.Range(func(int, *Order) bool { return true })
+		}(i)
+	}
+	wg.Wait()
+	if store.Len() != 10 {
+		t.Errorf("expected 10 values, got %d", store.Len())
+	}
+}
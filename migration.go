@@ -0,0 +1,220 @@
+package transition
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrNoMigrationPath is returned by Migrate when no chain of migrations leads from the recorded version to the current one
+var ErrNoMigrationPath = errors.New("no migration path")
+
+// Migration moves values from a version of the state machine definition to the next one, see StateMachine.Migrate
+type Migration[T Stater] struct {
+	from, to   string
+	states     map[string]string
+	transforms []func(value T) error
+}
+
+// NewMigration initialize a migration from version from to version to
+func NewMigration[T Stater](from, to string) *Migration[T] {
+	return &Migration[T]{from: from, to: to, states: map[string]string{}}
+}
+
+// MapState move values in state from to state to
+func (migration *Migration[T]) MapState(from, to string) *Migration[T] {
+	migration.states[from] = to
+	return migration
+}
+
+// Transform register a func migrating values, run after states are mapped
+func (migration *Migration[T]) Transform(fc func(value T) error) *Migration[T] {
+	migration.transforms = append(migration.transforms, fc)
+	return migration
+}
+
+// AddMigration register migrations, they are chained by Migrate from the recorded version of values to the current one
+func (sm *StateMachine[T]) AddMigration(migrations ...*Migration[T]) *StateMachine[T] {
+	sm.owner.checkMutable("AddMigration")
+	sm.migrations = append(sm.migrations, migrations...)
+	return sm
+}
+
+// MigrateOption configure Migrate and MigrateAll
+type MigrateOption func(*migrateConfig)
+
+type migrateConfig struct {
+	runEnterHooks bool
+	dryRun        bool
+}
+
+// MigrateRunEnterHooks run the enter hooks of the state values are moved to
+func MigrateRunEnterHooks() MigrateOption {
+	return func(config *migrateConfig) {
+		config.runEnterHooks = true
+	}
+}
+
+// MigrateDryRun only report what MigrateAll would do, states are mapped but values are left untouched and transforms not run
+func MigrateDryRun() MigrateOption {
+	return func(config *migrateConfig) {
+		config.dryRun = true
+	}
+}
+
+// migrationPath returns the chain of migrations from version to the current version
+func (sm *StateMachine[T]) migrationPath(version string) ([]*Migration[T], error) {
+	var path []*Migration[T]
+	for version != sm.version {
+		if len(path) > len(sm.migrations) {
+			return nil, fmt.Errorf("%w: migrations from %s loop", ErrNoMigrationPath, version)
+		}
+
+		var next *Migration[T]
+		for _, migration := range sm.migrations {
+			if migration.from == version {
+				next = migration
+				break
+			}
+		}
+		if next == nil {
+			return nil, fmt.Errorf("%w from version %s to %s", ErrNoMigrationPath, version, sm.version)
+		}
+		path = append(path, next)
+		version = next.to
+	}
+	return path, nil
+}
+
+// migratedState returns the state values in state end up in after the migrations of path
+func migratedState[T Stater](path []*Migration[T], state string) string {
+	for _, migration := range path {
+		if to, ok := migration.states[state]; ok {
+			state = to
+		}
+	}
+	return state
+}
+
+// Migrate apply the chain of migrations from the version recorded on value to the version of the state machine,
+// mapping its state, running transforms and recording the current version. Values without recorded version, or
+// already at the current version, are left untouched. When a transform or an enter hook fails, the state is
+// restored, changes made by the transforms and hooks themselves are not
+func (sm *StateMachine[T]) Migrate(value T, opts ...MigrateOption) error {
+	var config migrateConfig
+	for _, opt := range opts {
+		opt(&config)
+	}
+	_, _, err := sm.migrate(value, config)
+	return err
+}
+
+func (sm *StateMachine[T]) migrate(value T, config migrateConfig) (from, to string, err error) {
+	if isNil(value) {
+		return "", "", ErrNilValue
+	}
+
+	from = value.GetState()
+	tracker, ok := any(value).(VersionTracker)
+	if !ok {
+		return from, from, fmt.Errorf("failed to migrate: %T doesn't implement VersionTracker", value)
+	}
+
+	version, _ := tracker.GetVersion()
+	if version == "" || version == sm.version {
+		return from, from, nil
+	}
+
+	path, err := sm.migrationPath(version)
+	if err != nil {
+		return from, from, err
+	}
+
+	to = migratedState(path, from)
+	if _, ok := sm.states[to]; !ok && to != sm.initialState {
+		return from, to, fmt.Errorf("failed to migrate from state %s to %s: %w", from, to, ErrUndeclaredState)
+	}
+	if config.dryRun {
+		return from, to, nil
+	}
+
+	// the mapped state is reverted if a transform or an enter hook fails
+	var pending mutations
+	defer func() {
+		if err != nil {
+			pending.revert()
+		}
+	}()
+	stateWas := value.GetState()
+	value.SetState(to)
+	pending.apply(func() { value.SetState(stateWas) })
+	pending.apply(recordMachineState(value, to))
+
+	for _, migration := range path {
+		for _, transform := range migration.transforms {
+			if err := transform(value); err != nil {
+				return from, to, fmt.Errorf("failed to migrate from version %s to %s: %w", migration.from, migration.to, err)
+			}
+		}
+	}
+
+	if state, ok := sm.states[to]; ok && config.runEnterHooks && to != from {
+		for i, enter := range state.enters {
+			if err := enter(value); err != nil {
+				return from, to, fmt.Errorf("failed to migrate to state %s: %s hook %s: %w", to, PhaseEnter, hookName(to, i), err)
+			}
+		}
+	}
+
+	tracker.SetVersion(sm.version, sm.Fingerprint())
+	return from, to, nil
+}
+
+// MigrationReport describe the outcome of MigrateAll
+type MigrationReport struct {
+	Total int `json:"total"`
+	// Migrated is the number of values moved to the current version, or that would be in dry-run mode
+	Migrated int `json:"migrated"`
+	// Moves counts values per state change, e.g. "awaiting_stock -> backordered"
+	Moves map[string]int `json:"moves"`
+	// Errors holds the error of every value that failed to migrate
+	Errors []error `json:"-"`
+}
+
+// MigrateAll migrate every value yielded by values, see Migrate and AuditValues for the iterator. It continues after
+// failures, collected in the report, and stops when ctx is done, returning ctx's error
+func (sm *StateMachine[T]) MigrateAll(ctx context.Context, values func(yield func(T) bool), opts ...MigrateOption) (MigrationReport, error) {
+	var config migrateConfig
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	var (
+		report = MigrationReport{Moves: map[string]int{}}
+		err    error
+	)
+	values(func(value T) bool {
+		if err = ctx.Err(); err != nil {
+			return false
+		}
+
+		report.Total++
+		version := ""
+		if tracker, ok := any(value).(VersionTracker); ok {
+			version, _ = tracker.GetVersion()
+		}
+
+		from, to, migrateErr := sm.migrate(value, config)
+		switch {
+		case migrateErr != nil:
+			report.Errors = append(report.Errors, fmt.Errorf("value %d: %w", report.Total-1, migrateErr))
+		case version != "" && version != sm.version:
+			report.Migrated++
+			if from != to {
+				report.Moves[from+" -> "+to]++
+			}
+		}
+		return true
+	})
+	return report, err
+}
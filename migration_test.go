@@ -0,0 +1,97 @@
+package transition
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func getMigrationStateMachine() *StateMachine[*Order] {
+	orderStateMachine := getStateMachine().Version("v4")
+	orderStateMachine.State("backordered")
+	orderStateMachine.AddMigration(
+		NewMigration[*Order]("v3", "v4").MapState("awaiting_stock", "backordered"),
+		NewMigration[*Order]("v2", "v3").MapState("on_hold", "awaiting_stock").Transform(func(order *Order) error {
+			order.Address += " (migrated)"
+			return nil
+		}),
+	)
+	return orderStateMachine
+}
+
+func TestMigrate(t *testing.T) {
+	var (
+		orderStateMachine = getMigrationStateMachine()
+		entered           int
+	)
+	orderStateMachine.State("backordered").Enter(func(*Order) error {
+		entered++
+		return nil
+	})
+
+	order := &Order{Address: "home"}
+	order.State, order.Version = "on_hold", "v2"
+	if err := orderStateMachine.Migrate(order, MigrateRunEnterHooks()); err != nil {
+		t.Fatalf("should not raise any error when migrating, got %v", err)
+	}
+	if order.State != "backordered" || order.Version != "v4" || order.Address != "home (migrated)" || entered != 1 {
+		t.Errorf("migrations should be chained, got %+v", order)
+	}
+
+	order.State, order.Version = "on_hold", "v1"
+	if err := orderStateMachine.Migrate(order); !errors.Is(err, ErrNoMigrationPath) || order.State != "on_hold" {
+		t.Errorf("missing migration should raise ErrNoMigrationPath, got %v", err)
+	}
+}
+
+func TestMigrateFailure(t *testing.T) {
+	orderStateMachine := getMigrationStateMachine()
+	orderStateMachine.State("backordered").Enter(func(*Order) error {
+		return errors.New("stock service unavailable")
+	})
+
+	order := &Order{}
+	order.State, order.Version = "on_hold", "v2"
+	if err := orderStateMachine.Migrate(order, MigrateRunEnterHooks()); err == nil {
+		t.Fatalf("failed enter hooks should fail the migration")
+	}
+	if order.State != "on_hold" || order.Version != "v2" {
+		t.Errorf("failed migrations should restore the state and version, got %+v", order)
+	}
+}
+
+func TestMigrateAll(t *testing.T) {
+	orderStateMachine := getMigrationStateMachine()
+
+	var orders []*Order
+	for _, versioned := range [][2]string{{"on_hold", "v2"}, {"awaiting_stock", "v3"}, {"checkout", "v3"}, {"paid", "v4"}, {"lost", "v3"}} {
+		order := &Order{}
+		order.State, order.Version = versioned[0], versioned[1]
+		orders = append(orders, order)
+	}
+	iterate := func(yield func(*Order) bool) {
+		for _, order := range orders {
+			if !yield(order) {
+				return
+			}
+		}
+	}
+
+	report, err := orderStateMachine.MigrateAll(context.Background(), iterate, MigrateDryRun())
+	if err != nil {
+		t.Fatalf("should not raise any error when migrating, got %v", err)
+	}
+	if report.Total != 5 || report.Migrated != 3 || report.Moves["on_hold -> backordered"] != 1 || report.Moves["awaiting_stock -> backordered"] != 1 || len(report.Errors) != 1 {
+		t.Errorf("unexpected dry-run report %+v", report)
+	}
+	if orders[0].State != "on_hold" || orders[0].Version != "v2" {
+		t.Errorf("dry-run should not change values, got %+v", orders[0])
+	}
+
+	if _, err := orderStateMachine.MigrateAll(context.Background(), iterate); err != nil {
+		t.Fatalf("should not raise any error when migrating, got %v", err)
+	}
+	if orders[0].State != "backordered" || orders[2].State != "checkout" || orders[2].Version != "v4" || orders[4].Version != "v3" {
+		t.Errorf("values should be migrated, got %+v", orders)
+	}
+}
@@ -0,0 +1,82 @@
+package transition
+
+import "time"
+
+// mutations are the changes a trigger made to the fields of a value managed by the state machine: its state, when
+// and from which state it changed, its entry counts, first entry times, recorded version, machine state and sub-state. Every change is applied
+// along with how to undo it, so a failed trigger reverts exactly what it changed and nothing else
+type mutations struct {
+	reverts []func()
+	// buffer holds the reverts of most triggers
+	buffer [8]func()
+}
+
+// apply records how to undo a change just made to the value
+func (pending *mutations) apply(revert func()) {
+	if pending.reverts == nil {
+		pending.reverts = pending.buffer[:0]
+	}
+	pending.reverts = append(pending.reverts, revert)
+}
+
+// revert undo the recorded changes, last first, and forget them
+func (pending *mutations) revert() {
+	for i := len(pending.reverts) - 1; i >= 0; i-- {
+		pending.reverts[i]()
+	}
+	pending.reverts = nil
+}
+
+// changeState set the state of value to state, along with the fields tracking state changes
+func (sm *StateMachine[T]) changeState(value T, state string, pending *mutations) {
+	// the state, when and from which state it changed are reverted together, as most values track them
+	var (
+		stateWas                        = value.GetState()
+		timeTracker, tracksTime         = any(value).(TimeTracker)
+		previousTracker, tracksPrevious = any(value).(PreviousStateTracker)
+		changedAtWas                    time.Time
+		previousWas                     string
+	)
+	value.SetState(sm.Intern(state))
+	if tracksTime {
+		changedAtWas = timeTracker.GetStateChangedAt()
+		timeTracker.SetStateChangedAt(sm.clock.Now())
+	}
+	if tracksPrevious {
+		previousWas = previousTracker.GetPreviousState()
+		previousTracker.SetPreviousState(stateWas)
+	}
+	pending.apply(func() {
+		if tracksPrevious {
+			previousTracker.SetPreviousState(previousWas)
+		}
+		if tracksTime {
+			timeTracker.SetStateChangedAt(changedAtWas)
+		}
+		value.SetState(stateWas)
+	})
+	pending.apply(recordMachineState(value, state))
+
+	if counter, ok := any(value).(EntryCounter); ok {
+		entriesWas := counter.Entries(state)
+		counter.SetEntries(state, entriesWas+1)
+		pending.apply(func() { counter.SetEntries(state, entriesWas) })
+	}
+
+	if tracker, ok := any(value).(FunnelTracker); ok {
+		if _, entered := tracker.FirstEntered(state); !entered {
+			tracker.SetFirstEntered(state, sm.clock.Now())
+			pending.apply(func() { tracker.SetFirstEntered(state, time.Time{}) })
+		}
+	}
+
+	if declared, ok := sm.states[state]; ok && declared.sub != nil {
+		declared.sub.enter(value, pending)
+	}
+
+	if tracker, ok := any(value).(VersionTracker); ok && sm.version != "" {
+		versionWas, fingerprintWas := tracker.GetVersion()
+		tracker.SetVersion(sm.version, sm.Fingerprint())
+		pending.apply(func() { tracker.SetVersion(versionWas, fingerprintWas) })
+	}
+}
@@ -0,0 +1,59 @@
+package transition
+
+import (
+	"fmt"
+	"strings"
+)
+
+// StateNormalizer define how state names are normalized before being compared, e.g. to accept legacy values with
+// inconsistent casing. It's applied to the current state of values and to the names of states, initial state and
+// transitions defined after it, which makes normalized names the ones written by SetState. Define it first, states
+// are compared as is without it
+func (sm *StateMachine[T]) StateNormalizer(fc func(state string) string) *StateMachine[T] {
+	sm.owner.checkMutable("StateNormalizer")
+	sm.owner.normalize = fc
+	return sm
+}
+
+// normalizeState returns the normalized name of state, see StateMachine.StateNormalizer
+func (current *owner) normalizeState(state string) string {
+	if current.normalize == nil {
+		return state
+	}
+	return current.normalize(state)
+}
+
+// currentState returns the normalized state of value, or its initial state when it has none, see InitialFor
+func (sm *StateMachine[T]) currentState(value T) string {
+	if state := sm.owner.normalizeState(value.GetState()); state != "" {
+		return state
+	}
+	initial, _ := sm.initialStateOf(value)
+	return initial
+}
+
+// normalizationCollisions returns an error for each group of declared states having the same normalized name
+func (sm *StateMachine[T]) normalizationCollisions() []error {
+	if sm.owner.normalize == nil {
+		return nil
+	}
+
+	var (
+		errs       []error
+		normalized []string
+		groups     = map[string][]string{}
+	)
+	for _, name := range sm.stateNamesWithInitial() {
+		key := sm.owner.normalizeState(name)
+		if _, ok := groups[key]; !ok {
+			normalized = append(normalized, key)
+		}
+		groups[key] = append(groups[key], name)
+	}
+	for _, key := range normalized {
+		if group := groups[key]; len(group) > 1 {
+			errs = append(errs, fmt.Errorf("states %s are all normalized to %q", strings.Join(group, ", "), key))
+		}
+	}
+	return errs
+}
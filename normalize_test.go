@@ -0,0 +1,65 @@
+package transition
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func normalizeState(state string) string {
+	return strings.ToLower(strings.TrimSpace(state))
+}
+
+func TestStateNormalizer(t *testing.T) {
+	orderStateMachine := New(&Order{})
+	orderStateMachine.StateNormalizer(normalizeState)
+	orderStateMachine.Initial("Draft")
+	orderStateMachine.State("Checkout")
+	orderStateMachine.State("PAID")
+	orderStateMachine.Event("checkout").To("Checkout").From("DRAFT")
+	orderStateMachine.Event("pay").To("Paid").From(" checkout")
+
+	if err := orderStateMachine.Validate(); err != nil {
+		t.Fatalf("normalized definition should be valid, got %v", err)
+	}
+
+	for _, legacy := range []string{"Checkout ", "CHECKOUT", "checkout"} {
+		order := &Order{}
+		order.State = legacy
+		if !orderStateMachine.Can("pay", order) || !reflect.DeepEqual(orderStateMachine.AvailableEvents(order), []string{"pay"}) {
+			t.Errorf("pay should be available from %q", legacy)
+		}
+		if err := orderStateMachine.Trigger("pay", order); err != nil {
+			t.Fatalf("pay should be triggered from %q, got %v", legacy, err)
+		}
+		if order.State != "paid" {
+			t.Errorf("state should be rewritten canonically, got %q", order.State)
+		}
+	}
+
+	order := &Order{}
+	if err := orderStateMachine.Trigger("checkout", order); err != nil || order.State != "checkout" {
+		t.Errorf("initial state should be normalized, got %q and %v", order.State, err)
+	}
+}
+
+func TestStateNormalizerCollisions(t *testing.T) {
+	orderStateMachine := getStateMachine()
+	orderStateMachine.State("Paid ")
+	orderStateMachine.State("CANCELLED")
+	orderStateMachine.StateNormalizer(normalizeState)
+
+	err := orderStateMachine.Validate()
+	if err == nil {
+		t.Fatal("Validate should report states normalized to the same name")
+	}
+	for _, expected := range []string{`states CANCELLED, cancelled are all normalized to "cancelled"`, `states Paid , paid are all normalized to "paid"`} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected %s in %v", expected, err)
+		}
+	}
+
+	if err := getStateMachine().Validate(); err != nil {
+		t.Errorf("states should be compared as is without normalizer, got %v", err)
+	}
+}
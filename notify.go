@@ -0,0 +1,96 @@
+package transition
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// TransitionInfo describe the transition being performed
+type TransitionInfo struct {
+	Event string
+	From  string
+	To    string
+	// Actor is the actor of the trigger, see WithActor
+	Actor string
+	// CorrelationID is the correlation ID of the trigger, see WithCorrelationID
+	CorrelationID string
+}
+
+// transitionInfo describe the transition of event from from to to, performed by a trigger with ctx
+func (sm *StateMachine[T]) transitionInfo(ctx context.Context, event, from, to string) TransitionInfo {
+	return TransitionInfo{Event: event, From: from, To: to, Actor: sm.actor(ctx), CorrelationID: CorrelationIDFromContext(ctx)}
+}
+
+// NotifyData is what the template of a notification hook is executed against
+type NotifyData[T Stater] struct {
+	Value      T
+	Transition TransitionInfo
+	// Actor is the actor of the trigger, see WithActor
+	Actor string
+	// Reason is the reason of the trigger, see WithReason
+	Reason string
+	// CorrelationID is the correlation ID of the trigger, see WithCorrelationID
+	CorrelationID string
+	// Variant is the variant performing the trigger, see VariantResolver
+	Variant string
+	Time    time.Time
+}
+
+// NotifyHook is an after hook receiving the context of the trigger and the transition performed, see Notify
+type NotifyHook[T Stater] func(ctx context.Context, data NotifyData[T]) error
+
+// Notify returns a hook rendering tmpl against NotifyData and passing the message to send, register it with
+// EventTransition.Notify. The template is parsed right away, so a malformed template is reported here rather than
+// when triggering. Errors of the template execution or of send fail the transition like any after hook
+func Notify[T Stater](tmpl string, send func(ctx context.Context, msg string) error) (NotifyHook[T], error) {
+	parsed, err := template.New("notify").Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return nil, fmt.Errorf("notify: %w", err)
+	}
+
+	return func(ctx context.Context, data NotifyData[T]) error {
+		var builder strings.Builder
+		if err := parsed.Execute(&builder, data); err != nil {
+			return fmt.Errorf("notify: %w", err)
+		}
+		return send(ctx, builder.String())
+	}, nil
+}
+
+// Notify register a notification hook, run after the after hooks of the transition
+func (transition *EventTransition[T]) Notify(hook NotifyHook[T]) *EventTransition[T] {
+	transition.checkMutable("EventTransition.Notify")
+	transition.notifiers = append(transition.notifiers, hook)
+	return transition
+}
+
+type reasonContextKey struct{}
+
+// WithReason returns a copy of ctx carrying why events are triggered, passed to notification hooks
+func WithReason(ctx context.Context, reason string) context.Context {
+	return context.WithValue(ctx, reasonContextKey{}, reason)
+}
+
+// ReasonFromContext returns the reason set with WithReason, or an empty string
+func ReasonFromContext(ctx context.Context) string {
+	reason, _ := ctx.Value(reasonContextKey{}).(string)
+	return reason
+}
+
+// bindNotify returns a hook running notifier with the data of the transition being performed
+func (sm *StateMachine[T]) bindNotify(ctx context.Context, notifier NotifyHook[T], info TransitionInfo) func(value T) error {
+	return func(value T) error {
+		return notifier(ctx, NotifyData[T]{
+			Value:         value,
+			Transition:    info,
+			Actor:         sm.actor(ctx),
+			Reason:        ReasonFromContext(ctx),
+			CorrelationID: CorrelationIDFromContext(ctx),
+			Variant:       VariantFromContext(ctx),
+			Time:          sm.clock.Now(),
+		})
+	}
+}
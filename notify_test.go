@@ -0,0 +1,68 @@
+package transition
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNotify(t *testing.T) {
+	var (
+		builder strings.Builder
+		writer  = bufio.NewWriter(&builder)
+		now     = time.Date(2023, 1, 24, 12, 0, 0, 0, time.UTC)
+	)
+
+	notify, err := Notify[*Order](
+		"{{.Actor}} moved order {{.Value.Id}} from {{.Transition.From}} to {{.Transition.To}} ({{.Reason}}) at {{.Time.Format \"15:04\"}}",
+		func(ctx context.Context, msg string) error {
+			_, err := fmt.Fprintln(writer, msg)
+			return err
+		},
+	)
+	if err != nil {
+		t.Fatalf("no error should happen when parsing the template, but got %v", err)
+	}
+
+	orderStateMachine := New(&Order{}, WithClock(&manualClock{now: now}))
+	orderStateMachine.Initial("draft")
+	orderStateMachine.State("checkout")
+	orderStateMachine.Event("checkout").To("checkout").From("draft").Notify(notify)
+
+	order := &Order{Id: 7}
+	ctx := WithReason(WithActor(context.Background(), "alice"), "customer request")
+	if err := orderStateMachine.TriggerContext(ctx, "checkout", order); err != nil {
+		t.Fatalf("no error should happen, but got %v", err)
+	}
+	writer.Flush()
+
+	if want := "alice moved order 7 from draft to checkout (customer request) at 12:00\n"; builder.String() != want {
+		t.Errorf("expected message %q, got %q", want, builder.String())
+	}
+}
+
+func TestNotifyErrors(t *testing.T) {
+	if _, err := Notify[*Order]("{{.Value", nil); err == nil {
+		t.Errorf("a malformed template should be reported when creating the hook")
+	}
+
+	errSend := errors.New("send failed")
+	notify, _ := Notify[*Order]("{{.Transition.Event}}", func(ctx context.Context, msg string) error { return errSend })
+
+	orderStateMachine := getStateMachine()
+	orderStateMachine.Event("checkout").To("checkout").From("draft").After(func(order *Order) error { return nil }).Notify(notify)
+
+	order := &Order{}
+	err := orderStateMachine.Trigger("checkout", order)
+	var transitionErr *TransitionError
+	if !errors.As(err, &transitionErr) || !errors.Is(err, errSend) || transitionErr.Hook != "checkout#1" {
+		t.Errorf("a failed notification should fail the transition as an after hook, got %v", err)
+	}
+	if order.State != "draft" {
+		t.Errorf("a failed notification should roll back the state, got %v", order.State)
+	}
+}
@@ -0,0 +1,52 @@
+package transition
+
+// Option configure a StateMachine when created by New
+type Option func(*options)
+
+type options struct {
+	clock         Clock
+	scheduler     Scheduler
+	maxChainDepth int
+	maxHooks      int
+	// mutableAfterStart allow modifying the definition after the first trigger, see WithMutableAfterStart
+	mutableAfterStart bool
+	// trackDefinitions record where transitions are defined, see WithDefinitionTracking
+	trackDefinitions bool
+	// maxConcurrentTriggers and concurrencyPolicy limit the triggers in flight, see WithMaxConcurrentTriggers
+	maxConcurrentTriggers int
+	concurrencyPolicy     *ConcurrencyPolicy
+	// strictHookState fail triggers whose hooks set the state, see WithStrictHookState
+	strictHookState bool
+	// generateCorrelationIDs generate the correlation IDs of triggers without one, see WithGeneratedCorrelationIDs
+	generateCorrelationIDs bool
+}
+
+// WithClock use clock instead of the system clock, e.g. to time-travel in tests
+func WithClock(clock Clock) Option {
+	return func(opts *options) {
+		opts.clock = clock
+	}
+}
+
+// WithScheduler use scheduler for timeouts instead of an in-process MemoryScheduler
+func WithScheduler(scheduler Scheduler) Option {
+	return func(opts *options) {
+		opts.scheduler = scheduler
+	}
+}
+
+// WithMaxChainDepth limit to n the triggers a trigger may cause on the same value, e.g. hooks triggering other
+// events with the context of the trigger, see ContextHook and ErrChainDepthExceeded
+func WithMaxChainDepth(n int) Option {
+	return func(opts *options) {
+		opts.maxChainDepth = n
+	}
+}
+
+// WithMaxHooksPerTrigger limit to n the hooks run by a trigger and the triggers it causes on the same value,
+// see ErrHookBudgetExceeded
+func WithMaxHooksPerTrigger(n int) Option {
+	return func(opts *options) {
+		opts.maxHooks = n
+	}
+}
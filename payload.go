@@ -0,0 +1,106 @@
+package transition
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrPayloadType is returned by triggers of a TypedEvent whose payload isn't of the type of the event
+var ErrPayloadType = errors.New("unexpected payload type")
+
+// TypedEvent is an event triggered with a typed payload passed to its hooks, see DefineEvent.
+// It shares the transitions and guards of the underlying Event, defined with To and From as usual
+type TypedEvent[T Stater, P any] struct {
+	*Event[T]
+	sm *StateMachine[T]
+}
+
+// DefineEvent define an event triggered with a payload of type P
+//
+//	pay := transition.DefineEvent[*Order, PaymentInfo](sm, "pay")
+//	pay.To("paid").From("checkout")
+//	pay.Before(func(order *Order, payment PaymentInfo) error { ... })
+//	pay.Trigger(order, PaymentInfo{Amount: 42})
+func DefineEvent[T Stater, P any](sm *StateMachine[T], name string) *TypedEvent[T, P] {
+	return &TypedEvent[T, P]{Event: sm.Event(name), sm: sm}
+}
+
+// Trigger trigger the event with payload
+func (event *TypedEvent[T, P]) Trigger(value T, payload P) error {
+	_, err := event.sm.execute(context.Background(), TriggerCommand{Event: event.Name}, value, triggerOptions{payload: payload})
+	return err
+}
+
+// ToFunc define EventTransition of go to the state returned by fc, decided by the payload, see Event.ToFunc.
+// When the event is triggered without payload, e.g. by StateMachine.Trigger, fc receives the zero value of P, the
+// trigger fails with ErrPayloadType when the payload isn't a P
+func (event *TypedEvent[T, P]) ToFunc(fc func(value T, payload P) (string, error)) *EventTransition[T] {
+	return event.Event.toFunc(func(value T, payload any) (string, error) {
+		typed, err := payloadOf[P](payload)
+		if err != nil {
+			return "", err
+		}
+		return fc(value, typed)
+	})
+}
+
+// Before register a before hook receiving the payload, it runs after the before hooks of the matched transition.
+// When the event is triggered without payload, e.g. by StateMachine.Trigger, the hook receives the zero value of P,
+// the trigger fails with ErrPayloadType when the payload isn't a P
+func (event *TypedEvent[T, P]) Before(fc func(value T, payload P) error) *TypedEvent[T, P] {
+	event.checkMutable("TypedEvent.Before")
+	event.Event.payloadBefores = append(event.Event.payloadBefores, erasePayload(fc))
+	return event
+}
+
+// After register an after hook receiving the payload, it runs after the after hooks of the matched transition.
+// When the event is triggered without payload, e.g. by StateMachine.Trigger, the hook receives the zero value of P,
+// the trigger fails with ErrPayloadType when the payload isn't a P
+func (event *TypedEvent[T, P]) After(fc func(value T, payload P) error) *TypedEvent[T, P] {
+	event.checkMutable("TypedEvent.After")
+	event.Event.payloadAfters = append(event.Event.payloadAfters, erasePayload(fc))
+	return event
+}
+
+func erasePayload[T Stater, P any](fc func(value T, payload P) error) func(value T, payload any) error {
+	return func(value T, payload any) error {
+		typed, err := payloadOf[P](payload)
+		if err != nil {
+			return err
+		}
+		return fc(value, typed)
+	}
+}
+
+// payloadOf returns payload as a P, the zero value when there's no payload. Payloads of commands decoded from JSON,
+// e.g. by queues, are generic JSON values, they're encoded again and decoded into a P
+func payloadOf[P any](payload any) (P, error) {
+	var typed P
+	if payload == nil {
+		return typed, nil
+	}
+	if typed, ok := payload.(P); ok {
+		return typed, nil
+	}
+
+	switch payload.(type) {
+	case map[string]any, []any, string, float64, bool, json.Number:
+		data, err := json.Marshal(payload)
+		if err == nil {
+			err = json.Unmarshal(data, &typed)
+		}
+		if err != nil {
+			return typed, fmt.Errorf("%w: can't decode %T into %T: %v", ErrPayloadType, payload, typed, err)
+		}
+		return typed, nil
+	}
+	return typed, fmt.Errorf("%w: got %T, expected %T", ErrPayloadType, payload, typed)
+}
+
+func bindPayload[T Stater](fc func(value T, payload any) error, payload any) func(value T) error {
+	return func(value T) error {
+		return fc(value, payload)
+	}
+}
@@ -0,0 +1,153 @@
+package transition
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+type PaymentInfo struct {
+	Amount int
+}
+
+func TestTypedEvent(t *testing.T) {
+	var (
+		orderStateMachine = getStateMachine()
+		order             = &Order{}
+		paid              int
+		calls             []string
+	)
+
+	pay := DefineEvent[*Order, PaymentInfo](orderStateMachine, "pay")
+	pay.To("paid").From("checkout").Before(func(order *Order) error {
+		calls = append(calls, "before")
+		return nil
+	})
+	pay.Before(func(order *Order, payment PaymentInfo) error {
+		calls = append(calls, "typed before")
+		if payment.Amount <= 0 {
+			return errors.New("amount must be positive")
+		}
+		return nil
+	}).After(func(order *Order, payment PaymentInfo) error {
+		calls = append(calls, "typed after")
+		paid = payment.Amount
+		return nil
+	})
+
+	if err := orderStateMachine.Trigger("checkout", order); err != nil {
+		t.Fatalf("should not raise any error when trigger event checkout")
+	}
+
+	if err := pay.Trigger(order, PaymentInfo{Amount: 0}); err == nil || order.State != "checkout" {
+		t.Errorf("typed hook error should abort the transition, got %v", err)
+	}
+
+	calls = nil
+	if err := pay.Trigger(order, PaymentInfo{Amount: 42}); err != nil {
+		t.Errorf("should not raise any error when trigger event pay, got %v", err)
+	}
+
+	if order.State != "paid" || paid != 42 {
+		t.Errorf("typed hooks should read the payload, got %d", paid)
+	}
+
+	if len(calls) != 3 || calls[0] != "before" || calls[1] != "typed before" || calls[2] != "typed after" {
+		t.Errorf("unexpected hooks order %v", calls)
+	}
+}
+
+func TestTypedEventPayloadType(t *testing.T) {
+	orderStateMachine := getStateMachine()
+	pay := DefineEvent[*Order, PaymentInfo](orderStateMachine, "pay")
+	pay.Before(func(order *Order, payment PaymentInfo) error { return nil })
+
+	order := &Order{}
+	order.State = "checkout"
+	_, err := orderStateMachine.Execute(context.Background(), TriggerCommand{Event: "pay", Payload: Review{Score: 1}}, order)
+	if !errors.Is(err, ErrPayloadType) || order.State != "checkout" {
+		t.Errorf("a payload of another type should fail the trigger with ErrPayloadType, got %v in state %s", err, order.State)
+	}
+
+	if err := orderStateMachine.Trigger("pay", order); err != nil || order.State != "paid" {
+		t.Errorf("triggers without payload should run the hooks with the zero value, got %v", err)
+	}
+}
+
+type Review struct {
+	Score int
+}
+
+func TestTypedEventToFunc(t *testing.T) {
+	orderStateMachine := getStateMachine()
+	orderStateMachine.State("approved")
+	orderStateMachine.State("rejected")
+	orderStateMachine.State("escalated")
+
+	review := DefineEvent[*Order, Review](orderStateMachine, "review")
+	review.ToFunc(func(order *Order, review Review) (string, error) {
+		switch {
+		case review.Score < 0:
+			return "unknown", nil
+		case review.Score >= 80:
+			return "approved", nil
+		case review.Score >= 50:
+			return "escalated", nil
+		}
+		return "rejected", nil
+	}).From("checkout")
+
+	for score, expected := range map[int]string{90: "approved", 60: "escalated", 10: "rejected"} {
+		order := &Order{}
+		order.State = "checkout"
+		if err := review.Trigger(order, Review{Score: score}); err != nil {
+			t.Errorf("should not raise any error when trigger event review, got %v", err)
+		}
+		if order.State != expected {
+			t.Errorf("score %d should go to state %s, got %s", score, expected, order.State)
+		}
+	}
+
+	order := &Order{}
+	order.State = "checkout"
+	err := review.Trigger(order, Review{Score: -1})
+	if !errors.Is(err, ErrUndeclaredState) {
+		t.Errorf("unknown destination should raise ErrUndeclaredState, got %v", err)
+	}
+	if order.State != "checkout" {
+		t.Errorf("state should not change when the destination is unknown, got %s", order.State)
+	}
+}
+
+func TestTypedEventJSONPayload(t *testing.T) {
+	var paid int
+	orderStateMachine := getStateMachine()
+	pay := DefineEvent[*Order, PaymentInfo](orderStateMachine, "pay")
+	pay.After(func(order *Order, payment PaymentInfo) error {
+		paid = payment.Amount
+		return nil
+	})
+
+	// Commands travel through queues as JSON, their payload is decoded as generic JSON values
+	data, err := json.Marshal(TriggerCommand{Event: "pay", Payload: PaymentInfo{Amount: 42}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var command TriggerCommand
+	if err := json.Unmarshal(data, &command); err != nil {
+		t.Fatal(err)
+	}
+
+	order := &Order{}
+	order.State = "checkout"
+	if _, err := orderStateMachine.Execute(context.Background(), command, order); err != nil || paid != 42 {
+		t.Errorf("the JSON payload should be decoded into the payload type, got %d, %v", paid, err)
+	}
+
+	order.State = "checkout"
+	command.Payload = map[string]any{"Amount": "42"}
+	if _, err := orderStateMachine.Execute(context.Background(), command, order); !errors.Is(err, ErrPayloadType) || order.State != "checkout" {
+		t.Errorf("a JSON payload not decodable into the payload type should fail the trigger, got %v", err)
+	}
+}
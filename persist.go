@@ -0,0 +1,26 @@
+package transition
+
+import "context"
+
+// Persister saves values whose state a trigger changed, e.g. in their table, see StateMachine.SetPersister
+type Persister[T Stater] interface {
+	// Persist is called last, once the trigger succeeded and its change was logged, with the context of the trigger.
+	// An error fails the trigger, which is reverted, so the value in memory stays as it is stored
+	Persist(ctx context.Context, value T) error
+}
+
+// PersisterFunc is a func implementing Persister
+type PersisterFunc[T Stater] func(ctx context.Context, value T) error
+
+// Persist calls fc
+func (fc PersisterFunc[T]) Persist(ctx context.Context, value T) error {
+	return fc(ctx, value)
+}
+
+// SetPersister define how values are saved once a trigger changed their state. Without persister, saving them is
+// up to the caller
+func (sm *StateMachine[T]) SetPersister(persister Persister[T]) *StateMachine[T] {
+	sm.owner.checkMutable("SetPersister")
+	sm.persister = persister
+	return sm
+}
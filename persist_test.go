@@ -0,0 +1,59 @@
+package transition
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestPersister(t *testing.T) {
+	var (
+		orderStateMachine = getStateMachine()
+		saved             []string
+		steps             []string
+	)
+	orderStateMachine.SetStateChangeLog(StateChangeLogFunc(func(ctx context.Context, change StateChange) error {
+		steps = append(steps, "log")
+		return nil
+	}))
+	orderStateMachine.SetPersister(PersisterFunc[*Order](func(ctx context.Context, order *Order) error {
+		steps = append(steps, "persist")
+		saved = append(saved, order.GetState()+" by "+ActorFromContext(ctx))
+		return nil
+	}))
+
+	order := &Order{}
+	if err := orderStateMachine.TriggerContext(WithActor(context.Background(), "alice"), "checkout", order); err != nil {
+		t.Fatal(err)
+	}
+	if len(saved) != 1 || saved[0] != "checkout by alice" {
+		t.Errorf("the value should be persisted with the context of the trigger, got %v", saved)
+	}
+	if len(steps) != 2 || steps[0] != "log" || steps[1] != "persist" {
+		t.Errorf("the value should be persisted once its change is logged, got %v", steps)
+	}
+
+	if err := orderStateMachine.Trigger("checkout", order); err == nil || len(saved) != 1 {
+		t.Errorf("failed triggers should not be persisted, got %v, %v", err, saved)
+	}
+}
+
+func TestPersisterFailure(t *testing.T) {
+	var (
+		orderStateMachine = getStateMachine()
+		errPersist        = errors.New("connection refused")
+	)
+	orderStateMachine.SetPersister(PersisterFunc[*Order](func(ctx context.Context, order *Order) error {
+		return errPersist
+	}))
+
+	order := &Order{}
+	err := orderStateMachine.Trigger("checkout", order)
+	var transitionErr *TransitionError
+	if !errors.As(err, &transitionErr) || transitionErr.Phase != PhasePersist || !errors.Is(err, errPersist) {
+		t.Fatalf("expected a persist failure, got %v", err)
+	}
+	if order.GetState() != "draft" || order.GetPreviousState() != "" {
+		t.Errorf("values that can't be persisted should be reverted, got %+v", order.Transition)
+	}
+}
@@ -0,0 +1,112 @@
+package transition
+
+import (
+	"context"
+	"fmt"
+)
+
+// Preview describe what triggering an event would do to a value, see StateMachine.Preview
+type Preview struct {
+	Event string
+	From  string
+	To    string
+	// Steps are the hooks the trigger would run, in order
+	Steps []PreviewStep
+}
+
+// PreviewStep is a hook a trigger would run, Name is the hook's owner (state or event) and its registration index
+// like in traces and errors, Ref names hooks registered with a name, see HookManifest
+type PreviewStep struct {
+	Phase Phase
+	Name  string
+	Ref   HookRef
+}
+
+// Preview tells where triggering an event would move value and which hooks it would run, without running them or
+// modifying value. Authorization, guards and the ToFunc of dynamic transitions are evaluated, events rejected like
+// Trigger would return the same *TransitionError. Events of sub-machines are unknown to Preview, preview them on the
+// sub-machine
+func (sm *StateMachine[T]) Preview(name string, value T) (Preview, error) {
+	return sm.PreviewContext(context.Background(), name, value)
+}
+
+// PreviewContext preview an event like Preview, ctx is passed to guards, e.g. to carry a GuardCache
+func (sm *StateMachine[T]) PreviewContext(ctx context.Context, name string, value T) (Preview, error) {
+	preview := Preview{Event: name}
+	if err := checkTriggerArgs(name, value); err != nil {
+		return preview, &TransitionError{Event: name, Phase: PhaseMatch, Err: err}
+	}
+
+	preview.From = sm.currentState(value)
+	reject := func(err error) (Preview, error) {
+		return preview, &TransitionError{Event: name, From: preview.From, Phase: PhaseMatch, Err: err}
+	}
+	if sm.isFrozen(name, value) {
+		return reject(ErrValueFrozen)
+	}
+
+	event := sm.events[name]
+	if event == nil {
+		return reject(ErrUnknownEvent)
+	}
+	if err := sm.checkAuthorization(ctx, name, event, value); err != nil {
+		return preview, &TransitionError{Event: name, From: preview.From, Phase: PhaseAuthorize, Err: err}
+	}
+	if err := sm.blocked(name, preview.From, value); err != nil {
+		return reject(err)
+	}
+
+	matched, rejected := sm.match(ctx, event, preview.From, value)
+	switch {
+	case len(matched) > 1:
+		return reject(ErrAmbiguousTransition)
+	case len(matched) == 0:
+		matchErr := ErrNoMatchingTransition
+		if guardErr := firstRejection(event, rejected); guardErr != nil {
+			matchErr = fmt.Errorf("%w: %w", ErrNoMatchingTransition, guardErr)
+		}
+		return preview, &TransitionError{Event: name, From: preview.From, Phase: PhaseMatch, AllowedFrom: event.allowedFrom(), Err: matchErr}
+	}
+
+	transition := matched[0]
+	to, err := sm.destination(transition, value, nil)
+	if err != nil {
+		return reject(err)
+	}
+	preview.To = to
+
+	// the hooks are listed in the order perform runs them
+	step := func(phase Phase, owner string, index int, refs []HookRef) {
+		previewed := PreviewStep{Phase: phase, Name: hookName(owner, index)}
+		if index < len(refs) {
+			previewed.Ref = refs[index]
+		}
+		preview.Steps = append(preview.Steps, previewed)
+	}
+	if state, ok := sm.states[preview.From]; ok {
+		for i := range state.exits {
+			step(PhaseExit, preview.From, i, state.exitRefs)
+		}
+	}
+	for i := range sm.befores {
+		step(PhaseBefore, machineHookOwner, i, nil)
+	}
+	for i := 0; i < len(transition.befores)+len(event.payloadBefores)+len(transition.pendingBefores); i++ {
+		step(PhaseBefore, name, i, transition.beforeRefs)
+	}
+	if state, ok := sm.states[to]; ok {
+		for i := range state.enters {
+			step(PhaseEnter, to, i, state.enterRefs)
+		}
+		if len(state.invariants) > 0 {
+			step(PhaseInvariant, to, 0, nil)
+		}
+	}
+	for i := 0; i < len(transition.afters)+len(event.payloadAfters)+len(transition.notifiers)+len(transition.emitters); i++ {
+		step(PhaseAfter, name, i, transition.afterRefs)
+	}
+	for i := range sm.afters {
+		step(PhaseAfter, machineHookOwner, i, nil)
+	}
+	return preview, nil
+}
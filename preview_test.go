@@ -0,0 +1,55 @@
+package transition
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestPreview(t *testing.T) {
+	orderStateMachine := getStateMachine()
+	noop := func(*Order) error { return nil }
+	orderStateMachine.State("draft").Exit(noop)
+	orderStateMachine.State("checkout").EnterNamed("reserve_stock", noop)
+	orderStateMachine.Event("checkout").To("checkout").Before(noop).After(noop)
+	orderStateMachine.AfterEach(func(context.Context, *Order, TransitionInfo) error { return nil })
+
+	order := &Order{}
+	preview, err := orderStateMachine.Preview("checkout", order)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var steps []string
+	for _, step := range preview.Steps {
+		steps = append(steps, string(step.Phase)+" "+step.Name+" "+step.Ref.Name)
+	}
+	expected := []string{"exit draft#0 ", "before checkout#0 ", "enter checkout#0 reserve_stock", "after checkout#0 ", "after machine#0 "}
+	if preview.From != "draft" || preview.To != "checkout" || !reflect.DeepEqual(steps, expected) {
+		t.Errorf("expected %v from draft to checkout, got %v from %s to %s", expected, steps, preview.From, preview.To)
+	}
+	if order.GetState() != "" {
+		t.Errorf("previewing should not modify the value, got state %s", order.GetState())
+	}
+}
+
+func TestPreviewRejected(t *testing.T) {
+	orderStateMachine := getStateMachine()
+	errDeclined := errors.New("payment declined")
+	orderStateMachine.Event("pay").To("paid").Guard(func(context.Context, *Order) error { return errDeclined })
+
+	order := &Order{}
+	order.SetState("checkout")
+	_, previewErr := orderStateMachine.Preview("pay", order)
+	triggerErr := orderStateMachine.Trigger("pay", order)
+	if !errors.Is(previewErr, ErrNoMatchingTransition) || !errors.Is(previewErr, errDeclined) || previewErr.Error() != triggerErr.Error() {
+		t.Errorf("previews should be rejected like triggers, got %v and %v", previewErr, triggerErr)
+	}
+
+	if _, err := orderStateMachine.Preview("archive", order); !errors.Is(err, ErrUnknownEvent) {
+		t.Errorf("expected ErrUnknownEvent, got %v", err)
+	}
+	if _, err := orderStateMachine.Preview("pay", nil); !errors.Is(err, ErrNilValue) {
+		t.Errorf("expected ErrNilValue, got %v", err)
+	}
+}
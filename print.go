@@ -0,0 +1,81 @@
+package transition
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// Sprint renders the state machine as a compact text table, see Fprint
+func (sm *StateMachine[T]) Sprint() string {
+	var builder strings.Builder
+	sm.Fprint(&builder)
+	return builder.String()
+}
+
+// Fprint writes the state machine as a compact text table to w: one line per state with its
+// hook counts, the initial state flagged with *, then one line per transition formatted as
+// `event: from1,from2 -> to`. Transitions accepting any state are rendered from *. The states of the sub-machine of
+// a composite state are listed under it as state/sub-state, its entry state flagged with *
+func (sm *StateMachine[T]) Fprint(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 1, ' ', 0)
+
+	fmt.Fprintln(tw, "states:")
+	var states []string
+	for name := range sm.states {
+		states = append(states, name)
+	}
+	if _, ok := sm.states[sm.initialState]; !ok && sm.initialState != "" {
+		states = append(states, sm.initialState)
+	}
+	sort.Strings(states)
+
+	for _, name := range states {
+		flag := " "
+		if sm.isInitial(name) {
+			flag = "*"
+		}
+
+		var enters, exits, invariants int
+		state, ok := sm.states[name]
+		if ok {
+			enters, exits, invariants = len(state.enters), len(state.exits), len(state.invariants)
+		}
+		fmt.Fprintf(tw, "%s %s\t(enter %d, exit %d, invariant %d)\n", flag, name, enters, exits, invariants)
+
+		if ok && state.sub != nil {
+			for _, sub := range state.sub.states {
+				flag := " "
+				if sub == state.sub.entry {
+					flag = "*"
+				}
+				fmt.Fprintf(tw, "  %s %s/%s\t\n", flag, name, sub)
+			}
+		}
+	}
+
+	fmt.Fprintln(tw, "events:")
+	var events []string
+	for name := range sm.events {
+		events = append(events, name)
+	}
+	sort.Strings(events)
+
+	for _, name := range events {
+		for _, transition := range sm.events[name].transitions {
+			froms := transition.fromList()
+			if len(froms) == 0 {
+				froms = []string{"*"}
+			}
+			to := transition.to
+			if transition.toFunc != nil {
+				to = "?"
+			}
+			fmt.Fprintf(tw, "  %s:\t%s\t-> %s\t(before %d, after %d)\n", name, strings.Join(froms, ","), to, len(transition.befores)+len(transition.pendingBefores), len(transition.afters)+len(transition.notifiers))
+		}
+	}
+
+	return tw.Flush()
+}
@@ -0,0 +1,42 @@
+package transition
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func TestSprint(t *testing.T) {
+	orderStateMachine := getStateMachine()
+	orderStateMachine.State("checkout").Enter(func(order *Order) error { return nil })
+	cancelEvent := orderStateMachine.Event("cancel")
+	cancelEvent.To("cancelled").From("draft", "checkout")
+	cancelEvent.To("paid_cancelled").From("paid", "processed").After(func(order *Order) error { return nil })
+	orderStateMachine.Event("reset").To("draft")
+
+	output := orderStateMachine.Sprint()
+	golden := filepath.Join("testdata", "sprint.golden")
+	if *update {
+		if err := os.WriteFile(golden, []byte(output), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if output != string(expected) {
+		t.Errorf("unexpected output, got\n%s\nexpected\n%s", output, expected)
+	}
+
+	for i := 0; i < 10; i++ {
+		if orderStateMachine.Sprint() != output {
+			t.Fatalf("Sprint output is not deterministic")
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package transition
+
+import (
+	"context"
+)
+
+// MachineHook is a hook of the state machine run on every transition, see BeforeEach and AfterEach
+type MachineHook[T Stater] func(ctx context.Context, value T, info TransitionInfo) error
+
+// machineHookOwner names the hooks of the state machine in errors and traces
+const machineHookOwner = "machine"
+
+// BeforeEach register a hook run on every transition, before the before hooks of the transition
+func (sm *StateMachine[T]) BeforeEach(hook MachineHook[T]) *StateMachine[T] {
+	sm.owner.checkMutable("BeforeEach")
+	sm.befores = append(sm.befores, hook)
+	return sm
+}
+
+// AfterEach register a hook run on every transition, after the after hooks and notifiers of the transition
+func (sm *StateMachine[T]) AfterEach(hook MachineHook[T]) *StateMachine[T] {
+	sm.owner.checkMutable("AfterEach")
+	sm.afters = append(sm.afters, hook)
+	return sm
+}
+
+// bindMachineHook binds hook to the context and transition of a trigger
+func bindMachineHook[T Stater](ctx context.Context, hook MachineHook[T], info TransitionInfo) func(value T) error {
+	return func(value T) error {
+		return hook(ctx, value, info)
+	}
+}
+
+// ProfileHook is a hook of a Profile, it receives values of any state machine the profile is applied to
+type ProfileHook func(ctx context.Context, value Stater, info TransitionInfo) error
+
+// Profile bundles hooks and settings shared by state machines of different value types, e.g. auditing every
+// transition of orders and shipments alike, see StateMachine.ApplyProfile
+type Profile struct {
+	// Before and After are registered with BeforeEach and AfterEach
+	Before []ProfileHook
+	After  []ProfileHook
+	// OnError are registered with OnError
+	OnError []func(err error)
+	// ActorFunc, RolesFunc and CorrelationIDFunc are set unless nil, see SetActorFunc, SetRolesFunc and SetCorrelationIDFunc
+	ActorFunc         func(ctx context.Context) string
+	RolesFunc         func(ctx context.Context) []string
+	CorrelationIDFunc func(ctx context.Context) string
+	// Stats enables stats, see EnableStats
+	Stats bool
+}
+
+// ApplyProfile register the hooks and settings of profile, hooks and settings registered afterwards layer on top:
+// hooks run after those of the profile and settings replace those of the profile
+func (sm *StateMachine[T]) ApplyProfile(profile Profile) *StateMachine[T] {
+	for _, hook := range profile.Before {
+		sm.BeforeEach(adaptProfileHook[T](hook))
+	}
+	for _, hook := range profile.After {
+		sm.AfterEach(adaptProfileHook[T](hook))
+	}
+	for _, onError := range profile.OnError {
+		sm.OnError(onError)
+	}
+	if profile.ActorFunc != nil {
+		sm.SetActorFunc(profile.ActorFunc)
+	}
+	if profile.RolesFunc != nil {
+		sm.SetRolesFunc(profile.RolesFunc)
+	}
+	if profile.CorrelationIDFunc != nil {
+		sm.SetCorrelationIDFunc(profile.CorrelationIDFunc)
+	}
+	if profile.Stats {
+		sm.EnableStats()
+	}
+	return sm
+}
+
+func adaptProfileHook[T Stater](hook ProfileHook) MachineHook[T] {
+	return func(ctx context.Context, value T, info TransitionInfo) error {
+		return hook(ctx, value, info)
+	}
+}
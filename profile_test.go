@@ -0,0 +1,80 @@
+package transition
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestApplyProfile(t *testing.T) {
+	var audit []string
+	profile := Profile{
+		After: []ProfileHook{func(ctx context.Context, value Stater, info TransitionInfo) error {
+			audit = append(audit, fmt.Sprintf("%T %s: %s -> %s by %s", value, info.Event, info.From, info.To, ActorFromContext(ctx)))
+			return nil
+		}},
+		Stats: true,
+	}
+
+	orderStateMachine := getStateMachine().ApplyProfile(profile)
+	shipmentStateMachine := getShipmentStateMachine().ApplyProfile(profile)
+
+	ctx := WithActor(context.Background(), "alice")
+	if err := orderStateMachine.TriggerContext(ctx, "checkout", &Order{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := shipmentStateMachine.TriggerContext(ctx, "start_packing", &Shipment{}); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "*transition.Order checkout: draft -> checkout by alice\n*transition.Shipment start_packing: pending -> packing by alice"
+	if got := strings.Join(audit, "\n"); got != expected {
+		t.Errorf("both machines should emit the shared audit records, got\n%s", got)
+	}
+	if len(orderStateMachine.Stats().Transitions) != 1 || len(shipmentStateMachine.Stats().Transitions) != 1 {
+		t.Errorf("the profile should enable stats")
+	}
+}
+
+func TestMachineHooksOrder(t *testing.T) {
+	var calls []string
+	record := func(name string) func(*Order) error {
+		return func(*Order) error {
+			calls = append(calls, name)
+			return nil
+		}
+	}
+	recordEach := func(name string) MachineHook[*Order] {
+		return func(ctx context.Context, order *Order, info TransitionInfo) error {
+			return record(name)(order)
+		}
+	}
+
+	orderStateMachine := getStateMachine().ApplyProfile(Profile{
+		Before: []ProfileHook{func(context.Context, Stater, TransitionInfo) error {
+			calls = append(calls, "profile before")
+			return nil
+		}},
+	})
+	orderStateMachine.BeforeEach(recordEach("machine before")).AfterEach(recordEach("machine after"))
+	orderStateMachine.Event("checkout").To("checkout").Before(record("before")).After(record("after"))
+
+	if err := orderStateMachine.Trigger("checkout", &Order{}); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(calls, ", "); got != "profile before, machine before, before, after, machine after" {
+		t.Errorf("unexpected hooks order %s", got)
+	}
+
+	// a clone can be modified until it triggers
+	failing := orderStateMachine.Clone()
+	failing.AfterEach(func(context.Context, *Order, TransitionInfo) error { return errors.New("audit unavailable") })
+	order := &Order{}
+	err := failing.Trigger("checkout", order)
+	var transitionErr *TransitionError
+	if !errors.As(err, &transitionErr) || transitionErr.Hook != "machine#1" || transitionErr.Phase != PhaseAfter || order.State != "draft" {
+		t.Errorf("a failing machine hook should fail the trigger, got %v", err)
+	}
+}
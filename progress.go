@@ -0,0 +1,192 @@
+package transition
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ProgressHook is an enter hook doing long running work, e.g. generating shipping labels, it reports its progress
+// and stops early once the trigger is cancelled, see State.EnterProgress
+type ProgressHook[T Stater] func(ctx context.Context, value T, progress *HookProgress) error
+
+type progressHook[T Stater] struct {
+	fc ProgressHook[T]
+}
+
+// HookProgress is passed to progress hooks to report their progress, see StateMachine.InFlight
+type HookProgress struct {
+	ctx       context.Context
+	execution *execution
+	// id is the id of the execution, which is reused by other triggers once the hook returned
+	id uint64
+}
+
+// Report record that done out of total units of work are done
+func (progress *HookProgress) Report(done, total int) {
+	progress.execution.report(progress.id, done, total)
+}
+
+// Cancelled reports whether the context of the trigger is done, the hook should then return its error
+func (progress *HookProgress) Cancelled() bool {
+	return progress.ctx.Err() != nil
+}
+
+// EnterProgress register an enter hook for State reporting its progress, see ProgressHook
+func (state *State[T]) EnterProgress(fc ProgressHook[T]) *State[T] {
+	state.checkMutable("State.EnterProgress")
+	ref := anonymousHook(2)
+	ref.progress = &progressHook[T]{fc: fc}
+	state.enters = append(state.enters, bindProgress(context.Background(), fc, nil))
+	state.enterRefs = append(state.enterRefs, ref)
+	return state
+}
+
+// bindProgress returns the hook running fc with the context and the execution of a trigger
+func bindProgress[T Stater](ctx context.Context, fc ProgressHook[T], execution *execution) func(value T) error {
+	return func(value T) error {
+		progress := &HookProgress{ctx: ctx, execution: execution}
+		if execution != nil {
+			progress.id = execution.id
+		}
+		return fc(ctx, value, progress)
+	}
+}
+
+// Progress is the progress last reported by a progress hook, see HookProgress.Report
+type Progress struct {
+	Done  int `json:"done"`
+	Total int `json:"total"`
+}
+
+// InFlightTrigger is a trigger being executed, see StateMachine.InFlight
+type InFlightTrigger struct {
+	Event string `json:"event"`
+	From  string `json:"from"`
+	// To is set once the transition is matched
+	To string `json:"to,omitempty"`
+	// Phase and Hook are those of the hook being run, Hook is empty until the first one
+	Phase Phase  `json:"phase,omitempty"`
+	Hook  string `json:"hook,omitempty"`
+	// Progress is set once the hook being run reported its progress
+	Progress *Progress `json:"progress,omitempty"`
+	Started  time.Time `json:"started"`
+}
+
+// InFlight returns the triggers being executed, in the order they started. Triggers of sub-machines and chained
+// triggers are listed on their own
+func (sm *StateMachine[T]) InFlight() []InFlightTrigger {
+	return sm.executions.snapshot()
+}
+
+// executions tracks the triggers being executed, its zero value is ready to use
+type executions struct {
+	mu      sync.Mutex
+	next    uint64
+	running map[uint64]*execution
+	// stopping, workers, draining and idle are those of Shutdown, see shutdown.go
+	stopping atomic.Bool
+	workers  int
+	draining chan struct{}
+	idle     chan struct{}
+	// pool holds the finished executions, they're only reused by the triggers of the same state machine
+	pool sync.Pool
+}
+
+// execution is a trigger being executed, its fields are guarded by the mutex of its executions. Executions are
+// pooled, so tracking triggers doesn't allocate, executions never changes once set
+type execution struct {
+	executions *executions
+	id         uint64
+	trigger    InFlightTrigger
+	// hookOwner and hookIndex name the hook being run, formatted into trigger.Hook by snapshot
+	hookOwner string
+	hookIndex int
+}
+
+// start track a trigger until finish is called. Once the state machine drains, only nested triggers start, the
+// others fail with ErrShuttingDown
+func (executions *executions) start(event, from string, now time.Time, nested bool) (*execution, error) {
+	executions.mu.Lock()
+	defer executions.mu.Unlock()
+
+	if executions.stopping.Load() && !nested {
+		return nil, ErrShuttingDown
+	}
+
+	if executions.running == nil {
+		executions.running = map[uint64]*execution{}
+	}
+	executions.next++
+	current, _ := executions.pool.Get().(*execution)
+	if current == nil {
+		current = &execution{}
+	}
+	*current = execution{executions: executions, id: executions.next, trigger: InFlightTrigger{Event: event, From: from, Started: now}}
+	executions.running[current.id] = current
+	return current, nil
+}
+
+// finish stop tracking the trigger, current must not be used afterwards
+func (current *execution) finish() {
+	executions := current.executions
+	executions.mu.Lock()
+	defer executions.mu.Unlock()
+
+	delete(executions.running, current.id)
+	executions.settle()
+	*current = execution{executions: executions}
+	executions.pool.Put(current)
+}
+
+func (executions *executions) snapshot() []InFlightTrigger {
+	executions.mu.Lock()
+	defer executions.mu.Unlock()
+
+	running := make([]*execution, 0, len(executions.running))
+	for _, current := range executions.running {
+		running = append(running, current)
+	}
+	sort.Slice(running, func(i, j int) bool {
+		return running[i].id < running[j].id
+	})
+
+	triggers := make([]InFlightTrigger, len(running))
+	for i, current := range running {
+		triggers[i] = current.trigger
+		if current.hookOwner != "" {
+			triggers[i].Hook = hookName(current.hookOwner, current.hookIndex)
+		}
+		if current.trigger.Progress != nil {
+			progress := *current.trigger.Progress
+			triggers[i].Progress = &progress
+		}
+	}
+	return triggers
+}
+
+// running record the hook the trigger runs, see hookName, nil executions aren't tracked
+func (current *execution) running(phase Phase, owner string, index int, to string) {
+	if current == nil {
+		return
+	}
+	current.executions.mu.Lock()
+	defer current.executions.mu.Unlock()
+	current.trigger.Phase, current.trigger.To, current.trigger.Progress = phase, to, nil
+	current.hookOwner, current.hookIndex = owner, index
+}
+
+// report record the progress of the hook the execution id runs, reports of hooks of finished executions are ignored
+func (current *execution) report(id uint64, done, total int) {
+	if current == nil {
+		return
+	}
+	current.executions.mu.Lock()
+	defer current.executions.mu.Unlock()
+	if current.id != id {
+		return
+	}
+	current.trigger.Progress = &Progress{Done: done, Total: total}
+}
@@ -0,0 +1,112 @@
+package transition
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestEnterProgress(t *testing.T) {
+	reported, proceed := make(chan struct{}), make(chan struct{})
+	orderStateMachine := getStateMachine()
+	orderStateMachine.State("checkout").Enter(func(order *Order) error { return nil }).EnterProgress(func(ctx context.Context, order *Order, progress *HookProgress) error {
+		for done := 0; done < 500; done += 100 {
+			if progress.Cancelled() {
+				return ctx.Err()
+			}
+			progress.Report(done, 500)
+			reported <- struct{}{}
+			<-proceed
+		}
+		return nil
+	})
+
+	if len(orderStateMachine.InFlight()) != 0 {
+		t.Errorf("no trigger should be in flight")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	order := &Order{}
+	errs := make(chan error, 1)
+	go func() {
+		errs <- orderStateMachine.TriggerContext(ctx, "checkout", order)
+	}()
+
+	<-reported
+	inFlight := orderStateMachine.InFlight()
+	if len(inFlight) != 1 {
+		t.Fatalf("the checkout should be in flight, got %v", inFlight)
+	}
+	if trigger := inFlight[0]; trigger.Event != "checkout" || trigger.From != "draft" || trigger.To != "checkout" || trigger.Phase != PhaseEnter || trigger.Hook != "checkout#1" || trigger.Progress == nil || *trigger.Progress != (Progress{Done: 0, Total: 500}) {
+		t.Errorf("unexpected in flight trigger %+v", trigger)
+	}
+
+	proceed <- struct{}{}
+	<-reported
+	if progress := orderStateMachine.InFlight()[0].Progress; progress == nil || progress.Done != 100 {
+		t.Errorf("the latest progress should be reported, got %v", progress)
+	}
+
+	cancel()
+	proceed <- struct{}{}
+	if err := <-errs; !errors.Is(err, context.Canceled) || order.GetState() != "draft" {
+		t.Errorf("cancelled progress hooks should fail the trigger, got %v in state %s", err, order.GetState())
+	}
+	if len(orderStateMachine.InFlight()) != 0 {
+		t.Errorf("finished triggers shouldn't be in flight, got %v", orderStateMachine.InFlight())
+	}
+}
+
+func TestEnterProgressRunsAsEnterHook(t *testing.T) {
+	orderStateMachine := getStateMachine()
+	orderStateMachine.State("checkout").EnterProgress(func(ctx context.Context, order *Order, progress *HookProgress) error {
+		progress.Report(1, 1)
+		if progress.Cancelled() {
+			return ctx.Err()
+		}
+		order.Address = "reported"
+		return nil
+	})
+
+	order := &Order{}
+	if err := orderStateMachine.Trigger("checkout", order); err != nil || order.Address != "reported" {
+		t.Errorf("progress hooks should run like enter hooks, got %v", err)
+	}
+	if enters := orderStateMachine.Describe().States; enters[1].Name != "checkout" || enters[1].Enter != 1 {
+		t.Errorf("progress hooks should be described as enter hooks, got %+v", enters[1])
+	}
+}
+
+func TestEnterProgressAfterReturn(t *testing.T) {
+	var retained *HookProgress
+	reported, proceed := make(chan struct{}), make(chan struct{})
+	orderStateMachine := getStateMachine()
+	orderStateMachine.State("checkout").EnterProgress(func(ctx context.Context, order *Order, progress *HookProgress) error {
+		retained = progress
+		return nil
+	})
+	orderStateMachine.State("paid").EnterProgress(func(ctx context.Context, order *Order, progress *HookProgress) error {
+		reported <- struct{}{}
+		<-proceed
+		return nil
+	})
+
+	order := &Order{}
+	if err := orderStateMachine.Trigger("checkout", order); err != nil {
+		t.Fatal(err)
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- orderStateMachine.Trigger("pay", order)
+	}()
+	<-reported
+	retained.Report(1, 1)
+	if inFlight := orderStateMachine.InFlight(); len(inFlight) != 1 || inFlight[0].Event != "pay" || inFlight[0].Progress != nil {
+		t.Errorf("reports of finished triggers should be ignored, got %+v", inFlight)
+	}
+	proceed <- struct{}{}
+	if err := <-errs; err != nil {
+		t.Error(err)
+	}
+}
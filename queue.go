@@ -0,0 +1,293 @@
+package transition
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// QueueCommand is a command to execute on the value identified by its Key, see Queue. The command is stored and
+// executed as enqueued, except for Attempts which is the number of times it failed
+type QueueCommand struct {
+	// ID is assigned by the QueueStore
+	ID string `json:"id"`
+	TriggerCommand
+}
+
+// QueueStore stores queued commands, implement it to back a Queue with a database or a message broker.
+// Commands are delivered at least once: a dequeued command is delivered again unless acked
+type QueueStore interface {
+	// Enqueue stores a command, returning its ID
+	Enqueue(ctx context.Context, command QueueCommand) (string, error)
+	// Dequeue returns the next available command, ok is false when there is none
+	Dequeue(ctx context.Context) (command QueueCommand, ok bool, err error)
+	// Ack removes a command processed successfully, or given up on
+	Ack(ctx context.Context, id string) error
+	// Nack increments the attempts of a command and makes it available again at retryAt
+	Nack(ctx context.Context, id string, retryAt time.Time) error
+}
+
+// QueueOption configure a Queue
+type QueueOption func(*queueConfig)
+
+type queueConfig struct {
+	maxAttempts  int
+	backoff      func(attempts int) time.Duration
+	pollInterval time.Duration
+	deadLetter   func(command QueueCommand, err error)
+}
+
+// QueueMaxAttempts give up on commands failing n times, 5 by default
+func QueueMaxAttempts(n int) QueueOption {
+	return func(config *queueConfig) {
+		config.maxAttempts = n
+	}
+}
+
+// QueueBackoff define how long to wait before retrying a command that failed attempts times,
+// by default it doubles from a second up to a minute
+func QueueBackoff(backoff func(attempts int) time.Duration) QueueOption {
+	return func(config *queueConfig) {
+		config.backoff = backoff
+	}
+}
+
+// QueuePollInterval define how long Run waits when the queue is empty, a second by default
+func QueuePollInterval(d time.Duration) QueueOption {
+	return func(config *queueConfig) {
+		config.pollInterval = d
+	}
+}
+
+// QueueDeadLetter register a callback receiving the commands given up on, failing with a permanent error or too many times
+func QueueDeadLetter(fc func(command QueueCommand, err error)) QueueOption {
+	return func(config *queueConfig) {
+		config.deadLetter = fc
+	}
+}
+
+func defaultQueueBackoff(attempts int) time.Duration {
+	backoff := time.Second
+	for i := 1; i < attempts && backoff < time.Minute; i++ {
+		backoff *= 2
+	}
+	if backoff > time.Minute {
+		backoff = time.Minute
+	}
+	return backoff
+}
+
+// Queue is a worker triggering queued commands on values re-loaded with the state machine's resolver
+type Queue[T Stater] struct {
+	store  QueueStore
+	config queueConfig
+}
+
+// NewQueue initialize a queue of commands stored in store
+func NewQueue[T Stater](store QueueStore, opts ...QueueOption) *Queue[T] {
+	config := queueConfig{maxAttempts: 5, backoff: defaultQueueBackoff, pollInterval: time.Second}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	return &Queue[T]{store: store, config: config}
+}
+
+// Enqueue queue command to be executed on the value identified by its key, returning the command's ID
+func (queue *Queue[T]) Enqueue(ctx context.Context, command TriggerCommand) (string, error) {
+	if command.Event == "" {
+		return "", ErrEmptyEventName
+	}
+	command.Attempts = 0
+	return queue.store.Enqueue(ctx, QueueCommand{TriggerCommand: command})
+}
+
+// Run process commands with sm until ctx is done, waiting for new commands when the queue is empty.
+// It returns ctx's error, the first error of the store, or ErrShuttingDown once sm drains, see StateMachine.Shutdown
+func (queue *Queue[T]) Run(ctx context.Context, sm *StateMachine[T]) error {
+	done, err := sm.executions.work()
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	draining := sm.executions.drained()
+	for {
+		if err := queue.Drain(ctx, sm); err != nil {
+			return err
+		}
+
+		wake := make(chan struct{})
+		timer := sm.clock.AfterFunc(queue.config.pollInterval, func() { close(wake) })
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-draining:
+			timer.Stop()
+			return ErrShuttingDown
+		case <-wake:
+		}
+	}
+}
+
+// Drain process the available commands with sm until the queue is empty. Commands are acked when triggered
+// successfully, retried later on retryable errors, and dead-lettered on permanent errors or after too many attempts,
+// see QueueDeadLetter and StateMachine.OnDeadLetter. It returns ctx's error, the first error of the store, or
+// ErrShuttingDown once sm drains, Shutdown waits for the command being processed
+func (queue *Queue[T]) Drain(ctx context.Context, sm *StateMachine[T]) error {
+	if sm.resolver == nil {
+		return errors.New("queue requires a resolver, see StateMachine.SetResolver")
+	}
+	done, err := sm.executions.work()
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if sm.Draining() {
+			return ErrShuttingDown
+		}
+
+		command, ok, err := queue.store.Dequeue(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to dequeue command: %w", err)
+		}
+		if !ok {
+			return nil
+		}
+
+		if err := queue.process(ctx, sm, command); err != nil {
+			return err
+		}
+	}
+}
+
+func (queue *Queue[T]) process(ctx context.Context, sm *StateMachine[T], command QueueCommand) error {
+	value, err := sm.resolver.Resolve(command.Key)
+	if err == nil {
+		executed := command.TriggerCommand
+		executed.Attempts++
+		_, err = sm.execute(ctx, executed, value, triggerOptions{dequeued: true})
+	}
+	if err == nil {
+		return queue.store.Ack(ctx, command.ID)
+	}
+
+	command.Attempts++
+	if IsPermanent(err) || command.Attempts >= queue.config.maxAttempts {
+		if queue.config.deadLetter != nil {
+			queue.config.deadLetter(command, err)
+		}
+		sm.deadLetter(ctx, command.TriggerCommand, err)
+		return queue.store.Ack(ctx, command.ID)
+	}
+	return queue.store.Nack(ctx, command.ID, sm.clock.Now().Add(queue.config.backoff(command.Attempts)))
+}
+
+// MemoryQueueStore is an in-process QueueStore, commands are lost when the process exits
+type MemoryQueueStore struct {
+	clock    Clock
+	mu       sync.Mutex
+	lastID   int
+	commands map[string]*memoryCommand
+}
+
+type memoryCommand struct {
+	command     QueueCommand
+	availableAt time.Time
+	inFlight    bool
+}
+
+// NewMemoryQueueStore initialize a MemoryQueueStore, a nil clock uses the system clock
+func NewMemoryQueueStore(clock Clock) *MemoryQueueStore {
+	if clock == nil {
+		clock = realClock{}
+	}
+	return &MemoryQueueStore{clock: clock, commands: map[string]*memoryCommand{}}
+}
+
+// Enqueue store a command, available immediately
+func (store *MemoryQueueStore) Enqueue(_ context.Context, command QueueCommand) (string, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.lastID++
+	command.ID = strconv.Itoa(store.lastID)
+	store.commands[command.ID] = &memoryCommand{command: command, availableAt: store.clock.Now()}
+	return command.ID, nil
+}
+
+// Dequeue returns the available command enqueued first
+func (store *MemoryQueueStore) Dequeue(context.Context) (QueueCommand, bool, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	var (
+		next   *memoryCommand
+		nextID int
+		now    = store.clock.Now()
+	)
+	for _, entry := range store.commands {
+		if entry.inFlight || entry.availableAt.After(now) {
+			continue
+		}
+		if id, _ := strconv.Atoi(entry.command.ID); next == nil || id < nextID {
+			next, nextID = entry, id
+		}
+	}
+	if next == nil {
+		return QueueCommand{}, false, nil
+	}
+
+	next.inFlight = true
+	return next.command, true, nil
+}
+
+// Ack remove a command
+func (store *MemoryQueueStore) Ack(_ context.Context, id string) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	delete(store.commands, id)
+	return nil
+}
+
+// Nack make a command available again at retryAt
+func (store *MemoryQueueStore) Nack(_ context.Context, id string, retryAt time.Time) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	entry, ok := store.commands[id]
+	if !ok {
+		return fmt.Errorf("unknown command %s", id)
+	}
+	entry.command.Attempts++
+	entry.availableAt = retryAt
+	entry.inFlight = false
+	return nil
+}
+
+// Pending returns the stored commands, in-flight ones included, in enqueue order
+func (store *MemoryQueueStore) Pending() []QueueCommand {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	commands := make([]QueueCommand, 0, len(store.commands))
+	for _, entry := range store.commands {
+		commands = append(commands, entry.command)
+	}
+	sort.Slice(commands, func(i, j int) bool {
+		id1, _ := strconv.Atoi(commands[i].ID)
+		id2, _ := strconv.Atoi(commands[j].ID)
+		return id1 < id2
+	})
+	return commands
+}
@@ -0,0 +1,171 @@
+package transition_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/daegalus/transition"
+	"github.com/daegalus/transition/transitiontest"
+)
+
+func TestQueue(t *testing.T) {
+	var (
+		ctx               = context.Background()
+		clock             = transitiontest.NewTestClock(time.Now())
+		orders            = map[string]*Order{"1": {ID: "1"}, "2": {ID: "2"}, "3": {ID: "3"}}
+		orderStateMachine = getTimeoutStateMachine(clock, getOrderStore(orders["1"], orders["2"], orders["3"]))
+		store             = transition.NewMemoryQueueStore(clock)
+		deadLetters       []transition.QueueCommand
+		failures          = 2
+	)
+	orderStateMachine.Event("checkout").To("checkout").Before(func(order *Order) error {
+		if order.ID == "3" && failures > 0 {
+			failures--
+			return transition.Retryable(errors.New("database unavailable"))
+		}
+		return nil
+	})
+
+	queue := transition.NewQueue[*Order](store, transition.QueueMaxAttempts(3), transition.QueueDeadLetter(func(command transition.QueueCommand, err error) {
+		deadLetters = append(deadLetters, command)
+	}))
+	for _, command := range [][2]string{{"1", "checkout"}, {"2", "pay"}, {"3", "checkout"}, {"missing", "checkout"}} {
+		if _, err := queue.Enqueue(ctx, transition.TriggerCommand{Key: command[0], Event: command[1]}); err != nil {
+			t.Fatalf("should not raise any error when enqueuing, got %v", err)
+		}
+	}
+
+	if err := queue.Drain(ctx, orderStateMachine); err != nil {
+		t.Fatalf("should not raise any error when draining, got %v", err)
+	}
+	transitiontest.AssertState(t, orders["1"], "checkout")
+	transitiontest.AssertState(t, orders["3"], "draft")
+	if len(deadLetters) != 1 || deadLetters[0].Key != "2" {
+		t.Errorf("permanent failures should be dead-lettered, got %+v", deadLetters)
+	}
+	if pending := store.Pending(); len(pending) != 2 || pending[0].Attempts != 1 {
+		t.Errorf("retryable failures should be retried, got %+v", pending)
+	}
+
+	clock.Advance(time.Second)
+	if err := queue.Drain(ctx, orderStateMachine); err != nil {
+		t.Fatalf("should not raise any error when draining, got %v", err)
+	}
+	transitiontest.AssertState(t, orders["3"], "draft")
+
+	clock.Advance(time.Second)
+	if err := queue.Drain(ctx, orderStateMachine); err != nil || len(deadLetters) != 1 {
+		t.Fatalf("retry should wait for the backoff, got %v", err)
+	}
+
+	clock.Advance(time.Second)
+	if err := queue.Drain(ctx, orderStateMachine); err != nil {
+		t.Fatalf("should not raise any error when draining, got %v", err)
+	}
+	transitiontest.AssertState(t, orders["3"], "checkout")
+	if len(deadLetters) != 2 || deadLetters[1].Key != "missing" || deadLetters[1].Attempts != 3 {
+		t.Errorf("commands failing too many times should be dead-lettered, got %+v", deadLetters)
+	}
+	if pending := store.Pending(); len(pending) != 0 {
+		t.Errorf("queue should be empty, got %+v", pending)
+	}
+	if recorded := orderStateMachine.DeadLetters(); len(recorded) != 2 || recorded[1].Command.Key != "missing" || recorded[1].Command.Attempts != 3 {
+		t.Errorf("state machine should record dead letters, got %+v", recorded)
+	}
+}
+
+func TestQueueRun(t *testing.T) {
+	var (
+		clock             = transitiontest.NewTestClock(time.Now())
+		orders            = map[string]*Order{"1": {ID: "1"}}
+		orderStateMachine = getTimeoutStateMachine(clock, getOrderStore(orders["1"]))
+		queue             = transition.NewQueue[*Order](transition.NewMemoryQueueStore(clock))
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	orderStateMachine.State("checkout").Enter(func(*Order) error {
+		cancel()
+		return nil
+	})
+
+	if _, err := queue.Enqueue(ctx, transition.TriggerCommand{Key: "1", Event: "checkout"}); err != nil {
+		t.Fatalf("should not raise any error when enqueuing, got %v", err)
+	}
+	if err := queue.Run(ctx, orderStateMachine); !errors.Is(err, context.Canceled) {
+		t.Errorf("run should stop when the context is done, got %v", err)
+	}
+	transitiontest.AssertState(t, orders["1"], "checkout")
+}
+
+func TestQueueReplaysCommands(t *testing.T) {
+	var (
+		ctx               = context.Background()
+		clock             = transitiontest.NewTestClock(time.Now())
+		orders            = map[string]*Order{"1": {ID: "1"}}
+		orderStateMachine = getTimeoutStateMachine(clock, getOrderStore(orders["1"]))
+		queue             = transition.NewQueue[*Order](transition.NewMemoryQueueStore(clock))
+		replayed          transition.TriggerCommand
+	)
+	orderStateMachine.BeforeEach(func(ctx context.Context, order *Order, info transition.TransitionInfo) error {
+		replayed = transition.TriggerCommand{
+			Actor:         transition.ActorFromContext(ctx),
+			Reason:        transition.ReasonFromContext(ctx),
+			Args:          transition.ArgsFromContext(ctx),
+			CorrelationID: transition.CorrelationIDFromContext(ctx),
+		}
+		return nil
+	})
+
+	command := transition.TriggerCommand{
+		Key: "1", Event: "checkout", Actor: "billing", Reason: "cart submitted", Args: map[string]any{"coupon": "SPRING"},
+		CorrelationID: "req-42", Fingerprint: orderStateMachine.Fingerprint(),
+	}
+	if _, err := queue.Enqueue(ctx, command); err != nil {
+		t.Fatalf("should not raise any error when enqueuing, got %v", err)
+	}
+	if err := queue.Drain(ctx, orderStateMachine); err != nil {
+		t.Fatalf("should not raise any error when draining, got %v", err)
+	}
+	transitiontest.AssertState(t, orders["1"], "checkout")
+	expected := transition.TriggerCommand{Actor: "billing", Reason: "cart submitted", Args: map[string]any{"coupon": "SPRING"}, CorrelationID: "req-42"}
+	if !reflect.DeepEqual(replayed, expected) {
+		t.Errorf("queued commands should be executed as enqueued, got %+v", replayed)
+	}
+}
+
+func TestQueueStaleCommands(t *testing.T) {
+	var (
+		ctx               = context.Background()
+		clock             = transitiontest.NewTestClock(time.Now())
+		orders            = map[string]*Order{"1": {ID: "1"}}
+		orderStateMachine = getTimeoutStateMachine(clock, getOrderStore(orders["1"]))
+		store             = transition.NewMemoryQueueStore(clock)
+		deadLetters       []error
+	)
+	queue := transition.NewQueue[*Order](store, transition.QueueDeadLetter(func(command transition.QueueCommand, err error) {
+		deadLetters = append(deadLetters, err)
+	}))
+	if _, err := queue.Enqueue(ctx, transition.TriggerCommand{Key: "1", Event: "checkout", Fingerprint: orderStateMachine.Fingerprint()}); err != nil {
+		t.Fatalf("should not raise any error when enqueuing, got %v", err)
+	}
+
+	// deployed with another definition while the command waited in the queue
+	orderStateMachine.State("cancelled")
+	orderStateMachine.Event("cancel").To("cancelled").From("draft", "checkout")
+
+	if err := queue.Drain(ctx, orderStateMachine); err != nil {
+		t.Fatalf("should not raise any error when draining, got %v", err)
+	}
+	if state := orders["1"].GetState(); state == "checkout" {
+		t.Errorf("stale commands should not be triggered, got %s", state)
+	}
+	var staleErr *transition.StaleCommandError
+	if len(deadLetters) != 1 || !errors.Is(deadLetters[0], transition.ErrDefinitionChanged) || !errors.As(deadLetters[0], &staleErr) {
+		t.Errorf("commands built for another definition should be dead-lettered, got %v", deadLetters)
+	}
+	if pending := store.Pending(); len(pending) != 0 {
+		t.Errorf("stale commands should not be retried, got %+v", pending)
+	}
+}
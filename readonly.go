@@ -0,0 +1,104 @@
+package transition
+
+import "context"
+
+// MachineSnapshot is a read-only copy of the definition of a state machine, frozen when it was taken: definition
+// changes made to the state machine afterwards, e.g. with WithMutableAfterStart, don't affect it. Its methods
+// never modify it, so it's safe for concurrent use without locking, see StateMachine.ReadOnlySnapshot
+type MachineSnapshot[T Stater] struct {
+	sm *StateMachine[T]
+}
+
+// cachedSnapshot is the snapshot of a definition generation, see ownerGuard.generation
+type cachedSnapshot[T Stater] struct {
+	owner      *owner
+	generation uint64
+	snapshot   MachineSnapshot[T]
+}
+
+// ReadOnlySnapshot returns a snapshot of the definition of the state machine to inspect values on hot paths, e.g.
+// grabbed once per request:
+//
+//	snapshot := OrderStateMachine.ReadOnlySnapshot()
+//	if snapshot.Can("cancel", order) { ... }
+//
+// Snapshots share hooks and guards with the state machine, and the snapshot is only copied again after the
+// definition changed, so taking one is cheap
+func (sm *StateMachine[T]) ReadOnlySnapshot() MachineSnapshot[T] {
+	owner := sm.owner
+	generation := owner.generation.Load()
+	if cached := sm.readOnly.Load(); cached != nil && cached.owner == owner && cached.generation == generation {
+		return cached.snapshot
+	}
+
+	snapshot := MachineSnapshot[T]{sm: sm.frozenCopy()}
+	sm.readOnly.Store(&cachedSnapshot[T]{owner: owner, generation: generation, snapshot: snapshot})
+	return snapshot
+}
+
+// frozenCopy returns a copy of the definition of the state machine whose definition methods panic
+func (sm *StateMachine[T]) frozenCopy() *StateMachine[T] {
+	frozen := &owner{normalize: sm.owner.normalize}
+	frozen.started.Store(true)
+
+	states := make(map[string]*State[T], len(sm.states))
+	for name, state := range sm.states {
+		states[name] = state.clone(frozen)
+	}
+	events := make(map[string]*Event[T], len(sm.events))
+	for name, event := range sm.events {
+		events[name] = event.clone(frozen)
+	}
+
+	return &StateMachine[T]{
+		initialState: sm.initialState,
+		initials:     sm.initials,
+		states:       states,
+		events:       events,
+		owner:        frozen,
+
+		clock:         sm.clock,
+		keyFunc:       sm.keyFunc,
+		defaultLocale: sm.defaultLocale,
+		frozen:        sm.frozen,
+		blocks:        clip(sm.blocks),
+		befores:       clip(sm.befores),
+		afters:        clip(sm.afters),
+	}
+}
+
+// Can check if the event could be triggered for value from its current state, see StateMachine.Can
+func (snapshot MachineSnapshot[T]) Can(name string, value T) bool {
+	return snapshot.sm.Can(name, value)
+}
+
+// CanContext check if the event could be triggered like Can, see StateMachine.CanContext
+func (snapshot MachineSnapshot[T]) CanContext(ctx context.Context, name string, value T) bool {
+	return snapshot.sm.CanContext(ctx, name, value)
+}
+
+// AvailableEvents returns the sorted names of the events that could be triggered for value, see
+// StateMachine.AvailableEvents
+func (snapshot MachineSnapshot[T]) AvailableEvents(value T) []string {
+	return snapshot.sm.AvailableEvents(value)
+}
+
+// Explain describe whether event can be triggered for value from its current state, see StateMachine.Explain
+func (snapshot MachineSnapshot[T]) Explain(name string, value T) Explanation {
+	return snapshot.sm.Explain(name, value)
+}
+
+// Preview tells where triggering an event would move value and which hooks it would run, see StateMachine.Preview
+func (snapshot MachineSnapshot[T]) Preview(name string, value T) (Preview, error) {
+	return snapshot.sm.Preview(name, value)
+}
+
+// PreviewContext preview an event like Preview, see StateMachine.PreviewContext
+func (snapshot MachineSnapshot[T]) PreviewContext(ctx context.Context, name string, value T) (Preview, error) {
+	return snapshot.sm.PreviewContext(ctx, name, value)
+}
+
+// WhatIf walks events through the definition from state start, see StateMachine.WhatIf
+func (snapshot MachineSnapshot[T]) WhatIf(start string, events []string, opts ...WhatIfOption) (WhatIfResult, error) {
+	return snapshot.sm.WhatIf(start, events, opts...)
+}
@@ -0,0 +1,122 @@
+package transition
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestReadOnlySnapshot(t *testing.T) {
+	orderStateMachine := New(&Order{}, WithMutableAfterStart())
+	orderStateMachine.Initial("draft")
+	orderStateMachine.State("checkout")
+	orderStateMachine.State("paid")
+	orderStateMachine.Event("checkout").To("checkout").From("draft")
+	orderStateMachine.Event("pay").To("paid").From("checkout")
+
+	snapshot := orderStateMachine.ReadOnlySnapshot()
+	if again := orderStateMachine.ReadOnlySnapshot(); again.sm != snapshot.sm {
+		t.Errorf("snapshots of an unchanged definition should be shared")
+	}
+
+	order := &Order{}
+	if !snapshot.Can("checkout", order) || snapshot.Can("pay", order) {
+		t.Errorf("snapshot should match like the state machine")
+	}
+	if events := snapshot.AvailableEvents(order); !reflect.DeepEqual(events, []string{"checkout"}) {
+		t.Errorf("expected [checkout], got %v", events)
+	}
+	if explanation := snapshot.Explain("pay", order); explanation.String() != orderStateMachine.Explain("pay", order).String() {
+		t.Errorf("snapshot should explain like the state machine, got %s", explanation)
+	}
+	if preview, err := snapshot.Preview("checkout", order); err != nil || preview.To != "checkout" {
+		t.Errorf("snapshot should preview events, got %+v, %v", preview, err)
+	}
+	if result, err := snapshot.WhatIf("draft", []string{"checkout", "pay"}); err != nil || result.State() != "paid" {
+		t.Errorf("snapshot should walk events, got %s, %v", result.State(), err)
+	}
+
+	if err := orderStateMachine.Trigger("checkout", order); err != nil {
+		t.Fatal(err)
+	}
+	orderStateMachine.Event("pay").To("paid").From("checkout").Guard(func(ctx context.Context, order *Order) error {
+		return errors.New("payment declined")
+	})
+	orderStateMachine.Event("cancel").To("draft").From("checkout")
+
+	if !snapshot.Can("pay", order) || snapshot.Can("cancel", order) {
+		t.Errorf("snapshot should be frozen when it was taken")
+	}
+	changed := orderStateMachine.ReadOnlySnapshot()
+	if changed.sm == snapshot.sm || changed.Can("pay", order) || !changed.Can("cancel", order) {
+		t.Errorf("snapshots should be taken again after definition changes")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("the definition of snapshots should not be modifiable")
+		}
+	}()
+	changed.sm.Event("archive")
+}
+
+func TestReadOnlySnapshotConcurrent(t *testing.T) {
+	orderStateMachine := getStateMachine()
+	orderStateMachine.Frozen(func(order *Order) bool { return order.Address == "archived" })
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				order := &Order{}
+				if !orderStateMachine.ReadOnlySnapshot().Can("checkout", order) {
+					t.Errorf("checkout should be possible from draft")
+				}
+				if err := orderStateMachine.Trigger("checkout", order); err != nil {
+					t.Error(err)
+				}
+				if orderStateMachine.ReadOnlySnapshot().Can("checkout", &Order{Address: "archived"}) {
+					t.Errorf("snapshots should keep the frozen predicate")
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func BenchmarkCan(b *testing.B) {
+	b.Run("machine", func(b *testing.B) {
+		sm := getRingStateMachine()
+		b.RunParallel(func(pb *testing.PB) {
+			order := &Order{}
+			for pb.Next() {
+				sm.Can("advance", order)
+			}
+		})
+	})
+
+	b.Run("snapshot", func(b *testing.B) {
+		sm := getRingStateMachine()
+		b.RunParallel(func(pb *testing.PB) {
+			order := &Order{}
+			snapshot := sm.ReadOnlySnapshot()
+			for pb.Next() {
+				snapshot.Can("advance", order)
+			}
+		})
+	})
+
+	b.Run("snapshot per call", func(b *testing.B) {
+		sm := getRingStateMachine()
+		b.RunParallel(func(pb *testing.PB) {
+			order := &Order{}
+			for pb.Next() {
+				sm.ReadOnlySnapshot().Can("advance", order)
+			}
+		})
+	})
+}
@@ -0,0 +1,72 @@
+package transition
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidRedirect is returned by PendingTransition.SetDestination for states the event doesn't go to
+var ErrInvalidRedirect = errors.New("invalid redirect")
+
+// PendingTransition is the transition being performed, received by hooks registered with BeforePending
+type PendingTransition struct {
+	event   string
+	from    string
+	to      string
+	targets map[string]bool
+}
+
+// Event returns the name of the event being triggered
+func (pending *PendingTransition) Event() string {
+	return pending.event
+}
+
+// From returns the state the value is leaving
+func (pending *PendingTransition) From() string {
+	return pending.from
+}
+
+// To returns the state the value will land in
+func (pending *PendingTransition) To() string {
+	return pending.to
+}
+
+// SetDestination redirect the transition to state, which must be the destination of another transition of the
+// same event, otherwise an error wrapping ErrInvalidRedirect is returned and the destination is unchanged.
+// Guards aren't evaluated again, the hooks of the matched transition keep running, and the enter hooks and
+// invariants of the final destination are run. Hooks run later see the new destination and may redirect again
+func (pending *PendingTransition) SetDestination(state string) error {
+	if !pending.targets[state] {
+		return fmt.Errorf("%w: event %s has no transition to %s", ErrInvalidRedirect, pending.event, state)
+	}
+	pending.to = state
+	return nil
+}
+
+// BeforePending register a before hook receiving the pending transition, e.g. to redirect it to another destination
+// of the event. It runs after the other before hooks of the transition and the event
+func (transition *EventTransition[T]) BeforePending(fc func(value T, pending *PendingTransition) error) *EventTransition[T] {
+	transition.checkMutable("EventTransition.BeforePending")
+	transition.pendingBefores = append(transition.pendingBefores, fc)
+	return transition
+}
+
+// redirectTargets returns the declared destinations of the event's static transitions
+func (sm *StateMachine[T]) redirectTargets(event *Event[T]) map[string]bool {
+	targets := map[string]bool{}
+	for _, transition := range event.transitions {
+		if transition.toFunc != nil {
+			continue
+		}
+		if _, ok := sm.states[transition.to]; ok || transition.to == sm.initialState {
+			targets[transition.to] = true
+		}
+	}
+	return targets
+}
+
+func bindPending[T Stater](fc func(value T, pending *PendingTransition) error, pending *PendingTransition) func(value T) error {
+	return func(value T) error {
+		return fc(value, pending)
+	}
+}
@@ -0,0 +1,82 @@
+package transition
+
+import (
+	"errors"
+	"testing"
+)
+
+func getExpediteStateMachine() *StateMachine[*Order] {
+	orderStateMachine := getStateMachine()
+	orderStateMachine.State("priority_processing")
+	orderStateMachine.State("manual_review")
+	process := orderStateMachine.Event("process")
+	process.To("processed").From("paid")
+	process.To("priority_processing").From("expedited")
+	process.To("manual_review").From("flagged")
+	return orderStateMachine
+}
+
+func TestSetDestination(t *testing.T) {
+	var (
+		orderStateMachine = getExpediteStateMachine()
+		entered           []string
+	)
+	for _, state := range []string{"processed", "priority_processing", "manual_review"} {
+		state := state
+		orderStateMachine.State(state).Enter(func(order *Order) error {
+			entered = append(entered, state)
+			return nil
+		})
+	}
+
+	orderStateMachine.Event("process").To("processed").BeforePending(func(order *Order, pending *PendingTransition) error {
+		if order.Address == "express" {
+			return pending.SetDestination("priority_processing")
+		}
+		return nil
+	}).BeforePending(func(order *Order, pending *PendingTransition) error {
+		// redirects chain, later hooks see the new destination
+		if pending.To() == "priority_processing" && order.Id > 100 {
+			return pending.SetDestination("manual_review")
+		}
+		return nil
+	})
+
+	order := &Order{Address: "express"}
+	order.State = "paid"
+	if err := orderStateMachine.Trigger("process", order); err != nil || order.State != "priority_processing" {
+		t.Errorf("the transition should be redirected, got %v, %v", order.State, err)
+	}
+	if len(entered) != 1 || entered[0] != "priority_processing" {
+		t.Errorf("only the enter hooks of the final destination should run, got %v", entered)
+	}
+
+	order = &Order{Id: 101, Address: "express"}
+	order.State = "paid"
+	if err := orderStateMachine.Trigger("process", order); err != nil || order.State != "manual_review" {
+		t.Errorf("redirects should chain, got %v, %v", order.State, err)
+	}
+
+	order = &Order{}
+	order.State = "paid"
+	if err := orderStateMachine.Trigger("process", order); err != nil || order.State != "processed" {
+		t.Errorf("the transition should not be redirected, got %v, %v", order.State, err)
+	}
+}
+
+func TestSetDestinationInvalid(t *testing.T) {
+	orderStateMachine := getExpediteStateMachine()
+	orderStateMachine.Event("process").To("processed").BeforePending(func(order *Order, pending *PendingTransition) error {
+		if err := pending.SetDestination("delivered"); !errors.Is(err, ErrInvalidRedirect) {
+			t.Errorf("redirecting to a state the event doesn't go to should fail, got %v", err)
+		}
+		return pending.SetDestination("shipped")
+	})
+
+	order := &Order{}
+	order.State = "paid"
+	err := orderStateMachine.Trigger("process", order)
+	if !errors.Is(err, ErrInvalidRedirect) || !IsHookError(err) || order.State != "paid" {
+		t.Errorf("returning a failed redirect should fail the transition, got %v, %v", order.State, err)
+	}
+}
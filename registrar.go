@@ -0,0 +1,95 @@
+package transition
+
+import "strings"
+
+// ConditionalRegistrar defines states, events and hooks only when its condition holds, e.g. behind a feature flag
+// resolved at startup, see StateMachine.When
+type ConditionalRegistrar[T Stater] struct {
+	sm        *StateMachine[T]
+	condition func() bool
+}
+
+// SkippedRegistration is a definition a ConditionalRegistrar skipped because its condition didn't hold
+type SkippedRegistration struct {
+	// Target is what was defined, e.g. "state checkout" or "events cancel, pay"
+	Target string `json:"target"`
+	// Method is the definition method called, e.g. "State.Enter"
+	Method string `json:"method"`
+	// Site is where the method was called
+	Site string `json:"site,omitempty"`
+}
+
+// When returns a registrar defining states, events and hooks only when condition reports true, it's called for
+// every State, Event or selection requested from the registrar:
+//
+//	sm.When(flags.GiftCards).State("paid").Enter(sendGiftCard)
+//
+// When it reports false, the states and events returned are detached from the state machine: the methods called
+// on them are recorded, see SkippedRegistrations, and have no effect
+func (sm *StateMachine[T]) When(condition func() bool) *ConditionalRegistrar[T] {
+	return &ConditionalRegistrar[T]{sm: sm, condition: condition}
+}
+
+// SkippedRegistrations returns the definitions skipped by registrars, in the order they were made
+func (sm *StateMachine[T]) SkippedRegistrations() []SkippedRegistration {
+	return append([]SkippedRegistration(nil), sm.skipped...)
+}
+
+// State returns the state named name, see StateMachine.State
+func (registrar *ConditionalRegistrar[T]) State(name string) *State[T] {
+	if registrar.condition() {
+		return registrar.sm.State(name)
+	}
+	name = registrar.sm.owner.normalizeState(name)
+	owner := registrar.detached("state " + name)
+	owner.checkMutable("State")
+	return &State[T]{Name: name, owner: owner}
+}
+
+// Event returns the event named name, see StateMachine.Event
+func (registrar *ConditionalRegistrar[T]) Event(name string) *Event[T] {
+	if registrar.condition() {
+		return registrar.sm.Event(name)
+	}
+	owner := registrar.detached("event " + name)
+	owner.checkMutable("Event")
+	return &Event[T]{Name: name, owner: owner}
+}
+
+// StatesOf selects states to register the same hooks on all of them, see StateMachine.StatesOf
+func (registrar *ConditionalRegistrar[T]) StatesOf(names ...string) *StateSelection[T] {
+	if registrar.condition() {
+		return registrar.sm.StatesOf(names...)
+	}
+	return &StateSelection[T]{sm: registrar.sm, names: names, skip: registrar.skip("states " + strings.Join(names, ", "))}
+}
+
+// EventsMatching selects events to register the same hooks on their transitions, see StateMachine.EventsMatching
+func (registrar *ConditionalRegistrar[T]) EventsMatching(match func(name string) bool) *EventSelection[T] {
+	if registrar.condition() {
+		return registrar.sm.EventsMatching(match)
+	}
+	selection := registrar.sm.EventsMatching(match)
+	return &EventSelection[T]{sm: registrar.sm, names: selection.names, skip: registrar.skip("events " + strings.Join(selection.names, ", "))}
+}
+
+// AllEvents selects every event to register the same hooks on their transitions, see StateMachine.AllEvents
+func (registrar *ConditionalRegistrar[T]) AllEvents() *EventSelection[T] {
+	if registrar.condition() {
+		return registrar.sm.AllEvents()
+	}
+	return &EventSelection[T]{sm: registrar.sm, names: registrar.sm.eventNames(), skip: registrar.skip("all events")}
+}
+
+// skip returns a func recording the definition methods called on target
+func (registrar *ConditionalRegistrar[T]) skip(target string) func(method string) {
+	sm := registrar.sm
+	return func(method string) {
+		sm.skipped = append(sm.skipped, SkippedRegistration{Target: target, Method: method, Site: definitionSite()})
+	}
+}
+
+// detached returns an owner recording the definition methods called on target instead of allowing them
+func (registrar *ConditionalRegistrar[T]) detached(target string) *owner {
+	return &owner{ownerGuard: ownerGuard{skip: registrar.skip(target)}, normalize: registrar.sm.owner.normalize}
+}
@@ -0,0 +1,88 @@
+package transition
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWhen(t *testing.T) {
+	var calls []string
+	hook := func(name string) func(order *Order) error {
+		return func(order *Order) error {
+			calls = append(calls, name)
+			return nil
+		}
+	}
+
+	define := func(flag bool) *StateMachine[*Order] {
+		orderStateMachine := getStateMachine()
+		registrar := orderStateMachine.When(func() bool { return flag })
+		registrar.State("paid").Enter(hook("enter paid"))
+		registrar.Event("pay").To("paid").From("checkout").After(hook("after pay"))
+		if _, err := registrar.StatesOf("checkout", "paid").Exit(hook("exit")); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := registrar.AllEvents().Before(hook("before")); err != nil {
+			t.Fatal(err)
+		}
+		return orderStateMachine
+	}
+
+	direct := getStateMachine()
+	direct.State("paid").Enter(hook("enter paid"))
+	direct.Event("pay").To("paid").From("checkout").After(hook("after pay"))
+	if _, err := direct.StatesOf("checkout", "paid").Exit(hook("exit")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := direct.AllEvents().Before(hook("before")); err != nil {
+		t.Fatal(err)
+	}
+
+	trigger := func(sm *StateMachine[*Order]) []string {
+		calls = nil
+		order := &Order{}
+		for _, event := range []string{"checkout", "pay"} {
+			if err := sm.Trigger(event, order); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return calls
+	}
+
+	on, off := define(true), define(false)
+	if !reflect.DeepEqual(on.Describe(), direct.Describe()) || len(on.SkippedRegistrations()) != 0 {
+		t.Errorf("flag-on registrations should be direct registrations")
+	}
+	if got, expected := trigger(on), trigger(direct); !reflect.DeepEqual(got, expected) {
+		t.Errorf("flag-on hooks should run like direct ones, got %v, expected %v", got, expected)
+	}
+
+	description := off.Describe()
+	if description.States[4].Name != "paid" || description.States[4].Enter != 0 || description.States[4].Exit != 0 {
+		t.Errorf("flag-off states should have no hooks, got %+v", description.States[4])
+	}
+	if manifest := off.HookManifest(); len(manifest.Transitions) != 0 || len(direct.HookManifest().Transitions) == 0 {
+		t.Errorf("flag-off transitions should have no hooks, got %+v", manifest.Transitions)
+	}
+	if calls := trigger(off); len(calls) != 0 {
+		t.Errorf("flag-off hooks should not run, got %v", calls)
+	}
+
+	skipped := off.SkippedRegistrations()
+	var methods []string
+	for _, registration := range skipped {
+		methods = append(methods, registration.Target+" "+registration.Method)
+		if !strings.Contains(registration.Site, "/registrar_test.go:") {
+			t.Errorf("skipped registrations should record their site, got %q", registration.Site)
+		}
+	}
+	expected := []string{
+		"state paid State", "state paid State.Enter",
+		"event pay Event", "event pay Event.To", "event pay EventTransition.From", "event pay EventTransition.After",
+		"states checkout, paid StateSelection.Exit", "all events EventSelection.Before",
+	}
+	if !reflect.DeepEqual(methods, expected) {
+		t.Errorf("unexpected skipped registrations %q", methods)
+	}
+}
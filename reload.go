@@ -0,0 +1,51 @@
+package transition
+
+import (
+	"fmt"
+	"os"
+	"sync/atomic"
+)
+
+// Reloader keeps a state machine compiled from a JSON definition file, and swaps it when the file changes, see Reload
+type Reloader[T Stater] struct {
+	path    string
+	hooks   HookRegistry[T]
+	opts    []Option
+	machine atomic.Pointer[StateMachine[T]]
+}
+
+// NewReloader load the definition at path, see LoadDefinition, failing if it's invalid
+func NewReloader[T Stater](path string, hooks HookRegistry[T], opts ...Option) (*Reloader[T], error) {
+	reloader := &Reloader[T]{path: path, hooks: hooks, opts: opts}
+	if err := reloader.Reload(); err != nil {
+		return nil, err
+	}
+	return reloader, nil
+}
+
+// Machine returns the current state machine. Triggers performed on it keep running on it when Reload swaps it,
+// so get the machine for every trigger rather than keeping it around
+func (reloader *Reloader[T]) Machine() *StateMachine[T] {
+	return reloader.machine.Load()
+}
+
+// Reload load the definition again and swap the state machine if it compiles and passes Validate,
+// otherwise the current state machine is kept and the error returned. Wire it to file watchers or signals
+func (reloader *Reloader[T]) Reload() error {
+	file, err := os.Open(reloader.path)
+	if err != nil {
+		return fmt.Errorf("failed to reload definition: %w", err)
+	}
+	defer file.Close()
+
+	sm, err := LoadDefinition(file, reloader.hooks, reloader.opts...)
+	if err != nil {
+		return err
+	}
+	if err := sm.Validate(); err != nil {
+		return fmt.Errorf("failed to reload definition %s: %w", reloader.path, err)
+	}
+
+	reloader.machine.Store(sm)
+	return nil
+}
@@ -0,0 +1,99 @@
+package transition
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const reloadDefinitionV1 = `{
+  "initial": "draft",
+  "states": [{"name": "checkout"}, {"name": "paid"}],
+  "events": [{"name": "checkout", "transitions": [{"to": "checkout", "from": ["draft"], "before": ["wait"]}]}]
+}`
+
+const reloadDefinitionV2 = `{
+  "initial": "draft",
+  "states": [{"name": "checkout"}, {"name": "paid"}],
+  "events": [
+    {"name": "checkout", "transitions": [{"to": "checkout", "from": ["draft"]}]},
+    {"name": "pay", "transitions": [{"to": "paid", "from": ["checkout"]}]}
+  ]
+}`
+
+func writeDefinition(t *testing.T, path, definition string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(definition), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReloader(t *testing.T) {
+	var (
+		path     = filepath.Join(t.TempDir(), "order.json")
+		started  = make(chan struct{})
+		proceed  = make(chan struct{})
+		finished = make(chan error)
+	)
+	writeDefinition(t, path, reloadDefinitionV1)
+
+	reloader, err := NewReloader(path, HookRegistry[*Order]{
+		"wait": func(*Order) error {
+			close(started)
+			<-proceed
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("should not raise any error when loading definition, got %v", err)
+	}
+
+	inFlight := &Order{}
+	machine := reloader.Machine()
+	go func() {
+		finished <- machine.Trigger("checkout", inFlight)
+	}()
+	<-started
+
+	writeDefinition(t, path, reloadDefinitionV2)
+	if err := reloader.Reload(); err != nil {
+		t.Fatalf("should not raise any error when reloading definition, got %v", err)
+	}
+	close(proceed)
+
+	if err := <-finished; err != nil || inFlight.State != "checkout" {
+		t.Errorf("in-flight trigger should finish on the previous machine, got %v", err)
+	}
+
+	order := &Order{}
+	if err := transitionAll(reloader.Machine(), order, "checkout", "pay"); err != nil || order.State != "paid" {
+		t.Errorf("new machine should be used after reload, got %v", err)
+	}
+}
+
+func TestReloaderKeepsMachineOnFailure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "order.json")
+	writeDefinition(t, path, reloadDefinitionV2)
+
+	reloader, err := NewReloader(path, HookRegistry[*Order]{})
+	if err != nil {
+		t.Fatalf("should not raise any error when loading definition, got %v", err)
+	}
+	machine := reloader.Machine()
+
+	writeDefinition(t, path, strings.Replace(reloadDefinitionV2, `"to": "paid"`, `"to": "shipped"`, 1))
+	if err := reloader.Reload(); !errors.Is(err, ErrUndeclaredState) {
+		t.Errorf("invalid definition should fail validation, got %v", err)
+	}
+
+	writeDefinition(t, path, reloadDefinitionV1)
+	if err := reloader.Reload(); err == nil || !strings.Contains(err.Error(), "unknown hook wait") {
+		t.Errorf("definition using unknown hooks should fail, got %v", err)
+	}
+
+	if reloader.Machine() != machine {
+		t.Errorf("machine should be kept when reloading fails")
+	}
+}
@@ -0,0 +1,92 @@
+package transition
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// ReplayStep is a recorded event to replay, Expect is the optional state the value should end up in
+type ReplayStep struct {
+	Event  string `json:"event"`
+	Expect string `json:"expect,omitempty"`
+}
+
+// ReplayResult is the outcome of a single replayed step
+type ReplayResult struct {
+	Index int
+	Event string
+	From  string
+	To    string
+	Err   error
+}
+
+// ReplayReport describe the outcome of every replayed step
+type ReplayReport struct {
+	Results []ReplayResult
+	// Failed is the number of steps that raised an error
+	Failed int
+}
+
+// ReplayOption configure Replay
+type ReplayOption func(*replayConfig)
+
+type replayConfig struct {
+	continueOnError bool
+}
+
+// ReplayContinueOnError keep replaying the remaining steps after a step failed
+func ReplayContinueOnError() ReplayOption {
+	return func(config *replayConfig) {
+		config.continueOnError = true
+	}
+}
+
+// Replay trigger the recorded events in order against value, checking the expected state of each step.
+// It stops at the first failure unless ReplayContinueOnError is given, the returned error is the first failure
+func (sm *StateMachine[T]) Replay(value T, steps []ReplayStep, opts ...ReplayOption) (ReplayReport, error) {
+	var (
+		config   replayConfig
+		report   ReplayReport
+		firstErr error
+	)
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	if isNil(value) {
+		return report, fmt.Errorf("failed to replay: %w", ErrNilValue)
+	}
+
+	for index, step := range steps {
+		from := value.GetState()
+		err := sm.Trigger(step.Event, value)
+		if err == nil && step.Expect != "" && value.GetState() != step.Expect {
+			err = fmt.Errorf("replay step %d: event %s expected state %s, got %s", index, step.Event, step.Expect, value.GetState())
+		} else if err != nil {
+			err = fmt.Errorf("replay step %d: %w", index, err)
+		}
+
+		report.Results = append(report.Results, ReplayResult{Index: index, Event: step.Event, From: from, To: value.GetState(), Err: err})
+		if err != nil {
+			report.Failed++
+			if firstErr == nil {
+				firstErr = err
+			}
+			if !config.continueOnError {
+				break
+			}
+		}
+	}
+
+	return report, firstErr
+}
+
+// LoadReplaySteps read a JSON array of replay steps, e.g. `[{"event": "checkout", "expect": "checkout"}]`
+func LoadReplaySteps(r io.Reader) ([]ReplayStep, error) {
+	var steps []ReplayStep
+	if err := json.NewDecoder(r).Decode(&steps); err != nil {
+		return nil, fmt.Errorf("failed to load replay steps: %w", err)
+	}
+	return steps, nil
+}
@@ -0,0 +1,59 @@
+package transition
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReplay(t *testing.T) {
+	steps, err := LoadReplaySteps(strings.NewReader(`[{"event": "checkout", "expect": "checkout"}, {"event": "pay"}]`))
+	if err != nil {
+		t.Fatalf("should not raise any error when load replay steps, got %v", err)
+	}
+
+	order := &Order{}
+	report, err := getStateMachine().Replay(order, steps)
+	if err != nil {
+		t.Errorf("should not raise any error when replay, got %v", err)
+	}
+
+	if len(report.Results) != 2 || report.Failed != 0 {
+		t.Errorf("replay report doesn't record all steps")
+	}
+
+	if report.Results[1].From != "checkout" || report.Results[1].To != "paid" {
+		t.Errorf("replay report doesn't record from/to states")
+	}
+}
+
+func TestReplayStopsAtFirstFailure(t *testing.T) {
+	steps := []ReplayStep{{Event: "pay"}, {Event: "checkout", Expect: "paid"}, {Event: "pay"}}
+
+	report, err := getStateMachine().Replay(&Order{}, steps)
+	if err == nil {
+		t.Errorf("should raise an error when an event can't be performed")
+	}
+
+	if len(report.Results) != 1 {
+		t.Errorf("replay should stop at the first failure")
+	}
+
+	report, err = getStateMachine().Replay(&Order{}, steps, ReplayContinueOnError())
+	if err == nil || !strings.Contains(err.Error(), "step 0") {
+		t.Errorf("should return the first failure, got %v", err)
+	}
+
+	if len(report.Results) != 3 || report.Failed != 2 {
+		t.Errorf("replay should continue after failures, got %d results and %d failures", len(report.Results), report.Failed)
+	}
+
+	if report.Results[1].Err == nil {
+		t.Errorf("unexpected state should be reported as a failure")
+	}
+}
+
+func TestLoadReplayStepsInvalidJSON(t *testing.T) {
+	if _, err := LoadReplaySteps(strings.NewReader(`{`)); err == nil {
+		t.Errorf("should raise an error for invalid JSON")
+	}
+}
@@ -0,0 +1,70 @@
+package transition
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Roles restrict the event to actors having one of roles, enforced by Trigger once the state machine has a
+// roles func, see SetRolesFunc. Events without roles can be triggered by any actor
+func (event *Event[T]) Roles(roles ...string) *Event[T] {
+	event.checkMutable("Event.Roles")
+	event.roles = append(event.roles, roles...)
+	return event
+}
+
+// SetRolesFunc define how the roles of the actor of a trigger are extracted from its context, e.g. RolesFromContext.
+// Roles of events are only enforced once a roles func is defined
+func (sm *StateMachine[T]) SetRolesFunc(fc func(ctx context.Context) []string) *StateMachine[T] {
+	sm.owner.checkMutable("SetRolesFunc")
+	sm.rolesFunc = fc
+	return sm
+}
+
+type rolesContextKey struct{}
+
+// WithRoles returns a copy of ctx carrying the roles of the actor triggering events
+func WithRoles(ctx context.Context, roles ...string) context.Context {
+	return context.WithValue(ctx, rolesContextKey{}, roles)
+}
+
+// RolesFromContext returns the roles set with WithRoles
+func RolesFromContext(ctx context.Context) []string {
+	roles, _ := ctx.Value(rolesContextKey{}).([]string)
+	return roles
+}
+
+// AvailableEventsFor returns the available events of value, see AvailableEvents, that actors having roles can trigger
+func (sm *StateMachine[T]) AvailableEventsFor(value T, roles []string) []string {
+	var names []string
+	for _, name := range sm.AvailableEvents(value) {
+		if sm.events[name].allowsRoles(roles) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// allowsRoles reports whether the event has no roles or shares one with roles
+func (event *Event[T]) allowsRoles(roles []string) bool {
+	if len(event.roles) == 0 {
+		return true
+	}
+	for _, role := range roles {
+		for _, allowed := range event.roles {
+			if role == allowed {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// checkRoles rejects the trigger when the state machine has a roles func and the actor of ctx has none of the event's roles
+func (sm *StateMachine[T]) checkRoles(ctx context.Context, event *Event[T]) error {
+	if sm.rolesFunc == nil || event.allowsRoles(sm.rolesFunc(ctx)) {
+		return nil
+	}
+	return fmt.Errorf("%w: event %s requires one of the roles %s", ErrUnauthorized, event.Name, strings.Join(event.roles, ", "))
+}
@@ -0,0 +1,89 @@
+package transition
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func getRolesStateMachine(opts ...Option) *StateMachine[*Order] {
+	orderStateMachine := getStateMachine(opts...)
+	orderStateMachine.Event("cancel").To("cancelled").From("draft", "checkout")
+	orderStateMachine.Event("cancel").Roles("admin", "support")
+	orderStateMachine.Event("refund").To("paid_cancelled").From("paid")
+	orderStateMachine.Event("refund").Roles("finance")
+	return orderStateMachine
+}
+
+func TestRoles(t *testing.T) {
+	orderStateMachine := getRolesStateMachine(WithMutableAfterStart())
+
+	order := &Order{}
+	if err := orderStateMachine.Trigger("cancel", order); err != nil {
+		t.Fatalf("roles should not be enforced without roles func, got %v", err)
+	}
+
+	orderStateMachine.SetRolesFunc(RolesFromContext)
+
+	order = &Order{}
+	err := orderStateMachine.TriggerContext(WithRoles(context.Background(), "agent"), "cancel", order)
+	var transitionErr *TransitionError
+	if !errors.Is(err, ErrUnauthorized) || !errors.As(err, &transitionErr) || transitionErr.Phase != PhaseAuthorize {
+		t.Fatalf("agent should not be allowed to cancel, got %v", err)
+	}
+	if transitionErr.Err.Error() != "unauthorized: event cancel requires one of the roles admin, support" || order.State != "" {
+		t.Errorf("unexpected error %v", transitionErr.Err)
+	}
+
+	if err := orderStateMachine.TriggerContext(WithRoles(context.Background(), "agent", "support"), "cancel", order); err != nil {
+		t.Errorf("support should be allowed to cancel, got %v", err)
+	}
+	if err := orderStateMachine.Trigger("checkout", &Order{}); err != nil {
+		t.Errorf("events without roles should be allowed to anyone, got %v", err)
+	}
+}
+
+func TestAvailableEventsFor(t *testing.T) {
+	orderStateMachine := getRolesStateMachine()
+	order := &Order{}
+
+	if events := orderStateMachine.AvailableEventsFor(order, []string{"agent"}); !reflect.DeepEqual(events, []string{"checkout"}) {
+		t.Errorf("expected [checkout], got %v", events)
+	}
+	if events := orderStateMachine.AvailableEventsFor(order, []string{"admin"}); !reflect.DeepEqual(events, []string{"cancel", "checkout"}) {
+		t.Errorf("expected [cancel checkout], got %v", events)
+	}
+
+	order.State = "paid"
+	if events := orderStateMachine.AvailableEventsFor(order, []string{"admin"}); len(events) != 0 {
+		t.Errorf("expected no events, got %v", events)
+	}
+}
+
+func TestAllowedActionsRoles(t *testing.T) {
+	orderStateMachine := getRolesStateMachine().SetRolesFunc(RolesFromContext)
+	ctx := WithRoles(context.Background(), "agent")
+
+	actions := orderStateMachine.AllowedActions(ctx, &Order{})
+	if len(actions) != 2 || actions[0].Event != "cancel" || actions[0].Authorized || !actions[1].Authorized {
+		t.Errorf("cancel should be marked unauthorized, got %+v", actions)
+	}
+
+	actions = orderStateMachine.AllowedActions(ctx, &Order{}, HideUnauthorized())
+	if len(actions) != 1 || actions[0].Event != "checkout" {
+		t.Errorf("cancel should be hidden, got %+v", actions)
+	}
+}
+
+func TestDescribeRoles(t *testing.T) {
+	description := getRolesStateMachine().Describe()
+	for _, event := range description.Events {
+		if event.Name == "cancel" && !reflect.DeepEqual(event.Roles, []string{"admin", "support"}) {
+			t.Errorf("expected roles of cancel, got %v", event.Roles)
+		}
+		if event.Name == "checkout" && event.Roles != nil {
+			t.Errorf("checkout has no roles, got %v", event.Roles)
+		}
+	}
+}
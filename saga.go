@@ -0,0 +1,141 @@
+package transition
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Machine is a state machine of any value type, used to orchestrate several machines, see Saga
+type Machine interface {
+	triggerAny(ctx context.Context, name string, value any) error
+}
+
+func (sm *StateMachine[T]) triggerAny(ctx context.Context, name string, value any) error {
+	typed, ok := value.(T)
+	if !ok {
+		return fmt.Errorf("value of type %T is not handled by the state machine", value)
+	}
+	return sm.TriggerContext(ctx, name, typed)
+}
+
+type sagaAction struct {
+	machine Machine
+	event   string
+	value   any
+}
+
+func (action sagaAction) run(ctx context.Context) error {
+	return action.machine.triggerAny(ctx, action.event, action.value)
+}
+
+// compensationContext carries the values of the context of a saga, e.g. its correlation ID, without its
+// cancellation, so compensations run even once the saga was cancelled
+type compensationContext struct {
+	context.Context
+}
+
+func (compensationContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (compensationContext) Done() <-chan struct{}       { return nil }
+func (compensationContext) Err() error                  { return nil }
+
+type sagaStep struct {
+	sagaAction
+	compensation *sagaAction
+}
+
+// Saga trigger events on several machines in order, compensating the completed steps when a step fails
+//
+//	transition.NewSaga().
+//		Step(OrderStateMachine, "checkout", order).Compensate(OrderStateMachine, "uncheckout", order).
+//		Step(PaymentStateMachine, "authorize", payment).
+//		Run(ctx)
+type Saga struct {
+	steps []sagaStep
+}
+
+// NewSaga initialize a saga
+func NewSaga() *Saga {
+	return &Saga{}
+}
+
+// Step add a step triggering event on value
+func (saga *Saga) Step(machine Machine, event string, value any) *Saga {
+	saga.steps = append(saga.steps, sagaStep{sagaAction: sagaAction{machine: machine, event: event, value: value}})
+	return saga
+}
+
+// Compensate define the event undoing the last step, triggered when a later step fails
+func (saga *Saga) Compensate(machine Machine, event string, value any) *Saga {
+	if len(saga.steps) > 0 {
+		saga.steps[len(saga.steps)-1].compensation = &sagaAction{machine: machine, event: event, value: value}
+	}
+	return saga
+}
+
+// SagaStepResult is the outcome of a saga step
+type SagaStepResult struct {
+	Index int
+	Event string
+	// Ran reports whether the step's event succeeded
+	Ran bool
+	Err error
+	// Compensated reports whether the step's compensation succeeded
+	Compensated     bool
+	CompensationErr error
+}
+
+// SagaReport describe the outcome of every step of a saga, steps after the failed one are not reported
+type SagaReport struct {
+	Results []SagaStepResult
+}
+
+// Run trigger the steps in order with ctx. When a step fails, or ctx is done, the compensations of the completed
+// steps are triggered in reverse order, with the values of ctx, e.g. its correlation ID, but not its cancellation.
+// The returned MultiError holds the failure and the errors of failed compensations
+func (saga *Saga) Run(ctx context.Context) (SagaReport, error) {
+	var (
+		report SagaReport
+		errs   []error
+	)
+
+	for index, step := range saga.steps {
+		result := SagaStepResult{Index: index, Event: step.event}
+
+		err := ctx.Err()
+		if err == nil {
+			err = step.run(ctx)
+		}
+		if err == nil {
+			result.Ran = true
+			report.Results = append(report.Results, result)
+			continue
+		}
+
+		result.Err = err
+		report.Results = append(report.Results, result)
+		errs = append(errs, fmt.Errorf("saga step %d: %w", index, err))
+		break
+	}
+
+	if len(errs) == 0 {
+		return report, nil
+	}
+
+	for index := len(report.Results) - 1; index >= 0; index-- {
+		result := &report.Results[index]
+		compensation := saga.steps[index].compensation
+		if !result.Ran || compensation == nil {
+			continue
+		}
+
+		if err := compensation.run(compensationContext{ctx}); err != nil {
+			result.CompensationErr = err
+			errs = append(errs, fmt.Errorf("saga step %d compensation: %w", index, err))
+			continue
+		}
+		result.Compensated = true
+	}
+
+	return report, newMultiError(errs)
+}
@@ -0,0 +1,124 @@
+package transition
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func getSagaMachines() (*StateMachine[*Order], *StateMachine[*Shipment]) {
+	orderStateMachine := getStateMachine()
+	orderStateMachine.Event("uncheckout").To("draft").From("checkout")
+
+	shipmentStateMachine := getShipmentStateMachine()
+	shipmentStateMachine.Event("unpack").To("pending").From("packing")
+	return orderStateMachine, shipmentStateMachine
+}
+
+func TestSaga(t *testing.T) {
+	orderStateMachine, shipmentStateMachine := getSagaMachines()
+	order, shipment := &Order{}, &Shipment{}
+
+	report, err := NewSaga().
+		Step(orderStateMachine, "checkout", order).Compensate(orderStateMachine, "uncheckout", order).
+		Step(shipmentStateMachine, "start_packing", shipment).
+		Run(context.Background())
+	if err != nil {
+		t.Fatalf("should not raise any error when running saga, got %v", err)
+	}
+
+	if order.State != "checkout" || shipment.State != "packing" || len(report.Results) != 2 {
+		t.Errorf("every step should run, got %s, %s, %+v", order.State, shipment.State, report)
+	}
+}
+
+func TestSagaCompensation(t *testing.T) {
+	orderStateMachine, shipmentStateMachine := getSagaMachines()
+	order, shipment := &Order{}, &Shipment{}
+	shipmentStateMachine.State("packing").Enter(func(*Shipment) error {
+		return errors.New("unpack failed")
+	})
+
+	report, err := NewSaga().
+		Step(orderStateMachine, "checkout", order).Compensate(orderStateMachine, "uncheckout", order).
+		Step(shipmentStateMachine, "start_packing", shipment).Compensate(shipmentStateMachine, "unpack", shipment).
+		Step(orderStateMachine, "pay", order).
+		Run(context.Background())
+
+	if !IsHookError(err) {
+		t.Fatalf("should raise the failed step error, got %v", err)
+	}
+	if order.State != "draft" || shipment.State != "pending" {
+		t.Errorf("completed steps should be compensated, got %s, %s", order.State, shipment.State)
+	}
+	if len(report.Results) != 2 || report.Results[0].Compensated != true || report.Results[1].Ran {
+		t.Errorf("unexpected report %+v", report)
+	}
+}
+
+func TestSagaCompensationFailure(t *testing.T) {
+	orderStateMachine, shipmentStateMachine := getSagaMachines()
+	order, shipment := &Order{}, &Shipment{}
+	shipment.State = "packing"
+
+	report, err := NewSaga().
+		Step(orderStateMachine, "checkout", order).Compensate(orderStateMachine, "uncheckout", order).
+		Step(orderStateMachine, "pay", order).Compensate(orderStateMachine, "uncheckout", order).
+		Step(shipmentStateMachine, "start_packing", shipment).
+		Run(context.Background())
+
+	var multiErr *MultiError
+	if !errors.As(err, &multiErr) || len(multiErr.Errors()) != 3 {
+		t.Fatalf("should raise the step failure and the compensation failures, got %v", err)
+	}
+	if report.Results[1].CompensationErr == nil || report.Results[0].CompensationErr == nil || order.State != "paid" {
+		t.Errorf("unexpected report %+v", report)
+	}
+}
+
+func TestSagaContextCancelled(t *testing.T) {
+	orderStateMachine, _ := getSagaMachines()
+	order := &Order{}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := NewSaga().Step(orderStateMachine, "checkout", order).Run(ctx)
+	if !errors.Is(err, context.Canceled) || order.State != "" {
+		t.Errorf("cancelled saga should not run any step, got %v", err)
+	}
+}
+
+func TestSagaContext(t *testing.T) {
+	orderStateMachine, shipmentStateMachine := getSagaMachines()
+	order, shipment := &Order{}, &Shipment{}
+	ctx, cancel := context.WithCancel(WithCorrelationID(context.Background(), "saga-1"))
+	defer cancel()
+
+	var correlationIDs []string
+	orderStateMachine.Event("checkout").To("checkout").BeforeContext(func(ctx context.Context, order *Order) error {
+		correlationIDs = append(correlationIDs, CorrelationIDFromContext(ctx))
+		return nil
+	})
+	orderStateMachine.Event("uncheckout").To("draft").BeforeContext(func(ctx context.Context, order *Order) error {
+		correlationIDs = append(correlationIDs, CorrelationIDFromContext(ctx))
+		return nil
+	})
+	shipmentStateMachine.Event("start_packing").To("packing").BeforeContext(func(ctx context.Context, shipment *Shipment) error {
+		cancel()
+		return ctx.Err()
+	})
+
+	report, err := NewSaga().
+		Step(orderStateMachine, "checkout", order).Compensate(orderStateMachine, "uncheckout", order).
+		Step(shipmentStateMachine, "start_packing", shipment).
+		Run(ctx)
+	if !errors.Is(err, context.Canceled) || shipment.State != "pending" {
+		t.Fatalf("steps should receive the cancellation of the saga, got %v", err)
+	}
+	if order.State != "draft" || !report.Results[0].Compensated {
+		t.Errorf("compensations should run once the saga was cancelled, got %+v", report)
+	}
+	if len(correlationIDs) != 2 || correlationIDs[0] != "saga-1" || correlationIDs[1] != "saga-1" {
+		t.Errorf("steps and compensations should receive the correlation ID of the saga, got %v", correlationIDs)
+	}
+}
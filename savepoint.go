@@ -0,0 +1,92 @@
+package transition
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrConcurrentChange is returned by Savepoint.Rollback when the revision of the value changed since the savepoint
+var ErrConcurrentChange = errors.New("value changed concurrently")
+
+// RevisionTracker is implemented by values carrying a revision counter bumped every time they are saved, e.g. the
+// optimistic locking column of their table. The state machine doesn't change it, it tells savepoints whether the
+// value was changed elsewhere since they were taken
+type RevisionTracker interface {
+	GetRevision() int64
+}
+
+// Savepoint is the state of a value captured by StateMachine.Savepoint, see Rollback
+type Savepoint[T Stater] struct {
+	sm             *StateMachine[T]
+	value          T
+	state          string
+	stateChangedAt time.Time
+	previousState  string
+	historyLen     int
+	revision       int64
+}
+
+// Savepoint capture the state of value, along with when it changed, its previous state, the length of its history
+// and its revision when tracked, to restore them after several transitions. It's in-memory only, persisting the
+// restored value is up to the caller
+func (sm *StateMachine[T]) Savepoint(value T) *Savepoint[T] {
+	savepoint := &Savepoint[T]{sm: sm, value: value}
+	if isNil(value) {
+		return savepoint
+	}
+
+	savepoint.state = value.GetState()
+	if tracker, ok := any(value).(TimeTracker); ok {
+		savepoint.stateChangedAt = tracker.GetStateChangedAt()
+	}
+	if tracker, ok := any(value).(PreviousStateTracker); ok {
+		savepoint.previousState = tracker.GetPreviousState()
+	}
+	if tracker, ok := any(value).(HistoryTracker); ok {
+		savepoint.historyLen = len(tracker.GetHistory())
+	}
+	if tracker, ok := any(value).(RevisionTracker); ok {
+		savepoint.revision = tracker.GetRevision()
+	}
+	return savepoint
+}
+
+// State returns the state captured by the savepoint
+func (savepoint *Savepoint[T]) State() string {
+	return savepoint.state
+}
+
+// Rollback restore the value to the savepoint, no hooks are run and the changes made since the savepoint are removed
+// from its history. Values whose revision changed since the savepoint are left untouched and ErrConcurrentChange is
+// returned, rather than overwriting changes made elsewhere. Timeouts of the state the value is in are cancelled and
+// those of the restored state scheduled again
+func (savepoint *Savepoint[T]) Rollback() error {
+	value := savepoint.value
+	if isNil(value) {
+		return ErrNilValue
+	}
+	if tracker, ok := any(value).(RevisionTracker); ok && tracker.GetRevision() != savepoint.revision {
+		return fmt.Errorf("rollback to state %s: revision %d is now %d: %w", savepoint.state, savepoint.revision, tracker.GetRevision(), ErrConcurrentChange)
+	}
+
+	current := value.GetState()
+	value.SetState(savepoint.state)
+	recordMachineState(value, savepoint.state)
+	if tracker, ok := any(value).(TimeTracker); ok {
+		tracker.SetStateChangedAt(savepoint.stateChangedAt)
+	}
+	if tracker, ok := any(value).(PreviousStateTracker); ok {
+		tracker.SetPreviousState(savepoint.previousState)
+	}
+	if tracker, ok := any(value).(HistoryTracker); ok {
+		if history := tracker.GetHistory(); len(history) > savepoint.historyLen {
+			tracker.SetHistory(history[:savepoint.historyLen])
+		}
+	}
+
+	if current != savepoint.state {
+		savepoint.sm.rescheduleTimeouts(value, current, savepoint.state)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package transition
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSavepoint(t *testing.T) {
+	orderStateMachine := getStateMachine()
+	orderStateMachine.Event("process").To("processed").From("paid")
+	orderStateMachine.State("processed").Enter(func(*Order) error {
+		return errors.New("warehouse unavailable")
+	})
+
+	order := &Order{}
+	savepoint := orderStateMachine.Savepoint(order)
+
+	if err := transitionAll(orderStateMachine, order, "checkout", "pay", "process"); err == nil {
+		t.Fatalf("process should fail")
+	}
+	if order.State != "paid" {
+		t.Fatalf("order should be paid, got %s", order.State)
+	}
+
+	if err := savepoint.Rollback(); err != nil {
+		t.Fatalf("should not raise any error when rolling back, got %v", err)
+	}
+	if order.State != "" || order.PreviousState != "" || !order.StateChangedAt.IsZero() {
+		t.Errorf("order should be restored, got %+v", order.Transition)
+	}
+}
+
+func transitionAll(sm *StateMachine[*Order], order *Order, events ...string) error {
+	for _, event := range events {
+		if err := sm.Trigger(event, order); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestSavepointNilValue(t *testing.T) {
+	if err := getStateMachine().Savepoint(nil).Rollback(); !errors.Is(err, ErrNilValue) {
+		t.Errorf("rolling back a nil value should raise ErrNilValue, got %v", err)
+	}
+}
+
+type RevisedOrder struct {
+	Revision int64
+
+	Transition
+	HistoriedTransition
+}
+
+func (order *RevisedOrder) GetRevision() int64 {
+	return order.Revision
+}
+
+func TestSavepointHistory(t *testing.T) {
+	sm := New(&RevisedOrder{})
+	sm.Initial("draft")
+	sm.State("checkout")
+	sm.State("paid")
+	sm.Event("checkout").To("checkout").From("draft")
+	sm.Event("pay").To("paid").From("checkout")
+
+	order := &RevisedOrder{}
+	sm.Trigger("checkout", order)
+	savepoint := sm.Savepoint(order)
+	sm.Trigger("pay", order)
+
+	if err := savepoint.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	if history := order.GetHistory(); order.GetState() != "checkout" || len(history) != 1 || history[0].To != "checkout" {
+		t.Errorf("changes made since the savepoint should be removed from the history, got %s, %v", order.GetState(), history)
+	}
+}
+
+func TestSavepointConcurrentChange(t *testing.T) {
+	sm := New(&RevisedOrder{})
+	sm.Initial("draft")
+	sm.State("checkout")
+	sm.Event("checkout").To("checkout").From("draft")
+
+	order := &RevisedOrder{Revision: 3}
+	savepoint := sm.Savepoint(order)
+	sm.Trigger("checkout", order)
+
+	// saved elsewhere meanwhile
+	order.Revision++
+	if err := savepoint.Rollback(); !errors.Is(err, ErrConcurrentChange) {
+		t.Fatalf("rolling back a value changed elsewhere should raise ErrConcurrentChange, got %v", err)
+	}
+	if order.GetState() != "checkout" {
+		t.Errorf("the value should be left untouched, got %s", order.GetState())
+	}
+}
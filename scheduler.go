@@ -0,0 +1,184 @@
+package transition
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ScheduledTrigger is an event to trigger later on the value identified by Key
+type ScheduledTrigger struct {
+	// ID is assigned by the Scheduler
+	ID    string
+	Key   string
+	Event string
+	At    time.Time
+	// State, when set, is the state the value must still be in when the trigger fires
+	State string
+}
+
+// Scheduler schedule triggers to fire later, implement it to back scheduled triggers with a job queue or a database
+type Scheduler interface {
+	// Schedule arranges for fire to be called with the trigger at trigger.At, returning the trigger's ID
+	Schedule(trigger ScheduledTrigger, fire func(ScheduledTrigger)) (string, error)
+	// Cancel prevents a pending trigger from firing, cancelling an unknown or already fired trigger is not an error
+	Cancel(id string) error
+	// Pending returns the triggers that didn't fire yet
+	Pending() []ScheduledTrigger
+}
+
+// Resolver load a value by its key, it's used to re-load values when scheduled triggers fire
+type Resolver[T Stater] interface {
+	Resolve(key string) (T, error)
+}
+
+// ResolverFunc is a function implementing Resolver
+type ResolverFunc[T Stater] func(key string) (T, error)
+
+// Resolve calls fn(key)
+func (fn ResolverFunc[T]) Resolve(key string) (T, error) {
+	return fn(key)
+}
+
+// ScheduledTriggerError is reported to OnError when a scheduled trigger fails to fire
+type ScheduledTriggerError struct {
+	Trigger ScheduledTrigger
+	Err     error
+}
+
+func (scheduledErr *ScheduledTriggerError) Error() string {
+	return fmt.Sprintf("scheduled event %s for %s failed: %v", scheduledErr.Trigger.Event, scheduledErr.Trigger.Key, scheduledErr.Err)
+}
+
+// Unwrap returns the error raised when firing the trigger
+func (scheduledErr *ScheduledTriggerError) Unwrap() error {
+	return scheduledErr.Err
+}
+
+// MemoryScheduler is an in-process Scheduler based on timers of a Clock, pending triggers are lost when the process exits
+type MemoryScheduler struct {
+	clock   Clock
+	mu      sync.Mutex
+	lastID  int
+	pending map[string]*memoryTrigger
+}
+
+type memoryTrigger struct {
+	trigger ScheduledTrigger
+	timer   Timer
+}
+
+// NewMemoryScheduler initialize a MemoryScheduler, a nil clock uses the system clock
+func NewMemoryScheduler(clock Clock) *MemoryScheduler {
+	if clock == nil {
+		clock = realClock{}
+	}
+	return &MemoryScheduler{clock: clock, pending: map[string]*memoryTrigger{}}
+}
+
+// Schedule start a timer firing the trigger at trigger.At
+func (scheduler *MemoryScheduler) Schedule(trigger ScheduledTrigger, fire func(ScheduledTrigger)) (string, error) {
+	scheduler.mu.Lock()
+	defer scheduler.mu.Unlock()
+
+	scheduler.lastID++
+	trigger.ID = strconv.Itoa(scheduler.lastID)
+
+	entry := &memoryTrigger{trigger: trigger}
+	scheduler.pending[trigger.ID] = entry
+	entry.timer = scheduler.clock.AfterFunc(trigger.At.Sub(scheduler.clock.Now()), func() {
+		scheduler.mu.Lock()
+		_, ok := scheduler.pending[trigger.ID]
+		delete(scheduler.pending, trigger.ID)
+		scheduler.mu.Unlock()
+
+		if ok {
+			fire(trigger)
+		}
+	})
+	return trigger.ID, nil
+}
+
+// Cancel stop the timer of a pending trigger
+func (scheduler *MemoryScheduler) Cancel(id string) error {
+	scheduler.mu.Lock()
+	defer scheduler.mu.Unlock()
+
+	if entry, ok := scheduler.pending[id]; ok {
+		entry.timer.Stop()
+		delete(scheduler.pending, id)
+	}
+	return nil
+}
+
+// Pending returns the pending triggers, ordered by firing time
+func (scheduler *MemoryScheduler) Pending() []ScheduledTrigger {
+	scheduler.mu.Lock()
+	defer scheduler.mu.Unlock()
+
+	triggers := make([]ScheduledTrigger, 0, len(scheduler.pending))
+	for _, entry := range scheduler.pending {
+		triggers = append(triggers, entry.trigger)
+	}
+	sort.Slice(triggers, func(i, j int) bool {
+		if !triggers[i].At.Equal(triggers[j].At) {
+			return triggers[i].At.Before(triggers[j].At)
+		}
+		id1, _ := strconv.Atoi(triggers[i].ID)
+		id2, _ := strconv.Atoi(triggers[j].ID)
+		return id1 < id2
+	})
+	return triggers
+}
+
+// ScheduledHandle is a trigger scheduled by TriggerAt or TriggerAfter
+type ScheduledHandle struct {
+	Trigger   ScheduledTrigger
+	scheduler Scheduler
+}
+
+// Cancel prevents the trigger from firing
+func (handle *ScheduledHandle) Cancel() error {
+	return handle.scheduler.Cancel(handle.Trigger.ID)
+}
+
+// TriggerAt trigger event at t on the value identified by key, the value is re-loaded with the resolver
+// when the trigger fires and errors, including the event not being allowed anymore, are reported to OnError
+func (sm *StateMachine[T]) TriggerAt(t time.Time, event string, key string) (*ScheduledHandle, error) {
+	if event == "" {
+		return nil, fmt.Errorf("failed to schedule event: %w", ErrEmptyEventName)
+	}
+	if sm.resolver == nil {
+		return nil, fmt.Errorf("failed to schedule event %s: the state machine has no resolver", event)
+	}
+
+	trigger := ScheduledTrigger{Key: key, Event: event, At: t}
+	id, err := sm.scheduler.Schedule(trigger, sm.fireScheduled)
+	if err != nil {
+		return nil, fmt.Errorf("failed to schedule event %s: %w", event, err)
+	}
+
+	trigger.ID = id
+	return &ScheduledHandle{Trigger: trigger, scheduler: sm.scheduler}, nil
+}
+
+// TriggerAfter trigger event after d on the value identified by key, see TriggerAt
+func (sm *StateMachine[T]) TriggerAfter(d time.Duration, event string, key string) (*ScheduledHandle, error) {
+	return sm.TriggerAt(sm.clock.Now().Add(d), event, key)
+}
+
+// PendingTriggers returns the scheduled triggers that didn't fire yet, including state timeouts
+func (sm *StateMachine[T]) PendingTriggers() []ScheduledTrigger {
+	return sm.scheduler.Pending()
+}
+
+// FireScheduled fire a scheduled trigger, durable schedulers use it to fire the triggers they restored after a restart
+func (sm *StateMachine[T]) FireScheduled(trigger ScheduledTrigger) {
+	if trigger.State != "" {
+		sm.fireTimeout(trigger)
+		return
+	}
+	sm.fireScheduled(trigger)
+}
@@ -0,0 +1,107 @@
+package transition_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/daegalus/transition"
+	"github.com/daegalus/transition/transitiontest"
+)
+
+func TestTriggerAfter(t *testing.T) {
+	var (
+		clock             = transitiontest.NewTestClock(time.Now())
+		order             = &Order{ID: "1"}
+		orderStateMachine = getTimeoutStateMachine(clock, getOrderStore(order))
+	)
+	orderStateMachine.Event("remind").To("checkout").From("checkout")
+
+	transitiontest.TriggerAll(t, orderStateMachine, order, "checkout")
+
+	handle, err := orderStateMachine.TriggerAfter(10*time.Minute, "pay", "1")
+	if err != nil {
+		t.Fatalf("should not raise any error when schedule event, got %v", err)
+	}
+
+	if _, err := orderStateMachine.TriggerAt(clock.Now().Add(20*time.Minute), "remind", "1"); err != nil {
+		t.Fatalf("should not raise any error when schedule event, got %v", err)
+	}
+
+	if pending := orderStateMachine.PendingTriggers(); len(pending) != 3 || pending[0].ID != handle.Trigger.ID || pending[2].Event != "expire" {
+		t.Errorf("pending triggers should be ordered by firing time, got %+v", pending)
+	}
+
+	clock.Advance(10 * time.Minute)
+	transitiontest.AssertState(t, order, "paid")
+
+	if pending := orderStateMachine.PendingTriggers(); len(pending) != 1 || pending[0].Event != "remind" {
+		t.Errorf("fired and cancelled triggers should not be pending, got %+v", pending)
+	}
+}
+
+func TestTriggerAfterCancel(t *testing.T) {
+	var (
+		clock             = transitiontest.NewTestClock(time.Now())
+		order             = &Order{ID: "1"}
+		orderStateMachine = getTimeoutStateMachine(clock, getOrderStore(order))
+	)
+
+	transitiontest.TriggerAll(t, orderStateMachine, order, "checkout")
+
+	handle, _ := orderStateMachine.TriggerAfter(10*time.Minute, "pay", "1")
+	if err := handle.Cancel(); err != nil {
+		t.Errorf("should not raise any error when cancel, got %v", err)
+	}
+
+	clock.Advance(10 * time.Minute)
+	transitiontest.AssertState(t, order, "checkout")
+}
+
+func TestTriggerAfterNotAllowed(t *testing.T) {
+	var (
+		clock             = transitiontest.NewTestClock(time.Now())
+		order             = &Order{ID: "1"}
+		orderStateMachine = getTimeoutStateMachine(clock, getOrderStore(order))
+		errs              []error
+	)
+	orderStateMachine.OnError(func(err error) {
+		errs = append(errs, err)
+	})
+
+	orderStateMachine.TriggerAfter(time.Minute, "pay", "1")
+	clock.Advance(time.Minute)
+
+	var scheduledErr *transition.ScheduledTriggerError
+	if len(errs) != 1 || !errors.As(errs[0], &scheduledErr) || scheduledErr.Trigger.Event != "pay" {
+		t.Errorf("not allowed event should be reported to OnError, got %v", errs)
+	}
+
+	transitiontest.AssertState(t, order, "draft")
+
+	if deadLetters := orderStateMachine.DeadLetters(); len(deadLetters) != 1 || deadLetters[0].Command.Event != "pay" || !transition.IsNoMatch(deadLetters[0].Err) {
+		t.Errorf("failed scheduled trigger should be dead-lettered, got %+v", deadLetters)
+	}
+}
+
+func TestTriggerAfterOnDeadLetter(t *testing.T) {
+	var (
+		clock             = transitiontest.NewTestClock(time.Now())
+		orderStateMachine = getTimeoutStateMachine(clock, getOrderStore())
+		commands          []transition.TriggerCommand
+	)
+	orderStateMachine.OnDeadLetter(func(ctx context.Context, command transition.TriggerCommand, err error) {
+		commands = append(commands, command)
+	})
+
+	orderStateMachine.TriggerAfter(time.Minute, "pay", "missing")
+	clock.Advance(time.Minute)
+
+	if len(commands) != 1 || commands[0].Key != "missing" || commands[0].Attempts != 1 {
+		t.Errorf("failed scheduled trigger should be given to OnDeadLetter, got %+v", commands)
+	}
+	if deadLetters := orderStateMachine.DeadLetters(); len(deadLetters) != 0 {
+		t.Errorf("dead letters should not be recorded when OnDeadLetter is registered, got %+v", deadLetters)
+	}
+}
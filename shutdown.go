@@ -0,0 +1,85 @@
+package transition
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrShuttingDown is returned by triggers started once the state machine drains, see StateMachine.Shutdown
+var ErrShuttingDown = errors.New("state machine is shutting down")
+
+// Shutdown drain the state machine: new triggers fail fast with ErrShuttingDown, while triggers caused by the hooks
+// of the in-flight ones with the context of their trigger still run, see ContextHook, and queue workers stop
+// dequeuing commands once the dequeued ones ran. It returns once the in-flight triggers and the workers are done, or ctx's error if ctx is done first, the state machine keeps draining then.
+// Scheduled triggers firing while draining fail and are reported to OnError and OnDeadLetter, durable schedulers
+// fire them again on restart
+func (sm *StateMachine[T]) Shutdown(ctx context.Context) error {
+	select {
+	case <-sm.executions.drain():
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Draining reports whether Shutdown was called, e.g. to fail liveness or readiness probes
+func (sm *StateMachine[T]) Draining() bool {
+	return sm.executions.stopping.Load()
+}
+
+// drain start draining, the returned channel is closed once nothing runs anymore
+func (executions *executions) drain() <-chan struct{} {
+	executions.mu.Lock()
+	defer executions.mu.Unlock()
+
+	if !executions.stopping.Load() {
+		executions.stopping.Store(true)
+		if executions.draining == nil {
+			executions.draining = make(chan struct{})
+		}
+		close(executions.draining)
+		executions.idle = make(chan struct{})
+		executions.settle()
+	}
+	return executions.idle
+}
+
+// drained returns a channel closed once the state machine drains
+func (executions *executions) drained() <-chan struct{} {
+	executions.mu.Lock()
+	defer executions.mu.Unlock()
+
+	if executions.draining == nil {
+		executions.draining = make(chan struct{})
+	}
+	return executions.draining
+}
+
+// work track a worker, e.g. a queue, until done is called. It fails with ErrShuttingDown once the state machine drains
+func (executions *executions) work() (done func(), err error) {
+	executions.mu.Lock()
+	defer executions.mu.Unlock()
+
+	if executions.stopping.Load() {
+		return nil, ErrShuttingDown
+	}
+	executions.workers++
+	return func() {
+		executions.mu.Lock()
+		defer executions.mu.Unlock()
+		executions.workers--
+		executions.settle()
+	}, nil
+}
+
+// settle close idle once the state machine drains and nothing runs, executions.mu must be held
+func (executions *executions) settle() {
+	if executions.idle == nil || len(executions.running) > 0 || executions.workers > 0 {
+		return
+	}
+	select {
+	case <-executions.idle:
+	default:
+		close(executions.idle)
+	}
+}
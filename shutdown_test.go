@@ -0,0 +1,148 @@
+package transition
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestShutdown(t *testing.T) {
+	orderStateMachine, started, release := getBlockingStateMachine()
+	orderStateMachine.State("paid")
+	orderStateMachine.Event("pay").To("paid").From("checkout")
+	orderStateMachine.Event("checkout").To("checkout").From("draft").AfterContext(func(ctx context.Context, order *Order) error {
+		// triggers caused by the hooks of in-flight ones still run while draining
+		return orderStateMachine.TriggerContext(ctx, "pay", order)
+	})
+
+	var (
+		order     = &Order{}
+		triggered atomic.Bool
+		triggers  = make(chan error, 1)
+	)
+	go func() {
+		err := orderStateMachine.Trigger("checkout", order)
+		triggered.Store(true)
+		triggers <- err
+	}()
+	<-started
+
+	shutdown := make(chan error, 1)
+	go func() {
+		shutdown <- orderStateMachine.Shutdown(context.Background())
+	}()
+	for !orderStateMachine.Draining() {
+		time.Sleep(time.Millisecond)
+	}
+
+	var transitionErr *TransitionError
+	if err := orderStateMachine.Trigger("checkout", &Order{}); !errors.Is(err, ErrShuttingDown) || !errors.As(err, &transitionErr) || transitionErr.Phase != PhasePrepare || !IsRetryable(err) {
+		t.Errorf("new triggers should fail fast while draining, got %v", err)
+	}
+	if err := orderStateMachine.Trigger("pay", order); !errors.Is(err, ErrShuttingDown) {
+		t.Errorf("new triggers on the value being triggered should fail fast while draining, got %v", err)
+	}
+	if err := orderStateMachine.HealthCheck(""); !errors.Is(err, ErrShuttingDown) {
+		t.Errorf("draining state machines should not be healthy, got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := orderStateMachine.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("shutdown should return once its deadline passes, got %v", err)
+	}
+	select {
+	case err := <-shutdown:
+		t.Fatalf("shutdown should wait for the in-flight trigger, got %v", err)
+	default:
+	}
+
+	close(release)
+	if err := <-shutdown; err != nil || !triggered.Load() {
+		t.Errorf("shutdown should return once the in-flight trigger completed, got %v", err)
+	}
+	if err := <-triggers; err != nil || order.GetState() != "paid" {
+		t.Errorf("in-flight triggers should complete, got %v in state %s", err, order.GetState())
+	}
+	if err := orderStateMachine.Shutdown(context.Background()); err != nil {
+		t.Errorf("shutting down again should return at once, got %v", err)
+	}
+}
+
+func TestShutdownStopsQueues(t *testing.T) {
+	orderStateMachine := getStateMachine()
+	var resolver Resolver[*Order] = ResolverFunc[*Order](func(key string) (*Order, error) { return &Order{}, nil })
+	orderStateMachine.SetResolver(resolver)
+
+	store := NewMemoryQueueStore(nil)
+	queue := NewQueue[*Order](store, QueuePollInterval(time.Millisecond))
+	if _, err := queue.Enqueue(context.Background(), TriggerCommand{Key: "1", Event: "checkout"}); err != nil {
+		t.Fatal(err)
+	}
+
+	runs := make(chan error, 1)
+	go func() {
+		runs <- queue.Run(context.Background(), orderStateMachine)
+	}()
+	for len(store.Pending()) > 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	if err := orderStateMachine.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-runs; !errors.Is(err, ErrShuttingDown) {
+		t.Errorf("queue workers should stop once the state machine drains, got %v", err)
+	}
+	if err := queue.Run(context.Background(), orderStateMachine); !errors.Is(err, ErrShuttingDown) {
+		t.Errorf("queue workers should not start while draining, got %v", err)
+	}
+}
+
+func TestShutdownRunsDequeuedCommands(t *testing.T) {
+	orderStateMachine := getStateMachine()
+	var (
+		order    = &Order{}
+		dequeued = make(chan struct{})
+		resolve  = make(chan struct{})
+	)
+	var resolver Resolver[*Order] = ResolverFunc[*Order](func(key string) (*Order, error) {
+		close(dequeued)
+		<-resolve
+		return order, nil
+	})
+	orderStateMachine.SetResolver(resolver)
+
+	store := NewMemoryQueueStore(nil)
+	queue := NewQueue[*Order](store)
+	if _, err := queue.Enqueue(context.Background(), TriggerCommand{Key: "1", Event: "checkout"}); err != nil {
+		t.Fatal(err)
+	}
+
+	drains := make(chan error, 1)
+	go func() {
+		drains <- queue.Drain(context.Background(), orderStateMachine)
+	}()
+	<-dequeued
+
+	shutdown := make(chan error, 1)
+	go func() {
+		shutdown <- orderStateMachine.Shutdown(context.Background())
+	}()
+	for !orderStateMachine.Draining() {
+		time.Sleep(time.Millisecond)
+	}
+	close(resolve)
+
+	if err := <-shutdown; err != nil {
+		t.Fatal(err)
+	}
+	if err := <-drains; !errors.Is(err, ErrShuttingDown) {
+		t.Errorf("queue workers should stop once the state machine drains, got %v", err)
+	}
+	if order.GetState() != "checkout" || len(store.Pending()) != 0 {
+		t.Errorf("commands dequeued before draining should run and be acked, got %s and %+v", order.GetState(), store.Pending())
+	}
+}
@@ -0,0 +1,159 @@
+package transition
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+)
+
+// SimOptions configure a random walk performed by Simulate
+type SimOptions[T Stater] struct {
+	// Seed seeds the random number generator, the same seed always produces the same walk
+	Seed int64
+	// NewValue creates the value that is walked through the machine
+	NewValue func() T
+	// MaxSteps limits the number of events triggered
+	MaxSteps int
+	// DisableHooks only moves the value between states, without running any hooks
+	DisableHooks bool
+}
+
+// SimStep is a single event triggered during a simulation
+type SimStep struct {
+	Step  int
+	Event string
+	From  string
+	To    string
+	Err   error
+}
+
+// SimError is an error raised while triggering an event during a simulation
+type SimError struct {
+	Step  int
+	Event string
+	Err   error
+}
+
+// SimReport describe the walk performed by Simulate
+type SimReport struct {
+	// Path holds every step taken, including failed ones
+	Path []SimStep
+	// Visited holds the states visited, in the order they were first reached
+	Visited []string
+	// Errors holds the errors raised by failed steps
+	Errors []SimError
+	// Terminal is true when the walk stopped at a state without available events
+	Terminal bool
+	// Distribution counts, per state, how many times each event was chosen from it
+	Distribution map[string]map[string]int
+}
+
+// Weight set how likely the transition is chosen by Simulate relatively to the other available transitions,
+// transitions without weight weigh 1. Weights are only used by Simulate, never by Trigger
+func (transition *EventTransition[T]) Weight(weight float64) *EventTransition[T] {
+	transition.checkMutable("EventTransition.Weight")
+	transition.weight = &weight
+	return transition
+}
+
+// pickEvent chooses one of events, available for value from its state, according to the weights of their transitions
+func (sm *StateMachine[T]) pickEvent(rnd *rand.Rand, value T, state string, events []string) string {
+	var (
+		weights  = make([]float64, len(events))
+		total    float64
+		weighted bool
+	)
+	for i, name := range events {
+		weights[i] = 1
+		if matched, _ := sm.match(context.Background(), sm.events[name], state, value); len(matched) == 1 && matched[0].weight != nil {
+			weights[i], weighted = *matched[0].weight, true
+			if weights[i] < 0 {
+				weights[i] = 0
+			}
+		}
+		total += weights[i]
+	}
+
+	if !weighted || total == 0 {
+		return events[rnd.Intn(len(events))]
+	}
+
+	pick := rnd.Float64() * total
+	for i, weight := range weights {
+		if pick < weight {
+			return events[i]
+		}
+		pick -= weight
+	}
+	return events[len(events)-1]
+}
+
+// Simulate randomly walk the state machine, starting from the initial state and
+// triggering a random available event on each step until MaxSteps is reached or
+// no event is available anymore
+func (sm *StateMachine[T]) Simulate(opts SimOptions[T]) (SimReport, error) {
+	var report SimReport
+
+	if opts.NewValue == nil {
+		return report, errors.New("simulate: NewValue is required")
+	}
+	if opts.MaxSteps <= 0 {
+		return report, errors.New("simulate: MaxSteps must be positive")
+	}
+
+	var (
+		rnd     = rand.New(rand.NewSource(opts.Seed))
+		value   = opts.NewValue()
+		visited = map[string]bool{}
+	)
+
+	if isNil(value) {
+		return report, fmt.Errorf("simulate: NewValue returned a %w", ErrNilValue)
+	}
+
+	visit := func(state string) {
+		if !visited[state] {
+			visited[state] = true
+			report.Visited = append(report.Visited, state)
+		}
+	}
+
+	if value.GetState() == "" {
+		initial, err := sm.initialStateOf(value)
+		if err != nil {
+			return report, fmt.Errorf("simulate: %w", err)
+		}
+		value.SetState(initial)
+		recordMachineState(value, initial)
+	}
+	visit(value.GetState())
+
+	for step := 0; step < opts.MaxSteps; step++ {
+		from := value.GetState()
+		events := sm.availableEvents(value, from)
+		if len(events) == 0 {
+			report.Terminal = true
+			break
+		}
+
+		event := sm.pickEvent(rnd, value, from, events)
+		if report.Distribution == nil {
+			report.Distribution = map[string]map[string]int{}
+		}
+		if report.Distribution[from] == nil {
+			report.Distribution[from] = map[string]int{}
+		}
+		report.Distribution[from][event]++
+
+		_, err := sm.execute(context.Background(), TriggerCommand{Event: event}, value, triggerOptions{skipHooks: opts.DisableHooks})
+
+		report.Path = append(report.Path, SimStep{Step: step, Event: event, From: from, To: value.GetState(), Err: err})
+		if err != nil {
+			report.Errors = append(report.Errors, SimError{Step: step, Event: event, Err: err})
+		}
+		visit(value.GetState())
+	}
+
+	return report, nil
+}
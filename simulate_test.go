@@ -0,0 +1,111 @@
+package transition
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func getSimulationStateMachine() *StateMachine[*Order] {
+	orderStateMachine := getStateMachine()
+	orderStateMachine.Event("cancel").To("cancelled").From("draft", "checkout")
+	orderStateMachine.Event("cancel").To("paid_cancelled").From("paid")
+	orderStateMachine.Event("process").To("processed").From("paid")
+	orderStateMachine.Event("deliver").To("delivered").From("processed")
+	return orderStateMachine
+}
+
+func TestSimulateIsDeterministic(t *testing.T) {
+	orderStateMachine := getSimulationStateMachine()
+	opts := SimOptions[*Order]{Seed: 42, NewValue: func() *Order { return &Order{} }, MaxSteps: 10}
+
+	first, err := orderStateMachine.Simulate(opts)
+	if err != nil {
+		t.Fatalf("should not raise any error when simulate, got %v", err)
+	}
+
+	for i := 0; i < 10; i++ {
+		again, _ := orderStateMachine.Simulate(opts)
+		if !reflect.DeepEqual(first, again) {
+			t.Fatalf("simulation with the same seed walked a different path")
+		}
+	}
+
+	if !first.Terminal {
+		t.Errorf("simulation should stop at a terminal state")
+	}
+
+	if first.Visited[0] != "draft" {
+		t.Errorf("simulation should start from the initial state")
+	}
+}
+
+func TestSimulateRecordsErrors(t *testing.T) {
+	orderStateMachine := getSimulationStateMachine()
+	orderStateMachine.State("checkout").Enter(func(order *Order) error {
+		return errors.New("intentional error")
+	})
+
+	report, err := orderStateMachine.Simulate(SimOptions[*Order]{Seed: 1, NewValue: func() *Order { return &Order{} }, MaxSteps: 20})
+	if err != nil {
+		t.Fatalf("should not raise any error when simulate, got %v", err)
+	}
+
+	for _, step := range report.Path {
+		if step.To == "checkout" {
+			t.Errorf("value should never reach checkout when its enter hook fails")
+		}
+	}
+
+	for _, simErr := range report.Errors {
+		if report.Path[simErr.Step].Event != "checkout" {
+			t.Errorf("error recorded for the wrong step")
+		}
+	}
+
+	report, _ = orderStateMachine.Simulate(SimOptions[*Order]{Seed: 1, NewValue: func() *Order { return &Order{} }, MaxSteps: 20, DisableHooks: true})
+	if len(report.Errors) != 0 {
+		t.Errorf("hooks should not run when disabled")
+	}
+}
+
+func TestSimulateInvalidOptions(t *testing.T) {
+	if _, err := getStateMachine().Simulate(SimOptions[*Order]{MaxSteps: 1}); err == nil {
+		t.Errorf("should raise an error without NewValue")
+	}
+
+	if _, err := getStateMachine().Simulate(SimOptions[*Order]{NewValue: func() *Order { return &Order{} }}); err == nil {
+		t.Errorf("should raise an error without MaxSteps")
+	}
+}
+
+func TestSimulateWeights(t *testing.T) {
+	orderStateMachine := New(&Order{})
+	orderStateMachine.Initial("checkout")
+	orderStateMachine.Event("pay").To("paid").From("checkout").Weight(0.8)
+	orderStateMachine.Event("cancel").To("cancelled").From("checkout").Weight(0.2)
+	orderStateMachine.Event("reset").To("checkout").From("paid", "cancelled")
+
+	opts := SimOptions[*Order]{Seed: 7, NewValue: func() *Order { return &Order{} }, MaxSteps: 2000}
+	report, err := orderStateMachine.Simulate(opts)
+	if err != nil {
+		t.Fatalf("should not raise any error when simulate, got %v", err)
+	}
+
+	fromCheckout := report.Distribution["checkout"]
+	paid := float64(fromCheckout["pay"]) / float64(fromCheckout["pay"]+fromCheckout["cancel"])
+	if paid < 0.75 || paid > 0.85 {
+		t.Errorf("pay should be chosen about 80%% of the time, got %v", fromCheckout)
+	}
+
+	again, _ := orderStateMachine.Simulate(opts)
+	if !reflect.DeepEqual(report, again) {
+		t.Errorf("weighted simulation with the same seed walked a different path")
+	}
+
+	order := &Order{}
+	order.State = "checkout"
+	if err := orderStateMachine.Trigger("cancel", order); err != nil || order.State != "cancelled" {
+		t.Errorf("weights should not affect Trigger, got %v", err)
+	}
+}
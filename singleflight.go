@@ -0,0 +1,72 @@
+package transition
+
+import (
+	"context"
+)
+
+// SingleFlight coalesce identical concurrent triggers: while an event is being triggered on a value, triggering the
+// same event on a value with the same key, and the same idempotency key for TriggerIdempotent, waits for the first
+// trigger and returns its error instead of running again. When it succeeds, the waiting values are moved to the same
+// state without running hooks. It requires a key func, see SetKeyFunc, triggers are never coalesced without one
+func (sm *StateMachine[T]) SingleFlight() *StateMachine[T] {
+	sm.owner.checkMutable("SingleFlight")
+	sm.singleFlight = true
+	return sm
+}
+
+type flightKey struct {
+	key            string
+	event          string
+	idempotencyKey string
+}
+
+// flight is a trigger in progress, done is closed once err and state are set
+type flight struct {
+	done    chan struct{}
+	waiters int
+	err     error
+	state   string
+}
+
+// coalesce runs fc unless a trigger with the same key is in flight, in which case it waits for it and returns its error
+func (sm *StateMachine[T]) coalesce(ctx context.Context, key flightKey, value T, fc func() error) error {
+	sm.mu.Lock()
+	if inFlight, ok := sm.flights[key]; ok {
+		inFlight.waiters++
+		sm.mu.Unlock()
+
+		select {
+		case <-inFlight.done:
+		case <-ctx.Done():
+			return &TransitionError{Event: key.event, From: value.GetState(), Phase: PhasePrepare, Err: ctx.Err()}
+		}
+		if inFlight.err == nil {
+			value.SetState(sm.Intern(inFlight.state))
+			recordMachineState(value, inFlight.state)
+		}
+		return inFlight.err
+	}
+
+	if sm.flights == nil {
+		sm.flights = map[flightKey]*flight{}
+	}
+	current := &flight{done: make(chan struct{})}
+	sm.flights[key] = current
+	sm.mu.Unlock()
+
+	defer func() {
+		sm.mu.Lock()
+		delete(sm.flights, key)
+		sm.mu.Unlock()
+		close(current.done)
+	}()
+
+	current.err = fc()
+	current.state = value.GetState()
+	return current.err
+}
+
+// coalesced reports whether triggering on value should go through coalesce
+func (sm *StateMachine[T]) coalesced(value T, opts triggerOptions) bool {
+	return sm.singleFlight && sm.keyFunc != nil && !opts.inFlight && !isNil(value)
+}
@@ -0,0 +1,140 @@
+package transition
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSingleFlight(t *testing.T) {
+	var (
+		orderStateMachine = getStateMachine()
+		release           = make(chan struct{})
+		started           = make(chan struct{})
+		runs              atomic.Int32
+		errDeclined       = errors.New("declined")
+	)
+	orderStateMachine.SetKeyFunc(func(order *Order) string { return strconv.Itoa(order.Id) }).SingleFlight()
+	orderStateMachine.Event("pay").To("paid").From("checkout").Before(func(order *Order) error {
+		if runs.Add(1) == 1 {
+			close(started)
+		}
+		<-release
+		if order.Address == "declined" {
+			return errDeclined
+		}
+		return nil
+	})
+
+	run := func(address string) ([]*Order, []error) {
+		runs.Store(0)
+		started = make(chan struct{})
+		release = make(chan struct{})
+
+		var (
+			orders = make([]*Order, 3)
+			errs   = make([]error, 3)
+			wg     sync.WaitGroup
+		)
+		for i := range orders {
+			// each replica loaded its own copy of order 1
+			orders[i] = &Order{Id: 1, Address: address}
+			orders[i].State = "checkout"
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[0] = orderStateMachine.Trigger("pay", orders[0])
+		}()
+		<-started
+		for i := 1; i < len(orders); i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				errs[i] = orderStateMachine.Trigger("pay", orders[i])
+			}(i)
+		}
+		waitForWaiters(orderStateMachine, 2)
+		close(release)
+		wg.Wait()
+		return orders, errs
+	}
+
+	orders, errs := run("")
+	if runs.Load() != 1 {
+		t.Errorf("duplicates should be coalesced, the hook ran %d times", runs.Load())
+	}
+	for i, order := range orders {
+		if errs[i] != nil || order.State != "paid" {
+			t.Errorf("every duplicate should get the result of the first trigger, got %v, %v", order.State, errs[i])
+		}
+	}
+
+	orders, errs = run("declined")
+	for i, order := range orders {
+		if !errors.Is(errs[i], errDeclined) || order.State != "checkout" {
+			t.Errorf("every duplicate should get the error of the first trigger, got %v, %v", order.State, errs[i])
+		}
+	}
+}
+
+func TestSingleFlightIdempotencyKeys(t *testing.T) {
+	var (
+		orderStateMachine = getStateMachine()
+		release           = make(chan struct{})
+		runs              atomic.Int32
+	)
+	orderStateMachine.SetKeyFunc(func(order *Order) string { return strconv.Itoa(order.Id) }).SingleFlight()
+	orderStateMachine.SetIdempotencyStore(NewMemoryIdempotencyStore(nil))
+	orderStateMachine.Event("checkout").To("checkout").From("draft").Before(func(order *Order) error {
+		runs.Add(1)
+		<-release
+		return nil
+	})
+
+	var (
+		wg   sync.WaitGroup
+		errs = make([]error, 4)
+	)
+	for i, key := range []string{"message-1", "message-1", "message-1", "message-2"} {
+		wg.Add(1)
+		go func(i int, key string) {
+			defer wg.Done()
+			errs[i] = orderStateMachine.TriggerIdempotent(context.Background(), "checkout", &Order{Id: 1}, key)
+		}(i, key)
+	}
+	waitForWaiters(orderStateMachine, 2)
+	close(release)
+	wg.Wait()
+
+	if runs.Load() != 2 {
+		t.Errorf("duplicates should run once per idempotency key, ran %d times", runs.Load())
+	}
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("trigger %d should not fail, got %v", i, err)
+		}
+	}
+}
+
+// waitForWaiters waits until n triggers wait for triggers in flight
+func waitForWaiters[T Stater](sm *StateMachine[T], n int) {
+	for {
+		sm.mu.Lock()
+		var waiters int
+		for _, inFlight := range sm.flights {
+			waiters += inFlight.waiters
+		}
+		sm.mu.Unlock()
+
+		if waiters >= n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
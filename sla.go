@@ -0,0 +1,89 @@
+package transition
+
+import (
+	"time"
+)
+
+// SLA set how long values are expected to stay in the state at most, see StateMachine.SLABreaches
+func (state *State[T]) SLA(d time.Duration) *State[T] {
+	state.checkMutable("State.SLA")
+	state.sla = d
+	return state
+}
+
+// Breach is a value that stayed in its state longer than the state's SLA
+type Breach struct {
+	// Key identifies the value when the state machine has a key func
+	Key   string        `json:"key,omitempty"`
+	State string        `json:"state"`
+	SLA   time.Duration `json:"sla"`
+	// Over is how long the value exceeded the SLA
+	Over time.Duration `json:"over"`
+}
+
+// breach returns how long value exceeded the SLA of its state at now, values must track when their state changed
+func (sm *StateMachine[T]) breach(value T, now time.Time) (Breach, bool) {
+	if isNil(value) {
+		return Breach{}, false
+	}
+
+	state, ok := sm.states[value.GetState()]
+	if !ok || state.sla <= 0 {
+		return Breach{}, false
+	}
+
+	tracker, ok := any(value).(TimeTracker)
+	if !ok || tracker.GetStateChangedAt().IsZero() {
+		return Breach{}, false
+	}
+
+	over := now.Sub(tracker.GetStateChangedAt()) - state.sla
+	if over <= 0 {
+		return Breach{}, false
+	}
+
+	breach := Breach{State: state.Name, SLA: state.sla, Over: over}
+	if sm.keyFunc != nil {
+		breach.Key = sm.keyFunc(value)
+	}
+	return breach, true
+}
+
+// IsOverSLA reports whether value stayed in its state longer than the state's SLA, according to the state machine's clock
+func (sm *StateMachine[T]) IsOverSLA(value T) bool {
+	_, ok := sm.breach(value, sm.clock.Now())
+	return ok
+}
+
+// SLABreaches returns the values yielded by values, see AuditValues for the iterator, that stayed in their state
+// longer than the state's SLA at now. Values must track when their state changed, see TimeTracker
+func (sm *StateMachine[T]) SLABreaches(values func(yield func(T) bool), now time.Time) []Breach {
+	var breaches []Breach
+	values(func(value T) bool {
+		if breach, ok := sm.breach(value, now); ok {
+			breaches = append(breaches, breach)
+		}
+		return true
+	})
+	return breaches
+}
+
+// OverSLA is a sweep rule matching values that stayed in their state longer than the state's SLA, triggering event on
+// them, so SLAs declared on the state machine drive sweeps
+func (sm *StateMachine[T]) OverSLA(event string) SweepRule {
+	return overSLA[T]{sm: sm, event: event}
+}
+
+type overSLA[T Stater] struct {
+	sm    *StateMachine[T]
+	event string
+}
+
+func (rule overSLA[T]) Match(value Stater, now time.Time) (string, bool) {
+	typed, ok := value.(T)
+	if !ok {
+		return "", false
+	}
+	_, ok = rule.sm.breach(typed, now)
+	return rule.event, ok
+}
@@ -0,0 +1,51 @@
+package transition
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestSLA(t *testing.T) {
+	var (
+		now               = time.Date(2023, 1, 24, 12, 0, 0, 0, time.UTC)
+		clock             = &manualClock{now: now}
+		orderStateMachine = New(&Order{}, WithClock(clock))
+	)
+	orderStateMachine.Initial("draft")
+	orderStateMachine.State("checkout").SLA(time.Hour)
+	orderStateMachine.Event("checkout").To("checkout").From("draft")
+	orderStateMachine.Event("expire").To("draft").From("checkout")
+	orderStateMachine.SetKeyFunc(func(order *Order) string { return strconv.Itoa(order.Id) })
+
+	var orders []*Order
+	for i, age := range []time.Duration{30 * time.Minute, 90 * time.Minute, 3 * time.Hour} {
+		order := &Order{Id: i}
+		order.State, order.StateChangedAt = "checkout", now.Add(-age)
+		orders = append(orders, order)
+	}
+	draft := &Order{Id: 3}
+	draft.State, draft.StateChangedAt = "draft", now.Add(-24*time.Hour)
+	orders = append(orders, draft)
+
+	breaches := orderStateMachine.SLABreaches(func(yield func(*Order) bool) {
+		for _, order := range orders {
+			if !yield(order) {
+				return
+			}
+		}
+	}, now)
+	if len(breaches) != 2 || breaches[0] != (Breach{Key: "1", State: "checkout", SLA: time.Hour, Over: 30 * time.Minute}) || breaches[1].Over != 2*time.Hour {
+		t.Errorf("unexpected breaches %+v", breaches)
+	}
+
+	if orderStateMachine.IsOverSLA(orders[0]) || !orderStateMachine.IsOverSLA(orders[1]) || orderStateMachine.IsOverSLA(draft) {
+		t.Errorf("unexpected IsOverSLA results")
+	}
+
+	report, err := orderStateMachine.Sweep(context.Background(), orders, orderStateMachine.OverSLA("expire"))
+	if err != nil || report.Succeeded != 2 || orders[0].State != "checkout" || orders[2].State != "draft" {
+		t.Errorf("sweep should expire values over their SLA, got %+v, %v", report, err)
+	}
+}
@@ -0,0 +1,70 @@
+package transition
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// ErrIdentifierCollision is returned by generators when several names are generated as the same identifier
+var ErrIdentifierCollision = errors.New("identifier collision")
+
+// identifier returns the ASCII identifier generated for name, in CamelCase: runes other than letters and digits
+// separate words, other ASCII runes are kept and other letters and digits are written as their code point, e.g.
+// "awaiting approval" is AwaitingApproval and "в обработке" is U0432U043EU0431U0440U0430U0431U043EU0442U043AU0435.
+// Identifiers starting with a digit, or empty, are prefixed with X
+func identifier(name string) string {
+	var builder strings.Builder
+	upper := true
+	for _, r := range name {
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			if upper {
+				r = unicode.ToUpper(r)
+			}
+			builder.WriteRune(r)
+			upper = false
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			fmt.Fprintf(&builder, "U%04X", r)
+			upper = false
+		default:
+			upper = true
+		}
+	}
+
+	ident := builder.String()
+	if ident == "" || unicode.IsDigit(rune(ident[0])) {
+		ident = "X" + ident
+	}
+	return ident
+}
+
+// identifiers returns the identifiers generated for names of kind, e.g. "states", and an error wrapping
+// ErrIdentifierCollision listing the names generated as the same identifier
+func identifiers(kind string, names []string) (map[string]string, error) {
+	var (
+		idents = make(map[string]string, len(names))
+		byID   = map[string][]string{}
+	)
+	for _, name := range names {
+		ident := identifier(name)
+		if _, ok := idents[name]; !ok {
+			idents[name] = ident
+			byID[ident] = append(byID[ident], name)
+		}
+	}
+
+	var errs []error
+	for ident, names := range byID {
+		if len(names) > 1 {
+			sort.Strings(names)
+			errs = append(errs, fmt.Errorf("%s %q are all generated as %s: %w", kind, names, ident, ErrIdentifierCollision))
+		}
+	}
+	sort.Slice(errs, func(i, j int) bool {
+		return errs[i].Error() < errs[j].Error()
+	})
+	return idents, newMultiError(errs)
+}
@@ -0,0 +1,128 @@
+package transition
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestIdentifier(t *testing.T) {
+	for name, expected := range map[string]string{
+		"paid":              "Paid",
+		"paid_cancelled":    "PaidCancelled",
+		"awaiting approval": "AwaitingApproval",
+		"HTTP-error":        "HTTPError",
+		"в обработке":       "U0432U043EU0431U0440U0430U0431U043EU0442U043AU0435",
+		"2fa":               "X2fa",
+		"":                  "X",
+		"🚀":                 "X",
+	} {
+		if ident := identifier(name); ident != expected {
+			t.Errorf("expected %q to be generated as %s, got %s", name, expected, ident)
+		}
+	}
+
+	if _, err := identifiers("states", []string{"awaiting approval", "paid", "awaiting_approval", "Awaiting-Approval"}); !errors.Is(err, ErrIdentifierCollision) ||
+		err.Error() != `states ["Awaiting-Approval" "awaiting approval" "awaiting_approval"] are all generated as AwaitingApproval: identifier collision` {
+		t.Errorf("expected a collision, got %v", err)
+	}
+}
+
+// getLocalizedStateMachine returns a state machine with non-ASCII and spaced state and event names
+func getLocalizedStateMachine() *StateMachine[*Order] {
+	sm := New(&Order{})
+	sm.Initial("черновик")
+	sm.State("в обработке")
+	sm.State("awaiting approval")
+	sm.State("done").Final()
+	sm.Event("отправить").To("в обработке").From("черновик")
+	sm.Event("ask approval").To("awaiting approval").From("в обработке")
+	sm.Event("approve now").To("done").From("awaiting approval")
+	return sm
+}
+
+func TestLocalizedNamesExporters(t *testing.T) {
+	sm := getLocalizedStateMachine()
+	if err := sm.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	order := &Order{}
+	for _, event := range []string{"отправить", "ask approval", "approve now"} {
+		if err := sm.Trigger(event, order); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if printed := sm.Sprint(); !strings.Contains(printed, "awaiting approval") || !strings.Contains(printed, "в обработке") {
+		t.Errorf("names should be printed, got\n%s", printed)
+	}
+
+	data, err := json.Marshal(sm.Describe())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed, err := ParseDescription(data); err != nil || parsed.Fingerprint() != sm.Fingerprint() {
+		t.Errorf("descriptions should round-trip, got %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := sm.ExportCatalog(&buf, CatalogJSON); err != nil {
+		t.Fatal(err)
+	}
+	var catalog Catalog
+	if err := json.Unmarshal(buf.Bytes(), &catalog); err != nil || !reflect.DeepEqual(catalog.AllowedFrom["ask approval"], []string{"в обработке"}) {
+		t.Errorf("JSON catalogs should keep names, got %+v, %v", catalog, err)
+	}
+
+	buf.Reset()
+	if err := sm.ExportCatalog(&buf, CatalogTypeScript); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		`  AwaitingApproval: "awaiting approval",`,
+		`  U0432U043EU0431U0440U0430U0431U043EU0442U043AU0435: "в обработке",`,
+		`  AskApproval: "ask approval",`,
+		`  "ask approval": ["в обработке"],`,
+	} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("TypeScript catalog should contain %s, got\n%s", expected, buf.String())
+		}
+	}
+
+	definition := Definition{Initial: "черновик", States: []StateDefinition{{Name: "в обработке"}, {Name: "awaiting approval"}}, Events: []EventDefinition{
+		{Name: "ask approval", Transitions: []TransitionDefinition{{To: "awaiting approval", From: []string{"в обработке"}}}},
+	}}
+	source, err := GenerateGo(definition, "orders", "orderStateMachine")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		`OrderStateMachineStateAwaitingApproval`,
+		`OrderStateMachineEventAskApproval`,
+		`orderStateMachine.Event("ask approval").To("awaiting approval").From("в обработке")`,
+	} {
+		if !bytes.Contains(source, []byte(expected)) {
+			t.Errorf("generated go should contain %s, got\n%s", expected, source)
+		}
+	}
+}
+
+func TestIdentifierCollisions(t *testing.T) {
+	sm := getLocalizedStateMachine()
+	sm.State("awaiting_approval")
+
+	if err := sm.ExportCatalog(&bytes.Buffer{}, CatalogTypeScript); !errors.Is(err, ErrIdentifierCollision) {
+		t.Errorf("TypeScript catalogs should reject colliding identifiers, got %v", err)
+	}
+	if err := sm.ExportCatalog(&bytes.Buffer{}, CatalogJSON); err != nil {
+		t.Errorf("JSON catalogs have no identifiers, got %v", err)
+	}
+
+	definition := Definition{Initial: "draft", States: []StateDefinition{{Name: "awaiting approval"}, {Name: "awaiting_approval"}}}
+	if _, err := GenerateGo(definition, "orders", "orderStateMachine"); !errors.Is(err, ErrIdentifierCollision) || !strings.Contains(err.Error(), "AwaitingApproval") {
+		t.Errorf("generated go should reject colliding identifiers, got %v", err)
+	}
+}
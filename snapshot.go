@@ -0,0 +1,88 @@
+package transition
+
+import (
+	"fmt"
+	"time"
+)
+
+// Snapshot is the state machine related data of a value captured by StateMachine.Snapshot, it can be serialized,
+// e.g. to store it in a session, and written back with StateMachine.Restore
+type Snapshot struct {
+	State string `json:"state"`
+	// PreviousState is set when the value tracks it, see PreviousStateTracker
+	PreviousState string `json:"previous_state,omitempty"`
+	// StateChangedAt is set when the value tracks it, see TimeTracker
+	StateChangedAt time.Time `json:"state_changed_at,omitempty"`
+	// Version and Fingerprint are set when the value tracks them, see VersionTracker
+	Version     string `json:"version,omitempty"`
+	Fingerprint string `json:"fingerprint,omitempty"`
+	// History is set when the value tracks it, see HistoryTracker
+	History []StateChange `json:"history,omitempty"`
+}
+
+// Snapshot capture the state of value, along with its previous state, when it changed, the version of the state
+// machine that changed it and its history when tracked. Unlike a Savepoint, it isn't tied to value and can be persisted
+func (sm *StateMachine[T]) Snapshot(value T) Snapshot {
+	var snapshot Snapshot
+	if isNil(value) {
+		return snapshot
+	}
+
+	snapshot.State = value.GetState()
+	if tracker, ok := any(value).(PreviousStateTracker); ok {
+		snapshot.PreviousState = tracker.GetPreviousState()
+	}
+	if tracker, ok := any(value).(TimeTracker); ok {
+		snapshot.StateChangedAt = tracker.GetStateChangedAt()
+	}
+	if tracker, ok := any(value).(VersionTracker); ok {
+		snapshot.Version, snapshot.Fingerprint = tracker.GetVersion()
+	}
+	if tracker, ok := any(value).(HistoryTracker); ok {
+		snapshot.History = append([]StateChange(nil), tracker.GetHistory()...)
+	}
+	return snapshot
+}
+
+// Restore write snapshot back to value, no hooks are run. Snapshots taken with another version of the state machine
+// have their states renamed by the migrations to the current version, see AddMigration, and record the current
+// version. The state must still be defined, otherwise an error wrapping ErrUndeclaredState is returned and value is
+// left untouched. Timeouts of the state the value is in are cancelled and those of the restored state scheduled again
+func (sm *StateMachine[T]) Restore(value T, snapshot Snapshot) error {
+	if isNil(value) {
+		return ErrNilValue
+	}
+
+	state, previousState := snapshot.State, snapshot.PreviousState
+	version, fingerprint := snapshot.Version, snapshot.Fingerprint
+	if version != "" && version != sm.version {
+		if path, err := sm.migrationPath(version); err == nil {
+			state, previousState = migratedState(path, state), migratedState(path, previousState)
+			version, fingerprint = sm.version, sm.Fingerprint()
+		}
+	}
+	if _, ok := sm.states[state]; !ok && state != sm.initialState {
+		return fmt.Errorf("restore state %q: %w", state, ErrUndeclaredState)
+	}
+
+	current := value.GetState()
+	value.SetState(sm.Intern(state))
+	recordMachineState(value, state)
+	if tracker, ok := any(value).(PreviousStateTracker); ok {
+		tracker.SetPreviousState(previousState)
+	}
+	if tracker, ok := any(value).(TimeTracker); ok {
+		tracker.SetStateChangedAt(snapshot.StateChangedAt)
+	}
+	if tracker, ok := any(value).(VersionTracker); ok {
+		tracker.SetVersion(version, fingerprint)
+	}
+	if tracker, ok := any(value).(HistoryTracker); ok {
+		tracker.SetHistory(append([]StateChange(nil), snapshot.History...))
+	}
+
+	if current != state {
+		sm.rescheduleTimeouts(value, current, state)
+	}
+	return nil
+}
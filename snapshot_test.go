@@ -0,0 +1,116 @@
+package transition
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSnapshotRestore(t *testing.T) {
+	var (
+		now               = time.Date(2023, 1, 24, 12, 0, 0, 0, time.UTC)
+		orderStateMachine = New(&Order{}, WithClock(&manualClock{now: now}))
+	)
+	orderStateMachine.Initial("draft")
+	orderStateMachine.State("checkout")
+	orderStateMachine.State("paid")
+	orderStateMachine.Event("checkout").To("checkout").From("draft")
+	orderStateMachine.Event("pay").To("paid").From("checkout")
+	orderStateMachine.Version("v2")
+
+	order := &Order{}
+	orderStateMachine.Trigger("checkout", order)
+
+	data, err := json.Marshal(orderStateMachine.Snapshot(order))
+	if err != nil {
+		t.Fatalf("no error should happen when marshaling a snapshot, but got %v", err)
+	}
+
+	orderStateMachine.Trigger("pay", order)
+
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		t.Fatalf("no error should happen when unmarshaling a snapshot, but got %v", err)
+	}
+	if err := orderStateMachine.Restore(order, snapshot); err != nil {
+		t.Fatalf("no error should happen when restoring, but got %v", err)
+	}
+
+	version, fingerprint := order.GetVersion()
+	if order.State != "checkout" || order.PreviousState != "draft" || !order.StateChangedAt.Equal(now) || version != "v2" || fingerprint != orderStateMachine.Fingerprint() {
+		t.Errorf("the snapshot should be restored, got %+v", order.Transition)
+	}
+	if !reflect.DeepEqual(orderStateMachine.Snapshot(order), snapshot) {
+		t.Errorf("restoring a snapshot should round-trip")
+	}
+}
+
+func TestRestoreUndeclaredState(t *testing.T) {
+	orderStateMachine := getStateMachine()
+
+	order := &Order{}
+	orderStateMachine.Trigger("checkout", order)
+
+	err := orderStateMachine.Restore(order, Snapshot{State: "refunded", PreviousState: "paid"})
+	if !errors.Is(err, ErrUndeclaredState) {
+		t.Errorf("restoring a state no longer defined should fail with ErrUndeclaredState, got %v", err)
+	}
+	if order.State != "checkout" || order.PreviousState != "draft" {
+		t.Errorf("a rejected snapshot should leave the value untouched, got %+v", order.Transition)
+	}
+
+	if err := orderStateMachine.Restore(order, Snapshot{State: "draft"}); err != nil || order.State != "draft" {
+		t.Errorf("the initial state should be restorable, got %v", err)
+	}
+}
+
+func TestSnapshotHistory(t *testing.T) {
+	var (
+		sm    = getHistoryStateMachine(WithClock(&manualClock{now: time.Date(2023, 1, 24, 12, 0, 0, 0, time.UTC)}))
+		order = &HistoriedOrder{}
+	)
+	sm.Trigger("checkout", order)
+
+	data, _ := json.Marshal(sm.Snapshot(order))
+	sm.Trigger("pay", order)
+
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		t.Fatal(err)
+	}
+	if err := sm.Restore(order, snapshot); err != nil {
+		t.Fatal(err)
+	}
+	if history := order.GetHistory(); order.State != "checkout" || len(history) != 1 || history[0].Event != "checkout" || history[0].Actor != "system" {
+		t.Errorf("the history should be restored, got %v", history)
+	}
+
+	sm.Trigger("pay", order)
+	if len(snapshot.History) != 1 {
+		t.Errorf("the restored history should not share the snapshot's, got %v", snapshot.History)
+	}
+}
+
+func TestRestoreRenamedState(t *testing.T) {
+	orderStateMachine := getStateMachine()
+	orderStateMachine.Version("v2")
+	orderStateMachine.AddMigration(NewMigration[*Order]("v1", "v2").MapState("awaiting_payment", "checkout").MapState("new", "draft"))
+
+	order := &Order{}
+	snapshot := Snapshot{State: "awaiting_payment", PreviousState: "new", Version: "v1", Fingerprint: "old"}
+	if err := orderStateMachine.Restore(order, snapshot); err != nil {
+		t.Fatalf("renamed states should be mapped by the migrations, got %v", err)
+	}
+
+	version, fingerprint := order.GetVersion()
+	if order.State != "checkout" || order.PreviousState != "draft" || version != "v2" || fingerprint != orderStateMachine.Fingerprint() {
+		t.Errorf("the snapshot should be restored in the current version, got %+v", order.Transition)
+	}
+
+	snapshot.State = "on_hold"
+	if err := orderStateMachine.Restore(order, snapshot); !errors.Is(err, ErrUndeclaredState) {
+		t.Errorf("states neither defined nor renamed should fail with ErrUndeclaredState, got %v", err)
+	}
+}
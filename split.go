@@ -0,0 +1,228 @@
+package transition
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrSplitConflict is returned by SplitState when rewriting the transitions of an event would give it two
+// transitions to the same state
+var ErrSplitConflict = errors.New("split conflict")
+
+// SplitOptions configure SplitState
+type SplitOptions struct {
+	// Incoming chooses the new state transitions of event going to the split state now go to, the first new state
+	// when nil. It's called with an empty event for the initial state, when it's the split state
+	Incoming func(event string) string
+}
+
+// SplitChangeKind tells what SplitState changed, see SplitChange
+type SplitChangeKind string
+
+const (
+	// SplitStateCopied is a new state copied from the split state: hooks, invariants, materializers, labels,
+	// metadata, timeouts, SLA and final flag
+	SplitStateCopied SplitChangeKind = "state_copied"
+	// SplitIncoming is a transition now going to a new state instead of the split state
+	SplitIncoming SplitChangeKind = "incoming"
+	// SplitOutgoing is a transition now also going from the new states
+	SplitOutgoing SplitChangeKind = "outgoing"
+	// SplitSelf is a transition from and to the split state, now going to a new state from the split and new states
+	SplitSelf SplitChangeKind = "self"
+	// SplitInitial is the initial state, or the initial state of a discriminator, now being a new state
+	SplitInitial SplitChangeKind = "initial"
+)
+
+// SplitChange is a change made by SplitState
+type SplitChange struct {
+	Kind SplitChangeKind `json:"kind"`
+	// Event is the event of the changed transition, empty for states
+	Event string `json:"event,omitempty"`
+	// From and To are the from states and destination of the transition after the change, To is the new state for
+	// copied states and the new initial state
+	From []string `json:"from,omitempty"`
+	To   string   `json:"to"`
+}
+
+func (change SplitChange) String() string {
+	switch change.Kind {
+	case SplitStateCopied:
+		return fmt.Sprintf("state %s copied", change.To)
+	case SplitInitial:
+		if change.Event != "" {
+			return fmt.Sprintf("initial state of %q is %s", change.Event, change.To)
+		}
+		return fmt.Sprintf("initial state is %s", change.To)
+	}
+	return fmt.Sprintf("%s transition of event %s: %s -> %s", change.Kind, change.Event, strings.Join(change.From, ","), change.To)
+}
+
+// SplitReport lists the changes made by SplitState
+type SplitReport struct {
+	State   string        `json:"state"`
+	Into    []string      `json:"into"`
+	Changes []SplitChange `json:"changes"`
+}
+
+func (report SplitReport) String() string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "split %s into %s", report.State, strings.Join(report.Into, ", "))
+	for _, change := range report.Changes {
+		fmt.Fprintf(&builder, "\n  %s", change)
+	}
+	return builder.String()
+}
+
+// SplitState split the declared state name into the new states first and second, for a transition period where
+// values may still be in name:
+//
+//   - the new states copy the hooks, invariants, materializers, labels, metadata, timeouts, SLA and final flag of name
+//   - transitions going to name go to the new state chosen by SplitOptions.Incoming instead, so does the initial state
+//   - transitions going from name also go from both new states, including those matching name with a glob
+//
+// name keeps its hooks and outgoing transitions, so values still in it can leave it, and is marked as an alias of
+// the new states: Validate doesn't warn about it having no incoming transition, Describe lists it with AliasOf.
+// Nothing is changed when SplitState fails, e.g. on undeclared, composite or existing states
+func (sm *StateMachine[T]) SplitState(name, first, second string, opts SplitOptions) (SplitReport, error) {
+	sm.owner.checkMutable("SplitState")
+	report := SplitReport{State: name, Into: []string{first, second}}
+
+	incoming := func(event string) string {
+		if opts.Incoming == nil {
+			return first
+		}
+		return opts.Incoming(event)
+	}
+
+	if err := sm.checkSplit(name, first, second, incoming); err != nil {
+		return report, err
+	}
+
+	old := sm.State(name)
+	for _, into := range report.Into {
+		state := old.clone(sm.owner)
+		state.Name = into
+		state.aliasOf = nil
+		if sm.statesShared {
+			sm.states, sm.statesShared = cloneMap(sm.states), false
+		}
+		sm.states[into] = state
+		report.Changes = append(report.Changes, SplitChange{Kind: SplitStateCopied, To: into})
+	}
+	old.aliasOf = append(clip(old.aliasOf), first, second)
+
+	if sm.initialState == name {
+		sm.initialState = incoming("")
+		report.Changes = append(report.Changes, SplitChange{Kind: SplitInitial, To: sm.initialState})
+	}
+	if sm.initials != nil {
+		initials := *sm.initials
+		initials.states = cloneMap(initials.states)
+		kinds := make([]string, 0, len(initials.states))
+		for kind := range initials.states {
+			kinds = append(kinds, kind)
+		}
+		sort.Strings(kinds)
+		for _, kind := range kinds {
+			if initials.states[kind] == name {
+				initials.states[kind] = incoming("")
+				report.Changes = append(report.Changes, SplitChange{Kind: SplitInitial, Event: kind, To: initials.states[kind]})
+			}
+		}
+		sm.initials = &initials
+	}
+
+	for _, eventName := range sm.eventNames() {
+		if !sm.splitTouches(sm.events[eventName], name) {
+			continue
+		}
+
+		event := sm.Event(eventName)
+		for _, transition := range event.transitions {
+			from := transition.acceptsFrom(name) || transition.matchesGlob(name)
+			if from {
+				transition.addFroms(name, first, second)
+			}
+
+			kind := SplitOutgoing
+			if transition.to == name && transition.toFunc == nil {
+				kind = SplitIncoming
+				if from {
+					kind = SplitSelf
+				}
+				delete(event.transitionIndex, transition.to)
+				transition.to = incoming(eventName)
+				event.transitionIndex[transition.to] = indexOf(event.transitions, transition)
+			} else if !from {
+				continue
+			}
+			report.Changes = append(report.Changes, SplitChange{Kind: kind, Event: eventName, From: transition.fromList(), To: transition.to})
+		}
+	}
+	return report, nil
+}
+
+// checkSplit returns why name can't be split into first and second, if it can't
+func (sm *StateMachine[T]) checkSplit(name, first, second string, incoming func(event string) string) error {
+	var errs []error
+	state, ok := sm.states[name]
+	switch {
+	case !ok:
+		errs = append(errs, fmt.Errorf("state %s: %w", name, ErrUndeclaredState))
+	case state.sub != nil:
+		errs = append(errs, fmt.Errorf("state %s embeds a sub-machine, it can't be split", name))
+	}
+
+	if first == second || first == name || second == name {
+		errs = append(errs, fmt.Errorf("state %s must be split into two other states, got %s and %s", name, first, second))
+	}
+	for _, into := range []string{first, second} {
+		if _, ok := sm.states[into]; (ok || into == sm.initialState) && into != name {
+			errs = append(errs, fmt.Errorf("state %s is already declared", into))
+		}
+	}
+
+	checkTarget := func(what, target string) {
+		if target != first && target != second {
+			errs = append(errs, fmt.Errorf("%s would go to %s, which is neither %s nor %s", what, target, first, second))
+		}
+	}
+	if sm.isInitial(name) {
+		checkTarget("initial state", incoming(""))
+	}
+	for _, eventName := range sm.eventNames() {
+		event := sm.events[eventName]
+		if index, ok := event.transitionIndex[name]; ok && event.transitions[index].toFunc == nil {
+			target := incoming(eventName)
+			checkTarget("event "+eventName, target)
+			if _, exists := event.transitionIndex[target]; exists {
+				errs = append(errs, fmt.Errorf("event %s already goes to %s: %w", eventName, target, ErrSplitConflict))
+			}
+		}
+	}
+	return newMultiError(errs)
+}
+
+// splitTouches reports whether some transition of event goes to or from state
+func (sm *StateMachine[T]) splitTouches(event *Event[T], state string) bool {
+	if _, ok := event.transitionIndex[state]; ok {
+		return true
+	}
+	for _, transition := range event.transitions {
+		if transition.acceptsFrom(state) || transition.matchesGlob(state) {
+			return true
+		}
+	}
+	return false
+}
+
+func indexOf[T Stater](transitions []*EventTransition[T], transition *EventTransition[T]) int {
+	for i, candidate := range transitions {
+		if candidate == transition {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,167 @@
+package transition
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func getSplitStateMachine() (*StateMachine[*Order], *[]string) {
+	var calls []string
+	record := func(call string) func(order *Order) error {
+		return func(order *Order) error {
+			calls = append(calls, call)
+			return nil
+		}
+	}
+
+	orderStateMachine := getStateMachine()
+	orderStateMachine.State("processed").Enter(record("enter")).Exit(record("exit")).Meta("team", "warehouse").Label("fr", "traité")
+	orderStateMachine.State("delivered").Final()
+	orderStateMachine.Event("process").To("processed").From("paid")
+	orderStateMachine.Event("deliver").To("delivered").From("processed")
+	orderStateMachine.Event("recount").To("processed").From("processed")
+	orderStateMachine.Event("cancel").To("cancelled").FromGlob("p*")
+	return orderStateMachine, &calls
+}
+
+func TestSplitState(t *testing.T) {
+	orderStateMachine, calls := getSplitStateMachine()
+
+	report, err := orderStateMachine.SplitState("processed", "picking", "packing", SplitOptions{
+		Incoming: func(event string) string {
+			if event == "recount" {
+				return "packing"
+			}
+			return "picking"
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `split processed into picking, packing
+  state picking copied
+  state packing copied
+  outgoing transition of event cancel: p*,packing,picking,processed -> cancelled
+  outgoing transition of event deliver: packing,picking,processed -> delivered
+  incoming transition of event process: paid -> picking
+  self transition of event recount: packing,picking,processed -> packing`
+	if report.String() != expected {
+		t.Errorf("unexpected report:\n%s", report)
+	}
+	if !reflect.DeepEqual(report.Changes[4], SplitChange{Kind: SplitIncoming, Event: "process", From: []string{"paid"}, To: "picking"}) {
+		t.Errorf("unexpected change %v", report.Changes[4])
+	}
+
+	if err := orderStateMachine.Validate(WithWarningsAsErrors()); err != nil && strings.Contains(err.Error(), "state processed: no incoming transition") {
+		t.Errorf("the split state should not be warned about, got %v", err)
+	}
+
+	// new values go through the new states, running the copied hooks
+	order := &Order{}
+	order.State = "paid"
+	for _, event := range []string{"process", "recount", "deliver"} {
+		if err := orderStateMachine.Trigger(event, order); err != nil {
+			t.Fatalf("%s: %v", event, err)
+		}
+	}
+	if order.State != "delivered" || !reflect.DeepEqual(*calls, []string{"enter", "exit", "enter", "exit"}) {
+		t.Errorf("unexpected state %s and calls %v", order.State, *calls)
+	}
+
+	// values still in the split state can leave it
+	order.State = "processed"
+	if err := orderStateMachine.Trigger("cancel", order); err != nil || order.State != "cancelled" {
+		t.Errorf("values in the split state should leave it, got %s and %v", order.State, err)
+	}
+
+	description := orderStateMachine.Describe()
+	for _, state := range description.States {
+		switch state.Name {
+		case "processed":
+			if !reflect.DeepEqual(state.AliasOf, []string{"picking", "packing"}) {
+				t.Errorf("processed should be an alias, got %v", state.AliasOf)
+			}
+		case "picking", "packing":
+			if state.Enter != 1 || state.Exit != 1 || state.Metadata["team"] != "warehouse" || state.AliasOf != nil {
+				t.Errorf("unexpected copied state %+v", state)
+			}
+			if label := orderStateMachine.StateLabel(state.Name, "fr"); label != "traité" {
+				t.Errorf("labels should be copied, got %s", label)
+			}
+		}
+	}
+}
+
+func TestSplitStateFinalAndInitial(t *testing.T) {
+	orderStateMachine, _ := getSplitStateMachine()
+	orderStateMachine.State("draft")
+	orderStateMachine.InitialFor(func(order *Order) string { return order.Address }, map[string]string{"sale": "draft"})
+
+	report, err := orderStateMachine.SplitState("draft", "cart", "quote", SplitOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := report.Changes[2:4]; !reflect.DeepEqual(got, []SplitChange{{Kind: SplitInitial, To: "cart"}, {Kind: SplitInitial, Event: "sale", To: "cart"}}) {
+		t.Errorf("unexpected initial changes %v", got)
+	}
+	if _, err := orderStateMachine.SplitState("delivered", "shipped", "received", SplitOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if !orderStateMachine.states["shipped"].final || !orderStateMachine.states["received"].final {
+		t.Error("the final flag should be copied")
+	}
+
+	if order := (&Order{Address: "sale"}); orderStateMachine.Trigger("checkout", order) != nil || order.State != "checkout" {
+		t.Errorf("new orders should start in cart and checkout, got %s", order.State)
+	}
+}
+
+func TestSplitStateErrors(t *testing.T) {
+	orderStateMachine, _ := getSplitStateMachine()
+	orderStateMachine.Event("process").To("picking").From("checkout")
+	fingerprint := orderStateMachine.Fingerprint()
+
+	_, err := orderStateMachine.SplitState("processed", "picking", "processed", SplitOptions{
+		Incoming: func(event string) string {
+			if event == "recount" {
+				return "archived"
+			}
+			return "picking"
+		},
+	})
+	for _, message := range []string{
+		"state processed must be split into two other states, got picking and processed",
+		"event recount would go to archived, which is neither picking nor processed",
+		"event process already goes to picking: split conflict",
+	} {
+		if err == nil || !strings.Contains(err.Error(), message) {
+			t.Errorf("expected %q in %v", message, err)
+		}
+	}
+	if !errors.Is(err, ErrSplitConflict) || orderStateMachine.Fingerprint() != fingerprint {
+		t.Errorf("nothing should change when splitting fails, got %v", err)
+	}
+
+	if _, err := orderStateMachine.SplitState("unknown", "a", "b", SplitOptions{}); !errors.Is(err, ErrUndeclaredState) {
+		t.Errorf("expected ErrUndeclaredState, got %v", err)
+	}
+	if _, err := orderStateMachine.SplitState("paid", "checkout", "b", SplitOptions{}); err == nil || !strings.Contains(err.Error(), "state checkout is already declared") {
+		t.Errorf("expected an existing state, got %v", err)
+	}
+}
+
+func TestSplitStateClone(t *testing.T) {
+	orderStateMachine, _ := getSplitStateMachine()
+	fingerprint := orderStateMachine.Fingerprint()
+
+	clone := orderStateMachine.Clone()
+	if _, err := clone.SplitState("processed", "picking", "packing", SplitOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if orderStateMachine.Fingerprint() != fingerprint || clone.Fingerprint() == fingerprint {
+		t.Error("splitting a clone should not change the original")
+	}
+}
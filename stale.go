@@ -0,0 +1,64 @@
+package transition
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrDefinitionChanged is returned by Execute for commands built for another definition of the state machine,
+// see TriggerCommand.Fingerprint and OnStaleCommand. HealthCheck reports it for unexpected fingerprints
+var ErrDefinitionChanged = errors.New("definition changed")
+
+// StaleCommandError reports a command built for another definition of the state machine
+type StaleCommandError struct {
+	Command TriggerCommand
+	// Current is the fingerprint of the state machine executing the command
+	Current string
+	Err     error
+}
+
+func (staleErr *StaleCommandError) Error() string {
+	return fmt.Sprintf("command %s was built for definition %s, the state machine is %s: %v", staleErr.Command.Event, staleErr.Command.Fingerprint, staleErr.Current, staleErr.Err)
+}
+
+// Unwrap returns ErrDefinitionChanged, or the error of the OnStaleCommand hook
+func (staleErr *StaleCommandError) Unwrap() error {
+	return staleErr.Err
+}
+
+// OnStaleCommand register a hook called by Execute with commands whose fingerprint differs from the state machine's,
+// e.g. queued before a deployment. It returns the command to execute, possibly rewritten, e.g. renaming an event,
+// or an error to reject it. Stale commands going on are reported to OnError with ErrDefinitionChanged.
+// Without hook, stale commands are rejected with ErrDefinitionChanged, see KeepStaleCommands
+func (sm *StateMachine[T]) OnStaleCommand(fc func(ctx context.Context, command TriggerCommand) (TriggerCommand, error)) *StateMachine[T] {
+	sm.owner.checkMutable("OnStaleCommand")
+	sm.onStaleCommand = fc
+	return sm
+}
+
+// KeepStaleCommands is an OnStaleCommand hook executing stale commands unchanged, they are still reported to OnError
+func KeepStaleCommands(ctx context.Context, command TriggerCommand) (TriggerCommand, error) {
+	return command, nil
+}
+
+// checkFingerprint returns the command to execute when it carries a fingerprint differing from the state machine's
+func (sm *StateMachine[T]) checkFingerprint(ctx context.Context, command TriggerCommand) (TriggerCommand, error) {
+	if command.Fingerprint == "" {
+		return command, nil
+	}
+	current := sm.Fingerprint()
+	if command.Fingerprint == current {
+		return command, nil
+	}
+
+	if sm.onStaleCommand == nil {
+		return command, &StaleCommandError{Command: command, Current: current, Err: ErrDefinitionChanged}
+	}
+	rewritten, err := sm.onStaleCommand(ctx, command)
+	if err != nil {
+		return command, &StaleCommandError{Command: command, Current: current, Err: err}
+	}
+	sm.reportError(&StaleCommandError{Command: command, Current: current, Err: ErrDefinitionChanged})
+	return rewritten, nil
+}
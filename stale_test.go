@@ -0,0 +1,88 @@
+package transition
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestStaleCommands(t *testing.T) {
+	getMachines := func() (*StateMachine[*Order], *StateMachine[*Order]) {
+		previous, current := getStateMachine(), getStateMachine()
+		previous.Event("cancel").To("cancelled").From("draft")
+		current.Event("abort").To("cancelled").From("draft")
+		return previous, current
+	}
+	ctx := context.Background()
+
+	t.Run("reject", func(t *testing.T) {
+		previous, current := getMachines()
+		command := TriggerCommand{Event: "cancel", Fingerprint: previous.Fingerprint()}
+
+		order := &Order{}
+		_, err := current.Execute(ctx, command, order)
+		var staleErr *StaleCommandError
+		if !errors.Is(err, ErrDefinitionChanged) || !errors.As(err, &staleErr) || staleErr.Current != current.Fingerprint() || order.State != "" {
+			t.Errorf("stale commands should be rejected by default, got %v", err)
+		}
+
+		if _, err := current.Execute(ctx, TriggerCommand{Event: "abort", Fingerprint: current.Fingerprint()}, order); err != nil {
+			t.Errorf("commands of the current definition should be executed, got %v", err)
+		}
+	})
+
+	t.Run("warn", func(t *testing.T) {
+		previous, current := getMachines()
+		var warnings []error
+		current.OnStaleCommand(KeepStaleCommands).OnError(func(err error) { warnings = append(warnings, err) })
+
+		_, err := current.Execute(ctx, TriggerCommand{Event: "checkout", Fingerprint: previous.Fingerprint()}, &Order{})
+		if err != nil || len(warnings) != 1 || !errors.Is(warnings[0], ErrDefinitionChanged) {
+			t.Errorf("stale commands should be executed and reported, got %v, %v", err, warnings)
+		}
+
+		_, err = current.Execute(ctx, TriggerCommand{Event: "cancel", Fingerprint: previous.Fingerprint()}, &Order{})
+		if !errors.Is(err, ErrUnknownEvent) {
+			t.Errorf("the renamed event should be unknown, got %v", err)
+		}
+	})
+
+	t.Run("rewrite", func(t *testing.T) {
+		previous, current := getMachines()
+		current.OnStaleCommand(func(ctx context.Context, command TriggerCommand) (TriggerCommand, error) {
+			switch command.Event {
+			case "cancel":
+				command.Event = "abort"
+			case "pay":
+				return command, errors.New("payments of the previous definition must be retried")
+			}
+			return command, nil
+		})
+
+		order := &Order{}
+		result, err := current.Execute(ctx, TriggerCommand{Event: "cancel", Fingerprint: previous.Fingerprint()}, order)
+		if err != nil || result.Event != "abort" || order.State != "cancelled" {
+			t.Errorf("the command should be rewritten, got %+v, %v", result, err)
+		}
+
+		_, err = current.Execute(ctx, TriggerCommand{Event: "pay", Fingerprint: previous.Fingerprint()}, &Order{})
+		var staleErr *StaleCommandError
+		if !errors.As(err, &staleErr) || errors.Is(err, ErrDefinitionChanged) {
+			t.Errorf("the hook's error should be returned, got %v", err)
+		}
+	})
+}
+
+func TestStaleCommandsCachedFingerprint(t *testing.T) {
+	orderStateMachine := getStateMachine()
+	command := TriggerCommand{Event: "checkout", Fingerprint: orderStateMachine.Fingerprint()}
+
+	allocs := testing.AllocsPerRun(10, func() {
+		if _, err := orderStateMachine.checkFingerprint(context.Background(), command); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("checking commands of the current definition should use the cached fingerprint, got %v allocs", allocs)
+	}
+}
@@ -0,0 +1,175 @@
+package transition
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// TransitionStats counts the triggers of an event from a state to another, To is empty for triggers that failed
+// before a transition was matched
+type TransitionStats struct {
+	Event     string `json:"event"`
+	From      string `json:"from"`
+	To        string `json:"to,omitempty"`
+	Attempts  int64  `json:"attempts"`
+	Successes int64  `json:"successes"`
+	Failures  int64  `json:"failures"`
+	// FailuresByPhase counts the failures by the phase they happened in
+	FailuresByPhase map[Phase]int64 `json:"failures_by_phase,omitempty"`
+	// Duration is the cumulative duration of the triggers
+	Duration    time.Duration `json:"duration"`
+	LastError   string        `json:"last_error,omitempty"`
+	LastErrorAt time.Time     `json:"last_error_at,omitempty"`
+}
+
+// StatsSnapshot is a copy of the stats collected by a state machine, see StateMachine.EnableStats
+type StatsSnapshot struct {
+	// Transitions holds the stats sorted by event, from and to
+	Transitions []TransitionStats `json:"transitions"`
+	// InFlight is the number of triggers running, counted when the state machine limits them, see WithMaxConcurrentTriggers
+	InFlight int `json:"in_flight"`
+}
+
+// Transition returns the stats of the triggers of event from a state to another
+func (snapshot StatsSnapshot) Transition(event, from, to string) (TransitionStats, bool) {
+	for _, stats := range snapshot.Transitions {
+		if stats.Event == event && stats.From == from && stats.To == to {
+			return stats, true
+		}
+	}
+	return TransitionStats{}, false
+}
+
+type statsKey struct {
+	event string
+	from  string
+	to    string
+}
+
+// statsCounters are the stats of a transition, failures are rare enough to be guarded by a mutex
+type statsCounters struct {
+	attempts  atomic.Int64
+	successes atomic.Int64
+	duration  atomic.Int64
+
+	mu          sync.Mutex
+	failures    map[Phase]int64
+	lastError   string
+	lastErrorAt time.Time
+}
+
+type statsCollector struct {
+	counters sync.Map // statsKey -> *statsCounters
+}
+
+func (collector *statsCollector) record(key statsKey, err error, duration time.Duration, now time.Time) {
+	value, ok := collector.counters.Load(key)
+	if !ok {
+		value, _ = collector.counters.LoadOrStore(key, &statsCounters{})
+	}
+	counters := value.(*statsCounters)
+
+	counters.attempts.Add(1)
+	counters.duration.Add(int64(duration))
+	if err == nil {
+		counters.successes.Add(1)
+		return
+	}
+
+	phase := PhaseMatch
+	var transitionErr *TransitionError
+	if errors.As(err, &transitionErr) {
+		phase = transitionErr.Phase
+	}
+
+	counters.mu.Lock()
+	if counters.failures == nil {
+		counters.failures = map[Phase]int64{}
+	}
+	counters.failures[phase]++
+	counters.lastError, counters.lastErrorAt = err.Error(), now
+	counters.mu.Unlock()
+}
+
+// EnableStats collect stats for every transition triggered from now on, they are disabled by default.
+// Collecting stats is cheap enough to be left on in production
+func (sm *StateMachine[T]) EnableStats() *StateMachine[T] {
+	if sm.stats.Load() == nil {
+		sm.stats.Store(&statsCollector{})
+	}
+	return sm
+}
+
+// Stats returns a copy of the stats collected since stats were enabled or reset
+func (sm *StateMachine[T]) Stats() StatsSnapshot {
+	snapshot := StatsSnapshot{InFlight: sm.limiter.inFlight()}
+	collector := sm.stats.Load()
+	if collector == nil {
+		return snapshot
+	}
+
+	collector.counters.Range(func(key, value any) bool {
+		var (
+			statsKey = key.(statsKey)
+			counters = value.(*statsCounters)
+			stats    = TransitionStats{
+				Event:     statsKey.event,
+				From:      statsKey.from,
+				To:        statsKey.to,
+				Attempts:  counters.attempts.Load(),
+				Successes: counters.successes.Load(),
+				Duration:  time.Duration(counters.duration.Load()),
+			}
+		)
+
+		counters.mu.Lock()
+		for phase, count := range counters.failures {
+			if stats.FailuresByPhase == nil {
+				stats.FailuresByPhase = map[Phase]int64{}
+			}
+			stats.FailuresByPhase[phase] = count
+			stats.Failures += count
+		}
+		stats.LastError, stats.LastErrorAt = counters.lastError, counters.lastErrorAt
+		counters.mu.Unlock()
+
+		snapshot.Transitions = append(snapshot.Transitions, stats)
+		return true
+	})
+
+	sort.Slice(snapshot.Transitions, func(i, j int) bool {
+		a, b := snapshot.Transitions[i], snapshot.Transitions[j]
+		if a.Event != b.Event {
+			return a.Event < b.Event
+		}
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		return a.To < b.To
+	})
+	return snapshot
+}
+
+// ResetStats clear the collected stats, stats stay enabled
+func (sm *StateMachine[T]) ResetStats() {
+	if sm.stats.Load() != nil {
+		sm.stats.Store(&statsCollector{})
+	}
+}
+
+// recordStats record the outcome of a trigger of event on value from state from
+func (sm *StateMachine[T]) recordStats(collector *statsCollector, event string, from string, value T, err error, start time.Time) {
+	key := statsKey{event: event, from: from}
+	var transitionErr *TransitionError
+	switch {
+	case errors.As(err, &transitionErr):
+		key.from, key.to = transitionErr.From, transitionErr.To
+	case err == nil:
+		key.to = value.GetState()
+	}
+
+	collector.record(key, err, time.Since(start), sm.clock.Now())
+}
@@ -0,0 +1,92 @@
+package transition
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	orderStateMachine := getStateMachine()
+	if snapshot := orderStateMachine.Stats(); len(snapshot.Transitions) != 0 {
+		t.Errorf("stats should be disabled by default, got %+v", snapshot)
+	}
+
+	failing := true
+	orderStateMachine.Event("pay").To("paid").From("checkout").After(func(order *Order) error {
+		if failing {
+			return errors.New("payment declined")
+		}
+		return nil
+	})
+	orderStateMachine.EnableStats()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			orderStateMachine.Trigger("checkout", &Order{})
+		}()
+	}
+	wg.Wait()
+
+	order := &Order{}
+	orderStateMachine.Trigger("checkout", order)
+	orderStateMachine.Trigger("pay", order)
+	orderStateMachine.Trigger("pay", order)
+	failing = false
+	orderStateMachine.Trigger("pay", order)
+
+	snapshot := orderStateMachine.Stats()
+	checkout, ok := snapshot.Transition("checkout", "draft", "checkout")
+	if !ok || checkout.Attempts != 11 || checkout.Successes != 11 || checkout.Failures != 0 {
+		t.Errorf("unexpected checkout stats %+v", checkout)
+	}
+
+	pay, _ := snapshot.Transition("pay", "checkout", "paid")
+	if pay.Attempts != 3 || pay.Successes != 1 || pay.Failures != 2 || pay.FailuresByPhase[PhaseAfter] != 2 {
+		t.Errorf("unexpected pay stats %+v", pay)
+	}
+	if pay.LastErrorAt.IsZero() || pay.LastError != "failed to perform event pay from state checkout to paid: after hook pay#0: payment declined" {
+		t.Errorf("unexpected last error %q at %v", pay.LastError, pay.LastErrorAt)
+	}
+
+	orderStateMachine.Trigger("pay", order)
+	if noMatch, ok := orderStateMachine.Stats().Transition("pay", "paid", ""); !ok || noMatch.FailuresByPhase[PhaseMatch] != 1 {
+		t.Errorf("triggers failing to match should be counted without destination, got %+v", noMatch)
+	}
+
+	orderStateMachine.ResetStats()
+	if snapshot := orderStateMachine.Stats(); len(snapshot.Transitions) != 0 {
+		t.Errorf("stats should be cleared, got %+v", snapshot)
+	}
+	orderStateMachine.Trigger("checkout", &Order{})
+	if snapshot := orderStateMachine.Stats(); len(snapshot.Transitions) != 1 {
+		t.Errorf("stats should stay enabled after a reset, got %+v", snapshot)
+	}
+}
+
+func BenchmarkTriggerStats(b *testing.B) {
+	for _, enabled := range []bool{false, true} {
+		name := "disabled"
+		if enabled {
+			name = "enabled"
+		}
+
+		b.Run(name, func(b *testing.B) {
+			orderStateMachine := getStateMachine()
+			orderStateMachine.Event("reset").To("draft").From("checkout")
+			if enabled {
+				orderStateMachine.EnableStats()
+			}
+
+			order := &Order{}
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				orderStateMachine.Trigger("checkout", order)
+				orderStateMachine.Trigger("reset", order)
+			}
+		})
+	}
+}
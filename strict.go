@@ -0,0 +1,89 @@
+package transition
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrExternalMutation is reported when the state of a StrictTransition value was changed outside the state machine
+var ErrExternalMutation = errors.New("state changed outside the state machine")
+
+// StrictTransition records the state last set by a state machine, embed it along with Transition to detect states
+// set directly, e.g. order.State = "paid", see StateMachine.WasExternallyMutated. Persist MachineState along with
+// State, values stored before adopting StrictTransition need it backfilled
+type StrictTransition struct {
+	// MachineState is the state last set by a state machine
+	MachineState string
+}
+
+// GetMachineState returns the state last set by a state machine
+func (transition StrictTransition) GetMachineState() string {
+	return transition.MachineState
+}
+
+// machineStateRecorder is implemented by StrictTransition, the state machine records its own state changes
+// through it, they can't be recorded from outside the package
+type machineStateRecorder interface {
+	recordMachineState(state string)
+	recordedMachineState() string
+}
+
+func (transition *StrictTransition) recordMachineState(state string) {
+	transition.MachineState = state
+}
+
+func (transition *StrictTransition) recordedMachineState() string {
+	return transition.MachineState
+}
+
+// recordMachineState records state as set by the state machine on values embedding StrictTransition, the returned
+// func restores the previously recorded state
+func recordMachineState(value Stater, state string) (restore func()) {
+	recorder, ok := value.(machineStateRecorder)
+	if !ok {
+		return func() {}
+	}
+	recordedWas := recorder.recordedMachineState()
+	recorder.recordMachineState(state)
+	return func() { recorder.recordMachineState(recordedWas) }
+}
+
+// WasExternallyMutated reports whether the state of value differs from the state a state machine last set,
+// always false for values not embedding StrictTransition
+func (sm *StateMachine[T]) WasExternallyMutated(value T) bool {
+	return checkMutation(value) != nil
+}
+
+// CheckMutations check values for states changed outside the state machine, returning a MultiError holding
+// an error wrapping ErrExternalMutation for every mutated value, prefixed with its index
+func (sm *StateMachine[T]) CheckMutations(values ...T) error {
+	var errs []error
+	for i, value := range values {
+		if err := checkMutation(value); err != nil {
+			errs = append(errs, fmt.Errorf("value %d: %w", i, err))
+		}
+	}
+	return newMultiError(errs)
+}
+
+// DetectExternalMutations check values for states changed outside the state machine when triggering events,
+// mutations are reported to OnError and the trigger goes on
+func (sm *StateMachine[T]) DetectExternalMutations() *StateMachine[T] {
+	sm.owner.checkMutable("DetectExternalMutations")
+	sm.detectMutations = true
+	return sm
+}
+
+func checkMutation(value Stater) error {
+	if isNil(value) {
+		return nil
+	}
+	recorder, ok := value.(machineStateRecorder)
+	if !ok {
+		return nil
+	}
+	if state, recorded := value.GetState(), recorder.recordedMachineState(); state != recorded {
+		return fmt.Errorf("state %q, last set by the state machine to %q: %w", state, recorded, ErrExternalMutation)
+	}
+	return nil
+}
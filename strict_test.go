@@ -0,0 +1,105 @@
+package transition
+
+import (
+	"errors"
+	"testing"
+)
+
+type StrictOrder struct {
+	Id int
+	Transition
+	StrictTransition
+}
+
+func getStrictStateMachine() *StateMachine[*StrictOrder] {
+	orderStateMachine := New(&StrictOrder{})
+	orderStateMachine.Initial("draft")
+	orderStateMachine.State("checkout")
+	orderStateMachine.State("paid").Enter(func(order *StrictOrder) error {
+		if order.Id == 0 {
+			return errors.New("order has no id")
+		}
+		return nil
+	})
+	orderStateMachine.Event("checkout").To("checkout").From("draft")
+	orderStateMachine.Event("pay").To("paid").From("checkout")
+	return orderStateMachine
+}
+
+func TestWasExternallyMutated(t *testing.T) {
+	orderStateMachine := getStrictStateMachine()
+	order := &StrictOrder{}
+
+	if orderStateMachine.WasExternallyMutated(order) {
+		t.Errorf("a new value should not be mutated")
+	}
+
+	if err := orderStateMachine.Trigger("checkout", order); err != nil {
+		t.Fatalf("no error expected, got %v", err)
+	}
+	if orderStateMachine.WasExternallyMutated(order) || order.MachineState != "checkout" {
+		t.Errorf("state set by the state machine should be recorded, got %q", order.MachineState)
+	}
+
+	if err := orderStateMachine.Trigger("pay", order); err == nil {
+		t.Fatalf("pay should fail without id")
+	}
+	if order.MachineState != "checkout" {
+		t.Errorf("recorded state should be rolled back, got %q", order.MachineState)
+	}
+
+	order.State = "paid"
+	if !orderStateMachine.WasExternallyMutated(order) {
+		t.Errorf("state set directly should be detected")
+	}
+
+	order.SetState("checkout")
+	if orderStateMachine.WasExternallyMutated(order) {
+		t.Errorf("state set back to the recorded state should not be mutated")
+	}
+	if getStateMachine().WasExternallyMutated(&Order{Transition: Transition{State: "paid"}}) {
+		t.Errorf("values not embedding StrictTransition are never mutated")
+	}
+}
+
+func TestCheckMutations(t *testing.T) {
+	orderStateMachine := getStrictStateMachine()
+	orders := []*StrictOrder{{}, {}, {}}
+	orders[1].State = "paid"
+
+	err := orderStateMachine.CheckMutations(orders...)
+
+	var multiErr *MultiError
+	if !errors.As(err, &multiErr) || len(multiErr.Errors()) != 1 {
+		t.Fatalf("should raise a MultiError of 1 error, got %v", err)
+	}
+	if !errors.Is(err, ErrExternalMutation) || multiErr.Errors()[0].Error() != `value 1: state "paid", last set by the state machine to "": state changed outside the state machine` {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestDetectExternalMutations(t *testing.T) {
+	var reported []error
+	orderStateMachine := getStrictStateMachine().DetectExternalMutations().OnError(func(err error) {
+		reported = append(reported, err)
+	})
+
+	order := &StrictOrder{Id: 1}
+	order.State = "checkout"
+
+	if err := orderStateMachine.Trigger("pay", order); err != nil {
+		t.Fatalf("the trigger should go on, got %v", err)
+	}
+	if len(reported) != 1 || !errors.Is(reported[0], ErrExternalMutation) {
+		t.Fatalf("expected an external mutation reported, got %v", reported)
+	}
+
+	var transitionErr *TransitionError
+	if !errors.As(reported[0], &transitionErr) || transitionErr.Event != "pay" || transitionErr.From != "checkout" {
+		t.Errorf("unexpected report %v", reported[0])
+	}
+
+	if orderStateMachine.WasExternallyMutated(order) {
+		t.Errorf("the trigger should record the new state")
+	}
+}
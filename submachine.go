@@ -0,0 +1,147 @@
+package transition
+
+import (
+	"context"
+	"fmt"
+)
+
+// SubStater is implemented by values with a sub-state, driven by the sub-machine of their state, the embedded
+// NestedTransition implements it
+type SubStater interface {
+	GetSubState() string
+	SetSubState(state string)
+}
+
+// NestedTransition holds the state of the sub-machine of the state of a value, embed it in your struct along with
+// Transition, see SubMachine and SubStateOf
+type NestedTransition struct {
+	SubState string
+}
+
+// GetSubState returns the sub-state
+func (transition NestedTransition) GetSubState() string {
+	return transition.SubState
+}
+
+// SetSubState set the sub-state
+func (transition *NestedTransition) SetSubState(state string) {
+	transition.SubState = state
+}
+
+// SubStateValue is the sub-state of a value seen as the state of a value of its own, so that a sub-machine drives
+// it, see SubStateOf
+type SubStateValue struct {
+	Value SubStater
+}
+
+// GetState returns the sub-state of Value
+func (value *SubStateValue) GetState() string {
+	return value.Value.GetSubState()
+}
+
+// SetState set the sub-state of Value
+func (value *SubStateValue) SetState(state string) {
+	value.Value.SetSubState(state)
+}
+
+// SubStateOf returns the sub-state of value as a value of a sub-machine, it's the extractor of sub-machines of
+// type *StateMachine[*SubStateValue], see SubMachine
+func SubStateOf[T SubStater](value T) *SubStateValue {
+	return &SubStateValue{Value: value}
+}
+
+// SubMachineOption configure SubMachine
+type SubMachineOption func(*subMachineConfig)
+
+type subMachineConfig struct {
+	entry string
+	exits map[string]string
+}
+
+// WithEntry set the state the sub-machine starts in when the parent enters its state, the sub-machine's initial
+// state by default
+func WithEntry(state string) SubMachineOption {
+	return func(config *subMachineConfig) {
+		config.entry = state
+	}
+}
+
+// WithExits map states of the sub-machine to events of the parent, fired when the sub-machine reaches them
+func WithExits(exits map[string]string) SubMachineOption {
+	return func(config *subMachineConfig) {
+		config.exits = cloneMap(exits)
+	}
+}
+
+// subMachine is a sub-machine embedded in a state of its parent, see SubMachine
+type subMachine[T Stater] struct {
+	entry  string
+	states []string
+	// handles reports whether the sub-machine has event
+	handles func(event string) bool
+	// trigger triggers event of the sub-machine on the child value of value, recording the change of its state
+	trigger func(ctx context.Context, event string, value T, pending *mutations) error
+	// enter set the child value of value to the entry state, recording the change
+	enter func(value T, pending *mutations)
+}
+
+// SubMachine embed child in state of parent, as a composite state. extract returns the value child drives for a
+// value of parent, e.g. SubStateOf for values holding a dedicated sub-state field. When a value enters state, its
+// child value is set to the entry state, see WithEntry, without running hooks of child. While the value is in
+// state, events of child are triggered on the child value rather than on the value, and when child reaches a state
+// mapped by WithExits the parent event is triggered on the value. Triggers of child events go through the checks of
+// parent, e.g. Frozen, Authorize, BlockWhile or Shutdown, and the child value is rolled back when the parent fails:
+//
+//	payment := transition.New(&transition.SubStateValue{})
+//	...
+//	transition.SubMachine(OrderStateMachine, "payment", payment, transition.SubStateOf[*Order],
+//		transition.WithEntry("start"),
+//		transition.WithExits(map[string]string{"captured": "pay_succeeded", "failed": "pay_failed"}))
+//
+// Sub-machines can't be nested further. Can and AllowedActions don't consider events of sub-machines
+func SubMachine[T, C Stater](parent *StateMachine[T], state string, child *StateMachine[C], extract func(T) C, opts ...SubMachineOption) {
+	var config subMachineConfig
+	for _, opt := range opts {
+		opt(&config)
+	}
+	if config.entry == "" {
+		config.entry = child.initialState
+	}
+
+	sub := &subMachine[T]{entry: config.entry, states: child.stateNamesWithInitial()}
+	sub.handles = func(event string) bool {
+		_, ok := child.events[event]
+		return ok
+	}
+	sub.trigger = func(ctx context.Context, event string, value T, pending *mutations) error {
+		childValue := extract(value)
+		childStateWas := childValue.GetState()
+		if err := child.TriggerContext(ctx, event, childValue); err != nil {
+			return err
+		}
+		pending.apply(func() { childValue.SetState(childStateWas) })
+
+		if parentEvent, ok := config.exits[childValue.GetState()]; ok {
+			if err := parent.trigger(ctx, parentEvent, value, triggerOptions{}); err != nil {
+				return fmt.Errorf("sub-machine of state %s reached %s: %w", state, childValue.GetState(), err)
+			}
+		}
+		return nil
+	}
+	sub.enter = func(value T, pending *mutations) {
+		childValue := extract(value)
+		childStateWas := childValue.GetState()
+		childValue.SetState(child.Intern(config.entry))
+		pending.apply(func() { childValue.SetState(childStateWas) })
+	}
+
+	parent.State(state).sub = sub
+}
+
+// subMachineHandling returns the sub-machine of state when it has event, nil otherwise
+func (sm *StateMachine[T]) subMachineHandling(state, event string) *subMachine[T] {
+	if declared, ok := sm.states[state]; ok && declared.sub != nil && declared.sub.handles(event) {
+		return declared.sub
+	}
+	return nil
+}
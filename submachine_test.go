@@ -0,0 +1,131 @@
+package transition
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type NestedOrder struct {
+	Id int
+
+	Transition
+	NestedTransition
+}
+
+func getNestedStateMachine() (*StateMachine[*NestedOrder], *StateMachine[*SubStateValue]) {
+	payment := New(&SubStateValue{})
+	payment.Initial("start")
+	payment.State("authorized")
+	payment.State("captured")
+	payment.State("failed")
+	payment.Event("authorize").To("authorized").From("start")
+	payment.Event("capture").To("captured").From("authorized")
+	payment.Event("decline").To("failed").From("start", "authorized")
+
+	orderStateMachine := New(&NestedOrder{})
+	orderStateMachine.Initial("draft")
+	orderStateMachine.State("paid")
+	orderStateMachine.State("checkout")
+	orderStateMachine.Event("checkout").To("payment").From("draft")
+	orderStateMachine.Event("pay_succeeded").To("paid").From("payment")
+	orderStateMachine.Event("pay_failed").To("checkout").From("payment")
+	SubMachine(orderStateMachine, "payment", payment, SubStateOf[*NestedOrder],
+		WithEntry("start"),
+		WithExits(map[string]string{"captured": "pay_succeeded", "failed": "pay_failed"}))
+	return orderStateMachine, payment
+}
+
+func TestSubMachine(t *testing.T) {
+	orderStateMachine, _ := getNestedStateMachine()
+
+	order := &NestedOrder{}
+	if err := orderStateMachine.Trigger("checkout", order); err != nil || order.State != "payment" || order.SubState != "start" {
+		t.Fatalf("entering payment should start the sub-machine, got %+v, %v", order, err)
+	}
+	if err := orderStateMachine.Trigger("authorize", order); err != nil || order.State != "payment" || order.SubState != "authorized" {
+		t.Fatalf("events of the sub-machine should be triggered on the sub-state, got %+v, %v", order, err)
+	}
+	if err := orderStateMachine.Trigger("capture", order); err != nil || order.State != "paid" || order.SubState != "captured" {
+		t.Fatalf("reaching captured should fire pay_succeeded, got %+v, %v", order, err)
+	}
+	if err := orderStateMachine.Trigger("authorize", order); !errors.Is(err, ErrUnknownEvent) {
+		t.Errorf("events of the sub-machine should only be offered while in payment, got %v", err)
+	}
+
+	declined := &NestedOrder{}
+	orderStateMachine.Trigger("checkout", declined)
+	if err := orderStateMachine.Trigger("decline", declined); err != nil || declined.State != "checkout" {
+		t.Errorf("reaching failed should fire pay_failed, got %+v, %v", declined, err)
+	}
+	if err := orderStateMachine.Trigger("checkout", declined); !IsNoMatch(err) {
+		t.Errorf("parent events should still be triggered on the parent, got %v", err)
+	}
+}
+
+func TestSubMachineExitFailure(t *testing.T) {
+	orderStateMachine, _ := getNestedStateMachine()
+	errClosed := errors.New("closed")
+	orderStateMachine.State("paid").Enter(func(*NestedOrder) error { return errClosed })
+
+	order := &NestedOrder{}
+	orderStateMachine.Trigger("checkout", order)
+	orderStateMachine.Trigger("authorize", order)
+	if err := orderStateMachine.Trigger("capture", order); !errors.Is(err, errClosed) || order.State != "payment" || order.SubState != "authorized" {
+		t.Errorf("a failing parent event should be returned and the sub-state rolled back, got %+v, %v", order, err)
+	}
+}
+
+func TestSubMachineEntryFailure(t *testing.T) {
+	orderStateMachine, _ := getNestedStateMachine()
+	errClosed := errors.New("closed")
+	orderStateMachine.State("payment").Enter(func(*NestedOrder) error { return errClosed })
+
+	order := &NestedOrder{}
+	if err := orderStateMachine.Trigger("checkout", order); !errors.Is(err, errClosed) || order.State != "draft" || order.SubState != "" {
+		t.Errorf("the sub-state should be rolled back along with the state, got %+v, %v", order, err)
+	}
+}
+
+func TestSubMachineParentChecks(t *testing.T) {
+	var (
+		orderStateMachine, _ = getNestedStateMachine()
+		frozen               bool
+		anonymous            = errors.New("anonymous")
+	)
+	orderStateMachine.Frozen(func(order *NestedOrder) bool { return frozen })
+	orderStateMachine.Authorize(func(ctx context.Context, event string, order *NestedOrder) error {
+		if event == "capture" {
+			return anonymous
+		}
+		return nil
+	})
+
+	order := &NestedOrder{}
+	orderStateMachine.Trigger("checkout", order)
+
+	frozen = true
+	if err := orderStateMachine.Trigger("authorize", order); !errors.Is(err, ErrValueFrozen) || order.SubState != "start" {
+		t.Errorf("events of sub-machines should not be triggered on frozen values, got %+v, %v", order, err)
+	}
+
+	frozen = false
+	orderStateMachine.Trigger("authorize", order)
+	if err := orderStateMachine.Trigger("capture", order); !errors.Is(err, ErrUnauthorized) || order.SubState != "authorized" {
+		t.Errorf("events of sub-machines should be authorized by the parent, got %+v, %v", order, err)
+	}
+}
+
+func TestSubMachineDescribe(t *testing.T) {
+	orderStateMachine, _ := getNestedStateMachine()
+
+	for _, state := range orderStateMachine.Describe().States {
+		if state.Name == "payment" && (state.SubEntry != "start" || strings.Join(state.SubStates, ",") != "authorized,captured,failed,start") {
+			t.Errorf("payment should be described as a composite state, got %+v", state)
+		}
+	}
+	if printed := orderStateMachine.Sprint(); !strings.Contains(printed, "  * payment/start") || !strings.Contains(printed, "    payment/captured") {
+		t.Errorf("sub-states should be printed under their state, got\n%s", printed)
+	}
+}
@@ -0,0 +1,176 @@
+package transition
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// SweepRule selects the values a sweep triggers an event on
+type SweepRule interface {
+	// Match returns the event to trigger on value, and whether value matches the rule
+	Match(value Stater, now time.Time) (event string, ok bool)
+}
+
+// InStateLongerThan is a sweep rule matching values that stayed in state for more than d, triggering event on them.
+// Values must track when their state changed, see TimeTracker
+func InStateLongerThan(state string, d time.Duration, event string) SweepRule {
+	return inStateLongerThan{state: state, after: d, event: event}
+}
+
+type inStateLongerThan struct {
+	state string
+	after time.Duration
+	event string
+}
+
+func (rule inStateLongerThan) Match(value Stater, now time.Time) (string, bool) {
+	if value.GetState() != rule.state {
+		return "", false
+	}
+
+	tracker, ok := value.(TimeTracker)
+	if !ok || tracker.GetStateChangedAt().IsZero() {
+		return "", false
+	}
+	return rule.event, now.Sub(tracker.GetStateChangedAt()) > rule.after
+}
+
+// SweepOption configure Sweep
+type SweepOption func(*sweepConfig)
+
+type sweepConfig struct {
+	concurrency int
+	failFast    bool
+}
+
+// SweepConcurrency trigger events on up to n values at the same time, the default is 1
+func SweepConcurrency(n int) SweepOption {
+	return func(config *sweepConfig) {
+		config.concurrency = n
+	}
+}
+
+// SweepFailFast stop the sweep at the first failed trigger
+func SweepFailFast() SweepOption {
+	return func(config *sweepConfig) {
+		config.failFast = true
+	}
+}
+
+// SweepResult is the outcome of a sweep for a value matching the rule
+type SweepResult struct {
+	// Index is the position of the value in the swept values
+	Index int
+	// Key identifies the value when the state machine has a key func
+	Key   string
+	Event string
+	From  string
+	To    string
+	Err   error
+}
+
+// SweepReport describe the outcome of a sweep
+type SweepReport struct {
+	// Results holds the outcome for each value matching the rule, in the order of the swept values.
+	// Nil values are reported as failed with ErrNilValue
+	Results   []SweepResult
+	Matched   int
+	Succeeded int
+	Failed    int
+}
+
+// Sweep trigger the rule's event on every value matching the rule, e.g. pushing forward values stuck in a state.
+// Failures are collected into the report without stopping the sweep, unless SweepFailFast is given in which
+// case the first failure is returned. Events are triggered with ctx, the sweep stops when it's done, returning ctx.Err()
+func (sm *StateMachine[T]) Sweep(ctx context.Context, values []T, rule SweepRule, opts ...SweepOption) (SweepReport, error) {
+	config := sweepConfig{concurrency: 1}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	if config.concurrency < 1 {
+		config.concurrency = 1
+	}
+
+	var (
+		now     = sm.clock.Now()
+		results = make([]*SweepResult, len(values))
+		wg      sync.WaitGroup
+		sem     = make(chan struct{}, config.concurrency)
+
+		mu       sync.Mutex
+		firstErr error
+	)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	for index, value := range values {
+		if ctx.Err() != nil {
+			break
+		}
+		if isNil(value) {
+			results[index] = &SweepResult{Index: index, Err: ErrNilValue}
+			continue
+		}
+
+		event, ok := rule.Match(value, now)
+		if !ok {
+			continue
+		}
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		result := &SweepResult{Index: index, Event: event, From: value.GetState()}
+		if sm.keyFunc != nil {
+			result.Key = sm.keyFunc(value)
+		}
+		results[index] = result
+
+		wg.Add(1)
+		go func(value T) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			result.Err = sm.TriggerContext(ctx, result.Event, value)
+			result.To = value.GetState()
+
+			if result.Err != nil && config.failFast {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = fmt.Errorf("sweep value %d: %w", result.Index, result.Err)
+				}
+				mu.Unlock()
+				cancel()
+			}
+		}(value)
+	}
+	wg.Wait()
+
+	var report SweepReport
+	for _, result := range results {
+		if result == nil {
+			continue
+		}
+
+		report.Results = append(report.Results, *result)
+		report.Matched++
+		if result.Err != nil {
+			report.Failed++
+		} else {
+			report.Succeeded++
+		}
+	}
+
+	if firstErr != nil {
+		return report, firstErr
+	}
+	return report, ctx.Err()
+}
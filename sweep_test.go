@@ -0,0 +1,151 @@
+package transition
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func getSweepStateMachine(clock Clock) *StateMachine[*Order] {
+	orderStateMachine := New(&Order{}, WithClock(clock))
+	orderStateMachine.Initial("draft")
+	orderStateMachine.Event("checkout").To("checkout").From("draft")
+	orderStateMachine.Event("expire").To("expired").From("checkout")
+	return orderStateMachine
+}
+
+func TestSweep(t *testing.T) {
+	var (
+		clock             = &manualClock{now: time.Now()}
+		orderStateMachine = getSweepStateMachine(clock)
+		orders            []*Order
+	)
+
+	for i := 0; i < 10; i++ {
+		order := &Order{Id: i}
+		if i%2 == 0 {
+			orderStateMachine.Trigger("checkout", order)
+		}
+		orders = append(orders, order)
+		clock.now = clock.now.Add(10 * time.Minute)
+	}
+
+	report, err := orderStateMachine.Sweep(context.Background(), orders, InStateLongerThan("checkout", 30*time.Minute, "expire"), SweepConcurrency(3))
+	if err != nil {
+		t.Errorf("should not raise any error when sweep, got %v", err)
+	}
+
+	// orders 0, 2, 4, 6 entered checkout 100, 80, 60 and 40 minutes ago, 8 only 20 minutes ago
+	if report.Matched != 4 || report.Succeeded != 4 || report.Failed != 0 {
+		t.Errorf("unexpected report %+v", report)
+	}
+
+	for i, order := range orders {
+		expected := ""
+		if i%2 == 0 {
+			expected = "expired"
+		}
+		if i == 8 {
+			expected = "checkout"
+		}
+		if order.State != expected {
+			t.Errorf("order %d should be in state %q, got %q", i, expected, order.State)
+		}
+	}
+}
+
+func TestSweepFailures(t *testing.T) {
+	var (
+		clock             = &manualClock{now: time.Now()}
+		orderStateMachine = getSweepStateMachine(clock)
+		orders            []*Order
+	)
+	orderStateMachine.State("expired").Enter(func(order *Order) error {
+		if order.Id == 1 {
+			return errors.New("intentional error")
+		}
+		return nil
+	})
+
+	for i := 0; i < 3; i++ {
+		order := &Order{Id: i}
+		orderStateMachine.Trigger("checkout", order)
+		orders = append(orders, order)
+	}
+	clock.now = clock.now.Add(time.Hour)
+
+	rule := InStateLongerThan("checkout", 30*time.Minute, "expire")
+	report, err := orderStateMachine.Sweep(context.Background(), orders, rule)
+	if err != nil {
+		t.Errorf("failures should not abort the sweep, got %v", err)
+	}
+
+	if report.Matched != 3 || report.Failed != 1 || report.Results[1].Err == nil {
+		t.Errorf("unexpected report %+v", report)
+	}
+
+	orders = nil
+	for i := 0; i < 3; i++ {
+		order := &Order{Id: i}
+		orderStateMachine.Trigger("checkout", order)
+		orders = append(orders, order)
+	}
+	clock.now = clock.now.Add(time.Hour)
+
+	report, err = orderStateMachine.Sweep(context.Background(), orders, rule, SweepFailFast())
+	if err == nil {
+		t.Errorf("should raise the first failure when fail fast")
+	}
+
+	if report.Matched != 2 || orders[2].State != "checkout" {
+		t.Errorf("sweep should stop at the first failure, got %+v", report)
+	}
+}
+
+func TestSweepContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	clock := &manualClock{now: time.Now()}
+	orderStateMachine := getSweepStateMachine(clock)
+	order := &Order{}
+	orderStateMachine.Trigger("checkout", order)
+	clock.now = clock.now.Add(time.Hour)
+
+	_, err := orderStateMachine.Sweep(ctx, []*Order{order}, InStateLongerThan("checkout", time.Minute, "expire"))
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("should raise context.Canceled, got %v", err)
+	}
+}
+
+func TestSweepContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(WithActor(context.Background(), "janitor"))
+	defer cancel()
+
+	var (
+		clock             = &manualClock{now: time.Now()}
+		orderStateMachine = getSweepStateMachine(clock)
+		orders            = []*Order{{}, {}}
+		actors            []string
+	)
+	orderStateMachine.BeforeEach(func(ctx context.Context, order *Order, info TransitionInfo) error {
+		if info.Event == "expire" {
+			actors = append(actors, ActorFromContext(ctx))
+			cancel()
+		}
+		return nil
+	})
+	for _, order := range orders {
+		orderStateMachine.Trigger("checkout", order)
+	}
+	clock.now = clock.now.Add(time.Hour)
+
+	report, err := orderStateMachine.Sweep(ctx, orders, InStateLongerThan("checkout", time.Minute, "expire"))
+	if !errors.Is(err, context.Canceled) || report.Matched != 1 || orders[1].State != "checkout" {
+		t.Errorf("the sweep should stop once ctx is done, got %+v, %v", report, err)
+	}
+	if len(actors) != 1 || actors[0] != "janitor" {
+		t.Errorf("events should be triggered with the context of the sweep, got %v", actors)
+	}
+}
@@ -0,0 +1,138 @@
+package transition
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// ErrDuplicateRow is reported by Define for rows repeating a transition of an earlier row
+var ErrDuplicateRow = errors.New("duplicate row")
+
+// Row is a transition of a Table, rows of the same event and destination add to the same transition. Empty From
+// accepts any state
+type Row[T Stater] struct {
+	Event string
+	From  []string
+	To    string
+	// Guard, if any, is registered on the transition, see EventTransition.Guard
+	Guard Guard[T]
+	// Label is the default display label of the event, its "label" metadata, see Event.Meta
+	Label string
+	// Priority positions the event in AllowedActions, its "order" metadata, zero leaves it unset
+	Priority int
+}
+
+// Table is a table-driven definition of transitions, see StateMachine.Define
+type Table[T Stater] []Row[T]
+
+// DefineOption configure Define
+type DefineOption func(*defineConfig)
+
+type defineConfig struct {
+	strict bool
+}
+
+// DefineStrict reject rows using states not declared with State or Initial
+func DefineStrict() DefineOption {
+	return func(config *defineConfig) {
+		config.strict = true
+	}
+}
+
+// Define add the transitions of table, as if defined row by row with Event, To, From and Guard:
+//
+//	sm.Define(transition.Table[*Order]{
+//		{Event: "cancel", From: []string{"draft", "checkout"}, To: "cancelled"},
+//		{Event: "cancel", From: []string{"paid", "processed"}, To: "paid_cancelled"},
+//	})
+//
+// The table is checked first, and nothing is defined when it has problems: rows without event or destination,
+// rows repeating a from state of an earlier row of the same transition, events given conflicting labels or
+// priorities, and with DefineStrict undeclared states. The returned MultiError lists them all, prefixed with the row index
+func (sm *StateMachine[T]) Define(table Table[T], opts ...DefineOption) error {
+	var config defineConfig
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	var (
+		errs       []error
+		seen       = map[[3]string]bool{}
+		labels     = map[string]string{}
+		priorities = map[string]int{}
+	)
+	declared := func(state string) bool {
+		_, ok := sm.states[state]
+		return ok || state == sm.initialState
+	}
+
+	for i, row := range table {
+		rowErr := func(format string, args ...any) {
+			errs = append(errs, fmt.Errorf("row %d: "+format, append([]any{i}, args...)...))
+		}
+
+		if row.Event == "" || row.To == "" {
+			rowErr("event and destination are required")
+			continue
+		}
+
+		froms := row.From
+		if len(froms) == 0 {
+			froms = []string{""}
+		}
+		for _, from := range froms {
+			key := [3]string{row.Event, from, row.To}
+			if seen[key] {
+				if from == "" {
+					rowErr("event %s goes to %s from any state again: %w", row.Event, row.To, ErrDuplicateRow)
+				} else {
+					rowErr("event %s goes to %s from %s again: %w", row.Event, row.To, from, ErrDuplicateRow)
+				}
+			}
+			seen[key] = true
+		}
+
+		if config.strict {
+			if !declared(row.To) {
+				rowErr("event %s goes to state %s: %w", row.Event, row.To, ErrUndeclaredState)
+			}
+			for _, from := range row.From {
+				if !declared(from) {
+					rowErr("event %s goes from state %s: %w", row.Event, from, ErrUndeclaredState)
+				}
+			}
+		}
+
+		if row.Label != "" {
+			if label, ok := labels[row.Event]; ok && label != row.Label {
+				rowErr("event %s is labeled both %q and %q", row.Event, label, row.Label)
+			}
+			labels[row.Event] = row.Label
+		}
+		if row.Priority != 0 {
+			if priority, ok := priorities[row.Event]; ok && priority != row.Priority {
+				rowErr("event %s has both priorities %d and %d", row.Event, priority, row.Priority)
+			}
+			priorities[row.Event] = row.Priority
+		}
+	}
+	if len(errs) > 0 {
+		return newMultiError(errs)
+	}
+
+	for _, row := range table {
+		event := sm.Event(row.Event)
+		transition := event.To(row.To).From(row.From...)
+		if row.Guard != nil {
+			transition.Guard(row.Guard)
+		}
+		if row.Label != "" {
+			event.Meta("label", row.Label)
+		}
+		if row.Priority != 0 {
+			event.Meta("order", strconv.Itoa(row.Priority))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package transition
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestDefine(t *testing.T) {
+	notCancellable := func(ctx context.Context, order *Order) error {
+		if order.Address == "" {
+			return nil
+		}
+		return errors.New("shipping already arranged")
+	}
+
+	fluent := getStateMachine()
+	fluent.Event("cancel").To("cancelled").From("draft", "checkout").Guard(notCancellable)
+	fluent.Event("cancel").To("paid_cancelled").From("paid", "processed")
+	fluent.Event("cancel").Meta("label", "Cancel order").Meta("order", "2")
+	fluent.Event("deliver").To("delivered")
+
+	table := getStateMachine()
+	err := table.Define(Table[*Order]{
+		{Event: "cancel", From: []string{"draft", "checkout"}, To: "cancelled", Guard: notCancellable, Label: "Cancel order", Priority: 2},
+		{Event: "cancel", From: []string{"paid", "processed"}, To: "paid_cancelled"},
+		{Event: "deliver", To: "delivered"},
+	}, DefineStrict())
+	if err != nil {
+		t.Fatalf("no error expected, got %v", err)
+	}
+
+	if fluent.Fingerprint() != table.Fingerprint() {
+		t.Errorf("machines built fluently and from a table should have the same fingerprint")
+	}
+	fluentJSON, _ := json.Marshal(fluent.Describe())
+	tableJSON, _ := json.Marshal(table.Describe())
+	if string(fluentJSON) != string(tableJSON) {
+		t.Errorf("machines built fluently and from a table should have the same description\n%s\n%s", fluentJSON, tableJSON)
+	}
+
+	order := &Order{Address: "somewhere"}
+	if err := table.Trigger("cancel", order); !IsNoMatch(err) {
+		t.Errorf("the guard of the row should be registered, got %v", err)
+	}
+	if actions := table.AllowedActions(context.Background(), &Order{}); actions[0].Event != "cancel" || actions[0].Label != "Cancel order" {
+		t.Errorf("unexpected actions %+v", actions)
+	}
+}
+
+func TestDefineReportsAllProblems(t *testing.T) {
+	orderStateMachine := getStateMachine()
+	err := orderStateMachine.Define(Table[*Order]{
+		{Event: "cancel", From: []string{"draft"}, To: "cancelled", Label: "Cancel"},
+		{Event: "cancel", From: []string{"checkout", "draft"}, To: "cancelled", Label: "Cancel order"},
+		{Event: "refund", From: []string{"paid"}, To: "refunded"},
+		{Event: "archive"},
+		{Event: "reset", To: "draft", Priority: 1},
+		{Event: "reset", To: "draft", Priority: 2},
+	}, DefineStrict())
+
+	var multiErr *MultiError
+	if !errors.As(err, &multiErr) {
+		t.Fatalf("should raise a MultiError, got %v", err)
+	}
+	expected := []string{
+		"row 1: event cancel goes to cancelled from draft again: duplicate row",
+		`row 1: event cancel is labeled both "Cancel" and "Cancel order"`,
+		"row 2: event refund goes to state refunded: undeclared state",
+		"row 3: event and destination are required",
+		"row 5: event reset goes to draft from any state again: duplicate row",
+		"row 5: event reset has both priorities 1 and 2",
+	}
+	errs := multiErr.Errors()
+	if len(errs) != len(expected) {
+		t.Fatalf("expected %d errors, got %v", len(expected), err)
+	}
+	for i, message := range expected {
+		if errs[i].Error() != message {
+			t.Errorf("error %d: expected %q, got %q", i+1, message, errs[i])
+		}
+	}
+
+	if _, ok := orderStateMachine.events["cancel"]; ok {
+		t.Errorf("nothing should be defined when the table has problems")
+	}
+}
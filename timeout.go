@@ -0,0 +1,140 @@
+package transition
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+type stateTimeout struct {
+	after time.Duration
+	event string
+}
+
+// Timeout fire event on values that stayed in the state for d. The timer starts when Trigger
+// lands a value in the state, and is cancelled when Trigger moves the value out of it. Timeouts
+// require a key func and a resolver, see StateMachine.SetKeyFunc and StateMachine.SetResolver
+func (state *State[T]) Timeout(d time.Duration, event string) *State[T] {
+	state.checkMutable("State.Timeout")
+	state.timeouts = append(state.timeouts, stateTimeout{after: d, event: event})
+	return state
+}
+
+// SetKeyFunc define how values are identified, used by scheduled triggers to re-load values with the resolver
+func (sm *StateMachine[T]) SetKeyFunc(fc func(value T) string) *StateMachine[T] {
+	sm.owner.checkMutable("SetKeyFunc")
+	sm.keyFunc = fc
+	return sm
+}
+
+// SetResolver define how values are re-loaded by key when scheduled triggers fire
+func (sm *StateMachine[T]) SetResolver(resolver Resolver[T]) *StateMachine[T] {
+	sm.owner.checkMutable("SetResolver")
+	sm.resolver = resolver
+	return sm
+}
+
+// OnError register a hook called with errors raised in the background, e.g. by scheduled triggers
+func (sm *StateMachine[T]) OnError(fc func(err error)) *StateMachine[T] {
+	sm.owner.checkMutable("OnError")
+	sm.onErrors = append(sm.onErrors, fc)
+	return sm
+}
+
+func (sm *StateMachine[T]) reportError(err error) {
+	for _, onError := range sm.onErrors {
+		onError(err)
+	}
+}
+
+// checkTimeouts ensures timeouts of the destination state can be scheduled
+func (sm *StateMachine[T]) checkTimeouts(to string) error {
+	if state, ok := sm.states[to]; ok && len(state.timeouts) > 0 && sm.keyFunc == nil {
+		return fmt.Errorf("state %s has timeouts but the state machine has no key func", to)
+	}
+	return nil
+}
+
+// rescheduleTimeouts cancels the timeouts of the state the value left, and schedules those of the state it entered
+func (sm *StateMachine[T]) rescheduleTimeouts(value T, from, to string) {
+	if sm.keyFunc == nil {
+		return
+	}
+	key := sm.keyFunc(value)
+
+	sm.mu.Lock()
+	ids := sm.timeouts[timeoutKey{key: key, state: from}]
+	delete(sm.timeouts, timeoutKey{key: key, state: from})
+	sm.mu.Unlock()
+
+	for _, id := range ids {
+		if err := sm.scheduler.Cancel(id); err != nil {
+			sm.reportError(fmt.Errorf("failed to cancel timeout of state %s for %s: %w", from, key, err))
+		}
+	}
+
+	state, ok := sm.states[to]
+	if !ok {
+		return
+	}
+
+	now := sm.clock.Now()
+	for _, timeout := range state.timeouts {
+		trigger := ScheduledTrigger{Key: key, Event: timeout.event, At: now.Add(timeout.after), State: to}
+		id, err := sm.scheduler.Schedule(trigger, sm.fireTimeout)
+		if err != nil {
+			sm.reportError(&ScheduledTriggerError{Trigger: trigger, Err: err})
+			continue
+		}
+
+		sm.mu.Lock()
+		sm.timeouts[timeoutKey{key: key, state: to}] = append(sm.timeouts[timeoutKey{key: key, state: to}], id)
+		sm.mu.Unlock()
+	}
+}
+
+func (sm *StateMachine[T]) fireTimeout(trigger ScheduledTrigger) {
+	sm.mu.Lock()
+	ids := sm.timeouts[timeoutKey{key: trigger.Key, state: trigger.State}]
+	for i, id := range ids {
+		if id == trigger.ID {
+			sm.timeouts[timeoutKey{key: trigger.Key, state: trigger.State}] = append(ids[:i:i], ids[i+1:]...)
+			break
+		}
+	}
+	sm.mu.Unlock()
+
+	sm.fireScheduled(trigger)
+}
+
+// fireScheduled re-load the value of a scheduled trigger and trigger its event
+func (sm *StateMachine[T]) fireScheduled(trigger ScheduledTrigger) {
+	if sm.resolver == nil {
+		sm.reportError(&ScheduledTriggerError{Trigger: trigger, Err: fmt.Errorf("the state machine has no resolver")})
+		return
+	}
+
+	command := TriggerCommand{Event: trigger.Event, Key: trigger.Key, Attempts: 1}
+
+	value, err := sm.resolver.Resolve(trigger.Key)
+	if err != nil {
+		sm.reportError(&ScheduledTriggerError{Trigger: trigger, Err: err})
+		sm.deadLetter(context.Background(), command, err)
+		return
+	}
+
+	if trigger.State != "" && value.GetState() != trigger.State {
+		// the value left the state, e.g. it was changed outside of the state machine
+		return
+	}
+
+	if _, err := sm.Execute(context.Background(), command, value); err != nil {
+		sm.reportError(&ScheduledTriggerError{Trigger: trigger, Err: err})
+		sm.deadLetter(context.Background(), command, err)
+	}
+}
+
+type timeoutKey struct {
+	key   string
+	state string
+}
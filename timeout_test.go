@@ -0,0 +1,109 @@
+package transition_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/daegalus/transition"
+	"github.com/daegalus/transition/memstore"
+	"github.com/daegalus/transition/transitiontest"
+)
+
+type Order struct {
+	ID string
+
+	transition.Transition
+}
+
+// getOrderStore returns a store of orders by ID
+func getOrderStore(orders ...*Order) *memstore.Store[string, *Order] {
+	store := memstore.New[string, *Order]()
+	for _, order := range orders {
+		store.Put(order.ID, order)
+	}
+	return store
+}
+
+func getTimeoutStateMachine(clock *transitiontest.TestClock, orders *memstore.Store[string, *Order]) *transition.StateMachine[*Order] {
+	orderStateMachine := transition.New(&Order{}, transition.WithClock(clock))
+	orderStateMachine.Initial("draft")
+	orderStateMachine.State("checkout").Timeout(30*time.Minute, "expire")
+	orderStateMachine.Event("checkout").To("checkout").From("draft")
+	orderStateMachine.Event("pay").To("paid").From("checkout")
+	orderStateMachine.Event("expire").To("expired").From("checkout")
+
+	orderStateMachine.SetKeyFunc(func(order *Order) string { return order.ID })
+	orderStateMachine.SetResolver(orders)
+	return orderStateMachine
+}
+
+func TestStateTimeout(t *testing.T) {
+	var (
+		clock             = transitiontest.NewTestClock(time.Now())
+		order             = &Order{ID: "1"}
+		orderStateMachine = getTimeoutStateMachine(clock, getOrderStore(order))
+	)
+
+	transitiontest.TriggerAll(t, orderStateMachine, order, "checkout")
+
+	clock.Advance(29 * time.Minute)
+	transitiontest.AssertState(t, order, "checkout")
+
+	clock.Advance(time.Minute)
+	transitiontest.AssertState(t, order, "expired")
+}
+
+func TestStateTimeoutCancelledOnExit(t *testing.T) {
+	var (
+		clock             = transitiontest.NewTestClock(time.Now())
+		scheduler         = transition.NewMemoryScheduler(clock)
+		order             = &Order{ID: "1"}
+		orderStateMachine = transition.New(&Order{}, transition.WithClock(clock), transition.WithScheduler(scheduler))
+	)
+	orderStateMachine.Initial("draft")
+	orderStateMachine.State("checkout").Timeout(30*time.Minute, "expire")
+	orderStateMachine.Event("checkout").To("checkout").From("draft")
+	orderStateMachine.Event("pay").To("paid").From("checkout")
+	orderStateMachine.SetKeyFunc(func(order *Order) string { return order.ID })
+
+	transitiontest.TriggerAll(t, orderStateMachine, order, "checkout")
+	if pending := scheduler.Pending(); len(pending) != 1 || pending[0].Event != "expire" || pending[0].Key != "1" {
+		t.Errorf("timeout should be scheduled when entering checkout, got %+v", pending)
+	}
+
+	transitiontest.TriggerAll(t, orderStateMachine, order, "pay")
+	if pending := scheduler.Pending(); len(pending) != 0 {
+		t.Errorf("timeout should be cancelled when leaving checkout, got %+v", pending)
+	}
+
+	clock.Advance(time.Hour)
+	transitiontest.AssertState(t, order, "paid")
+}
+
+func TestStateTimeoutErrors(t *testing.T) {
+	var (
+		clock             = transitiontest.NewTestClock(time.Now())
+		order             = &Order{ID: "1"}
+		orderStateMachine = getTimeoutStateMachine(clock, getOrderStore())
+		errs              []error
+	)
+	orderStateMachine.OnError(func(err error) {
+		errs = append(errs, err)
+	})
+
+	transitiontest.TriggerAll(t, orderStateMachine, order, "checkout")
+	clock.Advance(time.Hour)
+
+	var scheduledErr *transition.ScheduledTriggerError
+	if len(errs) != 1 || !errors.As(errs[0], &scheduledErr) || scheduledErr.Trigger.Event != "expire" {
+		t.Errorf("resolver error should be reported to OnError, got %v", errs)
+	}
+
+	withoutKey := transition.New(&Order{})
+	withoutKey.State("checkout").Timeout(time.Minute, "expire")
+	withoutKey.Event("checkout").To("checkout")
+	if err := withoutKey.Trigger("checkout", &Order{}); err == nil {
+		t.Errorf("should raise an error when timeouts can't be scheduled")
+	}
+}
@@ -0,0 +1,146 @@
+package transition
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Phase is a step of a trigger
+type Phase string
+
+// Phases of a trigger, in the order they are run
+const (
+	// PhaseVersion checks the version recorded on the value, see StateMachine.OnVersionMismatch
+	PhaseVersion Phase = "version"
+	// PhaseAuthorize checks the authorizers of the state machine and the event accept the value
+	PhaseAuthorize Phase = "authorize"
+	// PhaseMatch finds the transition of the event accepting the value
+	PhaseMatch Phase = "match"
+	// PhasePrepare checks the matched transition can be performed, e.g. debouncing
+	PhasePrepare   Phase = "prepare"
+	PhaseExit      Phase = "exit"
+	PhaseBefore    Phase = "before"
+	PhaseEnter     Phase = "enter"
+	PhaseInvariant Phase = "invariant"
+	PhaseAfter     Phase = "after"
+	// PhaseLog records the state change, see StateMachine.SetStateChangeLog
+	PhaseLog Phase = "log"
+	// PhasePersist saves the value, see StateMachine.SetPersister
+	PhasePersist Phase = "persist"
+)
+
+// Trace records everything a trigger did, see TriggerTraced
+type Trace struct {
+	Event string `json:"event"`
+	From  string `json:"from"`
+	// Actor is the actor of the trigger, see WithActor and StateMachine.SetActorFunc
+	Actor string `json:"actor,omitempty"`
+	// CorrelationID is the correlation ID of the trigger, see WithCorrelationID
+	CorrelationID string `json:"correlation_id,omitempty"`
+	// To is the destination of the matched transition, empty if no transition matched
+	To         string           `json:"to,omitempty"`
+	Candidates []TraceCandidate `json:"candidates"`
+	Steps      []TraceStep      `json:"steps"`
+}
+
+// TraceCandidate is a transition considered while matching the event
+type TraceCandidate struct {
+	To      string   `json:"to"`
+	Froms   []string `json:"froms"`
+	Matched bool     `json:"matched"`
+	// GuardErr is the error of the guard that rejected the transition
+	GuardErr string `json:"guard_error,omitempty"`
+}
+
+// TraceStep is a hook run by a trigger, Name is the hook's owner (state or event) and its registration index
+type TraceStep struct {
+	Phase    Phase
+	Name     string
+	Duration time.Duration
+	Err      error
+}
+
+// MarshalJSON renders the duration and error as strings
+func (step TraceStep) MarshalJSON() ([]byte, error) {
+	var errMessage string
+	if step.Err != nil {
+		errMessage = step.Err.Error()
+	}
+
+	return json.Marshal(struct {
+		Phase    Phase  `json:"phase"`
+		Name     string `json:"name"`
+		Duration string `json:"duration"`
+		Err      string `json:"error,omitempty"`
+	}{step.Phase, step.Name, step.Duration.String(), errMessage})
+}
+
+func (trace *Trace) String() string {
+	var builder strings.Builder
+
+	if trace.To == "" {
+		fmt.Fprintf(&builder, "trigger %s: from %s, no transition matched\n", trace.Event, trace.From)
+	} else {
+		fmt.Fprintf(&builder, "trigger %s: %s -> %s\n", trace.Event, trace.From, trace.To)
+	}
+
+	for _, candidate := range trace.Candidates {
+		result := "rejected"
+		if candidate.Matched {
+			result = "matched"
+		}
+		if candidate.GuardErr != "" {
+			result = "rejected by guard: " + candidate.GuardErr
+		}
+		fmt.Fprintf(&builder, "  candidate %s from [%s]: %s\n", candidate.To, strings.Join(candidate.Froms, ", "), result)
+	}
+
+	for _, step := range trace.Steps {
+		fmt.Fprintf(&builder, "  %s %s %s", step.Phase, step.Name, step.Duration)
+		if step.Err != nil {
+			fmt.Fprintf(&builder, " error: %v", step.Err)
+		}
+		builder.WriteString("\n")
+	}
+	return builder.String()
+}
+
+// TriggerTraced trigger an event like Trigger, recording which transition matched and
+// which hooks ran in which order, how long each took and what each returned
+func (sm *StateMachine[T]) TriggerTraced(name string, value T) (*Trace, error) {
+	trace := &Trace{}
+	_, err := sm.execute(context.Background(), TriggerCommand{Event: name}, value, triggerOptions{trace: trace})
+	return trace, err
+}
+
+// recordCandidates records the event's transitions, in definition order as they are matched, and whether they were chosen
+func recordCandidates[T Stater](trace *Trace, event *Event[T], matched []*EventTransition[T], rejected map[*EventTransition[T]]error) {
+	for _, transition := range event.transitions {
+		candidate := TraceCandidate{To: transition.to, Froms: transition.fromList()}
+		candidate.Matched = len(matched) == 1 && matched[0] == transition
+		if err := rejected[transition]; err != nil {
+			candidate.GuardErr = err.Error()
+		}
+		trace.Candidates = append(trace.Candidates, candidate)
+	}
+}
+
+// hookName names a hook by its owner (state or event) and its registration index
+func hookName(owner string, index int) string {
+	return fmt.Sprintf("%s#%d", owner, index)
+}
+
+// runHook run a hook, recording it into trace when tracing
+func runHook[T Stater](trace *Trace, phase Phase, owner string, index int, hook func(value T) error, value T) error {
+	if trace == nil {
+		return hook(value)
+	}
+
+	start := time.Now()
+	err := hook(value)
+	trace.Steps = append(trace.Steps, TraceStep{Phase: phase, Name: hookName(owner, index), Duration: time.Since(start), Err: err})
+	return err
+}
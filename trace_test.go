@@ -0,0 +1,63 @@
+package transition
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestTriggerTraced(t *testing.T) {
+	var (
+		order             = &Order{}
+		orderStateMachine = getStateMachine()
+	)
+
+	orderStateMachine.State("checkout").Enter(func(order *Order) error {
+		return nil
+	})
+	orderStateMachine.Event("checkout").To("checkout").After(func(order *Order) error {
+		return errors.New("intentional error")
+	})
+
+	trace, err := orderStateMachine.TriggerTraced("checkout", order)
+	if err == nil {
+		t.Errorf("should raise an intentional error")
+	}
+
+	if trace.From != "draft" || trace.To != "checkout" {
+		t.Errorf("trace doesn't record the matched transition")
+	}
+
+	if len(trace.Candidates) != 1 || !trace.Candidates[0].Matched {
+		t.Errorf("trace doesn't record the candidates")
+	}
+
+	if len(trace.Steps) != 2 || trace.Steps[0].Phase != PhaseEnter || trace.Steps[1].Phase != PhaseAfter || trace.Steps[1].Err == nil {
+		t.Errorf("trace doesn't record the hooks in order, got %+v", trace.Steps)
+	}
+
+	if output := trace.String(); !strings.Contains(output, "trigger checkout: draft -> checkout") || !strings.Contains(output, "after checkout#0") {
+		t.Errorf("unexpected trace rendering, got\n%s", output)
+	}
+
+	data, err := json.Marshal(trace)
+	if err != nil {
+		t.Fatalf("should not raise any error when marshal trace, got %v", err)
+	}
+
+	if !strings.Contains(string(data), `"error":"intentional error"`) {
+		t.Errorf("trace JSON should contain hook errors, got %s", data)
+	}
+}
+
+func TestTriggerTracedNoMatch(t *testing.T) {
+	trace, err := getStateMachine().TriggerTraced("pay", &Order{})
+	if err == nil {
+		t.Errorf("should raise an error when no transition matches")
+	}
+
+	if trace.To != "" || len(trace.Candidates) != 1 || trace.Candidates[0].Matched {
+		t.Errorf("trace should record the rejected candidates")
+	}
+}
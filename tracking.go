@@ -0,0 +1,79 @@
+package transition
+
+import (
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// WithDefinitionTracking record where transitions and their from states are defined, reported by Describe, Explain
+// and Validate to tell where merged definitions come from. It's off by default as it walks the stack of every
+// To and From call
+func WithDefinitionTracking() Option {
+	return func(opts *options) {
+		opts.trackDefinitions = true
+	}
+}
+
+// packageDir is the directory of the package, its frames are skipped when looking for definition sites
+var packageDir = func() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Dir(file)
+}()
+
+// definitionSite returns the location of the first caller outside of the package, as directory/file.go:line
+func definitionSite() string {
+	pcs := make([]uintptr, 16)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+	for {
+		frame, more := frames.Next()
+		if filepath.Dir(frame.File) != packageDir || strings.HasSuffix(frame.File, "_test.go") {
+			return filepath.Join(filepath.Base(filepath.Dir(frame.File)), filepath.Base(frame.File)) + ":" + strconv.Itoa(frame.Line)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
+
+// trackTo records where transition was defined, when tracking definitions
+func (transition *EventTransition[T]) trackTo() {
+	if transition.owner.trackDefinitions {
+		transition.toSite = definitionSite()
+	}
+}
+
+// trackFrom records where states were added to the from states of transition, when tracking definitions
+func (transition *EventTransition[T]) trackFrom(states []string) {
+	if !transition.owner.trackDefinitions {
+		return
+	}
+
+	site := definitionSite()
+	if transition.fromSites == nil {
+		transition.fromSites = map[string][]string{}
+	}
+	for _, state := range states {
+		sites := transition.fromSites[state]
+		if len(sites) == 0 || sites[len(sites)-1] != site {
+			transition.fromSites[state] = append(clip(sites), site)
+		}
+	}
+}
+
+// fromAddedAt returns " (added at <sites>)" for a from state of transition having definition sites, or ""
+func (transition *EventTransition[T]) fromAddedAt(state string) string {
+	if sites := transition.fromSites[state]; len(sites) > 0 {
+		return " (added at " + strings.Join(sites, ", ") + ")"
+	}
+	return ""
+}
+
+// definedAt returns " (defined at <site>)" for a known definition site, or ""
+func definedAt(site string) string {
+	if site == "" {
+		return ""
+	}
+	return " (defined at " + site + ")"
+}
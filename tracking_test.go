@@ -0,0 +1,65 @@
+package transition
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestDefinitionTracking(t *testing.T) {
+	orderStateMachine := New(&Order{}, WithDefinitionTracking())
+	orderStateMachine.Initial("draft")
+	orderStateMachine.State("checkout")
+	orderStateMachine.State("processed")
+	orderStateMachine.State("cancelled")
+
+	_, file, line, _ := runtime.Caller(0)
+	orderStateMachine.Event("cancel").To("cancelled").From("draft", "checkout")
+	orderStateMachine.Event("cancel").To("cancelled").From("processed", "checkout")
+	orderStateMachine.Event("cancel").To("refunded").From("processed")
+	orderStateMachine.Event("cancel").To("cancelled").From("shipped")
+
+	site := func(offset int) string {
+		return filepath.Base(filepath.Dir(file)) + "/tracking_test.go:" + strconv.Itoa(line+offset)
+	}
+
+	description := orderStateMachine.Describe().Events[0].Transitions[0]
+	expected := map[string][]string{
+		"draft":     {site(1)},
+		"checkout":  {site(1), site(2)},
+		"processed": {site(2)},
+		"shipped":   {site(4)},
+	}
+	if !reflect.DeepEqual(description.FromSites, expected) || description.ToSite != site(1) {
+		t.Errorf("unexpected sites %s %v", description.ToSite, description.FromSites)
+	}
+
+	order := &Order{}
+	order.State = "processed"
+	explanation := orderStateMachine.Explain("cancel", order)
+	if sites := explanation.Transitions[0].FromSites; !reflect.DeepEqual(sites, []string{site(2)}) {
+		t.Errorf("explain should tell where processed was added, got %v", sites)
+	}
+
+	err := orderStateMachine.Validate()
+	for _, message := range []string{
+		"event cancel goes from state shipped (added at " + site(4) + "): undeclared state",
+		"event cancel goes to state refunded (defined at " + site(3) + "): undeclared state",
+		"event cancel goes to both cancelled (added at " + site(2) + ") and refunded (added at " + site(3) + ") from state processed: ambiguous transition",
+	} {
+		if err == nil || !strings.Contains(err.Error(), message) {
+			t.Errorf("expected %q in %v", message, err)
+		}
+	}
+
+	if sites := getStateMachine().Describe().Events[0].Transitions[0]; sites.ToSite != "" || sites.FromSites != nil {
+		t.Errorf("definitions should not be tracked by default, got %v", sites)
+	}
+	if err := orderStateMachine.Validate(); !errors.Is(err, ErrUndeclaredState) {
+		t.Errorf("expected ErrUndeclaredState, got %v", err)
+	}
+}
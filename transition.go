@@ -1,12 +1,27 @@
 package transition
 
 import (
+	"context"
+	"errors"
+	"expvar"
 	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // Transition is a struct, embed it in your struct to enable state machine for the struct
 type Transition struct {
 	State string
+	// StateChangedAt is set by the state machine every time the state changes
+	StateChangedAt time.Time
+	// PreviousState is the state before the last change, used by Undo
+	PreviousState string
+	// Version and Fingerprint identify the definition of the state machine that last changed the state, see StateMachine.Version
+	Version     string
+	Fingerprint string
 }
 
 // SetState set state to Stater, just set, won't save it into database
@@ -19,6 +34,36 @@ func (transition Transition) GetState() string {
 	return transition.State
 }
 
+// GetStateChangedAt get the time the state last changed
+func (transition Transition) GetStateChangedAt() time.Time {
+	return transition.StateChangedAt
+}
+
+// SetStateChangedAt set the time the state last changed
+func (transition *Transition) SetStateChangedAt(at time.Time) {
+	transition.StateChangedAt = at
+}
+
+// GetPreviousState get the state before the last change
+func (transition Transition) GetPreviousState() string {
+	return transition.PreviousState
+}
+
+// SetPreviousState set the state before the last change
+func (transition *Transition) SetPreviousState(name string) {
+	transition.PreviousState = name
+}
+
+// GetVersion get the version and fingerprint of the state machine that last changed the state
+func (transition Transition) GetVersion() (version, fingerprint string) {
+	return transition.Version, transition.Fingerprint
+}
+
+// SetVersion set the version and fingerprint of the state machine that last changed the state
+func (transition *Transition) SetVersion(version, fingerprint string) {
+	transition.Version, transition.Fingerprint = version, fingerprint
+}
+
 // Stater is a interface including methods `GetState`, `SetState`
 type Stater interface {
 	SetState(name string)
@@ -26,115 +71,650 @@ type Stater interface {
 }
 
 // New initialize a new StateMachine that hold states, events definitions
-func New[T Stater](_ T) *StateMachine[T] {
+func New[T Stater](_ T, opts ...Option) *StateMachine[T] {
+	config := options{clock: realClock{}}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	if config.scheduler == nil {
+		config.scheduler = NewMemoryScheduler(config.clock)
+	}
+
 	return &StateMachine[T]{
-		states: map[string]*State[T]{},
-		events: map[string]*Event[T]{},
+		states:    map[string]*State[T]{},
+		events:    map[string]*Event[T]{},
+		owner:     &owner{ownerGuard: ownerGuard{mutableAfterStart: config.mutableAfterStart}, trackDefinitions: config.trackDefinitions},
+		clock:     config.clock,
+		scheduler: config.scheduler,
+		timeouts:  map[timeoutKey][]string{},
+		debounced: map[debounceKey]time.Time{},
+
+		defaultActor:    "system",
+		defaultLocale:   "en",
+		maxChainDepth:   config.maxChainDepth,
+		maxHooks:        config.maxHooks,
+		strictHookState: config.strictHookState,
+		generateIDs:     config.generateCorrelationIDs,
+		limiter:         newLimiter(config.maxConcurrentTriggers, config.concurrencyPolicy),
 	}
 }
 
 // StateMachine a struct that hold states, events definitions
 type StateMachine[T Stater] struct {
 	initialState string
+	initials     *initialFor[T]
 	states       map[string]*State[T]
 	events       map[string]*Event[T]
+	// owner and the shared flags implement the copy on write of states and events, see Clone
+	owner        *owner
+	statesShared bool
+	eventsShared bool
+
+	clock             Clock
+	scheduler         Scheduler
+	resolver          Resolver[T]
+	keyFunc           func(value T) string
+	onErrors          []func(err error)
+	onWarnings        []func(warning Warning)
+	befores           []MachineHook[T]
+	afters            []MachineHook[T]
+	onDeadLetters     []func(ctx context.Context, command TriggerCommand, err error)
+	stateChangeLog    StateChangeLog
+	persister         Persister[T]
+	authorize         Authorizer[T]
+	actorFunc         func(ctx context.Context) string
+	correlationIDFunc func(ctx context.Context) string
+	generateIDs       bool
+	rolesFunc         func(ctx context.Context) []string
+	defaultActor      string
+	defaultLocale     string
+	actionLess        func(a, b Action) bool
+	version           string
+	onVersionMismatch func(value T, recorded, current string) error
+	migrations        []*Migration[T]
+	onStaleCommand    func(ctx context.Context, command TriggerCommand) (TriggerCommand, error)
+	frozen            func(value T) bool
+	blocks            []func(value T) bool
+
+	idempotencyStore IdempotencyStore
+	idempotency      idempotencyConfig
+	stats            atomic.Pointer[statsCollector]
+	expvarStats      atomic.Pointer[expvar.Map]
+	singleFlight     bool
+	maxChainDepth    int
+	maxHooks         int
+	detectMutations  bool
+	strictHookState  bool
+	limiter          *limiter
+	executions       executions
+	skipped          []SkippedRegistration
+	readOnly         atomic.Pointer[cachedSnapshot[T]]
+	fingerprint      atomic.Pointer[cachedFingerprint]
+
+	mu                sync.Mutex
+	timeouts          map[timeoutKey][]string
+	debounced         map[debounceKey]time.Time
+	nextDebounceSweep int
+	deadLetters       []DeadLetter
+	flights           map[flightKey]*flight
 }
 
 // Initial define the initial state
 func (sm *StateMachine[T]) Initial(name string) *StateMachine[T] {
-	sm.initialState = name
+	sm.owner.checkMutable("Initial")
+	sm.initialState = sm.owner.normalizeState(name)
 	return sm
 }
 
 // State define a state
 func (sm *StateMachine[T]) State(name string) *State[T] {
-	if _, ok := sm.states[name]; ok {
-		return sm.states[name]
+	sm.owner.checkMutable("State")
+	name = sm.owner.normalizeState(name)
+	if state, ok := sm.ownedState(name); ok {
+		return state
+	}
+	if sm.statesShared {
+		sm.states, sm.statesShared = cloneMap(sm.states), false
 	}
-	state := &State[T]{Name: name}
+	state := &State[T]{Name: name, owner: sm.owner, epoch: sm.owner.clones.Load()}
 	sm.states[name] = state
 	return state
 }
 
 // Event define an event
 func (sm *StateMachine[T]) Event(name string) *Event[T] {
-	if _, ok := sm.events[name]; ok {
-		return sm.events[name]
+	sm.owner.checkMutable("Event")
+	if event, ok := sm.ownedEvent(name); ok {
+		return event
 	}
-	event := &Event[T]{Name: name}
+	if sm.eventsShared {
+		sm.events, sm.eventsShared = cloneMap(sm.events), false
+	}
+	event := &Event[T]{Name: name, owner: sm.owner, epoch: sm.owner.clones.Load()}
 	sm.events[name] = event
 	return event
 }
 
 // Trigger trigger an event
 func (sm *StateMachine[T]) Trigger(name string, value T) error {
-	stateWas := value.GetState()
+	_, err := sm.execute(context.Background(), TriggerCommand{Event: name}, value, triggerOptions{})
+	return err
+}
 
-	if stateWas == "" {
-		stateWas = sm.initialState
-		value.SetState(sm.initialState)
+// TriggerContext trigger an event like Trigger, ctx is passed to authorizers and guards, e.g. to carry the actor
+func (sm *StateMachine[T]) TriggerContext(ctx context.Context, name string, value T) error {
+	_, err := sm.execute(ctx, TriggerCommand{Event: name}, value, triggerOptions{})
+	return err
+}
+
+// triggerOptions alter how a single trigger is performed
+type triggerOptions struct {
+	// skipHooks only moves the value to the new state
+	skipHooks bool
+	// trace records every step of the trigger when set
+	trace *Trace
+	// payload is passed to the payload hooks of the event
+	payload any
+	// inFlight is set once the trigger went through single flight, see StateMachine.SingleFlight
+	inFlight bool
+	// invocation is the trigger being performed, it tells the chain of triggers it belongs to, see WithMaxChainDepth
+	invocation *invocation
+	// warnings collects the warnings of the trigger when set, see Warning
+	warnings *[]Warning
+	// execution reports the progress of the trigger, see StateMachine.InFlight
+	execution *execution
+	// commands collects the commands emitted by the trigger when set, see EventTransition.AfterEmit
+	commands *[]Command
+	// dequeued is set for commands a queue worker dequeued, they run while draining as Shutdown waits for the worker
+	dequeued bool
+}
+
+func (sm *StateMachine[T]) trigger(ctx context.Context, name string, value T, opts triggerOptions) (err error) {
+	// execute arms it too, triggers of sub-machines only go through trigger
+	sm.owner.start()
+	if sm.coalesced(value, opts) {
+		return sm.coalesce(ctx, flightKey{key: sm.keyFunc(value), event: name}, value, func() error {
+			opts.inFlight = true
+			return sm.trigger(ctx, name, value, opts)
+		})
 	}
 
-	if event := sm.events[name]; event != nil {
-		var matchedTransitions []*EventTransition[T]
-		for _, transition := range event.transitions {
-			var validFrom = len(transition.froms) == 0
-			if len(transition.froms) > 0 {
-				for _, from := range transition.froms {
-					if from == stateWas {
-						validFrom = true
-					}
-				}
+	// the error may be shared with coalesced triggers once returned, it's only modified here
+	defer func() {
+		if err == nil {
+			return
+		}
+		var transitionErr *TransitionError
+		if errors.As(err, &transitionErr) && transitionErr.CorrelationID == "" {
+			transitionErr.CorrelationID = CorrelationIDFromContext(ctx)
+		}
+	}()
+
+	if !isNil(value) {
+		if ctx, opts.invocation, err = sm.enterChain(ctx, name, value); err != nil {
+			return &TransitionError{Event: name, From: value.GetState(), Phase: PhasePrepare, Err: err}
+		}
+	}
+
+	// triggers caused by hooks on the value being triggered still run while draining
+	nested := opts.dequeued || opts.invocation.nested()
+	if sm.Draining() && !nested {
+		transitionErr := &TransitionError{Event: name, Phase: PhasePrepare, Err: ErrShuttingDown}
+		if !isNil(value) {
+			transitionErr.From = value.GetState()
+		}
+		return transitionErr
+	}
+
+	release, err := sm.acquire(ctx, opts.invocation.nested())
+	if err != nil {
+		transitionErr := &TransitionError{Event: name, Phase: PhasePrepare, Err: err}
+		if !isNil(value) {
+			transitionErr.From = value.GetState()
+		}
+		return transitionErr
+	}
+	defer release()
+
+	if !isNil(value) {
+		opts.execution, err = sm.executions.start(name, sm.currentState(value), sm.clock.Now(), nested)
+		if err != nil {
+			return &TransitionError{Event: name, From: value.GetState(), Phase: PhasePrepare, Err: err}
+		}
+		defer opts.execution.finish()
+	}
+
+	collector := sm.stats.Load()
+	if collector == nil {
+		return sm.perform(ctx, name, value, opts)
+	}
+
+	var from string
+	if !isNil(value) {
+		from = sm.currentState(value)
+	}
+	start := time.Now()
+	err = sm.perform(ctx, name, value, opts)
+	sm.recordStats(collector, name, from, value, err, start)
+	sm.recordExpvar(name, err)
+	return err
+}
+
+// perform trigger an event, see trigger
+func (sm *StateMachine[T]) perform(ctx context.Context, name string, value T, opts triggerOptions) (err error) {
+	if cache, cacheKey := guardCacheFromContext(ctx, value); cache != nil {
+		defer func() {
+			if err == nil {
+				cache.forget(cacheKey)
 			}
+		}()
+	}
+
+	// pending holds the changes made to value, they're reverted if any phase fails
+	var pending mutations
+	defer func() {
+		if err != nil {
+			pending.revert()
+		}
+	}()
+
+	if err := checkTriggerArgs(name, value); err != nil {
+		return &TransitionError{Event: name, Phase: PhaseMatch, Err: err}
+	}
+
+	if sm.isFrozen(name, value) {
+		return &TransitionError{Event: name, From: value.GetState(), Phase: PhaseMatch, Err: ErrValueFrozen}
+	}
+
+	// events of the sub-machine of the state of value are triggered on its child value, see SubMachine
+	event, sub := sm.events[name], sm.subMachineHandling(sm.currentState(value), name)
+
+	// denied triggers run nothing, not even the version hooks
+	if event != nil || sub != nil {
+		if err := sm.checkAuthorization(ctx, name, event, value); err != nil {
+			return &TransitionError{Event: name, From: sm.currentState(value), Phase: PhaseAuthorize, Err: err}
+		}
+	}
 
-			if validFrom {
-				matchedTransitions = append(matchedTransitions, transition)
+	if err := sm.checkVersion(value); err != nil {
+		return &TransitionError{Event: name, From: value.GetState(), Phase: PhaseVersion, Err: err}
+	}
+
+	if sm.detectMutations {
+		if err := checkMutation(value); err != nil {
+			sm.reportError(&TransitionError{Event: name, From: value.GetState(), Phase: PhasePrepare, Err: err})
+		}
+	}
+
+	stateWas := sm.owner.normalizeState(value.GetState())
+
+	// values without state are in the initial state, they keep it even if the trigger fails
+	if stateWas == "" {
+		initial, err := sm.initialStateOf(value)
+		if err != nil {
+			return &TransitionError{Event: name, Phase: PhaseMatch, Err: err}
+		}
+		stateWas = initial
+		value.SetState(initial)
+		recordMachineState(value, initial)
+		sm.expectState(opts.invocation, initial)
+	}
+
+	trace := opts.trace
+	if trace != nil {
+		trace.Event, trace.From = name, stateWas
+		trace.Actor, trace.CorrelationID = sm.actor(ctx), CorrelationIDFromContext(ctx)
+	}
+
+	if event == nil && sub == nil {
+		return &TransitionError{Event: name, From: stateWas, Phase: PhaseMatch, Err: ErrUnknownEvent}
+	}
+
+	if err := sm.blocked(name, stateWas, value); err != nil {
+		return &TransitionError{Event: name, From: stateWas, Phase: PhaseMatch, Err: err}
+	}
+
+	if sub != nil {
+		return sub.trigger(ctx, name, value, &pending)
+	}
+
+	matchedTransitions, rejected := sm.match(ctx, event, stateWas, value)
+	if trace != nil {
+		recordCandidates(trace, event, matchedTransitions, rejected)
+	}
+
+	switch {
+	case len(matchedTransitions) > 1:
+		return &TransitionError{Event: name, From: stateWas, Phase: PhaseMatch, Err: ErrAmbiguousTransition}
+	case len(matchedTransitions) == 0:
+		matchErr := ErrNoMatchingTransition
+		if guardErr := firstRejection(event, rejected); guardErr != nil {
+			matchErr = fmt.Errorf("%w: %w", ErrNoMatchingTransition, guardErr)
+		}
+		return &TransitionError{Event: name, From: stateWas, Phase: PhaseMatch, AllowedFrom: event.allowedFrom(), Err: matchErr}
+	}
+
+	transition := matchedTransitions[0]
+	to, err := sm.destination(transition, value, opts.payload)
+	if err != nil {
+		return &TransitionError{Event: name, From: stateWas, Phase: PhaseMatch, Err: err}
+	}
+	if trace != nil {
+		trace.To = to
+	}
+
+	fail := func(phase Phase, owner string, index int, err error) error {
+		transitionErr := &TransitionError{Event: name, From: stateWas, To: to, Phase: phase, Err: err}
+		if owner != "" {
+			transitionErr.Hook = hookName(owner, index)
+		}
+		return transitionErr
+	}
+
+	if opts.skipHooks {
+		sm.changeState(value, to, &pending)
+		sm.recordStateChange(ctx, value, StateChange{Event: name, From: stateWas, To: to}, &pending)
+		pending.apply(sm.expectState(opts.invocation, value.GetState()))
+		return nil
+	}
+
+	// interrupted returns the error of ctx once it's done, or the error of an exhausted hook budget, it's
+	// checked before each hook, which is then reported by InFlight. Rolling back doesn't depend on ctx, so it's
+	// always performed
+	interrupted := func(phase Phase, owner string, index int) error {
+		opts.execution.running(phase, owner, index, to)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return fail(phase, "", 0, ctxErr)
+		}
+		if budgetErr := sm.spendHook(opts.invocation); budgetErr != nil {
+			return fail(phase, "", 0, budgetErr)
+		}
+		return nil
+	}
+
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return fail(PhasePrepare, "", 0, ctxErr)
+	}
+	if err := sm.checkTimeouts(to); err != nil {
+		return fail(PhasePrepare, "", 0, err)
+	}
+
+	if event.debounce > 0 {
+		release, reserveErr := sm.reserveDebounce(event, value)
+		if reserveErr != nil {
+			return fail(PhasePrepare, "", 0, reserveErr)
+		}
+		defer func() {
+			if err != nil {
+				release()
 			}
+		}()
+	}
+
+	// run runs a hook, then checks it didn't set the state of value, see WithStrictHookState
+	run := func(phase Phase, owner string, index int, hook func(value T) error) error {
+		if err := runHook(trace, phase, owner, index, hook, value); err != nil {
+			return err
 		}
+		return sm.checkHookState(opts, value, owner, index)
+	}
 
-		if len(matchedTransitions) == 1 {
-			transition := matchedTransitions[0]
+	// State: exit
+	if state, ok := sm.states[stateWas]; ok {
+		for i, exit := range state.exits {
+			if err := interrupted(PhaseExit, stateWas, i); err != nil {
+				return err
+			}
+			if err := run(PhaseExit, stateWas, i, withContext(ctx, state.exitRefs[i], exit)); err != nil && !sm.tolerate(opts, state.exitRefs, stateWas, i, err) {
+				return fail(PhaseExit, stateWas, i, err)
+			}
+		}
+	}
 
-			// State: exit
-			if state, ok := sm.states[stateWas]; ok {
-				for _, exit := range state.exits {
-					if err := exit(value); err != nil {
-						return err
-					}
-				}
+	// Transition: before, those of the state machine first
+	for i, before := range sm.befores {
+		if err := interrupted(PhaseBefore, machineHookOwner, i); err != nil {
+			return err
+		}
+		info := sm.transitionInfo(ctx, name, stateWas, to)
+		if err := run(PhaseBefore, machineHookOwner, i, bindMachineHook(ctx, before, info)); err != nil {
+			return fail(PhaseBefore, machineHookOwner, i, err)
+		}
+	}
+	for i, before := range transition.befores {
+		if err := interrupted(PhaseBefore, name, i); err != nil {
+			return err
+		}
+		if err := run(PhaseBefore, name, i, withContext(ctx, transition.beforeRefs[i], before)); err != nil && !sm.tolerate(opts, transition.beforeRefs, name, i, err) {
+			return fail(PhaseBefore, name, i, err)
+		}
+	}
+	for i, before := range event.payloadBefores {
+		index := len(transition.befores) + i
+		if err := interrupted(PhaseBefore, name, index); err != nil {
+			return err
+		}
+		if err := run(PhaseBefore, name, index, bindPayload(before, opts.payload)); err != nil {
+			return fail(PhaseBefore, name, index, err)
+		}
+	}
+	if len(transition.pendingBefores) > 0 {
+		pending := &PendingTransition{event: name, from: stateWas, to: to, targets: sm.redirectTargets(event)}
+		for i, before := range transition.pendingBefores {
+			index := len(transition.befores) + len(event.payloadBefores) + i
+			if err := interrupted(PhaseBefore, name, index); err != nil {
+				return err
+			}
+			if err := run(PhaseBefore, name, index, bindPending(before, pending)); err != nil {
+				return fail(PhaseBefore, name, index, err)
 			}
+		}
 
-			// Transition: before
-			for _, before := range transition.befores {
-				if err := before(value); err != nil {
-					return err
-				}
+		if pending.to != to {
+			to = pending.to
+			if trace != nil {
+				trace.To = to
+			}
+			if err := sm.checkTimeouts(to); err != nil {
+				return fail(PhasePrepare, "", 0, err)
 			}
+		}
+	}
 
-			value.SetState(transition.to)
+	sm.changeState(value, to, &pending)
+	change, logged := sm.recordStateChange(ctx, value, StateChange{Event: name, From: stateWas, To: to}, &pending)
+	pending.apply(sm.expectState(opts.invocation, value.GetState()))
 
-			// State: enter
-			if state, ok := sm.states[transition.to]; ok {
-				for _, enter := range state.enters {
-					if err := enter(value); err != nil {
-						value.SetState(stateWas)
-						return err
-					}
-				}
+	// State: enter
+	if state, ok := sm.states[to]; ok {
+		for i, enter := range state.enters {
+			if err := interrupted(PhaseEnter, to, i); err != nil {
+				return err
+			}
+			if hook, ok := state.enterRefs[i].progress.(*progressHook[T]); ok {
+				enter = bindProgress(ctx, hook.fc, opts.execution)
+			} else {
+				enter = withContext(ctx, state.enterRefs[i], enter)
 			}
+			if err := run(PhaseEnter, to, i, enter); err != nil && !sm.tolerate(opts, state.enterRefs, to, i, err) {
+				return fail(PhaseEnter, to, i, err)
+			}
+		}
 
-			// Transition: after
-			for _, after := range transition.afters {
-				if err := after(value); err != nil {
-					value.SetState(stateWas)
-					return err
-				}
+		// State: invariants
+		if len(state.invariants) > 0 {
+			if err := interrupted(PhaseInvariant, to, 0); err != nil {
+				return err
 			}
+			if err := run(PhaseInvariant, to, 0, state.checkInvariants); err != nil {
+				return fail(PhaseInvariant, to, 0, err)
+			}
+		}
+	}
 
-			return nil
+	// Transition: after
+	for i, after := range transition.afters {
+		if err := interrupted(PhaseAfter, name, i); err != nil {
+			return err
+		}
+		if err := run(PhaseAfter, name, i, withContext(ctx, transition.afterRefs[i], after)); err != nil && !sm.tolerate(opts, transition.afterRefs, name, i, err) {
+			return fail(PhaseAfter, name, i, err)
 		}
 	}
-	return fmt.Errorf("failed to perform event %s from state %s", name, stateWas)
+	for i, after := range event.payloadAfters {
+		index := len(transition.afters) + i
+		if err := interrupted(PhaseAfter, name, index); err != nil {
+			return err
+		}
+		if err := run(PhaseAfter, name, index, bindPayload(after, opts.payload)); err != nil {
+			return fail(PhaseAfter, name, index, err)
+		}
+	}
+	for i, notifier := range transition.notifiers {
+		index := len(transition.afters) + len(event.payloadAfters) + i
+		info := sm.transitionInfo(ctx, name, stateWas, to)
+		if err := interrupted(PhaseAfter, name, index); err != nil {
+			return err
+		}
+		if err := run(PhaseAfter, name, index, sm.bindNotify(ctx, notifier, info)); err != nil {
+			return fail(PhaseAfter, name, index, err)
+		}
+	}
+	var emitted []Command
+	for i, emitter := range transition.emitters {
+		index := len(transition.afters) + len(event.payloadAfters) + len(transition.notifiers) + i
+		info := sm.transitionInfo(ctx, name, stateWas, to)
+		if err := interrupted(PhaseAfter, name, index); err != nil {
+			return err
+		}
+		if err := run(PhaseAfter, name, index, bindEmit(emitter, info, &emitted)); err != nil {
+			return fail(PhaseAfter, name, index, err)
+		}
+	}
+
+	for i, after := range sm.afters {
+		info := sm.transitionInfo(ctx, name, stateWas, to)
+		if err := interrupted(PhaseAfter, machineHookOwner, i); err != nil {
+			return err
+		}
+		if err := run(PhaseAfter, machineHookOwner, i, bindMachineHook(ctx, after, info)); err != nil {
+			return fail(PhaseAfter, machineHookOwner, i, err)
+		}
+	}
+
+	if logged {
+		if err := sm.logStateChange(ctx, change); err != nil {
+			return fail(PhaseLog, "", 0, err)
+		}
+	}
+	if sm.persister != nil {
+		if err := sm.persister.Persist(ctx, value); err != nil {
+			return fail(PhasePersist, "", 0, err)
+		}
+	}
+
+	sm.rescheduleTimeouts(value, stateWas, to)
+	if opts.commands != nil {
+		*opts.commands = append(*opts.commands, emitted...)
+	}
+	return nil
+}
+
+// checkTriggerArgs ensures an event can be looked up and matched for value
+func checkTriggerArgs[T Stater](name string, value T) error {
+	if name == "" {
+		return ErrEmptyEventName
+	}
+	if isNil(value) {
+		return ErrNilValue
+	}
+	return nil
+}
+
+// isNil reports whether value is nil, including typed nil pointers
+func isNil(value any) bool {
+	if value == nil {
+		return true
+	}
+
+	switch v := reflect.ValueOf(value); v.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Func, reflect.Interface, reflect.Chan:
+		return v.IsNil()
+	}
+	return false
+}
+
+// TriggerAll trigger an event on every value, continuing after failures. Values the event succeeded on keep
+// their new state, the returned MultiError holds the error of every failed value, prefixed with its index
+func (sm *StateMachine[T]) TriggerAll(name string, values ...T) error {
+	var errs []error
+	for i, value := range values {
+		if err := sm.Trigger(name, value); err != nil {
+			errs = append(errs, fmt.Errorf("value %d: %w", i, err))
+		}
+	}
+	return newMultiError(errs)
+}
+
+// Can check if the event could be triggered for value from its current state, no hooks are run
+func (sm *StateMachine[T]) Can(name string, value T) bool {
+	return sm.CanContext(context.Background(), name, value)
+}
+
+// CanContext check if the event could be triggered like Can, ctx is passed to guards, e.g. to carry a GuardCache
+func (sm *StateMachine[T]) CanContext(ctx context.Context, name string, value T) bool {
+	if checkTriggerArgs(name, value) != nil || sm.isFrozen(name, value) {
+		return false
+	}
+
+	state := sm.currentState(value)
+	if sm.blocked(name, state, value) != nil {
+		return false
+	}
+
+	if event := sm.events[name]; event != nil {
+		matched, _ := sm.match(ctx, event, state, value)
+		return len(matched) == 1
+	}
+	return false
+}
+
+// CheckInvariants check the invariants of the value's current state, useful to validate already persisted values
+func (sm *StateMachine[T]) CheckInvariants(value T) error {
+	if isNil(value) {
+		return ErrNilValue
+	}
+
+	if state, ok := sm.states[sm.currentState(value)]; ok {
+		return state.checkInvariants(value)
+	}
+	return nil
+}
+
+// AvailableEvents returns the sorted names of the events that could be triggered for value from its current state,
+// no hooks are run
+func (sm *StateMachine[T]) AvailableEvents(value T) []string {
+	if isNil(value) {
+		return nil
+	}
+
+	state := sm.currentState(value)
+	return sm.availableEvents(value, state)
+}
+
+// availableEvents returns the sorted names of events that have exactly one transition accepting value from state
+func (sm *StateMachine[T]) availableEvents(value T, state string) []string {
+	var names []string
+	for name, event := range sm.events {
+		if sm.isFrozen(name, value) || sm.blocked(name, state, value) != nil {
+			continue
+		}
+		if matched, _ := sm.match(context.Background(), event, state, value); len(matched) == 1 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
 }
 
 // State contains State information, including enter, exit hooks
@@ -142,64 +722,245 @@ type State[T Stater] struct {
 	Name   string
 	enters []func(value T) error
 	exits  []func(value T) error
+	// enterRefs and exitRefs name the enters and exits, see HookManifest
+	enterRefs  []HookRef
+	exitRefs   []HookRef
+	invariants []func(value T) error
+	// materializers set the fields values need in the state, see Materialize
+	materializers []func(value T) error
+	blocks        []func(value T) bool
+	labels        map[string]string
+	metadata      map[string]string
+	timeouts      []stateTimeout
+	sla           time.Duration
+	final         bool
+	// sub is the sub-machine embedded in the state, see SubMachine
+	sub *subMachine[T]
+	// aliasOf are the states the state was split into, see SplitState
+	aliasOf []string
+	owner   *owner
+	// epoch is the number of clones of owner when the state was obtained, see owner.checkEpoch
+	epoch uint64
 }
 
 // Enter register an enter hook for State
 func (state *State[T]) Enter(fc func(value T) error) *State[T] {
+	state.checkMutable("State.Enter")
 	state.enters = append(state.enters, fc)
+	state.enterRefs = append(state.enterRefs, anonymousHook(2))
 	return state
 }
 
 // Exit register an exit hook for State
 func (state *State[T]) Exit(fc func(value T) error) *State[T] {
+	state.checkMutable("State.Exit")
 	state.exits = append(state.exits, fc)
+	state.exitRefs = append(state.exitRefs, anonymousHook(2))
 	return state
 }
 
+// Invariant register an invariant for State, it's checked every time a value lands in the state
+func (state *State[T]) Invariant(fc func(value T) error) *State[T] {
+	state.checkMutable("State.Invariant")
+	state.invariants = append(state.invariants, fc)
+	return state
+}
+
+// Final mark State as terminal, Validate doesn't warn about final states without outgoing transitions
+func (state *State[T]) Final() *State[T] {
+	state.checkMutable("State.Final")
+	state.final = true
+	return state
+}
+
+func (state *State[T]) checkInvariants(value T) error {
+	for _, invariant := range state.invariants {
+		if err := invariant(value); err != nil {
+			return &InvariantViolation{State: state.Name, Err: err}
+		}
+	}
+	return nil
+}
+
 // Event contains Event information, including transition hooks
 type Event[T Stater] struct {
-	Name        string
-	transitions map[string]*EventTransition[T]
+	Name string
+	// transitions are in definition order, transitionIndex maps their destination to their position
+	transitions     []*EventTransition[T]
+	transitionIndex map[string]int
+	debounce        time.Duration
+	authorize       Authorizer[T]
+	roles           []string
+	// group is the name of the EventGroup of the event, if any
+	group        string
+	ignoreFrozen bool
+	bypassBlocks bool
+	metadata     map[string]string
+	labels       map[string]string
+
+	payloadBefores []func(value T, payload any) error
+	payloadAfters  []func(value T, payload any) error
+
+	owner *owner
+	// epoch is the number of clones of owner when the event was obtained, see owner.checkEpoch
+	epoch uint64
+}
+
+// allowedFrom returns the sorted union of from states and globs of the event's transitions, it's empty when a
+// transition accepts any state
+func (event *Event[T]) allowedFrom() []string {
+	var froms [][]string
+	for _, transition := range event.transitions {
+		froms = append(froms, transition.fromList())
+	}
+	return unionFroms(froms)
+}
+
+func unionFroms(froms [][]string) []string {
+	set := map[string]bool{}
+	for _, states := range froms {
+		if len(states) == 0 {
+			return nil
+		}
+		for _, state := range states {
+			set[state] = true
+		}
+	}
+
+	var union []string
+	for state := range set {
+		union = append(union, state)
+	}
+	sort.Strings(union)
+	return union
+}
+
+// matchTransitions returns the event's transitions that accept state as a from state. Transitions accepting it
+// exactly, or accepting any state, beat those accepting it through a glob, see EventTransition.FromGlob
+func (event *Event[T]) matchTransitions(state string) []*EventTransition[T] {
+	var matched, globbed []*EventTransition[T]
+	for _, transition := range event.transitions {
+		var validFrom = len(transition.froms) == 0 && len(transition.fromGlobs) == 0 || transition.acceptsFrom(state)
+
+		switch {
+		case validFrom:
+			matched = append(matched, transition)
+		case len(matched) == 0 && transition.matchesGlob(state):
+			globbed = append(globbed, transition)
+		}
+	}
+	if len(matched) > 0 {
+		return matched
+	}
+	return globbed
 }
 
 // To define EventTransition of go to a state
 func (event *Event[T]) To(name string) *EventTransition[T] {
-	if event.transitions == nil {
-		event.transitions = map[string]*EventTransition[T]{}
+	event.checkMutable("Event.To")
+	name = event.owner.normalizeState(name)
+	if index, ok := event.transitionIndex[name]; ok {
+		return event.transitions[index]
 	}
-	if _, ok := event.transitions[name]; ok {
-		return event.transitions[name]
+	if event.transitionIndex == nil {
+		event.transitionIndex = map[string]int{}
 	}
 
-	transition := &EventTransition[T]{to: name}
-	event.transitions[name] = transition
+	transition := &EventTransition[T]{to: name, owner: event.owner, epoch: event.epoch}
+	transition.trackTo()
+	event.transitionIndex[name] = len(event.transitions)
+	event.transitions = append(event.transitions, transition)
 	return transition
 }
 
+// ToFunc define EventTransition of go to the state returned by fc, it must be a declared state.
+// An event has at most one such transition, calling ToFunc again replaces fc
+func (event *Event[T]) ToFunc(fc func(value T) (string, error)) *EventTransition[T] {
+	return event.toFunc(func(value T, _ any) (string, error) {
+		return fc(value)
+	})
+}
+
+func (event *Event[T]) toFunc(fc func(value T, payload any) (string, error)) *EventTransition[T] {
+	transition := event.To("")
+	transition.toFunc = fc
+	return transition
+}
+
+// destination returns the state transition goes to, calling its ToFunc if any
+func (sm *StateMachine[T]) destination(transition *EventTransition[T], value T, payload any) (string, error) {
+	if transition.toFunc == nil {
+		return transition.to, nil
+	}
+
+	to, err := transition.toFunc(value, payload)
+	if err != nil {
+		return "", err
+	}
+	if _, ok := sm.states[to]; !ok && to != sm.initialState {
+		return "", fmt.Errorf("destination %q: %w", to, ErrUndeclaredState)
+	}
+	return to, nil
+}
+
 // EventTransition hold event's to/froms states, also including befores, afters hooks
 type EventTransition[T Stater] struct {
-	to      string
-	froms   []string
-	befores []func(value T) error
-	afters  []func(value T) error
+	to        string
+	toFunc    func(value T, payload any) (string, error)
+	froms     []string
+	fromGlobs []string
+	befores   []func(value T) error
+	afters    []func(value T) error
+	notifiers []NotifyHook[T]
+	emitters  []EmitHook[T]
+	// beforeRefs and afterRefs name the befores and afters, see HookManifest
+	beforeRefs []HookRef
+	afterRefs  []HookRef
+
+	pendingBefores []func(value T, pending *PendingTransition) error
+	guards         []Guard[T]
+	weight         *float64
+	owner          *owner
+	// epoch is the number of clones of owner when the transition was obtained, see owner.checkEpoch
+	epoch uint64
+	// guardNames name the guards registered with GuardNamed, the others have an empty name
+	guardNames []string
+	// labels are the display labels of the transition by locale, see EventTransition.Label
+	labels map[string]string
+	// toSite and fromSites are where the transition and its from states were defined, see WithDefinitionTracking
+	toSite    string
+	fromSites map[string][]string
+	// fromSet holds froms for lookups, duplicateFroms are the states passed more than once to a From call
+	fromSet        map[string]struct{}
+	duplicateFroms []string
 }
 
 // From used to define from states
 func (transition *EventTransition[T]) From(states ...string) *EventTransition[T] {
-	transition.froms = append(transition.froms, states...)
-	transition.froms = removeDuplicateValues(transition.froms)
+	transition.checkMutable("EventTransition.From")
+	normalized := make([]string, len(states))
+	for i, state := range states {
+		normalized[i] = transition.owner.normalizeState(state)
+	}
+	transition.trackFrom(normalized)
+	transition.recordDuplicateFroms(normalized)
+	transition.addFroms(normalized...)
 	return transition
 }
 
 // Before register before hooks
 func (transition *EventTransition[T]) Before(fc func(value T) error) *EventTransition[T] {
+	transition.checkMutable("EventTransition.Before")
 	transition.befores = append(transition.befores, fc)
+	transition.beforeRefs = append(transition.beforeRefs, anonymousHook(2))
 	return transition
 }
 
 // After register after hooks
 func (transition *EventTransition[T]) After(fc func(value T) error) *EventTransition[T] {
+	transition.checkMutable("EventTransition.After")
 	transition.afters = append(transition.afters, fc)
+	transition.afterRefs = append(transition.afterRefs, anonymousHook(2))
 	return transition
 }
 
@@ -1,8 +1,10 @@
 package transition
 
 import (
+	"context"
 	"errors"
 	"testing"
+	"time"
 )
 
 type Order struct {
@@ -12,8 +14,8 @@ type Order struct {
 	Transition
 }
 
-func getStateMachine() *StateMachine[*Order] {
-	var orderStateMachine = New(&Order{})
+func getStateMachine(opts ...Option) *StateMachine[*Order] {
+	var orderStateMachine = New(&Order{}, opts...)
 
 	orderStateMachine.Initial("draft")
 	orderStateMachine.State("checkout")
@@ -223,3 +225,129 @@ func TestEventOnAfterCallbackError(t *testing.T) {
 		t.Errorf("state transitioned on Enter callback error")
 	}
 }
+
+func TestStateInvariant(t *testing.T) {
+	var (
+		order             = &Order{}
+		orderStateMachine = getStateMachine()
+		checked           []string
+	)
+
+	orderStateMachine.State("checkout").Invariant(func(order *Order) error {
+		checked = append(checked, "checkout")
+		return nil
+	})
+	orderStateMachine.State("paid").Invariant(func(order *Order) error {
+		checked = append(checked, "paid")
+		if order.Address == "" {
+			return errors.New("address is required")
+		}
+		return nil
+	})
+
+	if err := orderStateMachine.Trigger("checkout", order); err != nil {
+		t.Errorf("should not raise any error when trigger event checkout")
+	}
+
+	err := orderStateMachine.Trigger("pay", order)
+	var violation *InvariantViolation
+	if !errors.As(err, &violation) || violation.State != "paid" {
+		t.Errorf("should raise an invariant violation, got %v", err)
+	}
+
+	if order.State != "checkout" {
+		t.Errorf("state transitioned on invariant violation")
+	}
+
+	if len(checked) != 2 || checked[0] != "checkout" || checked[1] != "paid" {
+		t.Errorf("invariants should only run for the landed state, got %v", checked)
+	}
+
+	order.Address = "an address"
+	if err := orderStateMachine.Trigger("pay", order); err != nil {
+		t.Errorf("should not raise any error when invariant holds")
+	}
+}
+
+func TestCheckInvariants(t *testing.T) {
+	orderStateMachine := getStateMachine()
+	orderStateMachine.State("paid").Invariant(func(order *Order) error {
+		return errors.New("intentional error")
+	})
+
+	if err := orderStateMachine.CheckInvariants(&Order{Transition: Transition{State: "checkout"}}); err != nil {
+		t.Errorf("should not raise any error for a state without invariants")
+	}
+
+	if err := orderStateMachine.CheckInvariants(&Order{Transition: Transition{State: "paid"}}); err == nil {
+		t.Errorf("should raise an invariant violation")
+	}
+}
+
+func TestCan(t *testing.T) {
+	orderStateMachine := getStateMachine()
+	order := &Order{}
+
+	if !orderStateMachine.Can("checkout", order) {
+		t.Errorf("should be able to checkout from draft")
+	}
+
+	if orderStateMachine.Can("pay", order) || orderStateMachine.Can("unknown", order) {
+		t.Errorf("should not be able to pay from draft")
+	}
+
+	if order.State != "" {
+		t.Errorf("Can should not change state")
+	}
+}
+
+func TestTriggerCanceled(t *testing.T) {
+	orderStateMachine := getStateMachine()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var ran []string
+	orderStateMachine.State("draft").Exit(func(order *Order) error {
+		ran = append(ran, "exit")
+		cancel()
+		return nil
+	})
+	orderStateMachine.Event("checkout").To("checkout").From("draft").Before(func(order *Order) error {
+		ran = append(ran, "before")
+		return nil
+	})
+
+	order := &Order{}
+	err := orderStateMachine.TriggerContext(ctx, "checkout", order)
+	var transitionErr *TransitionError
+	if !errors.As(err, &transitionErr) || !IsCanceled(err) || transitionErr.Phase != PhaseBefore || transitionErr.Hook != "" {
+		t.Errorf("a canceled context should abort before the next phase, got %v", err)
+	}
+	if Classify(err) != ClassRetryable {
+		t.Errorf("cancellations should be retryable")
+	}
+	if order.State != "draft" || len(ran) != 1 {
+		t.Errorf("no hook should run once canceled, the state should be draft, got %v after %v", order.State, ran)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	ran = nil
+	orderStateMachine = getStateMachine()
+	orderStateMachine.State("checkout").Enter(func(order *Order) error {
+		ran = append(ran, "enter")
+		cancel()
+		return nil
+	}).Enter(func(order *Order) error {
+		ran = append(ran, "enter again")
+		return nil
+	})
+
+	order = &Order{}
+	err = orderStateMachine.TriggerContext(ctx, "checkout", order)
+	if !errors.As(err, &transitionErr) || !errors.Is(err, context.Canceled) || transitionErr.Phase != PhaseEnter {
+		t.Errorf("a canceled context should abort before the next hook, got %v", err)
+	}
+	if order.State != "draft" || len(ran) != 1 {
+		t.Errorf("the value should be rolled back when canceled while entering, got %v after %v", order.State, ran)
+	}
+}
@@ -0,0 +1,24 @@
+// Package transitionhttp serves transition state machines over HTTP
+package transitionhttp
+
+import (
+	"net/http"
+
+	"github.com/daegalus/transition"
+)
+
+// HealthHandler returns a handler running the health check of sm, see StateMachine.HealthCheck. It responds
+// 200 when healthy, otherwise 503 with the failures as body, e.g. to serve a readiness probe:
+//
+//	http.Handle("/healthz", transitionhttp.HealthHandler(sm, expectedFingerprint))
+func HealthHandler[T transition.Stater](sm *transition.StateMachine[T], expectedFingerprint string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		if err := sm.HealthCheckContext(r.Context(), expectedFingerprint); err != nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(err.Error() + "\n"))
+			return
+		}
+		_, _ = w.Write([]byte("ok\n"))
+	}
+}
@@ -0,0 +1,41 @@
+package transitionhttp_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/daegalus/transition"
+	"github.com/daegalus/transition/transitionhttp"
+)
+
+type Order struct {
+	ID string
+	transition.Transition
+}
+
+func getOrderStateMachine() *transition.StateMachine[*Order] {
+	orderStateMachine := transition.New(&Order{})
+	orderStateMachine.Initial("draft")
+	orderStateMachine.State("paid").Final()
+	orderStateMachine.Event("pay").To("paid").From("draft")
+	return orderStateMachine
+}
+
+func TestHealthHandler(t *testing.T) {
+	orderStateMachine := getOrderStateMachine()
+	fingerprint := orderStateMachine.Fingerprint()
+
+	recorder := httptest.NewRecorder()
+	transitionhttp.HealthHandler(orderStateMachine, fingerprint)(recorder, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if recorder.Code != http.StatusOK || recorder.Body.String() != "ok\n" {
+		t.Errorf("healthy machine should respond 200, got %d %q", recorder.Code, recorder.Body)
+	}
+
+	recorder = httptest.NewRecorder()
+	transitionhttp.HealthHandler(orderStateMachine, "stale")(recorder, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if recorder.Code != http.StatusServiceUnavailable || !strings.Contains(recorder.Body.String(), "expected stale: definition changed") {
+		t.Errorf("unexpected fingerprint should respond 503, got %d %q", recorder.Code, recorder.Body)
+	}
+}
@@ -0,0 +1,87 @@
+package transitiontest
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/daegalus/transition"
+)
+
+// TestClock is a transition.Clock only moving forward when advanced, use it with transition.WithClock
+type TestClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*testTimer
+}
+
+type testTimer struct {
+	clock *TestClock
+	at    time.Time
+	f     func()
+}
+
+// NewTestClock initialize a TestClock set to now
+func NewTestClock(now time.Time) *TestClock {
+	return &TestClock{now: now}
+}
+
+// Now returns the clock's current time
+func (clock *TestClock) Now() time.Time {
+	clock.mu.Lock()
+	defer clock.mu.Unlock()
+	return clock.now
+}
+
+// AfterFunc calls f when the clock is advanced past d from now
+func (clock *TestClock) AfterFunc(d time.Duration, f func()) transition.Timer {
+	clock.mu.Lock()
+	defer clock.mu.Unlock()
+
+	timer := &testTimer{clock: clock, at: clock.now.Add(d), f: f}
+	clock.timers = append(clock.timers, timer)
+	return timer
+}
+
+// Advance move the clock forward by d, synchronously calling the functions of the timers
+// that became due, in order of due time
+func (clock *TestClock) Advance(d time.Duration) {
+	clock.mu.Lock()
+	target := clock.now.Add(d)
+	clock.mu.Unlock()
+
+	for {
+		clock.mu.Lock()
+		sort.SliceStable(clock.timers, func(i, j int) bool {
+			return clock.timers[i].at.Before(clock.timers[j].at)
+		})
+		if len(clock.timers) == 0 || clock.timers[0].at.After(target) {
+			clock.now = target
+			clock.mu.Unlock()
+			return
+		}
+
+		timer := clock.timers[0]
+		clock.timers = clock.timers[1:]
+		if timer.at.After(clock.now) {
+			clock.now = timer.at
+		}
+		clock.mu.Unlock()
+
+		timer.f()
+	}
+}
+
+// Stop removes the timer from the clock
+func (timer *testTimer) Stop() bool {
+	timer.clock.mu.Lock()
+	defer timer.clock.mu.Unlock()
+
+	for i, pending := range timer.clock.timers {
+		if pending == timer {
+			timer.clock.timers = append(timer.clock.timers[:i], timer.clock.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,135 @@
+package transitiontest
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/daegalus/transition"
+)
+
+// FakeCall is a call received by a FakeTriggerer
+type FakeCall struct {
+	// Method is Trigger, TriggerContext, Can or AvailableEvents
+	Method string
+	// Event is empty for AvailableEvents
+	Event string
+	// State is the state of the value when called
+	State string
+}
+
+// FakeTriggerer is a transition.Triggerer recording calls and returning scripted responses, to test code depending
+// on a state machine without building one. By default triggers succeed without changing the value, Can is false
+// and no event is available
+type FakeTriggerer[T transition.Stater] struct {
+	mu        sync.Mutex
+	calls     []FakeCall
+	errs      map[string]error
+	moves     map[string]string
+	can       map[string]bool
+	available []string
+}
+
+// NewFakeTriggerer initialize a FakeTriggerer
+func NewFakeTriggerer[T transition.Stater]() *FakeTriggerer[T] {
+	return &FakeTriggerer[T]{errs: map[string]error{}, moves: map[string]string{}, can: map[string]bool{}}
+}
+
+// OnTrigger make triggering event return err
+func (fake *FakeTriggerer[T]) OnTrigger(event string, err error) *FakeTriggerer[T] {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	fake.errs[event] = err
+	return fake
+}
+
+// MoveTo make successfully triggering event set the state of the value to state
+func (fake *FakeTriggerer[T]) MoveTo(event string, state string) *FakeTriggerer[T] {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	fake.moves[event] = state
+	return fake
+}
+
+// Allow make Can return true for events, and AvailableEvents return them
+func (fake *FakeTriggerer[T]) Allow(events ...string) *FakeTriggerer[T] {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	for _, event := range events {
+		if !fake.can[event] {
+			fake.can[event] = true
+			fake.available = append(fake.available, event)
+		}
+	}
+	sort.Strings(fake.available)
+	return fake
+}
+
+// Trigger record the call and returns the scripted error of event
+func (fake *FakeTriggerer[T]) Trigger(event string, value T) error {
+	return fake.trigger("Trigger", event, value)
+}
+
+// TriggerContext record the call and returns the scripted error of event
+func (fake *FakeTriggerer[T]) TriggerContext(_ context.Context, event string, value T) error {
+	return fake.trigger("TriggerContext", event, value)
+}
+
+func (fake *FakeTriggerer[T]) trigger(method string, event string, value T) error {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	fake.calls = append(fake.calls, FakeCall{Method: method, Event: event, State: value.GetState()})
+	if err := fake.errs[event]; err != nil {
+		return err
+	}
+	if state, ok := fake.moves[event]; ok {
+		value.SetState(state)
+	}
+	return nil
+}
+
+// Can record the call and reports whether event was allowed
+func (fake *FakeTriggerer[T]) Can(event string, value T) bool {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	fake.calls = append(fake.calls, FakeCall{Method: "Can", Event: event, State: value.GetState()})
+	return fake.can[event]
+}
+
+// AvailableEvents record the call and returns the allowed events, sorted
+func (fake *FakeTriggerer[T]) AvailableEvents(value T) []string {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	fake.calls = append(fake.calls, FakeCall{Method: "AvailableEvents", State: value.GetState()})
+	return append([]string(nil), fake.available...)
+}
+
+// Calls returns the recorded calls, in order
+func (fake *FakeTriggerer[T]) Calls() []FakeCall {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	return append([]FakeCall(nil), fake.calls...)
+}
+
+// Triggered returns how many times event was triggered, with Trigger or TriggerContext
+func (fake *FakeTriggerer[T]) Triggered(event string) int {
+	var count int
+	for _, call := range fake.Calls() {
+		if (call.Method == "Trigger" || call.Method == "TriggerContext") && call.Event == event {
+			count++
+		}
+	}
+	return count
+}
+
+// AssertTriggered fail the test if event wasn't triggered exactly times
+func (fake *FakeTriggerer[T]) AssertTriggered(t testing.TB, event string, times int) {
+	t.Helper()
+	if count := fake.Triggered(event); count != times {
+		t.Errorf("expected event %s to be triggered %d times, got %d", event, times, count)
+	}
+}
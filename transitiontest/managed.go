@@ -0,0 +1,62 @@
+package transitiontest
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/daegalus/transition"
+)
+
+// Managed holds the fields of a value managed by state machines, taken by Capture. Fields the value doesn't track
+// are left empty
+type Managed struct {
+	State          string
+	StateChangedAt time.Time
+	PreviousState  string
+	Version        string
+	Fingerprint    string
+	EntryCounts    map[string]int
+	FirstEnteredAt map[string]time.Time
+	MachineState   string
+}
+
+// Capture returns a copy of the fields of value managed by state machines, e.g. before triggering an event
+func Capture(value transition.Stater) Managed {
+	managed := Managed{State: value.GetState()}
+	if tracker, ok := value.(transition.TimeTracker); ok {
+		managed.StateChangedAt = tracker.GetStateChangedAt()
+	}
+	if tracker, ok := value.(transition.PreviousStateTracker); ok {
+		managed.PreviousState = tracker.GetPreviousState()
+	}
+	if tracker, ok := value.(transition.VersionTracker); ok {
+		managed.Version, managed.Fingerprint = tracker.GetVersion()
+	}
+	if counter, ok := value.(interface{ GetEntryCounts() map[string]int }); ok {
+		managed.EntryCounts = counter.GetEntryCounts()
+	}
+	if tracker, ok := value.(interface{ GetFirstEnteredAt() map[string]time.Time }); ok {
+		managed.FirstEnteredAt = tracker.GetFirstEnteredAt()
+	}
+	if strict, ok := value.(interface{ GetMachineState() string }); ok {
+		managed.MachineState = strict.GetMachineState()
+	}
+	return managed
+}
+
+// AssertUnchanged fail the test if the managed fields captured before and after differ, e.g. to check a failed
+// trigger left nothing behind:
+//
+//	before := transitiontest.Capture(order)
+//	err := sm.Trigger("pay", order)
+//	transitiontest.AssertUnchanged(t, before, transitiontest.Capture(order))
+func AssertUnchanged(t testing.TB, before, after Managed) {
+	t.Helper()
+	beforeValue, afterValue := reflect.ValueOf(before), reflect.ValueOf(after)
+	for i := 0; i < beforeValue.NumField(); i++ {
+		if !reflect.DeepEqual(beforeValue.Field(i).Interface(), afterValue.Field(i).Interface()) {
+			t.Errorf("%s changed from %v to %v", beforeValue.Type().Field(i).Name, beforeValue.Field(i), afterValue.Field(i))
+		}
+	}
+}
@@ -0,0 +1,96 @@
+// Package transitiontest provides helpers to test code using transition state machines
+package transitiontest
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/daegalus/transition"
+)
+
+// AssertState fail the test if value is not in state
+func AssertState(t testing.TB, value transition.Stater, state string) {
+	t.Helper()
+	if got := value.GetState(); got != state {
+		t.Errorf("expected state %s, got %s", state, got)
+	}
+}
+
+// AssertCanTrigger fail the test if event can't be triggered for value
+func AssertCanTrigger[T transition.Stater](t testing.TB, sm *transition.StateMachine[T], event string, value T) {
+	t.Helper()
+	if !sm.Can(event, value) {
+		t.Errorf("expected event %s to be allowed from state %s", event, value.GetState())
+	}
+}
+
+// AssertCannotTrigger fail the test if event can be triggered for value
+func AssertCannotTrigger[T transition.Stater](t testing.TB, sm *transition.StateMachine[T], event string, value T) {
+	t.Helper()
+	if sm.Can(event, value) {
+		t.Errorf("expected event %s not to be allowed from state %s", event, value.GetState())
+	}
+}
+
+// TriggerAll trigger events in order, stopping the test at the first failing event
+func TriggerAll[T transition.Stater](t testing.TB, sm *transition.StateMachine[T], value T, events ...string) {
+	t.Helper()
+	for index, event := range events {
+		from := value.GetState()
+		if err := sm.Trigger(event, value); err != nil {
+			t.Fatalf("step %d: failed to trigger event %s from state %s: %v", index, event, from, err)
+		}
+	}
+}
+
+// Recorder capture hook invocations in order, to assert hooks sequencing
+type Recorder[T transition.Stater] struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+// Hook returns a hook recording name when invoked
+func (recorder *Recorder[T]) Hook(name string) func(value T) error {
+	return func(value T) error {
+		recorder.mu.Lock()
+		defer recorder.mu.Unlock()
+		recorder.calls = append(recorder.calls, name)
+		return nil
+	}
+}
+
+// Calls returns the names of the invoked hooks, in order
+func (recorder *Recorder[T]) Calls() []string {
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	return append([]string(nil), recorder.calls...)
+}
+
+// Reset forget the recorded invocations
+func (recorder *Recorder[T]) Reset() {
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	recorder.calls = nil
+}
+
+// AssertCalls fail the test if the recorded invocations are not exactly names, in order
+func (recorder *Recorder[T]) AssertCalls(t testing.TB, names ...string) {
+	t.Helper()
+	calls := recorder.Calls()
+	if !equalStrings(calls, names) {
+		t.Errorf("expected hook calls [%s], got [%s]", strings.Join(names, ", "), strings.Join(calls, ", "))
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,213 @@
+package transitiontest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/daegalus/transition"
+)
+
+type Order struct {
+	transition.Transition
+}
+
+// fakeTB records failures instead of failing the running test
+type fakeTB struct {
+	testing.TB
+	failed bool
+}
+
+func (fake *fakeTB) Helper()                                   {}
+func (fake *fakeTB) Errorf(format string, args ...interface{}) { fake.failed = true }
+func (fake *fakeTB) Fatalf(format string, args ...interface{}) { fake.failed = true }
+func (fake *fakeTB) Failed() bool                              { return fake.failed }
+
+func getStateMachine(recorder *Recorder[*Order]) *transition.StateMachine[*Order] {
+	orderStateMachine := transition.New(&Order{})
+	orderStateMachine.Initial("draft")
+	orderStateMachine.State("checkout").Enter(recorder.Hook("enter checkout")).Exit(recorder.Hook("exit checkout"))
+	orderStateMachine.State("paid").Enter(recorder.Hook("enter paid"))
+	orderStateMachine.Event("checkout").To("checkout").From("draft").Before(recorder.Hook("before checkout"))
+	orderStateMachine.Event("pay").To("paid").From("checkout").After(recorder.Hook("after pay"))
+	return orderStateMachine
+}
+
+func TestHelpers(t *testing.T) {
+	var (
+		recorder          = &Recorder[*Order]{}
+		orderStateMachine = getStateMachine(recorder)
+		order             = &Order{}
+	)
+
+	AssertCanTrigger(t, orderStateMachine, "checkout", order)
+	AssertCannotTrigger(t, orderStateMachine, "pay", order)
+
+	TriggerAll(t, orderStateMachine, order, "checkout", "pay")
+	AssertState(t, order, "paid")
+
+	recorder.AssertCalls(t, "before checkout", "enter checkout", "exit checkout", "enter paid", "after pay")
+
+	recorder.Reset()
+	if len(recorder.Calls()) != 0 {
+		t.Errorf("recorder should be empty after reset")
+	}
+}
+
+func TestHelpersReportFailures(t *testing.T) {
+	var (
+		recorder          = &Recorder[*Order]{}
+		orderStateMachine = getStateMachine(recorder)
+		order             = &Order{}
+		fake              = &fakeTB{}
+	)
+
+	AssertState(fake, order, "paid")
+	AssertCanTrigger(fake, orderStateMachine, "pay", order)
+	if !fake.Failed() {
+		t.Errorf("helpers should fail the test")
+	}
+
+	recorder.Hook("a")(order)
+	fake = &fakeTB{}
+	recorder.AssertCalls(fake, "b")
+	if !fake.Failed() {
+		t.Errorf("AssertCalls should fail on unexpected calls")
+	}
+}
+
+// payOrder is code under test depending only on the runtime surface of a state machine
+func payOrder(machine transition.Triggerer[*Order], order *Order) error {
+	if !machine.Can("pay", order) {
+		return nil
+	}
+	return machine.Trigger("pay", order)
+}
+
+func TestFakeTriggerer(t *testing.T) {
+	var _ transition.Triggerer[*Order] = getStateMachine(&Recorder[*Order]{})
+
+	fake := NewFakeTriggerer[*Order]().Allow("pay").MoveTo("pay", "paid")
+	order := &Order{}
+	order.SetState("checkout")
+
+	if err := payOrder(fake, order); err != nil {
+		t.Fatalf("no error expected, got %v", err)
+	}
+	AssertState(t, order, "paid")
+	fake.AssertTriggered(t, "pay", 1)
+	fake.AssertTriggered(t, "checkout", 0)
+
+	calls := fake.Calls()
+	if len(calls) != 2 || calls[0] != (FakeCall{Method: "Can", Event: "pay", State: "checkout"}) || calls[1] != (FakeCall{Method: "Trigger", Event: "pay", State: "checkout"}) {
+		t.Errorf("unexpected calls %+v", calls)
+	}
+	if events := fake.AvailableEvents(order); len(events) != 1 || events[0] != "pay" {
+		t.Errorf("expected available events [pay], got %v", events)
+	}
+
+	errRefused := errors.New("refused")
+	fake.OnTrigger("pay", errRefused)
+	order.SetState("checkout")
+	if err := payOrder(fake, order); !errors.Is(err, errRefused) {
+		t.Errorf("expected scripted error, got %v", err)
+	}
+	AssertState(t, order, "checkout")
+
+	tb := &fakeTB{}
+	fake.AssertTriggered(tb, "pay", 1)
+	if !tb.Failed() {
+		t.Errorf("AssertTriggered should fail when the count differs")
+	}
+}
+
+type ManagedOrder struct {
+	transition.Transition
+	transition.CountedTransition
+}
+
+type StrictOrder struct {
+	transition.Transition
+	transition.StrictTransition
+}
+
+func TestFailedTriggersLeaveValuesUnchanged(t *testing.T) {
+	errFailed := errors.New("failed")
+	fail := func(*ManagedOrder) error { return errFailed }
+
+	phases := map[transition.Phase]func(sm *transition.StateMachine[*ManagedOrder]){
+		transition.PhaseVersion: func(sm *transition.StateMachine[*ManagedOrder]) {
+			sm.Version("v2").OnVersionMismatch(func(*ManagedOrder, string, string) error { return errFailed })
+		},
+		transition.PhaseAuthorize: func(sm *transition.StateMachine[*ManagedOrder]) {
+			sm.Event("pay").Require(func(context.Context, string, *ManagedOrder) error { return errFailed })
+		},
+		transition.PhaseMatch: func(sm *transition.StateMachine[*ManagedOrder]) {
+			sm.Event("pay").To("paid").Guard(func(context.Context, *ManagedOrder) error { return errFailed })
+		},
+		transition.PhaseExit:   func(sm *transition.StateMachine[*ManagedOrder]) { sm.State("checkout").Exit(fail) },
+		transition.PhaseBefore: func(sm *transition.StateMachine[*ManagedOrder]) { sm.Event("pay").To("paid").Before(fail) },
+		transition.PhaseEnter:  func(sm *transition.StateMachine[*ManagedOrder]) { sm.State("paid").Enter(fail) },
+		transition.PhaseInvariant: func(sm *transition.StateMachine[*ManagedOrder]) {
+			sm.State("paid").Invariant(fail)
+		},
+		transition.PhaseAfter: func(sm *transition.StateMachine[*ManagedOrder]) { sm.Event("pay").To("paid").After(fail) },
+	}
+
+	for phase, setup := range phases {
+		t.Run(string(phase), func(t *testing.T) {
+			// the failure is set up after a first trigger, so the value has fields to revert
+			orderStateMachine := transition.New(&ManagedOrder{}, transition.WithMutableAfterStart()).Version("v1")
+			orderStateMachine.Initial("draft")
+			orderStateMachine.State("checkout")
+			orderStateMachine.State("paid")
+			orderStateMachine.Event("checkout").To("checkout").From("draft")
+			orderStateMachine.Event("pay").To("paid").From("checkout")
+
+			order := &ManagedOrder{}
+			TriggerAll(t, orderStateMachine, order, "checkout")
+			setup(orderStateMachine)
+
+			before := Capture(order)
+			err := orderStateMachine.Trigger("pay", order)
+
+			var transitionErr *transition.TransitionError
+			if !errors.As(err, &transitionErr) || transitionErr.Phase != phase {
+				t.Fatalf("expected a failure in phase %s, got %v", phase, err)
+			}
+			AssertUnchanged(t, before, Capture(order))
+		})
+	}
+}
+
+func TestCanceledTriggerLeavesValueUnchanged(t *testing.T) {
+	orderStateMachine := transition.New(&StrictOrder{})
+	orderStateMachine.Initial("draft")
+	orderStateMachine.State("checkout")
+	orderStateMachine.Event("checkout").To("checkout").From("draft")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	orderStateMachine.State("checkout").Enter(func(*StrictOrder) error {
+		cancel()
+		return nil
+	}).Enter(func(*StrictOrder) error { return nil })
+
+	order := &StrictOrder{}
+	order.SetState("draft")
+	before := Capture(order)
+	if err := orderStateMachine.TriggerContext(ctx, "checkout", order); !transition.IsCanceled(err) {
+		t.Fatalf("expected the trigger to be canceled, got %v", err)
+	}
+	AssertUnchanged(t, before, Capture(order))
+}
+
+func TestAssertUnchangedReportsChanges(t *testing.T) {
+	before := Managed{State: "draft", EntryCounts: map[string]int{"draft": 1}}
+	after := Managed{State: "paid", EntryCounts: map[string]int{"draft": 1, "paid": 1}}
+
+	tb := &fakeTB{}
+	AssertUnchanged(tb, before, after)
+	if !tb.Failed() {
+		t.Errorf("AssertUnchanged should fail when managed fields changed")
+	}
+}
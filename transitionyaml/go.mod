@@ -0,0 +1,10 @@
+module github.com/daegalus/transition/transitionyaml
+
+go 1.20
+
+require (
+	github.com/daegalus/transition v0.0.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+replace github.com/daegalus/transition => ../
@@ -0,0 +1,103 @@
+// Package transitionyaml reads state machine definitions and hook manifests written in YAML, and writes manifests
+// in YAML, so definitions and their wiring can live in configuration. It's a separate module so transition doesn't
+// depend on a YAML library. Documents follow the JSON format of transition, with the same field names
+package transitionyaml
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/daegalus/transition"
+	"gopkg.in/yaml.v3"
+)
+
+// LoadDefinition read a YAML definition, e.g.
+//
+//	initial: draft
+//	states:
+//	  - name: checkout
+//	    enter: [reserve_stock]
+//	events:
+//	  - name: checkout
+//	    transitions:
+//	      - to: checkout
+//	        from: [draft]
+//
+// and compile it into a state machine, looking up the referenced hooks in hooks, see transition.LoadDefinition
+func LoadDefinition[T transition.Stater](r io.Reader, hooks transition.HookRegistry[T], opts ...transition.Option) (*transition.StateMachine[T], error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load definition: %w", err)
+	}
+	data, err = toJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load definition: %w", err)
+	}
+	return transition.LoadDefinition(bytes.NewReader(data), hooks, opts...)
+}
+
+// MarshalManifest write manifest in YAML, see transition.StateMachine.HookManifest
+func MarshalManifest(manifest transition.Manifest) ([]byte, error) {
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	data, err = fromJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	return data, nil
+}
+
+// UnmarshalManifest read a YAML manifest, e.g. to attach hooks with transition.ApplyManifest
+func UnmarshalManifest(data []byte) (transition.Manifest, error) {
+	var manifest transition.Manifest
+	data, err := toJSON(data)
+	if err != nil {
+		return manifest, fmt.Errorf("failed to unmarshal manifest: %w", err)
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return manifest, fmt.Errorf("failed to unmarshal manifest: %w", err)
+	}
+	return manifest, nil
+}
+
+// toJSON converts a YAML document to JSON, so it's decoded with the JSON field names of transition
+func toJSON(data []byte) ([]byte, error) {
+	var document any
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+	return json.Marshal(document)
+}
+
+// fromJSON converts a JSON document to YAML, keeping the order of its fields
+func fromJSON(data []byte) ([]byte, error) {
+	// JSON is YAML in flow style, it's written back in block style
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+	blockStyle(&document)
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&document); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// blockStyle clears the styles of node and its children, so they're written in block style with plain scalars
+func blockStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
@@ -0,0 +1,135 @@
+package transitionyaml_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/daegalus/transition"
+	"github.com/daegalus/transition/transitionyaml"
+)
+
+type Order struct {
+	ID string
+	transition.Transition
+}
+
+const definition = `
+initial: draft
+states:
+  - name: checkout
+    enter: [reserve_stock]
+  - name: paid
+events:
+  - name: checkout
+    transitions:
+      - to: checkout
+        from: [draft]
+  - name: pay
+    transitions:
+      - to: paid
+        from: [checkout]
+`
+
+func getRegistry(calls *[]string) transition.HookRegistry[*Order] {
+	hook := func(name string) func(*Order) error {
+		return func(*Order) error {
+			*calls = append(*calls, name)
+			return nil
+		}
+	}
+	return transition.HookRegistry[*Order]{
+		"reserve_stock": hook("reserve_stock"),
+		"charge":        hook("charge"),
+		"send_receipt":  hook("send_receipt"),
+	}
+}
+
+func TestLoadDefinition(t *testing.T) {
+	var calls []string
+	sm, err := transitionyaml.LoadDefinition(strings.NewReader(definition), getRegistry(&calls))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	order := &Order{}
+	if err := sm.Trigger("checkout", order); err != nil || order.GetState() != "checkout" || len(calls) != 1 || calls[0] != "reserve_stock" {
+		t.Errorf("the definition should be loaded with its hooks, got %s, %v, %v", order.GetState(), calls, err)
+	}
+
+	if _, err := transitionyaml.LoadDefinition(strings.NewReader("states: [\n"), getRegistry(&calls)); err == nil {
+		t.Errorf("malformed YAML should be rejected")
+	}
+	if _, err := transitionyaml.LoadDefinition(strings.NewReader("states:\n  - name: paid\n    enter: [unknown]\n"), getRegistry(&calls)); err == nil || !strings.Contains(err.Error(), "unknown hook unknown") {
+		t.Errorf("unknown hooks should be rejected, got %v", err)
+	}
+}
+
+func TestManifestRoundTrip(t *testing.T) {
+	var calls []string
+	registry := getRegistry(&calls)
+
+	source, err := transitionyaml.LoadDefinition(strings.NewReader(definition), registry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	source.Event("pay").To("paid").BeforeNamed("charge", registry["charge"])
+	source.Event("pay").To("paid").AfterNamed("send_receipt", registry["send_receipt"])
+	source.Event("pay").To("paid").After(func(*Order) error { return nil })
+
+	data, err := transitionyaml.MarshalManifest(source.HookManifest())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{"- state: checkout", "- name: reserve_stock", "event: pay", "- name: charge", "site: yaml_test.go:"} {
+		if !strings.Contains(string(data), expected) {
+			t.Errorf("the manifest should be written in block style with %q, got\n%s", expected, data)
+		}
+	}
+
+	manifest, err := transitionyaml.UnmarshalManifest(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roundTripped, _ := json.Marshal(manifest)
+	if expected, _ := json.Marshal(source.HookManifest()); string(roundTripped) != string(expected) {
+		t.Errorf("the manifest should round-trip, expected %s, got %s", expected, roundTripped)
+	}
+
+	// the wiring is applied to a machine defined without hooks
+	target, err := transitionyaml.LoadDefinition(strings.NewReader(strings.Replace(definition, "    enter: [reserve_stock]\n", "", 1)), registry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := transition.ApplyManifest(target, manifest, registry); err != nil {
+		t.Fatal(err)
+	}
+	calls = nil
+	order := &Order{}
+	target.Trigger("checkout", order)
+	target.Trigger("pay", order)
+	if expected := []string{"reserve_stock", "charge", "send_receipt"}; !reflect.DeepEqual(calls, expected) {
+		t.Errorf("expected hooks %v, got %v", expected, calls)
+	}
+}
+
+func TestUnmarshalManifestMissingHook(t *testing.T) {
+	var calls []string
+	sm, err := transitionyaml.LoadDefinition(strings.NewReader(definition), getRegistry(&calls))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	manifest, err := transitionyaml.UnmarshalManifest([]byte("transitions:\n  - event: pay\n    to: paid\n    after:\n      - name: notify_warehouse\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := transition.ApplyManifest(sm, manifest, getRegistry(&calls)); err == nil || !strings.Contains(err.Error(), "unknown hook notify_warehouse") {
+		t.Errorf("manifests referencing missing hooks should be rejected, got %v", err)
+	}
+
+	if _, err := transitionyaml.UnmarshalManifest([]byte("states: {")); err == nil {
+		t.Errorf("malformed YAML should be rejected")
+	}
+}
@@ -0,0 +1,12 @@
+package transition
+
+import "context"
+
+// Triggerer is the runtime surface of a StateMachine, accept it instead of *StateMachine in code that only
+// triggers events, to substitute a fake in tests, see transitiontest.FakeTriggerer
+type Triggerer[T Stater] interface {
+	Trigger(name string, value T) error
+	TriggerContext(ctx context.Context, name string, value T) error
+	Can(name string, value T) bool
+	AvailableEvents(value T) []string
+}
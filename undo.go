@@ -0,0 +1,107 @@
+package transition
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrNothingToUndo is returned by Undo when the value has no previous state, or it's no longer defined
+var ErrNothingToUndo = errors.New("nothing to undo")
+
+// PreviousStateTracker is implemented by values tracking their state before the last change, the embedded Transition implements it
+type PreviousStateTracker interface {
+	GetPreviousState() string
+	SetPreviousState(name string)
+}
+
+// UndoOption configure Undo
+type UndoOption func(*undoConfig)
+
+type undoConfig struct {
+	skipHooks bool
+}
+
+// UndoSkipHooks move the value back without running exit and enter hooks, invariants are still checked
+func UndoSkipHooks() UndoOption {
+	return func(config *undoConfig) {
+		config.skipHooks = true
+	}
+}
+
+// Undo move value back to its previous state, running the exit hooks of its current state, then the enter hooks
+// and invariants of the previous state. No event is fired, so no before and after hooks of transitions are run.
+// The undo is recorded in the history and the state change log with StateChange.Undo set. The previous state is
+// cleared, undoing twice in a row returns ErrNothingToUndo rather than redoing the change
+func (sm *StateMachine[T]) Undo(value T, opts ...UndoOption) error {
+	return sm.UndoContext(context.Background(), value, opts...)
+}
+
+// UndoContext undo the last change of value like Undo, ctx carries the actor recorded along with the undo
+func (sm *StateMachine[T]) UndoContext(ctx context.Context, value T, opts ...UndoOption) (err error) {
+	var config undoConfig
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	if isNil(value) {
+		return ErrNilValue
+	}
+
+	tracker, ok := any(value).(PreviousStateTracker)
+	if !ok {
+		return fmt.Errorf("%w: %T doesn't implement PreviousStateTracker", ErrNothingToUndo, value)
+	}
+
+	from, to := value.GetState(), tracker.GetPreviousState()
+	if _, ok := sm.states[to]; to == "" || (!ok && to != sm.initialState) {
+		return fmt.Errorf("%w: no previous state of %s", ErrNothingToUndo, from)
+	}
+
+	fail := func(phase Phase, owner string, index int, err error) error {
+		return fmt.Errorf("failed to undo from state %s to %s: %s hook %s: %w", from, to, phase, hookName(owner, index), err)
+	}
+
+	if state, ok := sm.states[from]; ok && !config.skipHooks {
+		for i, exit := range state.exits {
+			if err := exit(value); err != nil {
+				return fail(PhaseExit, from, i, err)
+			}
+		}
+	}
+
+	// the previous state is reverted if an enter hook, an invariant or the log fails
+	var pending mutations
+	defer func() {
+		if err != nil {
+			pending.revert()
+		}
+	}()
+	sm.changeState(value, to, &pending)
+	change, logged := sm.recordStateChange(ctx, value, StateChange{From: from, To: to, Undo: true}, &pending)
+
+	if state, ok := sm.states[to]; ok {
+		for i, enter := range state.enters {
+			if config.skipHooks {
+				break
+			}
+			if err := enter(value); err != nil {
+				return fail(PhaseEnter, to, i, err)
+			}
+		}
+
+		if err := state.checkInvariants(value); err != nil {
+			return fail(PhaseInvariant, to, 0, err)
+		}
+	}
+
+	if logged {
+		if err := sm.logStateChange(ctx, change); err != nil {
+			return fmt.Errorf("failed to undo from state %s to %s: %s: %w", from, to, PhaseLog, err)
+		}
+	}
+
+	tracker.SetPreviousState("")
+	sm.rescheduleTimeouts(value, from, to)
+	return nil
+}
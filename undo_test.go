@@ -0,0 +1,127 @@
+package transition
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestUndo(t *testing.T) {
+	var (
+		orderStateMachine = getStateMachine()
+		order             = &Order{}
+		calls             []string
+	)
+	orderStateMachine.State("checkout").Exit(func(*Order) error {
+		calls = append(calls, "exit checkout")
+		return nil
+	})
+	orderStateMachine.State("draft").Enter(func(*Order) error {
+		calls = append(calls, "enter draft")
+		return nil
+	})
+	orderStateMachine.Event("checkout").To("checkout").Before(func(*Order) error {
+		calls = append(calls, "before checkout")
+		return nil
+	})
+
+	if err := orderStateMachine.Undo(order); !errors.Is(err, ErrNothingToUndo) {
+		t.Errorf("undo without previous state should raise ErrNothingToUndo, got %v", err)
+	}
+
+	if err := orderStateMachine.Trigger("checkout", order); err != nil {
+		t.Fatalf("should not raise any error when trigger event checkout, got %v", err)
+	}
+	if order.PreviousState != "draft" {
+		t.Errorf("previous state should be tracked, got %q", order.PreviousState)
+	}
+
+	calls = nil
+	if err := orderStateMachine.Undo(order); err != nil {
+		t.Fatalf("should not raise any error when undoing, got %v", err)
+	}
+	if order.State != "draft" || len(calls) != 2 || calls[0] != "exit checkout" || calls[1] != "enter draft" {
+		t.Errorf("undo should only run state hooks, got %s, %v", order.State, calls)
+	}
+
+	if err := orderStateMachine.Undo(order); !errors.Is(err, ErrNothingToUndo) {
+		t.Errorf("undoing twice should raise ErrNothingToUndo, got %v", err)
+	}
+}
+
+func TestUndoSkipHooks(t *testing.T) {
+	orderStateMachine := getStateMachine()
+	orderStateMachine.State("draft").Enter(func(*Order) error {
+		return errors.New("draft is closed")
+	})
+
+	order := &Order{}
+	order.State = "checkout"
+	order.PreviousState = "draft"
+	if err := orderStateMachine.Undo(order); err == nil || order.State != "checkout" || order.PreviousState != "draft" {
+		t.Errorf("failed enter hook should roll back the undo, got %v", err)
+	}
+
+	if err := orderStateMachine.Undo(order, UndoSkipHooks()); err != nil || order.State != "draft" {
+		t.Errorf("undo should skip hooks, got %v", err)
+	}
+}
+
+func TestUndoUndefinedState(t *testing.T) {
+	order := &Order{}
+	order.State = "checkout"
+	order.PreviousState = "removed"
+	if err := getStateMachine().Undo(order); !errors.Is(err, ErrNothingToUndo) || order.State != "checkout" {
+		t.Errorf("undo to an undefined state should raise ErrNothingToUndo, got %v", err)
+	}
+}
+
+func TestUndoRecorded(t *testing.T) {
+	var (
+		sm     = getHistoryStateMachine()
+		order  = &HistoriedOrder{}
+		logged []StateChange
+	)
+	sm.SetStateChangeLog(StateChangeLogFunc(func(ctx context.Context, change StateChange) error {
+		logged = append(logged, change)
+		return nil
+	}))
+
+	sm.Trigger("checkout", order)
+	if err := sm.UndoContext(WithActor(context.Background(), "admin"), order); err != nil {
+		t.Fatal(err)
+	}
+
+	history := order.GetHistory()
+	if len(history) != 2 || !history[1].Undo || history[1].Event != "" || history[1].From != "checkout" || history[1].To != "draft" || history[1].Actor != "admin" {
+		t.Errorf("the undo should be recorded in the history with a marker, got %v", history)
+	}
+	if len(logged) != 2 || logged[1] != history[1] {
+		t.Errorf("the undo should be logged, got %v", logged)
+	}
+}
+
+func TestUndoLogFailure(t *testing.T) {
+	var (
+		sm     = getHistoryStateMachine()
+		order  = &HistoriedOrder{}
+		errLog = errors.New("log unavailable")
+		failed bool
+	)
+	sm.SetStateChangeLog(StateChangeLogFunc(func(ctx context.Context, change StateChange) error {
+		if failed {
+			return errLog
+		}
+		return nil
+	}))
+
+	sm.Trigger("checkout", order)
+	failed = true
+
+	if err := sm.Undo(order); !errors.Is(err, errLog) {
+		t.Fatalf("expected the log to fail the undo, got %v", err)
+	}
+	if order.GetState() != "checkout" || order.GetPreviousState() != "draft" || len(order.GetHistory()) != 1 {
+		t.Errorf("an undo that can't be logged should be reverted, got %+v", order)
+	}
+}
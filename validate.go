@@ -0,0 +1,226 @@
+package transition
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ErrUndeclaredState is reported by Validate for states used by transitions but never declared with State or Initial
+var ErrUndeclaredState = errors.New("undeclared state")
+
+var (
+	// ErrNoIncomingTransition is warned by Validate for declared states, other than the initial state, no transition goes to
+	ErrNoIncomingTransition = errors.New("no incoming transition")
+	// ErrDeadEnd is warned by Validate for states without outgoing transitions that are not marked with State.Final
+	ErrDeadEnd = errors.New("dead end")
+	// ErrUnusableEvent is warned by Validate for events whose transitions only go from undeclared states
+	ErrUnusableEvent = errors.New("unusable event")
+)
+
+// ValidateOption configure Validate
+type ValidateOption func(*validateConfig)
+
+type validateConfig struct {
+	warn           func(warning error)
+	warningsAsErrs bool
+}
+
+// WithWarnings report to warn the warnings found by Validate, they don't make Validate fail unless
+// WithWarningsAsErrors is also given
+func WithWarnings(warn func(warning error)) ValidateOption {
+	return func(config *validateConfig) {
+		config.warn = warn
+	}
+}
+
+// WithWarningsAsErrors make Validate fail on warnings, listed after the errors in the returned MultiError
+func WithWarningsAsErrors() ValidateOption {
+	return func(config *validateConfig) {
+		config.warningsAsErrs = true
+	}
+}
+
+// Validate check the state machine definition, returning a MultiError holding every problem found:
+// a missing initial state, transitions using undeclared states, events without transitions, transitions
+// of an event sharing a from state without guards to tell them apart, timeouts firing unknown events,
+// timeouts or debounced events without key func, declared states sharing a normalized name, see StateNormalizer,
+// and hooks running after unknown hooks or after each other, see OrderedHook.
+//
+// Validate also looks for paths in and out of states, warning about states without incoming transitions,
+// dead ends and unusable events, see WithWarnings and WithWarningsAsErrors
+func (sm *StateMachine[T]) Validate(opts ...ValidateOption) error {
+	var config validateConfig
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	var errs []error
+
+	if sm.initialState == "" {
+		errs = append(errs, errors.New("initial state is not defined"))
+	}
+
+	declared := func(state string) bool {
+		_, ok := sm.states[state]
+		return ok || state == sm.initialState
+	}
+
+	errs = append(errs, sm.initialErrors()...)
+	errs = append(errs, sm.normalizationCollisions()...)
+
+	for _, name := range sm.stateNames() {
+		state := sm.states[name]
+		errs = append(errs, hookOrderErrors("state "+name, PhaseEnter, state.enterRefs)...)
+		errs = append(errs, hookOrderErrors("state "+name, PhaseExit, state.exitRefs)...)
+		for _, timeout := range sm.states[name].timeouts {
+			if _, ok := sm.events[timeout.event]; !ok {
+				errs = append(errs, fmt.Errorf("timeout of state %s fires event %s: %w", name, timeout.event, ErrUnknownEvent))
+			}
+			if sm.keyFunc == nil {
+				errs = append(errs, fmt.Errorf("state %s has timeouts but the state machine has no key func", name))
+				break
+			}
+		}
+	}
+
+	for _, name := range sm.eventNames() {
+		event := sm.events[name]
+		transitions := event.transitions
+		if len(transitions) == 0 {
+			errs = append(errs, fmt.Errorf("event %s has no transitions", name))
+		}
+
+		if event.debounce > 0 && sm.keyFunc == nil {
+			errs = append(errs, fmt.Errorf("event %s is debounced but the state machine has no key func", name))
+		}
+
+		for i, transition := range transitions {
+			owner := fmt.Sprintf("event %s to %s", name, transition.to)
+			errs = append(errs, hookOrderErrors(owner, PhaseBefore, transition.beforeRefs)...)
+			errs = append(errs, hookOrderErrors(owner, PhaseAfter, transition.afterRefs)...)
+			if transition.toFunc == nil && !declared(transition.to) {
+				errs = append(errs, fmt.Errorf("event %s goes to state %s%s: %w", name, transition.to, definedAt(transition.toSite), ErrUndeclaredState))
+			}
+			for _, from := range transition.froms {
+				if !declared(from) {
+					errs = append(errs, fmt.Errorf("event %s goes from state %s%s: %w", name, from, transition.fromAddedAt(from), ErrUndeclaredState))
+				}
+			}
+
+			for _, other := range transitions[i+1:] {
+				if len(transition.guards) > 0 || len(other.guards) > 0 {
+					continue
+				}
+				// transitions accepting states through globs are only ambiguous for the states they match, see matchTransitions
+				if (len(transition.fromGlobs) > 0 || len(other.fromGlobs) > 0) && (len(transition.froms) == 0 || len(other.froms) == 0) {
+					continue
+				}
+				if from, overlap := overlappingFrom(transition.froms, other.froms); overlap {
+					errs = append(errs, fmt.Errorf("event %s goes to both %s%s and %s%s from state %s: %w", name,
+						transition.to, transition.fromAddedAt(from), other.to, other.fromAddedAt(from), from, ErrAmbiguousTransition))
+				}
+			}
+		}
+	}
+
+	for _, warning := range append(append(sm.pathWarnings(), sm.globWarnings()...), sm.fromWarnings()...) {
+		if config.warn != nil {
+			config.warn(warning)
+		}
+		if config.warningsAsErrs {
+			errs = append(errs, warning)
+		}
+	}
+
+	return newMultiError(errs)
+}
+
+// pathWarnings returns the warnings about paths in and out of states: declared states no transition goes to,
+// states without outgoing transitions that are not final, and events only going from undeclared states
+func (sm *StateMachine[T]) pathWarnings() []error {
+	var (
+		warnings []error
+		graph    = sm.graph()
+		incoming = map[string]bool{}
+	)
+	for _, edges := range graph.edges {
+		for _, edge := range edges {
+			incoming[edge.To] = true
+		}
+	}
+
+	names := sm.stateNames()
+	if _, ok := sm.states[sm.initialState]; !ok && sm.initialState != "" {
+		names = append([]string{sm.initialState}, names...)
+	}
+	for _, name := range names {
+		if state, ok := sm.states[name]; !sm.isInitial(name) && !incoming[name] && !(ok && len(state.aliasOf) > 0) {
+			warnings = append(warnings, fmt.Errorf("state %s: %w", name, ErrNoIncomingTransition))
+		}
+		if state, ok := sm.states[name]; len(graph.edges[name]) == 0 && !(ok && state.final) {
+			warnings = append(warnings, fmt.Errorf("state %s has no outgoing transition and is not final: %w", name, ErrDeadEnd))
+		}
+	}
+
+	for _, name := range sm.eventNames() {
+		var declaredFrom, undeclaredFrom bool
+		for _, transition := range sm.events[name].transitions {
+			if len(transition.froms) == 0 {
+				declaredFrom = true
+			}
+			for _, from := range transition.froms {
+				if _, ok := sm.states[from]; ok || from == sm.initialState {
+					declaredFrom = true
+				} else {
+					undeclaredFrom = true
+				}
+			}
+		}
+		if undeclaredFrom && !declaredFrom {
+			warnings = append(warnings, fmt.Errorf("event %s only goes from undeclared states: %w", name, ErrUnusableEvent))
+		}
+	}
+	return warnings
+}
+
+// overlappingFrom returns a from state accepted by both from sets, "*" when both accept any state
+func overlappingFrom(froms, others []string) (string, bool) {
+	switch {
+	case len(froms) == 0 && len(others) == 0:
+		return "*", true
+	case len(froms) == 0:
+		return others[0], true
+	case len(others) == 0:
+		return froms[0], true
+	}
+
+	for _, from := range froms {
+		for _, other := range others {
+			if from == other {
+				return from, true
+			}
+		}
+	}
+	return "", false
+}
+
+// stateNames returns the names of the declared states, sorted
+func (sm *StateMachine[T]) stateNames() []string {
+	names := make([]string, 0, len(sm.states))
+	for name := range sm.states {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// eventNames returns the names of the events, sorted
+func (sm *StateMachine[T]) eventNames() []string {
+	names := make([]string, 0, len(sm.events))
+	for name := range sm.events {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
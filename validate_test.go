@@ -0,0 +1,159 @@
+package transition
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestValidate(t *testing.T) {
+	orderStateMachine := getStateMachine()
+	orderStateMachine.Event("cancel").To("cancelled").From("draft", "checkout")
+	orderStateMachine.Event("cancel").To("paid_cancelled").From("paid")
+
+	if err := orderStateMachine.Validate(); err != nil {
+		t.Errorf("should not raise any error for a valid definition, got %v", err)
+	}
+}
+
+func TestValidateReportsAllProblems(t *testing.T) {
+	orderStateMachine := New(&Order{})
+	orderStateMachine.State("checkout").Timeout(time.Minute, "expire")
+	orderStateMachine.Event("checkout").To("checkout").From("draft")
+	orderStateMachine.Event("refund")
+	orderStateMachine.Event("cancel").To("cancelled").From("checkout")
+	orderStateMachine.Event("cancel").To("checkout").From("checkout")
+	orderStateMachine.Event("reset").To("checkout").From("checkout").Guard(func(ctx context.Context, order *Order) error { return nil })
+	orderStateMachine.Event("reset").To("cancelled").From("checkout")
+
+	err := orderStateMachine.Validate()
+
+	var multiErr *MultiError
+	if !errors.As(err, &multiErr) {
+		t.Fatalf("should raise a MultiError, got %v", err)
+	}
+
+	expected := []string{
+		"initial state is not defined",
+		"timeout of state checkout fires event expire: unknown event",
+		"state checkout has timeouts but the state machine has no key func",
+		"event cancel goes to state cancelled: undeclared state",
+		"event cancel goes to both cancelled and checkout from state checkout: ambiguous transition",
+		"event checkout goes from state draft: undeclared state",
+		"event refund has no transitions",
+		"event reset goes to state cancelled: undeclared state",
+	}
+	errs := multiErr.Errors()
+	if len(errs) != len(expected) {
+		t.Fatalf("expected %d errors, got %v", len(expected), err)
+	}
+	for i, message := range expected {
+		if errs[i].Error() != message {
+			t.Errorf("error %d: expected %q, got %q", i+1, message, errs[i])
+		}
+	}
+
+	if !errors.Is(err, ErrAmbiguousTransition) || !errors.Is(err, ErrUndeclaredState) {
+		t.Errorf("errors.Is should match any aggregated error")
+	}
+}
+
+func TestValidateWarnings(t *testing.T) {
+	orderStateMachine := New(&Order{})
+	orderStateMachine.Initial("draft")
+	orderStateMachine.State("checkout")
+	orderStateMachine.State("paid")
+	orderStateMachine.State("archived")
+	orderStateMachine.State("cancelled").Final()
+	orderStateMachine.Event("checkout").To("checkout").From("draft")
+	orderStateMachine.Event("pay").To("paid").From("checkout")
+	orderStateMachine.Event("cancel").To("cancelled").From("draft", "checkout")
+	orderStateMachine.Event("refund").To("cancelled").From("refunding")
+
+	var warnings []error
+	err := orderStateMachine.Validate(WithWarnings(func(warning error) {
+		warnings = append(warnings, warning)
+	}))
+
+	var multiErr *MultiError
+	if !errors.As(err, &multiErr) || len(multiErr.Errors()) != 1 || multiErr.Errors()[0].Error() != "event refund goes from state refunding: undeclared state" {
+		t.Errorf("warnings should not be reported as errors, got %v", err)
+	}
+
+	expected := []string{
+		"state archived: no incoming transition",
+		"state archived has no outgoing transition and is not final: dead end",
+		"state paid has no outgoing transition and is not final: dead end",
+		"event refund only goes from undeclared states: unusable event",
+	}
+	if len(warnings) != len(expected) {
+		t.Fatalf("expected %d warnings, got %v", len(expected), warnings)
+	}
+	for i, message := range expected {
+		if warnings[i].Error() != message {
+			t.Errorf("warning %d: expected %q, got %q", i+1, message, warnings[i])
+		}
+	}
+
+	err = orderStateMachine.Validate(WithWarningsAsErrors())
+	if !errors.As(err, &multiErr) || len(multiErr.Errors()) != 1+len(expected) {
+		t.Fatalf("warnings should be reported as errors, got %v", err)
+	}
+	if !errors.Is(err, ErrNoIncomingTransition) || !errors.Is(err, ErrDeadEnd) || !errors.Is(err, ErrUnusableEvent) {
+		t.Errorf("errors.Is should match every warning")
+	}
+}
+
+func TestValidateWarningsInitialDeadEnd(t *testing.T) {
+	orderStateMachine := New(&Order{})
+	orderStateMachine.Initial("draft")
+
+	var warnings []error
+	if err := orderStateMachine.Validate(WithWarnings(func(warning error) { warnings = append(warnings, warning) })); err != nil {
+		t.Errorf("no error expected, got %v", err)
+	}
+	if len(warnings) != 1 || warnings[0].Error() != "state draft has no outgoing transition and is not final: dead end" {
+		t.Errorf("expected the initial state to be a dead end, got %v", warnings)
+	}
+}
+
+func TestTriggerAll(t *testing.T) {
+	orderStateMachine := getStateMachine()
+
+	var orders []*Order
+	for i := 0; i < 8; i++ {
+		order := &Order{}
+		if i%2 == 1 {
+			order.State = "paid"
+		}
+		orders = append(orders, order)
+	}
+	orders = append(orders, &Order{})
+
+	err := orderStateMachine.TriggerAll("checkout", orders...)
+
+	var multiErr *MultiError
+	if !errors.As(err, &multiErr) || len(multiErr.Errors()) != 4 {
+		t.Fatalf("should raise a MultiError of 4 errors, got %v", err)
+	}
+
+	if !errors.Is(err, ErrNoMatchingTransition) || !IsNoMatch(err) {
+		t.Errorf("errors.Is should match the aggregated errors")
+	}
+
+	var transitionErr *TransitionError
+	if !errors.As(err, &transitionErr) || transitionErr.From != "paid" {
+		t.Errorf("errors.As should reach the aggregated errors")
+	}
+
+	for i, order := range orders {
+		if i%2 == 0 && order.State != "checkout" {
+			t.Errorf("order %d should have been checked out despite other failures", i)
+		}
+	}
+
+	if err := orderStateMachine.TriggerAll("checkout", &Order{}); err != nil {
+		t.Errorf("should not raise any error when all succeed, got %v", err)
+	}
+}
@@ -0,0 +1,171 @@
+package transition
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrVariantUnresolved is returned when the state machine of a trigger can't be picked, see VariantResolver
+var ErrVariantUnresolved = errors.New("variant unresolved")
+
+// BaseVariant is the name of the base state machine of a VariantResolver
+const BaseVariant = "base"
+
+type tenantContextKey struct{}
+
+// WithTenant returns a copy of ctx carrying the tenant triggering events, see VariantResolver
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, tenant)
+}
+
+// TenantFromContext returns the tenant set with WithTenant, or an empty string
+func TenantFromContext(ctx context.Context) string {
+	tenant, _ := ctx.Value(tenantContextKey{}).(string)
+	return tenant
+}
+
+type variantContextKey struct{}
+
+// VariantFromContext returns the name of the variant performing the trigger, e.g. for hooks recording audit logs,
+// or an empty string when the trigger isn't performed by a VariantResolver
+func VariantFromContext(ctx context.Context) string {
+	variant, _ := ctx.Value(variantContextKey{}).(string)
+	return variant
+}
+
+// VariantResolver triggers events with the state machine picked per call, e.g. the overlay of a tenant, so call
+// sites don't pick it themselves. Picks are cached per tenant, see WithTenant, values triggered without a tenant
+// are picked on every call
+type VariantResolver[T Stater] struct {
+	base *StateMachine[T]
+	pick func(ctx context.Context, value T) (*StateMachine[T], error)
+
+	mu     sync.Mutex
+	picked map[string]*StateMachine[T]
+	names  map[*StateMachine[T]]string
+}
+
+// NewVariantResolver returns a resolver triggering events with the state machine returned by pick, base when it
+// returns nil:
+//
+//	resolver := transition.NewVariantResolver(OrderStateMachine, func(ctx context.Context, order *Order) (*transition.StateMachine[*Order], error) {
+//		return overlays[transition.TenantFromContext(ctx)], nil
+//	})
+//	resolver.TriggerContext(transition.WithTenant(ctx, "acme"), "cancel", &order)
+func NewVariantResolver[T Stater](base *StateMachine[T], pick func(ctx context.Context, value T) (*StateMachine[T], error)) *VariantResolver[T] {
+	return &VariantResolver[T]{
+		base:   base,
+		pick:   pick,
+		picked: map[string]*StateMachine[T]{},
+		names:  map[*StateMachine[T]]string{base: BaseVariant},
+	}
+}
+
+// Variant name sm, the name is passed to hooks with VariantFromContext and NotifyData. Unnamed variants are named
+// after their fingerprint
+func (resolver *VariantResolver[T]) Variant(name string, sm *StateMachine[T]) *VariantResolver[T] {
+	resolver.mu.Lock()
+	defer resolver.mu.Unlock()
+	resolver.names[sm] = name
+	return resolver
+}
+
+// Forget drop the state machine picked for tenant, e.g. once its overlay changed
+func (resolver *VariantResolver[T]) Forget(tenant string) {
+	resolver.mu.Lock()
+	defer resolver.mu.Unlock()
+	delete(resolver.picked, tenant)
+}
+
+// Resolve returns the state machine triggering events on value with ctx, and its variant name. Errors of pick are
+// wrapped along with ErrVariantUnresolved
+func (resolver *VariantResolver[T]) Resolve(ctx context.Context, value T) (*StateMachine[T], string, error) {
+	tenant := TenantFromContext(ctx)
+
+	resolver.mu.Lock()
+	sm, ok := resolver.picked[tenant]
+	resolver.mu.Unlock()
+
+	if !ok || tenant == "" {
+		var err error
+		if sm, err = resolver.pick(ctx, value); err != nil {
+			if tenant != "" {
+				return nil, "", fmt.Errorf("tenant %s: %w: %w", tenant, ErrVariantUnresolved, err)
+			}
+			return nil, "", fmt.Errorf("%w: %w", ErrVariantUnresolved, err)
+		}
+		if sm == nil {
+			sm = resolver.base
+		}
+	}
+
+	resolver.mu.Lock()
+	if tenant != "" {
+		resolver.picked[tenant] = sm
+	}
+	name, ok := resolver.names[sm]
+	resolver.mu.Unlock()
+
+	// cached by the state machine, see StateMachine.Fingerprint
+	if !ok {
+		name = sm.Fingerprint()
+	}
+	return sm, name, nil
+}
+
+// Trigger trigger an event with the state machine picked for value, see StateMachine.Trigger
+func (resolver *VariantResolver[T]) Trigger(name string, value T) error {
+	return resolver.TriggerContext(context.Background(), name, value)
+}
+
+// TriggerContext trigger an event with the state machine picked for ctx and value, see StateMachine.TriggerContext
+func (resolver *VariantResolver[T]) TriggerContext(ctx context.Context, name string, value T) error {
+	_, err := resolver.Execute(ctx, TriggerCommand{Event: name}, value)
+	return err
+}
+
+// Execute execute the command with the state machine picked for ctx and value, the result tells which variant
+// performed it, see StateMachine.Execute. Failures to pick the state machine are returned as is
+func (resolver *VariantResolver[T]) Execute(ctx context.Context, command TriggerCommand, value T) (*TransitionResult, error) {
+	sm, variant, err := resolver.Resolve(ctx, value)
+	if err != nil {
+		return &TransitionResult{Event: command.Event}, err
+	}
+
+	result, err := sm.Execute(context.WithValue(ctx, variantContextKey{}, variant), command, value)
+	result.Variant = variant
+	return result, err
+}
+
+// Can check if the event could be triggered with the state machine picked for value, see StateMachine.Can
+func (resolver *VariantResolver[T]) Can(name string, value T) bool {
+	return resolver.CanContext(context.Background(), name, value)
+}
+
+// CanContext check if the event could be triggered with the state machine picked for ctx and value, it reports
+// false when none can be picked
+func (resolver *VariantResolver[T]) CanContext(ctx context.Context, name string, value T) bool {
+	sm, variant, err := resolver.Resolve(ctx, value)
+	if err != nil {
+		return false
+	}
+	return sm.CanContext(context.WithValue(ctx, variantContextKey{}, variant), name, value)
+}
+
+// AvailableEvents returns the events that could be triggered with the state machine picked for value, see
+// StateMachine.AvailableEvents
+func (resolver *VariantResolver[T]) AvailableEvents(value T) []string {
+	return resolver.AvailableEventsContext(context.Background(), value)
+}
+
+// AvailableEventsContext returns the events that could be triggered with the state machine picked for ctx and
+// value, none when it can't be picked
+func (resolver *VariantResolver[T]) AvailableEventsContext(ctx context.Context, value T) []string {
+	sm, _, err := resolver.Resolve(ctx, value)
+	if err != nil {
+		return nil
+	}
+	return sm.AvailableEvents(value)
+}
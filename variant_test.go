@@ -0,0 +1,94 @@
+package transition
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestVariantResolver(t *testing.T) {
+	base := getStateMachine()
+	overlay := base.Clone()
+
+	var (
+		picks       int
+		variants    []string
+		unreachable = errors.New("tenant store unreachable")
+	)
+	overlay.Event("cancel").To("cancelled").From("draft", "checkout").Notify(func(ctx context.Context, data NotifyData[*Order]) error {
+		variants = append(variants, data.Variant)
+		return nil
+	})
+	resolver := NewVariantResolver(base, func(ctx context.Context, order *Order) (*StateMachine[*Order], error) {
+		picks++
+		switch TenantFromContext(ctx) {
+		case "acme":
+			return overlay, nil
+		case "broken":
+			return nil, unreachable
+		}
+		return nil, nil
+	}).Variant("acme-overlay", overlay)
+
+	// the same call site, for different tenants
+	availableEvents := func(tenant string) []string {
+		return resolver.AvailableEventsContext(WithTenant(context.Background(), tenant), &Order{})
+	}
+	if events := availableEvents("globex"); !reflect.DeepEqual(events, []string{"checkout"}) {
+		t.Errorf("tenants without overlay should use the base state machine, got %v", events)
+	}
+	if events := availableEvents("acme"); !reflect.DeepEqual(events, []string{"cancel", "checkout"}) {
+		t.Errorf("tenants with an overlay should use it, got %v", events)
+	}
+	if !resolver.CanContext(WithTenant(context.Background(), "acme"), "cancel", &Order{}) || resolver.Can("cancel", &Order{}) {
+		t.Errorf("cancel should only be allowed by the overlay")
+	}
+
+	order := &Order{}
+	result, err := resolver.Execute(WithTenant(context.Background(), "acme"), TriggerCommand{Event: "cancel"}, order)
+	if err != nil || order.GetState() != "cancelled" || result.Variant != "acme-overlay" || !reflect.DeepEqual(variants, []string{"acme-overlay"}) {
+		t.Errorf("triggers should be annotated with their variant, got %+v, %v, %v", result, variants, err)
+	}
+	if result, err := resolver.Execute(WithTenant(context.Background(), "globex"), TriggerCommand{Event: "checkout"}, &Order{}); err != nil || result.Variant != BaseVariant {
+		t.Errorf("the base state machine should be annotated as %s, got %+v, %v", BaseVariant, result, err)
+	}
+
+	// globex and acme once, and the check without tenant
+	if picks != 3 {
+		t.Errorf("picks should be cached per tenant, got %d picks", picks)
+	}
+	resolver.Forget("acme")
+	availableEvents("acme")
+	if picks != 4 {
+		t.Errorf("forgotten tenants should be picked again, got %d picks", picks)
+	}
+
+	err = resolver.TriggerContext(WithTenant(context.Background(), "broken"), "checkout", &Order{})
+	var transitionErr *TransitionError
+	if !errors.Is(err, ErrVariantUnresolved) || !errors.Is(err, unreachable) || errors.As(err, &transitionErr) {
+		t.Errorf("pick failures should be returned distinctly, got %v", err)
+	}
+	if events := availableEvents("broken"); events != nil || resolver.CanContext(WithTenant(context.Background(), "broken"), "checkout", &Order{}) {
+		t.Errorf("nothing should be allowed when no state machine can be picked, got %v", events)
+	}
+}
+
+func TestVariantResolverFingerprintName(t *testing.T) {
+	base := getStateMachine()
+	overlay := base.Clone()
+	overlay.Event("cancel").To("cancelled").From("draft")
+	resolver := NewVariantResolver(base, func(ctx context.Context, order *Order) (*StateMachine[*Order], error) {
+		return overlay, nil
+	})
+
+	order := &Order{}
+	allocs := testing.AllocsPerRun(10, func() {
+		if _, name, err := resolver.Resolve(context.Background(), order); err != nil || name != overlay.Fingerprint() {
+			t.Fatalf("unnamed variants should be named after their fingerprint, got %q, %v", name, err)
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("unnamed variants should be named after the cached fingerprint, got %v allocs", allocs)
+	}
+}
@@ -0,0 +1,49 @@
+package transition
+
+import "fmt"
+
+// VersionTracker is implemented by values recording the version of the state machine that last changed their state,
+// the embedded Transition implements it
+type VersionTracker interface {
+	GetVersion() (version, fingerprint string)
+	SetVersion(version, fingerprint string)
+}
+
+// Version set the version of the state machine definition, recorded on values along with the fingerprint of the
+// definition every time the state machine changes their state, see OnVersionMismatch
+func (sm *StateMachine[T]) Version(version string) *StateMachine[T] {
+	sm.owner.checkMutable("Version")
+	sm.version = version
+	return sm
+}
+
+// OnVersionMismatch register a hook called before triggering an event on a value whose recorded version differs
+// from the version of the state machine, it can migrate the value, e.g. rename its state, or reject the trigger
+// by returning an error. Without hook, mismatches are ignored
+func (sm *StateMachine[T]) OnVersionMismatch(fc func(value T, recorded, current string) error) *StateMachine[T] {
+	sm.owner.checkMutable("OnVersionMismatch")
+	sm.onVersionMismatch = fc
+	return sm
+}
+
+// checkVersion calls the OnVersionMismatch hook when the version recorded on value differs from the state machine's
+func (sm *StateMachine[T]) checkVersion(value T) error {
+	if sm.onVersionMismatch == nil || sm.version == "" {
+		return nil
+	}
+
+	tracker, ok := any(value).(VersionTracker)
+	if !ok {
+		return nil
+	}
+
+	recorded, _ := tracker.GetVersion()
+	if recorded == "" || recorded == sm.version {
+		return nil
+	}
+
+	if err := sm.onVersionMismatch(value, recorded, sm.version); err != nil {
+		return fmt.Errorf("version %s recorded on the value mismatches version %s: %w", recorded, sm.version, err)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package transition
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestVersion(t *testing.T) {
+	orderStateMachine := getStateMachine().Version("v1")
+
+	order := &Order{}
+	if err := orderStateMachine.Trigger("checkout", order); err != nil {
+		t.Fatalf("should not raise any error when trigger event checkout, got %v", err)
+	}
+	if order.Version != "v1" || order.Fingerprint != orderStateMachine.Fingerprint() {
+		t.Errorf("version and fingerprint should be recorded, got %q, %q", order.Version, order.Fingerprint)
+	}
+
+	if err := orderStateMachine.Trigger("checkout", order); err == nil || order.Version != "v1" {
+		t.Errorf("failed trigger should keep the recorded version")
+	}
+}
+
+func TestOnVersionMismatch(t *testing.T) {
+	orderStateMachine := getStateMachine(WithMutableAfterStart()).Version("v2")
+	orderStateMachine.Event("pay").To("paid").From("checkout")
+
+	order := &Order{}
+	order.State, order.Version = "awaiting_payment", "v1"
+	if err := orderStateMachine.Trigger("pay", order); !IsNoMatch(err) {
+		t.Errorf("mismatches should be ignored without hook, got %v", err)
+	}
+
+	orderStateMachine.OnVersionMismatch(func(order *Order, recorded, current string) error {
+		if recorded != "v1" {
+			return errors.New("unsupported version")
+		}
+		if order.State == "awaiting_payment" {
+			order.State = "checkout"
+		}
+		return nil
+	})
+	if err := orderStateMachine.Trigger("pay", order); err != nil || order.State != "paid" || order.Version != "v2" {
+		t.Errorf("hook should migrate the value, got %v", err)
+	}
+
+	order.State, order.Version = "checkout", "v0"
+	err := orderStateMachine.Trigger("pay", order)
+	var transitionErr *TransitionError
+	if !errors.As(err, &transitionErr) || transitionErr.Phase != PhaseVersion || order.State != "checkout" {
+		t.Errorf("hook should reject the trigger, got %v", err)
+	}
+}
+
+func TestFingerprintCached(t *testing.T) {
+	orderStateMachine := New(&Order{}, WithMutableAfterStart())
+	orderStateMachine.Initial("draft")
+	orderStateMachine.Version("v1").Event("checkout").To("checkout").From("draft")
+	fingerprint := orderStateMachine.Fingerprint()
+
+	if allocs := testing.AllocsPerRun(10, func() { orderStateMachine.Fingerprint() }); allocs != 0 {
+		t.Errorf("the fingerprint should be cached, got %v allocs", allocs)
+	}
+
+	order := &Order{}
+	if err := orderStateMachine.Trigger("checkout", order); err != nil || order.Fingerprint != fingerprint {
+		t.Fatalf("the cached fingerprint should be recorded, got %q, %v", order.Fingerprint, err)
+	}
+
+	orderStateMachine.Event("pay").To("paid").From("checkout")
+	if orderStateMachine.Fingerprint() == fingerprint {
+		t.Errorf("the fingerprint should be computed again after the definition changed")
+	}
+}
@@ -0,0 +1,136 @@
+package transition
+
+import (
+	"context"
+)
+
+// MachineView is a read-only projection of a state machine keeping the states and events whose metadata pass a
+// filter, see StateMachine.View. Triggers keep operating on the full machine
+type MachineView[T Stater] struct {
+	sm     *StateMachine[T]
+	filter func(metadata map[string]string) bool
+}
+
+// ViewSummary lists what a view hides
+type ViewSummary struct {
+	States []string `json:"states"`
+	Events []string `json:"events"`
+	// Transitions are the transitions of visible events dropped because all their from states or their
+	// destination are hidden
+	Transitions []HiddenTransition `json:"transitions"`
+}
+
+// HiddenTransition is a transition hidden by a view
+type HiddenTransition struct {
+	Event string   `json:"event"`
+	From  []string `json:"from,omitempty"`
+	To    string   `json:"to"`
+}
+
+// View returns a read-only projection of the state machine keeping the states and events whose metadata, set with
+// Meta, pass filter, e.g. the customer-visible ones. Transitions from or to hidden states are dropped from the view
+func (sm *StateMachine[T]) View(filter func(metadata map[string]string) bool) *MachineView[T] {
+	return &MachineView[T]{sm: sm, filter: filter}
+}
+
+// visibleState reports whether the view shows state
+func (view *MachineView[T]) visibleState(name string) bool {
+	var metadata map[string]string
+	if state, ok := view.sm.states[name]; ok {
+		metadata = state.metadata
+	}
+	return view.filter(metadata)
+}
+
+// visibleEvent reports whether the view shows event
+func (view *MachineView[T]) visibleEvent(name string) bool {
+	var metadata map[string]string
+	if event, ok := view.sm.events[name]; ok {
+		metadata = event.metadata
+	}
+	return view.filter(metadata)
+}
+
+// Describe returns the description of the visible states, events and transitions, see StateMachine.Describe
+func (view *MachineView[T]) Describe(opts ...DescribeOption) MachineDescription {
+	description, _ := view.describe(opts...)
+	return description
+}
+
+// Hidden returns what the view hides
+func (view *MachineView[T]) Hidden() ViewSummary {
+	_, hidden := view.describe()
+	return hidden
+}
+
+func (view *MachineView[T]) describe(opts ...DescribeOption) (MachineDescription, ViewSummary) {
+	var (
+		full        = view.sm.Describe(opts...)
+		description = MachineDescription{SchemaVersion: full.SchemaVersion, Initial: full.Initial, States: []StateDescription{}, Events: []EventDescription{}}
+		hidden      = ViewSummary{States: []string{}, Events: []string{}, Transitions: []HiddenTransition{}}
+		visible     = map[string]bool{}
+	)
+
+	for _, state := range full.States {
+		if !view.visibleState(state.Name) {
+			hidden.States = append(hidden.States, state.Name)
+			continue
+		}
+		visible[state.Name] = true
+		description.States = append(description.States, state)
+	}
+	if !visible[full.Initial] {
+		description.Initial = ""
+	}
+
+	for _, event := range full.Events {
+		if !view.visibleEvent(event.Name) {
+			hidden.Events = append(hidden.Events, event.Name)
+			continue
+		}
+
+		transitions := []TransitionDescription{}
+		for _, transition := range event.Transitions {
+			var from []string
+			for _, state := range transition.From {
+				if visible[state] {
+					from = append(from, state)
+				}
+			}
+
+			if (len(transition.From) > 0 && len(from) == 0) || (!transition.Dynamic && !visible[transition.To]) {
+				hidden.Transitions = append(hidden.Transitions, HiddenTransition{Event: event.Name, From: transition.From, To: transition.To})
+				continue
+			}
+			transition.From = from
+			transitions = append(transitions, transition)
+		}
+		event.Transitions = transitions
+		description.Events = append(description.Events, event)
+	}
+	return description, hidden
+}
+
+// AllowedActions returns the visible actions of value, see StateMachine.AllowedActions. Destinations hidden by the
+// view are removed, actions left without destination are dropped
+func (view *MachineView[T]) AllowedActions(ctx context.Context, value T, opts ...ActionOption) []Action {
+	actions := []Action{}
+	for _, action := range view.sm.AllowedActions(ctx, value, opts...) {
+		if !view.visibleEvent(action.Event) {
+			continue
+		}
+
+		var to []string
+		for _, state := range action.To {
+			if view.visibleState(state) {
+				to = append(to, state)
+			}
+		}
+		if len(to) == 0 {
+			continue
+		}
+		action.To = to
+		actions = append(actions, action)
+	}
+	return actions
+}
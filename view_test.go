@@ -0,0 +1,64 @@
+package transition
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestView(t *testing.T) {
+	orderStateMachine := getStateMachine()
+	for _, state := range []string{"checkout", "paid", "delivered", "cancelled"} {
+		orderStateMachine.State(state).Meta("visibility", "customer")
+	}
+	orderStateMachine.Event("checkout").Meta("visibility", "customer")
+	orderStateMachine.Event("pay").Meta("visibility", "customer")
+	cancel := orderStateMachine.Event("cancel").Meta("visibility", "customer")
+	cancel.To("cancelled").From("checkout", "processed")
+	cancel.To("paid_cancelled").From("paid")
+	orderStateMachine.Event("process").To("processed").From("paid")
+
+	view := orderStateMachine.View(func(metadata map[string]string) bool {
+		return metadata["visibility"] == "customer"
+	})
+
+	output, err := json.MarshalIndent(view.Describe(), "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden := filepath.Join("testdata", "view.golden")
+	if *update {
+		if err := os.WriteFile(golden, output, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expected, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(output) != string(expected) {
+		t.Errorf("unexpected view, got\n%s\nexpected\n%s", output, expected)
+	}
+
+	hidden := view.Hidden()
+	expectedHidden := ViewSummary{
+		States:      []string{"draft", "paid_cancelled", "processed"},
+		Events:      []string{"process"},
+		Transitions: []HiddenTransition{{Event: "cancel", From: []string{"paid"}, To: "paid_cancelled"}, {Event: "checkout", From: []string{"draft"}, To: "checkout"}},
+	}
+	if !reflect.DeepEqual(hidden, expectedHidden) {
+		t.Errorf("expected hidden %+v, got %+v", expectedHidden, hidden)
+	}
+
+	order := &Order{}
+	order.State = "paid"
+	if actions := view.AllowedActions(context.Background(), order); len(actions) != 0 {
+		t.Errorf("actions to hidden states should be dropped, got %+v", actions)
+	}
+	if err := orderStateMachine.Trigger("cancel", order); err != nil || order.State != "paid_cancelled" {
+		t.Errorf("the view should not change triggers, got %v", err)
+	}
+}
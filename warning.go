@@ -0,0 +1,67 @@
+package transition
+
+// WarningCode tells what degraded while performing a transition, see Warning
+type WarningCode string
+
+// WarningHookFailed is the code of the warnings of best-effort hooks that failed, see OrderedHook.BestEffort
+const WarningHookFailed WarningCode = "hook_failed"
+
+// Warning is a failure that didn't fail the trigger, e.g. of a best-effort hook. Warnings are listed by
+// TransitionResult.Warnings and reported to the OnWarning hooks, not to the OnError ones
+type Warning struct {
+	Code WarningCode `json:"code"`
+	// Hook names the hook or feature that produced the warning, e.g. the name of a best-effort hook
+	Hook    string `json:"hook"`
+	Message string `json:"message"`
+	Err     error  `json:"-"`
+}
+
+func (warning Warning) Error() string {
+	return warning.Hook + ": " + warning.Message
+}
+
+// Unwrap returns the error of the warning
+func (warning Warning) Unwrap() error {
+	return warning.Err
+}
+
+// OnWarning register a hook called with the warnings of triggers, see Warning
+func (sm *StateMachine[T]) OnWarning(fc func(warning Warning)) *StateMachine[T] {
+	sm.owner.checkMutable("OnWarning")
+	sm.onWarnings = append(sm.onWarnings, fc)
+	return sm
+}
+
+// BestEffort make the failures of the hook warnings instead of failing the trigger, the following hooks still run
+func (hook *OrderedHook[T]) BestEffort() *OrderedHook[T] {
+	hook.checkMutable("OrderedHook.BestEffort")
+	hook.update(func(ref *HookRef) {
+		ref.bestEffort = true
+	})
+	return hook
+}
+
+// tolerate reports the failure of the index-th hook described by refs as a warning and returns true when the hook
+// is best-effort
+func (sm *StateMachine[T]) tolerate(opts triggerOptions, refs []HookRef, owner string, index int, err error) bool {
+	if index >= len(refs) || !refs[index].bestEffort {
+		return false
+	}
+
+	warning := Warning{Code: WarningHookFailed, Hook: refs[index].Name, Message: err.Error(), Err: err}
+	if warning.Hook == "" {
+		warning.Hook = hookName(owner, index)
+	}
+	sm.warn(opts, warning)
+	return true
+}
+
+// warn records warning in the result of the trigger, and reports it to the OnWarning hooks
+func (sm *StateMachine[T]) warn(opts triggerOptions, warning Warning) {
+	if opts.warnings != nil {
+		*opts.warnings = append(*opts.warnings, warning)
+	}
+	for _, onWarning := range sm.onWarnings {
+		onWarning(warning)
+	}
+}
@@ -0,0 +1,57 @@
+package transition
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestWarnings(t *testing.T) {
+	orderStateMachine := getStateMachine()
+	errPublish := errors.New("broker unavailable")
+
+	var after bool
+	orderStateMachine.Event("pay").To("paid").AfterNamed("publish", func(order *Order) error {
+		return errPublish
+	}).BestEffort()
+	orderStateMachine.Event("pay").To("paid").After(func(order *Order) error {
+		after = true
+		return nil
+	})
+
+	strict := orderStateMachine.Clone()
+	strict.Event("pay").To("paid").After(func(order *Order) error { return errPublish })
+
+	var warnings []Warning
+	var errs []error
+	orderStateMachine.OnWarning(func(warning Warning) { warnings = append(warnings, warning) })
+	orderStateMachine.OnError(func(err error) { errs = append(errs, err) })
+
+	order := &Order{}
+	order.State = "checkout"
+	result, err := orderStateMachine.Execute(context.Background(), TriggerCommand{Event: "pay"}, order)
+	if err != nil || result.To != "paid" || order.State != "paid" || !after {
+		t.Fatalf("best-effort hook should not fail the trigger, got %+v, %v", result, err)
+	}
+	if len(result.Warnings) != 1 {
+		t.Fatalf("expected one warning, got %v", result.Warnings)
+	}
+	warning := result.Warnings[0]
+	if warning.Code != WarningHookFailed || warning.Hook != "publish" || !errors.Is(warning, errPublish) {
+		t.Errorf("unexpected warning %+v", warning)
+	}
+	if len(warnings) != 1 || len(errs) != 0 {
+		t.Errorf("warnings should be reported to OnWarning only, got %v and %v", warnings, errs)
+	}
+
+	data, err := json.Marshal(result.Warnings)
+	if err != nil || string(data) != `[{"code":"hook_failed","hook":"publish","message":"broker unavailable"}]` {
+		t.Errorf("unexpected JSON %s, %v", data, err)
+	}
+
+	order.State = "checkout"
+	if err := strict.Trigger("pay", order); !errors.Is(err, errPublish) {
+		t.Errorf("hooks should fail the trigger unless best-effort, got %v", err)
+	}
+}
@@ -0,0 +1,125 @@
+package transition
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrDynamicDestination is returned by WhatIf when the matched transition goes to the state returned by a ToFunc
+var ErrDynamicDestination = errors.New("dynamic destination")
+
+// WhatIfOption configure WhatIf
+type WhatIfOption func(*whatIfConfig)
+
+type whatIfConfig struct {
+	guards map[TransitionInfo]bool
+}
+
+// ForceGuards decide the result of the guards of transitions, keyed by event and destination, From is ignored.
+// Guarded transitions missing from results are assumed to accept
+func ForceGuards(results map[TransitionInfo]bool) WhatIfOption {
+	return func(config *whatIfConfig) {
+		config.guards = results
+	}
+}
+
+// WhatIfStep is an event walked by WhatIf, To is empty and Err set when the event couldn't be performed
+type WhatIfStep struct {
+	Event string
+	From  string
+	To    string
+	Err   error
+}
+
+// WhatIfResult describe the walk performed by WhatIf
+type WhatIfResult struct {
+	Start string
+	// Steps holds the performed events, and the failed one last if any
+	Steps []WhatIfStep
+}
+
+// State returns the state reached by the last performed event, or the start state
+func (result WhatIfResult) State() string {
+	state := result.Start
+	for _, step := range result.Steps {
+		if step.Err == nil {
+			state = step.To
+		}
+	}
+	return state
+}
+
+// Table renders the steps as a markdown table, e.g. to paste into a ticket
+func (result WhatIfResult) Table() string {
+	var builder strings.Builder
+	builder.WriteString("| # | Event | From | To | Result |\n|---|---|---|---|---|\n")
+	for i, step := range result.Steps {
+		outcome := "ok"
+		if step.Err != nil {
+			outcome = step.Err.Error()
+		}
+		fmt.Fprintf(&builder, "| %d | %s | %s | %s | %s |\n", i+1, step.Event, step.From, step.To, outcome)
+	}
+	return builder.String()
+}
+
+// WhatIf walks events through the definition from state start, or the initial state when empty, and returns the
+// state after each event until one can't be performed. Matching is purely structural: no value is needed, hooks
+// never run and guards are assumed to accept unless forced with ForceGuards. The returned error is the one of the
+// failed step
+func (sm *StateMachine[T]) WhatIf(start string, events []string, opts ...WhatIfOption) (WhatIfResult, error) {
+	var config whatIfConfig
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	if start == "" {
+		start = sm.initialState
+	}
+	result := WhatIfResult{Start: start}
+	if _, ok := sm.states[start]; !ok && start != sm.initialState {
+		return result, fmt.Errorf("start state %s: %w", start, ErrUndeclaredState)
+	}
+
+	state := start
+	for _, name := range events {
+		step := WhatIfStep{Event: name, From: state}
+		step.To, step.Err = sm.whatIfStep(config, name, state)
+		if step.Err != nil {
+			step.To = ""
+		}
+		result.Steps = append(result.Steps, step)
+		if step.Err != nil {
+			return result, fmt.Errorf("step %d: %w", len(result.Steps), step.Err)
+		}
+		state = step.To
+	}
+	return result, nil
+}
+
+// whatIfStep returns the destination of event from state, matching transitions structurally
+func (sm *StateMachine[T]) whatIfStep(config whatIfConfig, name string, state string) (string, error) {
+	event, ok := sm.events[name]
+	if !ok {
+		return "", ErrUnknownEvent
+	}
+
+	var matched []*EventTransition[T]
+	for _, transition := range event.matchTransitions(state) {
+		if accept, forced := config.guards[TransitionInfo{Event: name, To: transition.to}]; forced && len(transition.guards) > 0 && !accept {
+			continue
+		}
+		matched = append(matched, transition)
+	}
+
+	switch {
+	case len(matched) == 0:
+		return "", ErrNoMatchingTransition
+	case len(matched) > 1:
+		return "", ErrAmbiguousTransition
+	case matched[0].toFunc != nil:
+		return "", ErrDynamicDestination
+	}
+	return matched[0].to, nil
+}
@@ -0,0 +1,67 @@
+package transition
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func getWhatIfStateMachine() *StateMachine[*Order] {
+	orderStateMachine := getSimulationStateMachine()
+	orderStateMachine.Event("reopen").To("checkout").From("paid_cancelled", "cancelled")
+	orderStateMachine.Event("pay").To("paid").From("checkout").Guard(func(ctx context.Context, order *Order) error {
+		return errors.New("should never be called")
+	})
+	return orderStateMachine
+}
+
+func TestWhatIf(t *testing.T) {
+	orderStateMachine := getWhatIfStateMachine()
+
+	result, err := orderStateMachine.WhatIf("paid", []string{"cancel", "reopen", "pay"})
+	if err != nil {
+		t.Fatalf("no error expected, got %v", err)
+	}
+	if result.State() != "paid" {
+		t.Errorf("expected to end up paid, got %s", result.State())
+	}
+
+	expected := "| # | Event | From | To | Result |\n" +
+		"|---|---|---|---|---|\n" +
+		"| 1 | cancel | paid | paid_cancelled | ok |\n" +
+		"| 2 | reopen | paid_cancelled | checkout | ok |\n" +
+		"| 3 | pay | checkout | paid | ok |\n"
+	if table := result.Table(); table != expected {
+		t.Errorf("unexpected table\n%s", table)
+	}
+}
+
+func TestWhatIfFailures(t *testing.T) {
+	orderStateMachine := getWhatIfStateMachine()
+
+	result, err := orderStateMachine.WhatIf("", []string{"checkout", "process", "pay"})
+	if !errors.Is(err, ErrNoMatchingTransition) || err.Error() != "step 2: no matching transition" {
+		t.Errorf("expected no matching transition at step 2, got %v", err)
+	}
+	if len(result.Steps) != 2 || result.Steps[1].Err == nil || result.State() != "checkout" {
+		t.Errorf("walk should stop at the failed step, got %+v", result)
+	}
+
+	_, err = orderStateMachine.WhatIf("checkout", []string{"pay"}, ForceGuards(map[TransitionInfo]bool{{Event: "pay", To: "paid"}: false}))
+	if !errors.Is(err, ErrNoMatchingTransition) {
+		t.Errorf("forced guard should reject the transition, got %v", err)
+	}
+
+	if _, err = orderStateMachine.WhatIf("draft", []string{"refund"}); !errors.Is(err, ErrUnknownEvent) {
+		t.Errorf("expected unknown event, got %v", err)
+	}
+
+	if _, err = orderStateMachine.WhatIf("archived", nil); !errors.Is(err, ErrUndeclaredState) {
+		t.Errorf("expected undeclared start state, got %v", err)
+	}
+
+	orderStateMachine.Event("route").ToFunc(func(order *Order) (string, error) { return "paid", nil }).From("checkout")
+	if _, err = orderStateMachine.WhatIf("checkout", []string{"route"}); !errors.Is(err, ErrDynamicDestination) {
+		t.Errorf("expected dynamic destination, got %v", err)
+	}
+}
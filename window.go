@@ -0,0 +1,149 @@
+package transition
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrOutsideWindow is returned by time window guards rejecting a transition, see During
+var ErrOutsideWindow = errors.New("outside time window")
+
+// TimeWindow is a recurring window of time, e.g. operating hours: from Start to End each day, measured from midnight
+// wall clock time in Location. Windows with an End before their Start span midnight (22:00–06:00) and belong to the
+// day they start. Windows with equal Start and End last the whole day. Days restricts the window to some days of the
+// week, all days when empty. Without Location, times are compared in the location of the clock
+type TimeWindow struct {
+	Start    time.Duration
+	End      time.Duration
+	Days     []time.Weekday
+	Location *time.Location
+}
+
+// Contains returns whether t is in the window
+func (window TimeWindow) Contains(t time.Time) bool {
+	if window.Location != nil {
+		t = t.In(window.Location)
+	}
+
+	var (
+		clock = time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+		day   = t.Weekday()
+	)
+	switch {
+	case window.Start == window.End:
+	case window.Start < window.End:
+		if clock < window.Start || clock >= window.End {
+			return false
+		}
+	case clock < window.End:
+		// the morning part of an overnight window belongs to the day before
+		day = (day + 6) % 7
+	case clock < window.Start:
+		return false
+	}
+
+	if len(window.Days) == 0 {
+		return true
+	}
+	for _, allowed := range window.Days {
+		if allowed == day {
+			return true
+		}
+	}
+	return false
+}
+
+// String describes the window, e.g. "Mon–Fri 08:00–18:00 Europe/Berlin"
+func (window TimeWindow) String() string {
+	var parts []string
+	if len(window.Days) > 0 {
+		parts = append(parts, formatWeekdays(window.Days))
+	}
+	if window.Start != window.End {
+		parts = append(parts, formatClock(window.Start)+"–"+formatClock(window.End))
+	}
+	if len(parts) == 0 {
+		parts = append(parts, "at any time")
+	}
+	if window.Location != nil {
+		parts = append(parts, window.Location.String())
+	}
+	return strings.Join(parts, " ")
+}
+
+// During is a guard accepting values when the clock is in window, rejecting them with an error wrapping
+// ErrOutsideWindow that tells when the transition is allowed, e.g.
+// "allowed Mon–Fri 08:00–18:00 Europe/Berlin; current time is Sat 02:13"
+func During[T Stater](window TimeWindow) Guard[T] {
+	return func(ctx context.Context, value T) error {
+		now := ClockFromContext(ctx).Now()
+		if window.Contains(now) {
+			return nil
+		}
+		if window.Location != nil {
+			now = now.In(window.Location)
+		}
+		return fmt.Errorf("%w: allowed %s; current time is %s", ErrOutsideWindow, window, now.Format("Mon 15:04"))
+	}
+}
+
+// DuringHours is a guard accepting values from start to end each day in loc, e.g. 8*time.Hour to 18*time.Hour,
+// see TimeWindow
+func DuringHours[T Stater](start, end time.Duration, loc *time.Location) Guard[T] {
+	return During[T](TimeWindow{Start: start, End: end, Location: loc})
+}
+
+// OnWeekdays is a guard accepting values on days, in the location of the clock. Combine DuringHours and OnWeekdays
+// in a TimeWindow given to During to also pick the location of the days
+func OnWeekdays[T Stater](days ...time.Weekday) Guard[T] {
+	return During[T](TimeWindow{Days: days})
+}
+
+// NotDuring is a guard rejecting values when blackout reports the clock's time is in a blackout period, e.g. a
+// maintenance window given by TimeWindow.Contains
+func NotDuring[T Stater](blackout func(now time.Time) bool) Guard[T] {
+	return func(ctx context.Context, value T) error {
+		if now := ClockFromContext(ctx).Now(); blackout(now) {
+			return fmt.Errorf("%w: not allowed at %s", ErrOutsideWindow, now.Format("Mon 15:04"))
+		}
+		return nil
+	}
+}
+
+// formatClock formats a duration since midnight as hh:mm
+func formatClock(d time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(d/time.Hour), int(d%time.Hour/time.Minute))
+}
+
+// formatWeekdays lists days from Monday, runs of three days or more as ranges, e.g. "Mon–Fri" or "Mon, Wed"
+func formatWeekdays(days []time.Weekday) string {
+	var week [7]bool
+	for _, day := range days {
+		week[(day+6)%7] = true
+	}
+
+	var parts []string
+	for start := 0; start < 7; start++ {
+		if !week[start] {
+			continue
+		}
+		end := start
+		for end+1 < 7 && week[end+1] {
+			end++
+		}
+		name := func(i int) string { return time.Weekday((i + 1) % 7).String()[:3] }
+		switch {
+		case end-start >= 2:
+			parts = append(parts, name(start)+"–"+name(end))
+		case end > start:
+			parts = append(parts, name(start), name(end))
+		default:
+			parts = append(parts, name(start))
+		}
+		start = end
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,91 @@
+package transition
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimeWindow(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("time zone database unavailable")
+	}
+	var (
+		weekdays  = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+		office    = TimeWindow{Start: 8 * time.Hour, End: 18 * time.Hour, Days: weekdays, Location: berlin}
+		overnight = TimeWindow{Start: 22 * time.Hour, End: 6 * time.Hour, Days: []time.Weekday{time.Friday}, Location: berlin}
+		utc       = func(value string) time.Time {
+			at, _ := time.Parse(time.RFC3339, value)
+			return at
+		}
+	)
+
+	cases := []struct {
+		name     string
+		window   TimeWindow
+		at       string
+		contains bool
+	}{
+		{"opening", office, "2024-03-04T07:00:00Z", true},
+		{"before opening", office, "2024-03-04T06:59:00Z", false},
+		{"closing", office, "2024-03-04T17:00:00Z", false},
+		{"week-end", office, "2024-03-09T10:00:00Z", false},
+		// Berlin switches to summer time on 2024-03-31 at 02:00, opening hours follow the wall clock
+		{"summer time opening", office, "2024-04-01T06:00:00Z", true},
+		{"summer time before opening", office, "2024-04-01T05:59:00Z", false},
+		{"overnight start", overnight, "2024-03-08T21:00:00Z", true},
+		{"overnight after midnight", overnight, "2024-03-09T04:59:00Z", true},
+		{"overnight end", overnight, "2024-03-09T05:00:00Z", false},
+		{"overnight started the day before", overnight, "2024-03-08T03:00:00Z", false},
+		// Berlin switches back to winter time on 2024-10-27 at 03:00, 02:30 happens twice
+		{"overnight winter time", TimeWindow{Start: 22 * time.Hour, End: 6 * time.Hour, Location: berlin}, "2024-10-27T04:59:00Z", true},
+		{"overnight winter time end", TimeWindow{Start: 22 * time.Hour, End: 6 * time.Hour, Location: berlin}, "2024-10-27T05:00:00Z", false},
+		{"repeated hour", TimeWindow{Start: 2 * time.Hour, End: 3 * time.Hour, Location: berlin}, "2024-10-27T01:30:00Z", true},
+		{"whole days", TimeWindow{Days: []time.Weekday{time.Saturday}}, "2024-03-09T23:59:00Z", true},
+	}
+	for _, c := range cases {
+		if got := c.window.Contains(utc(c.at)); got != c.contains {
+			t.Errorf("%s: %s in %s should be %t", c.name, c.at, c.window, c.contains)
+		}
+	}
+
+	if got := overnight.String(); got != "Fri 22:00–06:00 Europe/Berlin" {
+		t.Errorf("unexpected window description %s", got)
+	}
+	if got := formatWeekdays([]time.Weekday{time.Sunday, time.Monday, time.Wednesday, time.Saturday}); got != "Mon, Wed, Sat, Sun" {
+		t.Errorf("unexpected weekdays %s", got)
+	}
+}
+
+func TestDuring(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("time zone database unavailable")
+	}
+	var (
+		clock             = &manualClock{now: time.Date(2024, 3, 9, 2, 13, 0, 0, berlin)}
+		orderStateMachine = New(&Order{}, WithClock(clock))
+		weekdays          = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+	)
+	orderStateMachine.Initial("draft")
+	orderStateMachine.Event("ship").To("shipped").From("draft").
+		Guard(During[*Order](TimeWindow{Start: 8 * time.Hour, End: 18 * time.Hour, Days: weekdays, Location: berlin}))
+	orderStateMachine.Event("deliver").To("delivered").From("draft").Guard(DuringHours[*Order](8*time.Hour, 18*time.Hour, berlin), OnWeekdays[*Order](weekdays...))
+	orderStateMachine.Event("cancel").To("cancelled").From("draft").Guard(NotDuring[*Order](TimeWindow{Start: 2 * time.Hour, End: 3 * time.Hour}.Contains))
+
+	order := &Order{}
+	expected := "ship from draft to shipped is rejected by a guard: outside time window: allowed Mon–Fri 08:00–18:00 Europe/Berlin; current time is Sat 02:13"
+	if got := orderStateMachine.Explain("ship", order).String(); got != expected {
+		t.Errorf("unexpected explanation %s", got)
+	}
+	if err := orderStateMachine.Trigger("cancel", order); !errors.Is(err, ErrOutsideWindow) || !strings.Contains(err.Error(), "not allowed at Sat 02:13") {
+		t.Errorf("should be rejected during the blackout, got %v", err)
+	}
+
+	clock.now = time.Date(2024, 3, 11, 9, 0, 0, 0, berlin)
+	if !orderStateMachine.Can("ship", order) || !orderStateMachine.Can("deliver", order) || !orderStateMachine.Can("cancel", order) {
+		t.Errorf("should be allowed on Monday morning")
+	}
+}